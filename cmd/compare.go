@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"inspektor/internal/analyzer"
+	"inspektor/internal/display"
+	"inspektor/internal/inspector"
+
+	"github.com/spf13/cobra"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <pid1> <pid2>",
+	Short: "Compare two running processes side by side",
+	Long: `Inspects two processes at the same time, e.g. the old and new instance of a
+deployment, and shows their metrics side by side with the difference of
+each (second minus first), the worse of each colored red, plus a verdict
+and a short commentary on which looks healthier.`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= 2 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return inspector.CompletePIDs(), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		provider, _ := cmd.Flags().GetString("provider")
+		aiModel, _ := cmd.Flags().GetString("ai-model")
+		cpuInterval, _ := cmd.Flags().GetDuration("cpu-interval")
+		noBanner, _ := cmd.Flags().GetBool("no-banner")
+
+		if noColor {
+			display.DisableColor()
+		}
+
+		var pids [2]int32
+		for idx, arg := range args {
+			pid, err := parsePID(arg)
+			if err != nil {
+				return err
+			}
+			pids[idx] = pid
+		}
+
+		secrets, err := redactor(cmd)
+		if err != nil {
+			return err
+		}
+		thresholds, err := diffThresholds(cmd)
+		if err != nil {
+			return err
+		}
+		disableAI, err := noAI(cmd)
+		if err != nil {
+			return err
+		}
+		envPath, err := envFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		insp := inspector.New(analyzer.Config{Provider: provider, Model: aiModel, NoAI: disableAI, EnvFile: envPath})
+		defer closeInspector(insp)
+		display.HandleInterrupts(func() { closeInspector(insp) })
+
+		if err := insp.Compare(pids[0], pids[1], inspector.Options{JSON: jsonOutput, CPUInterval: cpuInterval, DiffThresholds: thresholds, NoBanner: noBanner, Redactor: secrets}); err != nil {
+			return fmt.Errorf("comparing processes: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	compareCmd.Flags().BoolP("json", "j", false, "Output both inspections and their difference as JSON")
+	compareCmd.Flags().Bool("no-color", false, "Disable colored output")
+	compareCmd.Flags().String("provider", "", "AI providers to try in order, e.g. gemini,openai,rules (default: whichever is configured)")
+	compareCmd.Flags().String("ai-model", "", "Model name for the AI provider")
+	compareCmd.Flags().String("diff-threshold", "", "Smallest difference not shown as \"=\": a percentage of the first process's value (5%) or an amount in the metric's unit, for all metrics or per metric (cpu_percent=2,memory_rss=10%)")
+	compareCmd.Flags().Duration("cpu-interval", inspector.DefaultCPUInterval, "Sampling window for process CPU usage (0 = lifetime average)")
+	rootCmd.AddCommand(compareCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"inspektor/internal/analyzer"
+	"inspektor/internal/display"
+	"inspektor/internal/inspector"
+	"inspektor/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// registerCompletions wires dynamic shell completion for the PID argument and
+// the flags whose values can be listed. Cobra provides the `completion`
+// subcommand itself; these only supply the candidates.
+func registerCompletions() {
+	rootCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return inspector.CompletePIDs(), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	dynamic := map[string]func() []string{
+		"port": inspector.CompleteListeningPorts,
+		"name": inspector.CompleteProcessNames,
+		"user": inspector.CompleteUsers,
+	}
+	for flag, list := range dynamic {
+		_ = rootCmd.RegisterFlagCompletionFunc(flag, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return list(), cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+
+	fixed := map[string][]string{
+		"format":          {"text", "json", "json-compact", "jsonl", "markdown", "table", "csv", "github"},
+		"sort-by":         display.SortColumns,
+		"proto":           {"tcp", "udp"},
+		"min-severity":    {string(models.SeverityInfo), string(models.SeverityWarning), string(models.SeverityCritical)},
+		"fail-on":         failOnNames(),
+		"units":           {string(display.UnitsBinary), string(display.UnitsSI), string(display.UnitsRaw)},
+		"spinner":         {string(display.SpinnerDots), string(display.SpinnerLine), string(display.SpinnerArrow), string(display.SpinnerNone)},
+		"cpu-mode":        {string(models.CPUModeRaw), string(models.CPUModeNormalized)},
+		"mode":            analyzer.Modes,
+		"warnings-to":     {"stdout", "stderr"},
+		"warnings-format": {"text", "jsonl"},
+	}
+	for flag, values := range fixed {
+		_ = rootCmd.RegisterFlagCompletionFunc(flag, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"inspektor/internal/analyzer"
+	"inspektor/internal/inspector"
+
+	"github.com/spf13/cobra"
+)
+
+var probeCmd = &cobra.Command{
+	Use:   "probe [PID]",
+	Short: "Exit non-zero unless a process is up and within limits, for container probes",
+	Long: `Checks that a process exists, is running or sleeping (not a zombie or
+stopped) and stays within the given limits, then exits 0 if so and 1
+otherwise. Nothing is printed on stdout unless --verbose is set; the reason
+for a failure goes to stderr. Built to be a Kubernetes readiness or liveness
+probe: only the metrics a limit is set for are read, no AI is involved, and
+--timeout fails the probe instead of letting a hung collection hang the pod.
+
+With --name every matching process must pass.`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 || cmd.Flags().Changed("port") || cmd.Flags().Changed("name") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return inspector.CompletePIDs(), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, _ := cmd.Flags().GetInt("port")
+		name, _ := cmd.Flags().GetString("name")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		cpuInterval, _ := cmd.Flags().GetDuration("cpu-interval")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		var limits inspector.ProbeLimits
+		limits.CPUPercent, _ = cmd.Flags().GetFloat64("max-cpu")
+		limits.MemoryPercent, _ = cmd.Flags().GetFloat32("max-memory")
+		limits.MemoryMB, _ = cmd.Flags().GetUint64("max-memory-mb")
+		limits.OpenFiles, _ = cmd.Flags().GetInt("max-open-files")
+		limits.Threads, _ = cmd.Flags().GetInt("max-threads")
+
+		var target string
+		switch {
+		case len(args) == 1 && (port > 0 || name != ""), port > 0 && name != "":
+			return errors.New("probe one target: a PID, --port or --name")
+		case len(args) == 1:
+			pid, err := parsePID(args[0])
+			if err != nil {
+				return err
+			}
+			target = fmt.Sprintf("pid=%d", pid)
+		case port > 0:
+			target = fmt.Sprintf("port=%d", port)
+		case name != "":
+			target = "name=" + name
+		default:
+			return errors.New("nothing to probe: give a PID, --port or --name")
+		}
+		if timeout <= 0 {
+			return errors.New("--timeout must be positive")
+		}
+		if limits.CPUPercent > 0 && cpuInterval <= 0 {
+			return errors.New("--max-cpu needs a positive --cpu-interval")
+		}
+
+		insp := inspector.New(analyzer.Config{NoAI: true})
+		defer closeInspector(insp)
+
+		return insp.Probe(target, limits, inspector.Options{Timeout: timeout, CPUInterval: cpuInterval, Verbose: verbose})
+	},
+}
+
+func init() {
+	probeCmd.Flags().Int("port", 0, "Probe the process listening on this port")
+	probeCmd.Flags().String("name", "", "Probe every process with this name")
+	probeCmd.Flags().Duration("timeout", 5*time.Second, "Fail the probe if it takes longer than this; keep it below the probe's timeoutSeconds")
+	probeCmd.Flags().Duration("cpu-interval", 200*time.Millisecond, "Sampling window for --max-cpu")
+	probeCmd.Flags().Float64("max-cpu", 0, "Fail above this CPU usage, in percent of one core (0 = not checked)")
+	probeCmd.Flags().Float32("max-memory", 0, "Fail above this share of system memory, in percent (0 = not checked)")
+	probeCmd.Flags().Uint64("max-memory-mb", 0, "Fail above this resident memory, in MB (0 = not checked)")
+	probeCmd.Flags().Int("max-open-files", 0, "Fail above this many open files (0 = not checked)")
+	probeCmd.Flags().Int("max-threads", 0, "Fail above this many threads (0 = not checked)")
+	probeCmd.Flags().BoolP("verbose", "v", false, "Print \"healthy\" or \"unhealthy: <reason>\" on stdout")
+	rootCmd.AddCommand(probeCmd)
+}
@@ -1,17 +1,38 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
+	"inspektor/internal/analyzer"
+	"inspektor/internal/baseline"
+	"inspektor/internal/display"
 	"inspektor/internal/inspector"
+	"inspektor/internal/models"
+	"inspektor/internal/redact"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	portFlag int
+	portFlag   int
+	waitPort   int
+	nameFlag   string
+	stdinFlag  bool
+	unitFlag   string
+	cgroupFlag string
+	userFlag   string
+	systemFlag bool
+	topFlag    int
+	snapshot   string
 )
 
 var rootCmd = &cobra.Command{
@@ -22,51 +43,851 @@ providing detailed insights and AI-generated warnings about system health.
 
 You can inspect a process by:
   - PID: inspektor 1234
-  - Port: inspektor --port 8080`,
+  - Port: inspektor --port 8080
+  - Name: inspektor --name nginx
+  - Systemd unit: inspektor --unit nginx.service
+  - Cgroup: inspektor --cgroup /kubepods.slice/kubepods-pod1234.slice
+  - User: inspektor --user deploy --format table
+  - Stdin: pgrep nginx | inspektor --stdin --json
+
+Or get a host overview with no process: inspektor --system
+Or rank the heaviest processes on the host: inspektor --top-n 5
+Or analyze an inspection saved with --json: inspektor --from-snapshot crash.json
+Or inspect a process on another host: inspektor --ssh user@host 1234`,
+	// main reports the error; usage is only shown for bad arguments, not
+	// for failures once the command runs
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		// Without --ascii either way, a non-UTF-8 locale picks ASCII
+		ascii, _ := cmd.Flags().GetBool("ascii")
+		if ascii || (!cmd.Flags().Changed("ascii") && !display.LocaleSupportsUnicode()) {
+			display.UseASCII()
+		}
+		style, _ := cmd.Flags().GetString("spinner")
+		return display.SetSpinner(display.SpinnerStyle(style))
+	},
 	Args: func(cmd *cobra.Command, args []string) error {
-		// If port flag is set, no args needed
-		if portFlag > 0 {
+		// If a selector flag is set, no args needed
+		if hasSelector() {
 			return nil
 		}
 		// Otherwise, require exactly one PID argument
 		if len(args) != 1 {
-			return fmt.Errorf("requires either a PID argument or --port flag")
+			return fmt.Errorf("requires either a PID argument or one of --port, --wait-for-port, --name, --unit, --cgroup, --user, --stdin, --system, --top-n")
 		}
 		return nil
 	},
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		jsonOutput, _ := cmd.Flags().GetBool("json")
 		verbose, _ := cmd.Flags().GetBool("verbose")
+		tree, _ := cmd.Flags().GetBool("tree")
+		treeDepth, _ := cmd.Flags().GetInt("tree-depth")
+		threads, _ := cmd.Flags().GetBool("threads")
+		threadsTop, _ := cmd.Flags().GetInt("threads-top")
+		tid, _ := cmd.Flags().GetInt32("tid")
+		includeChildren, _ := cmd.Flags().GetBool("include-children")
+		followChildren, _ := cmd.Flags().GetBool("follow-children")
+		all, _ := cmd.Flags().GetBool("all")
+		explain, _ := cmd.Flags().GetBool("explain")
+		explainHealth, _ := cmd.Flags().GetBool("explain-health")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		allFindings, _ := cmd.Flags().GetBool("all-findings")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		timeFormat, _ := cmd.Flags().GetString("time-format")
+		utc, _ := cmd.Flags().GetBool("utc")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		strict, _ := cmd.Flags().GetBool("strict")
+		noBanner, _ := cmd.Flags().GetBool("no-banner")
+		cpuInterval, _ := cmd.Flags().GetDuration("cpu-interval")
+		trend, _ := cmd.Flags().GetBool("trend")
+		power, _ := cmd.Flags().GetBool("power")
+		systemSamples, _ := cmd.Flags().GetInt("system-samples")
+		detailLimit, _ := cmd.Flags().GetInt("detail-limit")
+		forceDetail, _ := cmd.Flags().GetBool("force-detail")
+		hashBinary, _ := cmd.Flags().GetBool("hash-binary")
+		proto, _ := cmd.Flags().GetString("proto")
+		bindAddress, _ := cmd.Flags().GetString("bind")
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		adaptive, _ := cmd.Flags().GetBool("interval-adaptive")
+		maxInterval, _ := cmd.Flags().GetDuration("interval-max")
+		repeat, _ := cmd.Flags().GetInt("repeat")
+		watchUntil, _ := cmd.Flags().GetString("watch-until")
+		watchTimeout, _ := cmd.Flags().GetDuration("watch-timeout")
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		units, _ := cmd.Flags().GetString("units")
+		cpuMode, _ := cmd.Flags().GetString("cpu-mode")
+		width, _ := cmd.Flags().GetInt("width")
+		borderless, _ := cmd.Flags().GetBool("borderless")
+		format, _ := cmd.Flags().GetString("format")
+		sortBy, _ := cmd.Flags().GetString("sort-by")
+		onWarning, _ := cmd.Flags().GetString("on-warning")
+		auditLog, _ := cmd.Flags().GetString("audit-log")
+		if auditLog == "" {
+			auditLog = os.Getenv("INSPEKTOR_AUDIT_LOG")
+		}
+		docker, _ := cmd.Flags().GetBool("docker")
+		resolve, _ := cmd.Flags().GetBool("resolve")
+		minSeverity, _ := cmd.Flags().GetString("min-severity")
+		failOn, _ := cmd.Flags().GetStringSlice("fail-on")
+		metricOnly, _ := cmd.Flags().GetString("metric-only")
+		sampleOutput, _ := cmd.Flags().GetBool("sample-output")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		batchAnalysis, _ := cmd.Flags().GetBool("batch-analysis")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		includeSelf, _ := cmd.Flags().GetBool("include-self")
+		noKernelThreads, _ := cmd.Flags().GetBool("no-kernel-threads")
+		exact, _ := cmd.Flags().GetBool("exact")
+		withTop, _ := cmd.Flags().GetBool("with-top")
+		warningsTo, _ := cmd.Flags().GetString("warnings-to")
+		warningsFormat, _ := cmd.Flags().GetString("warnings-format")
+		sshTarget, _ := cmd.Flags().GetString("ssh")
+
+		if noColor {
+			display.DisableColor()
+		}
+		if err := display.SetByteUnits(display.ByteUnits(units)); err != nil {
+			return fmt.Errorf("invalid --units %q (expected binary, si or raw)", units)
+		}
+
+		// --json is shorthand for --format json
+		if jsonOutput && format == "text" {
+			format = "json"
+		}
+		switch format {
+		case "text", "json", "json-compact", "jsonl", "markdown", "table", "csv", "github":
+		default:
+			return fmt.Errorf("invalid --format %q (expected text, json, json-compact, jsonl, markdown, table, csv or github)", format)
+		}
+		if (format == "markdown" || format == "table" || format == "csv") && systemFlag {
+			return fmt.Errorf("--format %s is not supported with --system", format)
+		}
+		if (format == "markdown" || format == "table") && topFlag > 0 {
+			return fmt.Errorf("--format %s is not supported with --top-n", format)
+		}
+		if format == "csv" && (watch || repeat > 0 || watchUntil != "") {
+			return errors.New("--format csv cannot be combined with --watch, --repeat or --watch-until")
+		}
+		if !slices.Contains(display.SortColumns, sortBy) {
+			if _, err := models.ParseScoreExpr(sortBy); err != nil {
+				return fmt.Errorf("invalid --sort-by %q (expected one of %s, or a weighted sum such as cpu*2+mem): %w", sortBy, strings.Join(display.SortColumns, ", "), err)
+			}
+		}
+
+		if !models.CPUMode(cpuMode).Valid() {
+			return fmt.Errorf("invalid --cpu-mode %q (expected raw or normalized)", cpuMode)
+		}
+		if systemSamples < 1 {
+			return errors.New("--system-samples must be at least 1")
+		}
+		if treeDepth < 0 {
+			return errors.New("--tree-depth cannot be negative")
+		}
+		if detailLimit < 0 {
+			return errors.New("--detail-limit cannot be negative (0 disables the limit)")
+		}
+		if !models.Severity(minSeverity).Valid() {
+			return fmt.Errorf("invalid --min-severity %q (expected info, warning or critical)", minSeverity)
+		}
+		for _, name := range failOn {
+			if !slices.Contains(failOnNames(), name) {
+				return fmt.Errorf("invalid --fail-on %q (expected a category or rule: %s)", name, strings.Join(failOnNames(), ", "))
+			}
+		}
+
+		if sampleOutput && format != "json" && format != "json-compact" && format != "jsonl" {
+			return errors.New("--sample-output only applies to JSON output (--json or --format json-compact or jsonl)")
+		}
+		if sampleOutput && !watch && repeat == 0 && watchUntil == "" {
+			return errors.New("--sample-output needs a series: use it with --watch, --repeat or --watch-until")
+		}
+		if outputDir != "" {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("--output-dir writes JSON files and cannot be combined with --format %s", format)
+			}
+			if nameFlag == "" && !stdinFlag && cgroupFlag == "" && userFlag == "" && !(all && (unitFlag != "" || portFlag > 0 || waitPort > 0)) {
+				return errors.New("--output-dir needs several processes: use it with --name, --stdin, --cgroup, --user or --all with --unit or --port")
+			}
+			if watch || repeat > 0 || watchUntil != "" {
+				return errors.New("--output-dir cannot be combined with --watch, --repeat or --watch-until")
+			}
+		}
+		if adaptive && !watch && repeat == 0 && watchUntil == "" {
+			return errors.New("--interval-adaptive varies the time between samples: use it with --watch, --repeat or --watch-until")
+		}
+		if cmd.Flags().Changed("interval-max") && !adaptive {
+			return errors.New("--interval-max only applies with --interval-adaptive")
+		}
+		if adaptive && maxInterval < interval {
+			return fmt.Errorf("--interval-max (%s) must not be below --interval (%s)", maxInterval, interval)
+		}
+		if sshTarget != "" {
+			if nameFlag != "" || stdinFlag || cgroupFlag != "" || userFlag != "" || systemFlag || topFlag > 0 || snapshot != "" || waitPort > 0 || all || tid > 0 {
+				return errors.New("--ssh inspects one remote process: use it with a PID, --port or --unit")
+			}
+			if watch || repeat > 0 || watchUntil != "" || outputDir != "" || withTop || tree {
+				return errors.New("--ssh takes a single sample and cannot be combined with --watch, --repeat, --watch-until, --output-dir, --with-top or --tree")
+			}
+		} else if cmd.Flags().Changed("ssh-inspektor") || cmd.Flags().Changed("ssh-timeout") {
+			return errors.New("--ssh-inspektor and --ssh-timeout only apply with --ssh")
+		}
+		if followChildren && !watch && repeat == 0 && watchUntil == "" {
+			return errors.New("--follow-children tracks children between samples: use it with --watch, --repeat or --watch-until")
+		}
+		if groupBy != "" && groupBy != display.GroupByCategory {
+			return fmt.Errorf("invalid --group-by %q (expected %s)", groupBy, display.GroupByCategory)
+		}
+		if threadsTop < 1 {
+			return errors.New("--threads-top must be at least 1")
+		}
+		if !threads {
+			threadsTop = 0
+		}
+		if cmd.Flags().Changed("tid") {
+			if tid <= 0 {
+				return errors.New("--tid must be a positive thread ID")
+			}
+			if hasSelector() {
+				return errors.New("--tid inspects a thread of one process: give its PID as the argument")
+			}
+			if format != "text" && format != "json" && format != "json-compact" && format != "jsonl" {
+				return fmt.Errorf("--format %s is not supported with --tid", format)
+			}
+			if watch || repeat > 0 || watchUntil != "" || tree || threads || metricOnly != "" || withTop {
+				return errors.New("--tid prints a single thread report and cannot be combined with watch modes, --tree, --threads, --metric-only or --with-top")
+			}
+		}
+		if concurrency < 1 {
+			return errors.New("--concurrency must be at least 1")
+		}
+		if batchAnalysis && nameFlag == "" && !stdinFlag && cgroupFlag == "" && userFlag == "" && !(all && (unitFlag != "" || portFlag > 0 || waitPort > 0)) {
+			return errors.New("--batch-analysis needs several processes: use it with --name, --stdin, --cgroup, --user or --all with --unit or --port")
+		}
+		var warnings io.Writer
+		switch warningsTo {
+		case "stdout":
+			if cmd.Flags().Changed("warnings-format") {
+				return errors.New("--warnings-format needs --warnings-to stderr")
+			}
+		case "stderr":
+			warnings = os.Stderr
+		default:
+			return fmt.Errorf("invalid --warnings-to %q (expected stdout or stderr)", warningsTo)
+		}
+		if warningsFormat != "text" && warningsFormat != "jsonl" {
+			return fmt.Errorf("invalid --warnings-format %q (expected text or jsonl)", warningsFormat)
+		}
+		if warnings != nil && format == "table" {
+			return errors.New("--warnings-to stderr cannot be combined with --format table, whose rows count the findings")
+		}
+		if warnings != nil && format == "github" {
+			return errors.New("--warnings-to stderr cannot be combined with --format github, which prints only the findings")
+		}
+		if withTop && (nameFlag != "" || stdinFlag || cgroupFlag != "" || userFlag != "" || systemFlag || topFlag > 0 || (all && (unitFlag != "" || portFlag > 0 || waitPort > 0))) {
+			return errors.New("--with-top adds to a single-process report: use it with a PID, --port, --wait-for-port or --unit")
+		}
+		if metricOnly != "" {
+			if !models.ValidMetric(metricOnly) {
+				return fmt.Errorf("invalid --metric-only %q (expected a field of the JSON output, e.g. cpu_percent, memory_rss or system.cpu_usage)", metricOnly)
+			}
+			if cmd.Flags().Changed("format") || jsonOutput {
+				return errors.New("--metric-only cannot be combined with --format or --json")
+			}
+			if watch || repeat > 0 || watchUntil != "" {
+				return errors.New("--metric-only prints a single value and cannot be combined with --watch, --repeat or --watch-until")
+			}
+			if nameFlag != "" || stdinFlag || cgroupFlag != "" || userFlag != "" || systemFlag || topFlag > 0 || (all && (unitFlag != "" || portFlag > 0 || waitPort > 0)) {
+				return errors.New("--metric-only needs a single process: a PID, --port, --wait-for-port, --unit or --from-snapshot")
+			}
+		}
+
+		// A bad PID is reported before any AI client is set up
+		var pid int32
+		if !hasSelector() {
+			var err error
+			if pid, err = parsePID(args[0]); err != nil {
+				return err
+			}
+		}
+
+		secrets, err := redactor(cmd)
+		if err != nil {
+			return err
+		}
+		diffThresholds, err := diffThresholds(cmd)
+		if err != nil {
+			return err
+		}
+		exclude, err := excludePatterns(cmd)
+		if err != nil {
+			return err
+		}
+		var layout *display.Layout
+		if layoutPath, _ := cmd.Flags().GetString("layout"); layoutPath != "" {
+			layout, err = display.LoadLayout(layoutPath)
+			if err != nil {
+				return err
+			}
+		}
+		if (exact || exclude != nil) && nameFlag == "" {
+			return errors.New("--exact and --exclude refine --name: use them with it")
+		}
+		disableAI, err := noAI(cmd)
+		if err != nil {
+			return err
+		}
+		envPath, err := envFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		var until *inspector.Condition
+		if watchUntil != "" {
+			var err error
+			until, err = inspector.ParseCondition(watchUntil)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Quiet runs (e.g. from cron) shouldn't produce diagnostic noise either
+		if quiet {
+			log.SetOutput(io.Discard)
+		}
+
+		var services map[uint32]string
+		if servicesPath, _ := cmd.Flags().GetString("services"); servicesPath != "" {
+			var err error
+			services, err = inspector.LoadServices(servicesPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		var runtimeEnv []string
+		if runtimeEnvPath, _ := cmd.Flags().GetString("runtime-env"); runtimeEnvPath != "" {
+			var err error
+			runtimeEnv, err = inspector.LoadRuntimeEnv(runtimeEnvPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		opts := inspector.Options{
+			JSON:        format == "json" || format == "json-compact" || format == "jsonl",
+			JSONLines:   format == "jsonl",
+			JSONCompact: format == "json-compact",
+			Markdown:    format == "markdown",
+			Table:       format == "table",
+			CSV:         format == "csv",
+			GitHub:      format == "github",
+			SortBy:      sortBy,
+
+			MetricOnly:   metricOnly,
+			SampleOutput: sampleOutput,
+			OutputDir:    outputDir,
+
+			BatchAnalysis: batchAnalysis,
+			Concurrency:   concurrency,
+			IncludeSelf:   includeSelf,
+			NameExact:     exact,
+			Exclude:       exclude,
+			Services:      services,
+			RuntimeEnv:    runtimeEnv,
+			WithTop:       withTop,
+
+			NoKernelThreads: noKernelThreads,
+
+			Warnings:     warnings,
+			WarningsJSON: warningsFormat == "jsonl",
+
+			Verbose:   verbose,
+			Tree:      tree,
+			TreeDepth: treeDepth,
+			Threads:   threadsTop,
+
+			IncludeChildren: includeChildren,
+			FollowChildren:  followChildren,
+
+			Watch:            watch,
+			Interval:         interval,
+			AdaptiveInterval: adaptive,
+			MaxInterval:      maxInterval,
+			Repeat:           repeat,
+
+			Until:        until,
+			WatchTimeout: watchTimeout,
+
+			All:           all,
+			Explain:       explain,
+			ExplainHealth: explainHealth,
+			GroupBy:       groupBy,
+			Quiet:         quiet,
+			Timeout:       timeout,
+			Strict:        strict,
+
+			AllFindings: allFindings,
+
+			NoBanner: noBanner,
+
+			TimeFormat: timeFormat,
+			UTC:        utc,
+
+			Width:      width,
+			Borderless: borderless,
+
+			CPUInterval:    cpuInterval,
+			DetailLimit:    detailLimit,
+			ForceDetail:    forceDetail,
+			HashBinary:     hashBinary,
+			Trend:          trend,
+			Power:          power,
+			CPUMode:        models.CPUMode(cpuMode),
+			DiffThresholds: diffThresholds,
+			Layout:         layout,
+
+			SystemSamples: systemSamples,
+
+			Proto:       proto,
+			BindAddress: bindAddress,
+
+			MinSeverity: models.Severity(minSeverity),
+			FailOn:      failOn,
+			Docker:      docker,
+			Resolve:     resolve,
+			OnWarning:   onWarning,
+			AuditLog:    auditLog,
+			Redactor:    secrets,
+		}
+
+		aiMaxFindings, _ := cmd.Flags().GetInt("ai-max-findings")
+		aiMaxItems, _ := cmd.Flags().GetInt("ai-max-items")
+		aiFailureLimit, _ := cmd.Flags().GetInt("ai-failure-limit")
+		aiRate, _ := cmd.Flags().GetFloat64("ai-rate")
+		if aiRate < 0 {
+			return errors.New("--ai-rate cannot be negative (0 disables the limit)")
+		}
+		aiTimeout, _ := cmd.Flags().GetDuration("ai-timeout")
+		if aiTimeout < 0 {
+			return errors.New("--ai-timeout cannot be negative (0 keeps the provider's default)")
+		}
+		aiJSON, _ := cmd.Flags().GetBool("ai-json")
+		dumpPrompt, _ := cmd.Flags().GetString("dump-prompt")
+		explainAI, _ := cmd.Flags().GetBool("explain-ai")
+		aiTrace, _ := cmd.Flags().GetString("ai-trace")
+		baselineAI, _ := cmd.Flags().GetBool("compare-baseline-ai")
+		provider, _ := cmd.Flags().GetString("provider")
+		mode, _ := cmd.Flags().GetString("mode")
+		if !slices.Contains(analyzer.Modes, mode) {
+			return fmt.Errorf("invalid --mode %q (expected %s)", mode, strings.Join(analyzer.Modes, ", "))
+		}
+		aiModel, _ := cmd.Flags().GetString("ai-model")
+		if mode == analyzer.ModeBoth && disableAI {
+			return errors.New("--mode both needs the AI and cannot be combined with --no-ai")
+		}
+		if aiTrace != "" && (disableAI || dumpPrompt != "" || mode == analyzer.ModeRules) {
+			return errors.New("--ai-trace records exchanges with the AI and cannot be combined with --no-ai, --dump-prompt or --mode rules")
+		}
+		var userBudget analyzer.Budget
+		if budget, _ := cmd.Flags().GetString("user-budget"); budget != "" {
+			if userFlag == "" {
+				return errors.New("--user-budget only applies with --user")
+			}
+			var err error
+			userBudget, err = analyzer.ParseBudget(budget)
+			if err != nil {
+				return err
+			}
+		}
+		closeWaitThreshold, _ := cmd.Flags().GetInt("close-wait-threshold")
+		timeWaitThreshold, _ := cmd.Flags().GetInt("time-wait-threshold")
+
+		var profiles baseline.Profiles
+		if baselinePath, _ := cmd.Flags().GetString("baseline"); baselinePath != "" {
+			var err error
+			profiles, err = baseline.Load(baselinePath)
+			if err != nil {
+				return err
+			}
+		}
+		if baselineAI && profiles == nil {
+			return errors.New("--compare-baseline-ai judges deviations from a profile: use it with --baseline")
+		}
+		if baselineAI && (disableAI || dumpPrompt != "" || mode == analyzer.ModeRules) {
+			return errors.New("--compare-baseline-ai needs the AI and cannot be combined with --no-ai, --dump-prompt or --mode rules")
+		}
+
+		var processTypes []analyzer.ProcessType
+		if typesPath, _ := cmd.Flags().GetString("process-types"); typesPath != "" {
+			var err error
+			processTypes, err = analyzer.LoadProcessTypes(typesPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		var thresholds analyzer.ThresholdOverrides
+		if thresholdsPath, _ := cmd.Flags().GetString("thresholds"); thresholdsPath != "" {
+			var err error
+			thresholds, err = analyzer.LoadThresholds(thresholdsPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		var allowlist []analyzer.Allowance
+		if allowlistPath, _ := cmd.Flags().GetString("allowlist"); allowlistPath != "" {
+			var err error
+			allowlist, err = analyzer.LoadAllowlist(allowlistPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		insp := inspector.New(analyzer.Config{
+			MaxFindings:  aiMaxFindings,
+			MaxItems:     aiMaxItems,
+			FailureLimit: aiFailureLimit,
+			AIRate:       aiRate,
+			AITimeout:    aiTimeout,
+			Timeout:      timeout,
+			Structured:   aiJSON,
+			Baseline:     profiles,
+			UserBudget:   userBudget,
+			ProcessTypes: processTypes,
+			Allowlist:    allowlist,
+			Thresholds:   thresholds,
+			DumpPrompt:   dumpPrompt,
+			Mode:         mode,
+			NoAI:         disableAI,
+			Provider:     provider,
+			Verbose:      verbose,
+			ExplainAI:    explainAI,
+			AITrace:      aiTrace,
+			BaselineAI:   baselineAI,
+			Model:        aiModel,
+			CPUMode:      models.CPUMode(cpuMode),
+			EnvFile:      envPath,
 
-		insp := inspector.New()
+			CloseWaitThreshold: closeWaitThreshold,
+			TimeWaitThreshold:  timeWaitThreshold,
+		})
+		// Deferred so the clients are closed on every return below, and on an
+		// interrupt too
+		defer closeInspector(insp)
+		display.HandleInterrupts(func() { closeInspector(insp) })
 
-		var err error
-		if portFlag > 0 {
+		// Long reports go through a pager; streams (JSON, watch screens)
+		// never do. Without --pager, only output taller than the terminal is
+		// paged, and --pager=false turns that off.
+		forcePager, _ := cmd.Flags().GetBool("pager")
+		streaming := opts.JSON || watch || repeat > 0 || until != nil
+		if !streaming && (forcePager || !cmd.Flags().Changed("pager")) {
+			defer display.StartPager(forcePager)()
+		}
+
+		if sshTarget != "" {
+			// Collected on another host, analyzed here
+			remote := inspector.Remote{Destination: sshTarget, Args: remoteArgs(cmd, pid)}
+			remote.Inspektor, _ = cmd.Flags().GetString("ssh-inspektor")
+			remote.Timeout, _ = cmd.Flags().GetDuration("ssh-timeout")
+			err = insp.InspectRemote(remote, opts)
+		} else if snapshot != "" {
+			// A saved inspection, no live process
+			err = insp.InspectSnapshot(snapshot, opts)
+		} else if systemFlag {
+			// Host overview only, no process
+			err = insp.InspectSystem(opts)
+		} else if topFlag > 0 {
+			// The heaviest processes on the host, per resource
+			err = insp.InspectTop(topFlag, opts)
+		} else if stdinFlag {
+			// Inspect every PID or selector piped in on stdin
+			var targets []string
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				targets = append(targets, scanner.Text())
+			}
+			if scanErr := scanner.Err(); scanErr != nil {
+				return fmt.Errorf("reading stdin: %w", scanErr)
+			}
+			err = insp.InspectBatch(targets, opts)
+		} else if cgroupFlag != "" {
+			// Inspect every process in a control group and sum their usage
+			err = insp.InspectByCgroup(cgroupFlag, opts)
+		} else if userFlag != "" {
+			// Inspect every process a user owns and sum their usage
+			err = insp.InspectByUser(userFlag, opts)
+		} else if unitFlag != "" {
+			// Inspect the main process (or all processes) of a systemd unit
+			err = insp.InspectByUnit(unitFlag, opts)
+		} else if nameFlag != "" {
+			// Inspect every process matching the name
+			err = insp.InspectByName(nameFlag, opts)
+		} else if waitPort > 0 {
+			// Wait for a listener on the port, then inspect it
+			waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
+			err = insp.WaitForPort(waitPort, waitTimeout, opts)
+		} else if portFlag > 0 {
 			// Inspect by port
-			err = insp.InspectByPort(portFlag, jsonOutput, verbose)
+			err = insp.InspectByPort(portFlag, opts)
+		} else if tid > 0 {
+			// A single thread of the process
+			err = insp.InspectThread(pid, tid, opts)
 		} else {
 			// Inspect by PID
-			pid, parseErr := strconv.Atoi(args[0])
-			if parseErr != nil {
-				fmt.Fprintf(os.Stderr, "Invalid PID: %s\n", args[0])
-				os.Exit(1)
-			}
-			err = insp.InspectWithOptions(int32(pid), jsonOutput, verbose)
+			err = insp.InspectWithOptions(pid, opts)
 		}
-
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error inspecting process: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("inspecting process: %w", err)
 		}
+		if err := insp.FailOnError(); err != nil {
+			return fmt.Errorf("failed: %w", err)
+		}
+		return nil
 	},
 }
 
+// remoteCollectionFlags shape what a collection reads, so --ssh passes them
+// on to the remote inspektor when they are set
+var remoteCollectionFlags = []string{"cpu-interval", "timeout", "verbose", "include-children", "proto", "bind",
+	"threads", "threads-top", "trend", "power", "docker", "system-samples", "detail-limit", "force-detail", "hash-binary"}
+
+// remoteArgs are the arguments the remote inspektor runs with for --ssh: the
+// same target, printed as JSON without AI, which runs here instead
+func remoteArgs(cmd *cobra.Command, pid int32) []string {
+	args := []string{"--json", "--no-ai"}
+	for _, name := range remoteCollectionFlags {
+		if flag := cmd.Flags().Lookup(name); flag.Changed {
+			args = append(args, "--"+name+"="+flag.Value.String())
+		}
+	}
+	patterns, _ := cmd.Flags().GetStringArray("redact-pattern")
+	for _, pattern := range patterns {
+		args = append(args, "--redact-pattern="+pattern)
+	}
+
+	switch {
+	case portFlag > 0:
+		return append(args, "--port", strconv.Itoa(portFlag))
+	case unitFlag != "":
+		return append(args, "--unit", unitFlag)
+	}
+	return append(args, strconv.Itoa(int(pid)))
+}
+
+// hasSelector reports whether a flag picks what to inspect, in place of a
+// PID argument
+func hasSelector() bool {
+	return portFlag > 0 || waitPort > 0 || nameFlag != "" || stdinFlag || unitFlag != "" || cgroupFlag != "" || userFlag != "" || systemFlag || topFlag > 0 || snapshot != ""
+}
+
+// InvalidPIDError is returned for a PID argument that isn't a positive
+// number that fits a PID
+type InvalidPIDError struct {
+	Arg string
+}
+
+func (e *InvalidPIDError) Error() string {
+	return fmt.Sprintf("invalid PID: %s", e.Arg)
+}
+
+// parsePID reads a PID argument. Parsing at 32 bits rejects values that
+// would otherwise wrap around to another process's PID.
+func parsePID(arg string) (int32, error) {
+	pid, err := strconv.ParseInt(arg, 10, 32)
+	if err != nil || pid <= 0 {
+		return 0, &InvalidPIDError{Arg: arg}
+	}
+	return int32(pid), nil
+}
+
+// closeInspector releases the AI clients once all inspections are done
+func closeInspector(insp *inspector.Inspector) {
+	if err := insp.Close(); err != nil {
+		fmt.Printf("Warning: Failed to close AI client: %v\n", err)
+	}
+}
+
+// failOnNames are the values --fail-on accepts: finding categories and the
+// IDs of individual rules
+func failOnNames() []string {
+	names := append(analyzer.Categories(), analyzer.Rules()...)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// envFile resolves --env-file, falling back to $INSPEKTOR_ENV. A file named
+// either way must exist; with neither set, ./.env is read if present.
+func envFile(cmd *cobra.Command) (string, error) {
+	path, _ := cmd.Flags().GetString("env-file")
+	if path == "" {
+		path = os.Getenv("INSPEKTOR_ENV")
+	}
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("env file: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// redactor builds the secret redactor from the built-in patterns and any
+// --redact-pattern
+func redactor(cmd *cobra.Command) (*redact.Redactor, error) {
+	patterns, _ := cmd.Flags().GetStringArray("redact-pattern")
+	return redact.New(patterns)
+}
+
+// excludePatterns compiles the --exclude regexps; nil when there are none
+func excludePatterns(cmd *cobra.Command) ([]*regexp.Regexp, error) {
+	patterns, _ := cmd.Flags().GetStringArray("exclude")
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// diffThresholds reads --diff-threshold; nil, the defaults, when unset
+func diffThresholds(cmd *cobra.Command) (models.DiffThresholds, error) {
+	spec, _ := cmd.Flags().GetString("diff-threshold")
+	if spec == "" {
+		return nil, nil
+	}
+	thresholds, err := models.ParseDiffThresholds(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --diff-threshold: %w", err)
+	}
+	return thresholds, nil
+}
+
+// noAI reports whether --no-ai was given. A provider chosen explicitly
+// alongside it is a contradiction rather than something to ignore quietly.
+func noAI(cmd *cobra.Command) (bool, error) {
+	disabled, _ := cmd.Flags().GetBool("no-ai")
+	if disabled && cmd.Flags().Changed("provider") {
+		return false, errors.New("--provider cannot be combined with --no-ai")
+	}
+	return disabled, nil
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("env-file", "", "Read API keys from this file instead of ./.env (default $INSPEKTOR_ENV)")
+	rootCmd.PersistentFlags().StringArray("redact-pattern", nil, "Also mask matches of this regexp in command lines and environments (repeatable; a (?P<secret>...) group masks only that part)")
+	rootCmd.PersistentFlags().Bool("no-banner", false, "Never print the banner, spinner or progress messages (implied when stdout is not a terminal)")
+	rootCmd.PersistentFlags().Bool("ascii", false, "Draw with ASCII only ([!], ->, [OK]) instead of unicode symbols; the default when the locale isn't UTF-8, and --ascii=false forces unicode")
+	rootCmd.PersistentFlags().String("spinner", string(display.SpinnerDots), "Progress animation: dots, line (plain ASCII), arrow, or none (print the message once, e.g. for CI logs)")
+	rootCmd.PersistentFlags().Bool("no-ai", false, "Never read API keys, create an AI client or make a network request (air-gapped hosts)")
 	rootCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
 	rootCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	rootCmd.Flags().String("format", "text", "Output format: text, json, json-compact (the json document on one line, for log ingestion), jsonl (one compact object per line, for --watch streams), markdown, table (one row per process), csv (one row of key metrics per process, for spreadsheets), or github (findings only, as GitHub Actions annotations)")
+	rootCmd.Flags().String("sort-by", "cpu", "Row order for --format table: cpu, rss, threads, conn, health, pid, name, pressure (cpu+mem+io), or a weighted sum of cpu, mem, rss, threads, conn, files and io such as cpu*2+mem; with --top-n, a sum or pressure adds a combined ranking")
+	rootCmd.Flags().String("metric-only", "", "Print only this field's value, by its JSON name (e.g. cpu_percent, memory_rss, system.cpu_usage, health_score), for scripts")
 	rootCmd.Flags().IntVarP(&portFlag, "port", "p", 0, "Inspect process listening on specified port")
+	rootCmd.Flags().IntVar(&waitPort, "wait-for-port", 0, "Wait until a process listens on this port, then inspect it")
+	rootCmd.Flags().Duration("wait-timeout", inspector.DefaultWaitTimeout, "With --wait-for-port, give up and exit non-zero after this long (0 = wait forever)")
+	rootCmd.Flags().String("proto", "", "With --port, only match tcp or udp listeners")
+	rootCmd.Flags().String("bind", "", "With --port, only match listeners bound to this local address")
+	rootCmd.Flags().StringVarP(&nameFlag, "name", "n", "", "Inspect all processes whose name contains the given string")
+	rootCmd.Flags().StringVarP(&unitFlag, "unit", "u", "", "Inspect the main process of a systemd unit")
+	rootCmd.Flags().StringVar(&cgroupFlag, "cgroup", "", "Inspect every process in a control group and its subgroups (e.g. a Kubernetes pod), with combined totals")
+	rootCmd.Flags().StringVar(&userFlag, "user", "", "Inspect every process owned by a user, with combined totals (try --format table)")
+	rootCmd.Flags().String("user-budget", "", "With --user, warn when the user's combined usage exceeds e.g. cpu=200,rss=4G,procs=50")
+	rootCmd.Flags().Bool("all", false, "With --unit, inspect every process in the unit's control group; with --port or --wait-for-port, every process listening on the port")
+	rootCmd.Flags().Int("concurrency", inspector.DefaultConcurrency, "With several processes, inspect up to this many at once; 1 inspects them one after another")
+	rootCmd.Flags().Bool("batch-analysis", false, "With several processes, analyze them all in one AI call (per 20 processes) instead of one call each; also reports issues they share")
+	rootCmd.Flags().String("output-dir", "", "With several processes (--name, --stdin, --cgroup, --user, --unit or --port with --all), save each inspection as <pid>-<name>.json in this directory, created if needed")
+	rootCmd.Flags().BoolVar(&stdinFlag, "stdin", false, "Read newline-separated PIDs (or pid=/name=/port=/user= selectors) from stdin")
+	rootCmd.Flags().String("ssh", "", "Collect on another host over SSH ([user@]host, or a Host from ~/.ssh/config) by running inspektor there, then analyze and render here")
+	rootCmd.Flags().String("ssh-inspektor", "inspektor", "The inspektor command on the --ssh host")
+	rootCmd.Flags().Duration("ssh-timeout", inspector.DefaultRemoteTimeout, "Give up on an --ssh host that hasn't returned its inspection after this long")
+	rootCmd.Flags().StringVar(&snapshot, "from-snapshot", "", "Analyze an inspection saved earlier with --json instead of a live process")
+	rootCmd.Flags().BoolVar(&systemFlag, "system", false, "Show only the host overview and system-level warnings")
+	rootCmd.Flags().IntVar(&topFlag, "top-n", 0, "Rank every process and show the N heaviest by CPU, memory, open files and connections, with a summary")
+	rootCmd.Flags().Bool("with-top", false, "Append the host's 5 heaviest processes by CPU and by memory to the report")
+	rootCmd.Flags().Bool("no-kernel-threads", false, "Leave kernel threads (kworker, ksoftirqd, ...) out of --top-n rankings and --name and --user results")
+	rootCmd.Flags().Bool("include-self", false, "Count inspektor's own process in --top-n, --name, --user and --cgroup results and in system CPU usage, which by default leave it out; with --name, also the processes that launched it")
+	rootCmd.Flags().Bool("exact", false, "With --name, match whole process names only instead of any name containing the string")
+	rootCmd.Flags().StringArray("exclude", nil, "With --name, skip processes whose name or command line matches this regexp (repeatable)")
+	rootCmd.Flags().Bool("tree", false, "Show the descendant process tree")
+	rootCmd.Flags().Int("tree-depth", inspector.DefaultTreeDepth, "Maximum depth of the process tree")
+	rootCmd.Flags().Bool("threads", false, "List the threads using the most CPU, with their state and CPU time, like top -H (Linux only)")
+	rootCmd.Flags().Int("threads-top", inspector.DefaultHotThreads, "How many threads --threads lists")
+	rootCmd.Flags().Int32("tid", 0, "Inspect only this thread of the PID: its state, CPU usage and time, context switches and scheduling (Linux only)")
+	rootCmd.Flags().Bool("include-children", false, "Also report CPU, memory, open files and connections summed over all descendants")
+	rootCmd.Flags().Bool("follow-children", false, "In watch modes, track descendants as they spawn and exit, noting each change (implies --include-children)")
+	rootCmd.Flags().BoolP("watch", "w", false, "Continuously re-inspect the process until interrupted")
+	rootCmd.Flags().Duration("interval", inspector.DefaultWatchInterval, "Time between samples in watch mode")
+	rootCmd.Flags().Bool("interval-adaptive", false, "Double the time between watch samples while the process holds steady, up to --interval-max, and return to --interval once it changes (see --diff-threshold)")
+	rootCmd.Flags().Duration("interval-max", inspector.DefaultMaxInterval, "Longest time between samples with --interval-adaptive")
+	rootCmd.Flags().String("watch-until", "", "Watch until a condition holds, e.g. 'cpu<5' (metrics: cpu, mem_percent, rss, connections, threads)")
+	rootCmd.Flags().Duration("watch-timeout", 0, "Stop watching after this long; with --watch-until, exit non-zero if the condition was never met")
+	rootCmd.Flags().Int("repeat", 0, "Take exactly N samples, --interval apart, then exit")
+	rootCmd.Flags().Bool("sample-output", false, "With watch modes and JSON output, include every CPU and RSS reading so far (timestamped, the last 1000) under \"samples\"")
+	rootCmd.Flags().Bool("pager", false, "Show the report through $PAGER (less -R by default); without the flag only reports taller than the terminal are paged, --pager=false never pages")
+	rootCmd.Flags().Bool("no-color", false, "Disable colored output and unicode graphs")
+	rootCmd.Flags().String("units", string(display.UnitsBinary), "Byte units in text output: binary (KiB, MiB), si (kB, MB), or raw bytes")
+	rootCmd.Flags().Int("width", 0, "Force the text layout width, wrapping longer lines")
+	rootCmd.Flags().Bool("borderless", false, "Drop separators and section highlights for plain structured text")
+	rootCmd.Flags().Duration("timeout", 0, "Give up collection after this long and show partial results (e.g. 5s)")
+	rootCmd.Flags().Bool("strict", false, "Fail instead of reporting partial data when any collector fails or --timeout expires")
+	rootCmd.Flags().String("cpu-mode", string(models.CPUModeRaw), "Process CPU scale: raw (100% = one core, can exceed 100%) or normalized (100% = all cores)")
+	rootCmd.Flags().String("diff-threshold", "", "Smallest change marked between watch samples: a percentage of the earlier value (5%) or an amount in the metric's unit, for all metrics or per metric (cpu_percent=2,memory_rss=10%)")
+	rootCmd.Flags().Duration("cpu-interval", inspector.DefaultCPUInterval, "Sampling window for process CPU usage; adds this much latency (0 = lifetime average)")
+	rootCmd.Flags().Int("detail-limit", inspector.DefaultDetailLimit, "Only count the descriptors of a process holding more than this many, skipping the costly listing of its files and sockets (0 = no limit)")
+	rootCmd.Flags().Bool("hash-binary", false, "Add the SHA-256 of the process's executable to its command_hash, so a rebuilt binary at the same path counts as a change (reads the whole file)")
+	rootCmd.Flags().Bool("force-detail", false, "List every descriptor and socket however many a process holds, ignoring --detail-limit")
+	rootCmd.Flags().Bool("power", false, "Estimate the process's share of the CPU's power draw from its share of busy CPU time, in watts where RAPL energy counters are readable (usually as root on Linux)")
+	rootCmd.Flags().Bool("trend", false, "Take a second quick reading of CPU and memory and show which way they are heading (↑ ↓ →); adds 250ms")
+	rootCmd.Flags().Int("system-samples", 1, "Average system CPU and memory over this many one-second readings and show their min/max")
+	rootCmd.Flags().BoolP("quiet", "q", false, "Only print findings, and nothing at all when healthy")
+	rootCmd.Flags().String("time-format", "", "Timestamp format: rfc3339, unix, or a Go time layout")
+	rootCmd.Flags().Bool("utc", false, "Show timestamps in UTC instead of local time")
+	rootCmd.Flags().Bool("docker", false, "Resolve container names and images through the Docker socket")
+	rootCmd.Flags().Bool("resolve", false, "With --verbose, list connections with peer hostnames (best-effort reverse DNS) and well-known services")
+	rootCmd.Flags().String("baseline", "", "YAML file of expected per-process resource ranges to compare against")
+	rootCmd.Flags().Bool("compare-baseline-ai", false, "Have the AI judge whether deviations from --baseline are a real regression for this kind of process")
+	rootCmd.Flags().String("thresholds", "", "YAML file overriding the limits findings and report colors are judged by")
+	rootCmd.Flags().String("layout", "", "YAML file ordering, and hiding, the rows of the report's process, resources and system sections")
+	rootCmd.Flags().String("services", "", "YAML file mapping ports to service names (e.g. 8081: billing-api) to label listening ports with, on top of the built-in ones")
+	rootCmd.Flags().String("runtime-env", "", "YAML list of extra environment variables (e.g. APP_WORKERS, or MYAPP_* for a prefix) to report in the RUNTIME section next to the built-in ones such as GOMAXPROCS and JAVA_OPTS")
+	rootCmd.Flags().String("allowlist", "", "YAML file of processes expected to use a lot of CPU and memory, whose findings about it are suppressed or downgraded to info")
+	rootCmd.Flags().String("process-types", "", "YAML file of extra process types (by name or listening port) to classify processes with")
+	rootCmd.Flags().String("warnings-to", "stdout", "Where findings go: stdout (in the report) or stderr (one line each, leaving only the data on stdout)")
+	rootCmd.Flags().String("warnings-format", "text", "With --warnings-to stderr, write findings as text or jsonl (one JSON object per line)")
+	rootCmd.Flags().String("on-warning", "", "Shell command to run with the inspection JSON on stdin when critical findings are present")
+	rootCmd.Flags().String("audit-log", "", "Append a JSON line per inspection (time, user, process, finding counts) to this file (default $INSPEKTOR_AUDIT_LOG)")
+	rootCmd.Flags().String("min-severity", string(models.SeverityInfo), "Only show findings at or above this severity: info, warning, or critical")
+	rootCmd.Flags().StringSlice("fail-on", nil, "Exit non-zero if any finding matches these categories or rules, e.g. zombie,disk_full or memory")
+	rootCmd.Flags().Bool("explain", false, "Show the metric and threshold that triggered each finding")
+	rootCmd.Flags().String("group-by", "", "Group the findings of text reports under a header per category (cpu, memory, network, ...): category")
+	rootCmd.Flags().Bool("explain-health", false, "List what took points off the health score, by component (JSON always has them as health_components)")
+	rootCmd.Flags().Bool("all-findings", false, fmt.Sprintf("List every finding in text output; past %d, only the most severe are listed and the rest counted", display.DefaultMaxFindings))
+	rootCmd.Flags().Int("close-wait-threshold", analyzer.DefaultCloseWaitThreshold, "Warn when the process has more sockets than this in CLOSE_WAIT")
+	rootCmd.Flags().Int("time-wait-threshold", analyzer.DefaultTimeWaitThreshold, "Warn when the process has more sockets than this in TIME_WAIT")
+	rootCmd.Flags().Int("ai-max-findings", analyzer.DefaultMaxFindings, "Maximum number of AI findings to keep")
+	rootCmd.Flags().String("provider", "", "AI providers to try in order, e.g. gemini,openai,rules (default: whichever is configured)")
+	rootCmd.Flags().String("mode", analyzer.ModeAuto, "Analysis engines: auto (AI if configured, else rules), rules, or both (AI and rules, merged)")
+	rootCmd.Flags().String("ai-model", "", "Model name for the AI provider (e.g. llama3 for ollama)")
+	rootCmd.Flags().Int("ai-max-items", analyzer.DefaultMaxItems, "Maximum entries of each list (arguments, deleted files, ...) sent to the AI model")
+	rootCmd.Flags().Float64("ai-rate", analyzer.DefaultAIRate, "Send each AI provider at most this many requests per minute across the run, waiting for a turn rather than failing (0 = unlimited)")
+	rootCmd.Flags().Duration("ai-timeout", 0, "Fall back to rule-based analysis if the AI hasn't replied within this long, capped by --timeout (0 = provider default: 30s, 2m for Ollama)")
+	rootCmd.Flags().Int("ai-failure-limit", analyzer.DefaultFailureLimit, "Stop calling the AI for the rest of the run after this many analyses in a row where no provider answered")
+	rootCmd.Flags().Bool("ai-json", false, "Request structured JSON findings from the AI model instead of free text")
+	rootCmd.Flags().String("dump-prompt", "", "Write the AI prompt to stderr (or =FILE) without calling the API; rule-based analysis is used")
+	rootCmd.Flags().Lookup("dump-prompt").NoOptDefVal = "-"
+	rootCmd.Flags().Bool("explain-ai", false, "Print the raw AI response to stderr before it is parsed into findings")
+	rootCmd.Flags().String("ai-trace", "", "Write each AI prompt, raw response, parsed findings and timing to this JSON Lines file, for evaluating prompt changes offline")
+
+	registerCompletions()
 }
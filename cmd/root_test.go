@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestParsePID(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    int32
+		wantErr bool
+	}{
+		{arg: "1", want: 1},
+		{arg: "1234", want: 1234},
+		{arg: "2147483647", want: 2147483647},
+		{arg: "abc", wantErr: true},
+		{arg: "12abc", wantErr: true},
+		{arg: "", wantErr: true},
+		{arg: "-5", wantErr: true},
+		{arg: "0", wantErr: true},
+		{arg: "2147483648", wantErr: true},
+		// Would wrap around to PID 1 if truncated to 32 bits
+		{arg: "4294967297", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.arg, func(t *testing.T) {
+			pid, err := parsePID(test.arg)
+			if test.wantErr {
+				var invalid *InvalidPIDError
+				if !errors.As(err, &invalid) || invalid.Arg != test.arg {
+					t.Errorf("parsePID(%q) = %d, %v; want an InvalidPIDError", test.arg, pid, err)
+				}
+				return
+			}
+			if err != nil || pid != test.want {
+				t.Errorf("parsePID(%q) = %d, %v; want %d", test.arg, pid, err, test.want)
+			}
+		})
+	}
+}
+
+// The command returns a bad PID as an error rather than exiting, so the
+// test binary survives it
+func TestRootRejectsNonNumericPID(t *testing.T) {
+	rootCmd.SetArgs([]string{"--no-ai", "not-a-pid"})
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() { rootCmd.SetArgs(nil) })
+
+	err := Execute()
+	var invalid *InvalidPIDError
+	if !errors.As(err, &invalid) || invalid.Arg != "not-a-pid" {
+		t.Errorf("Execute() = %v, want an InvalidPIDError for not-a-pid", err)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"inspektor/internal/analyzer"
+	"inspektor/internal/display"
+	"inspektor/internal/inspector"
+
+	"github.com/spf13/cobra"
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check that inspektor works in this environment",
+	Long: `Inspects inspektor's own process end to end, checks that every collector
+returns plausible values and that the configured AI providers answer, then
+prints a pass/fail summary. Exits non-zero if any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		provider, _ := cmd.Flags().GetString("provider")
+		aiModel, _ := cmd.Flags().GetString("ai-model")
+
+		if noColor {
+			display.DisableColor()
+		}
+
+		secrets, err := redactor(cmd)
+		if err != nil {
+			return err
+		}
+		disableAI, err := noAI(cmd)
+		if err != nil {
+			return err
+		}
+		envPath, err := envFile(cmd)
+		if err != nil {
+			return err
+		}
+
+		insp := inspector.New(analyzer.Config{Provider: provider, Model: aiModel, NoAI: disableAI, EnvFile: envPath})
+		defer closeInspector(insp)
+		display.HandleInterrupts(func() { closeInspector(insp) })
+
+		return insp.SelfTest(inspector.Options{JSON: jsonOutput, CPUInterval: inspector.DefaultCPUInterval, Redactor: secrets})
+	},
+}
+
+func init() {
+	selftestCmd.Flags().BoolP("json", "j", false, "Output the check results as JSON")
+	selftestCmd.Flags().Bool("no-color", false, "Disable colored output")
+	selftestCmd.Flags().String("provider", "", "AI providers to check, e.g. gemini,openai (default: whichever is configured)")
+	selftestCmd.Flags().String("ai-model", "", "Model name for the AI provider")
+	rootCmd.AddCommand(selftestCmd)
+}
@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"inspektor/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Allowlist actions: drop the matching findings, or keep them at info
+const (
+	AllowSuppress = "suppress"
+	AllowInfo     = "info"
+)
+
+// allowableRules are the findings an allowlist entry can silence: those
+// about how much CPU and memory the process uses, which batch jobs and
+// compilers legitimately max out. Risks such as an OOM kill still apply.
+var allowableRules = []string{RuleHighCPU, RuleKernelCPU, RuleHighMemory, RuleMemoryLeak}
+
+// Allowance marks processes expected to be resource-hungry: those whose
+// name and command line match the regular expressions given (either may be
+// left out). Action is AllowSuppress, the default, or AllowInfo.
+type Allowance struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+	Action  string `yaml:"action"`
+	Reason  string `yaml:"reason"`
+
+	name    *regexp.Regexp
+	command *regexp.Regexp
+}
+
+// LoadAllowlist reads a YAML list of allowances
+func LoadAllowlist(path string) ([]Allowance, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowlist: %w", err)
+	}
+
+	var allowlist []Allowance
+	if err := yaml.Unmarshal(content, &allowlist); err != nil {
+		return nil, fmt.Errorf("failed to parse allowlist %s: %w", path, err)
+	}
+
+	for idx := range allowlist {
+		entry := &allowlist[idx]
+		if entry.Name == "" && entry.Command == "" {
+			return nil, fmt.Errorf("allowlist %s: entry %d has neither name nor command", path, idx+1)
+		}
+		switch entry.Action {
+		case "":
+			entry.Action = AllowSuppress
+		case AllowSuppress, AllowInfo:
+		default:
+			return nil, fmt.Errorf("allowlist %s: entry %d has unknown action %q (expected %s or %s)",
+				path, idx+1, entry.Action, AllowSuppress, AllowInfo)
+		}
+		if entry.Name != "" {
+			if entry.name, err = regexp.Compile(entry.Name); err != nil {
+				return nil, fmt.Errorf("allowlist %s: entry %d: invalid name pattern: %w", path, idx+1, err)
+			}
+		}
+		if entry.Command != "" {
+			if entry.command, err = regexp.Compile(entry.Command); err != nil {
+				return nil, fmt.Errorf("allowlist %s: entry %d: invalid command pattern: %w", path, idx+1, err)
+			}
+		}
+	}
+
+	return allowlist, nil
+}
+
+// matches reports whether every pattern the entry sets matches the process
+func (e *Allowance) matches(proc *models.ProcessInfo) bool {
+	if e.name != nil && !e.name.MatchString(proc.Name) {
+		return false
+	}
+	return e.command == nil || e.command.MatchString(proc.CommandLine)
+}
+
+// describe names the entry in output, by its reason when it has one
+func (e *Allowance) describe() string {
+	if e.Reason != "" {
+		return e.Reason
+	}
+	if e.Name != "" {
+		return "name " + e.Name
+	}
+	return "command " + e.Command
+}
+
+// allowance returns the first allowlist entry matching the process, or nil
+func (a *AIAnalyzer) allowance(proc *models.ProcessInfo) *Allowance {
+	for idx := range a.config.Allowlist {
+		if a.config.Allowlist[idx].matches(proc) {
+			return &a.config.Allowlist[idx]
+		}
+	}
+	return nil
+}
+
+// applyAllowlist suppresses or downgrades the CPU and memory findings of an
+// allowlisted process. Suppressed findings leave a single info finding
+// behind, so the policy never hides a problem without saying so.
+func (a *AIAnalyzer) applyAllowlist(proc *models.ProcessInfo, findings []models.Finding) []models.Finding {
+	entry := a.allowance(proc)
+	if entry == nil {
+		return findings
+	}
+
+	kept := findings[:0]
+	suppressed := 0
+	for _, finding := range findings {
+		switch {
+		case !slices.Contains(allowableRules, finding.Rule):
+		case entry.Action == AllowInfo:
+			finding.Severity = models.SeverityInfo
+			finding.Message += fmt.Sprintf(" (expected per allowlist: %s)", entry.describe())
+		default:
+			suppressed++
+			continue
+		}
+		kept = append(kept, finding)
+	}
+
+	if suppressed > 0 {
+		kept = append(kept, ruleFinding(models.SeverityInfo, RuleAllowlisted, fmt.Sprintf(
+			"%d CPU/memory finding(s) suppressed by allowlist policy: %s",
+			suppressed, entry.describe())))
+	}
+	return kept
+}
+
+// formatAllowance tells the model heavy usage is expected of the process
+func (a *AIAnalyzer) formatAllowance(proc *models.ProcessInfo) string {
+	entry := a.allowance(proc)
+	if entry == nil {
+		return ""
+	}
+	return fmt.Sprintf("- Expected High Usage: allowlisted (%s); high CPU and memory are normal for it, don't flag them\n",
+		entry.describe())
+}
@@ -2,123 +2,408 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"inspektor/internal/baseline"
 	"inspektor/internal/models"
 
-	"github.com/google/generative-ai-go/genai"
 	"github.com/joho/godotenv"
-	"google.golang.org/api/option"
 )
 
-// AIAnalyzer provides intelligent analysis of system and process data using Gemini AI
-type AIAnalyzer struct {
-	client    *genai.Client
-	model     *genai.GenerativeModel
-	aiEnabled bool
+const (
+	// DefaultMaxFindings caps how many AI findings are kept per analysis
+	DefaultMaxFindings = 7
+
+	// DefaultMaxItems caps how many entries of each list go into the prompt
+	DefaultMaxItems = 10
+
+	// DefaultCloseWaitThreshold and DefaultTimeWaitThreshold bound the
+	// connection-state leak rules
+	DefaultCloseWaitThreshold = 10
+	DefaultTimeWaitThreshold  = 200
+
+	// DefaultFailureLimit is how many analyses in a row the whole provider
+	// chain may fail before the run gives up on AI
+	DefaultFailureLimit = 3
+
+	// DefaultAIRate is how many requests per minute each provider is sent
+	// by default, within the free tiers of the hosted APIs
+	DefaultAIRate = 60
+
+	// maxFindingLength rejects AI lines that are clearly not a single finding
+	maxFindingLength = 300
+
+	// flappingRestarts is how many restarts in one watch make the restart
+	// warning critical
+	flappingRestarts = 3
+
+	// fdExhaustionCritical is how soon a projected run out of file
+	// descriptors makes the fd_exhaustion warning critical
+	fdExhaustionCritical = 5 * time.Minute
+)
+
+// Analysis modes accepted by Config.Mode
+const (
+	// ModeAuto uses the AI when a provider is configured, else the rules
+	ModeAuto = "auto"
+	// ModeRules never asks the AI
+	ModeRules = "rules"
+	// ModeBoth runs the AI and the rules and merges their findings
+	ModeBoth = "both"
+)
+
+// Modes lists the analysis modes, for validation and completion
+var Modes = []string{ModeAuto, ModeRules, ModeBoth}
+
+// Config controls how the analyzer talks to and trusts the AI model
+type Config struct {
+	// Mode picks the analysis engines: ModeAuto (the default when empty),
+	// ModeRules or ModeBoth
+	Mode string
+
+	// NoAI guarantees an offline run whatever Mode and Provider say: no env
+	// file is read, no AI client is constructed and no request is sent
+	NoAI bool
+
+	// MaxFindings bounds the number of AI findings kept; the model is asked
+	// for this many but the cap is enforced in code as well
+	MaxFindings int
+
+	// MaxItems caps how many entries of each list (arguments, deleted
+	// files, connection states) are embedded in the prompt; the remainder is
+	// summarized as a count
+	MaxItems int
+
+	// Baseline holds expected per-service profiles; deviations are reported
+	// regardless of whether AI or rules produced the other findings
+	Baseline baseline.Profiles
+
+	// UserBudget caps the combined usage of the processes inspected by
+	// --user
+	UserBudget Budget
+
+	// Thresholds override the built-in rule limits, see Thresholds
+	Thresholds ThresholdOverrides
+
+	// ProcessTypes extend the built-in table used to tell what kind of
+	// service a process is, which steers both the prompt and rule thresholds
+	ProcessTypes []ProcessType
+
+	// Allowlist names processes expected to use a lot of CPU and memory,
+	// whose findings about it are suppressed or downgraded to info
+	Allowlist []Allowance
+
+	// DumpPrompt writes the AI prompt to this file ("-" for stderr) instead
+	// of sending it; analysis then runs offline on the rules
+	DumpPrompt string
+
+	// CloseWaitThreshold and TimeWaitThreshold are the per-process socket
+	// counts in those states above which a leak is reported
+	CloseWaitThreshold int
+	TimeWaitThreshold  int
+
+	// Provider is an ordered, comma-separated chain of AI backends
+	// ("gemini", "openai", "ollama", "rules"), each optionally suffixed with
+	// ":model"; empty picks whichever is configured in the environment
+	Provider string
+
+	// Verbose logs which provider answered
+	Verbose bool
+
+	// FailureLimit trips the circuit breaker: after this many consecutive
+	// analyses where no provider answered, the rest of the run uses the
+	// rules without calling the AI again
+	FailureLimit int
+
+	// AIRate caps the requests per minute sent to each provider across the
+	// whole run; calls over the limit wait for their turn rather than fail.
+	// 0 leaves them unlimited.
+	AIRate float64
+
+	// AITimeout bounds each AI call, replacing the provider's default; 0
+	// keeps the default
+	AITimeout time.Duration
+
+	// Timeout is the overall --timeout, which no AI call may outlast
+	Timeout time.Duration
+
+	// ExplainAI prints each raw model reply to stderr before it is parsed
+	ExplainAI bool
+
+	// Model overrides the provider's default model name
+	Model string
+
+	// CPUMode judges per-process CPU per core (raw) or as a share of all
+	// cores (normalized); the rule thresholds apply to the scaled value
+	CPUMode models.CPUMode
+
+	// EnvFile is read for API keys instead of ./.env; variables already set
+	// in the environment take precedence
+	EnvFile string
+
+	// Structured asks the model for JSON matching findingsSchema instead of
+	// WARNING:/RECOMMEND: lines
+	Structured bool
+
+	// AITrace appends each prompt sent, the raw reply, the findings parsed
+	// from it and the time it took to this JSON Lines file, for building
+	// evaluation sets offline
+	AITrace string
+
+	// BaselineAI has the model judge deviations from the Baseline profile
+	// instead of reporting each one crossed; the threshold comparison
+	// remains the fallback
+	BaselineAI bool
 }
 
-func New() *AIAnalyzer {
-	// Load environment variables
-	_ = godotenv.Load()
+// AIAnalyzer provides intelligent analysis of system and process data using
+// an AI provider, falling back to built-in rules. It is safe for concurrent
+// use: the config is read-only after New, providers are stateless between
+// calls, and mu guards the rest.
+type AIAnalyzer struct {
+	config Config
+
+	mu        sync.RWMutex
+	providers []AIProvider
+
+	// failures counts consecutive analyses the provider chain failed; once
+	// it reaches FailureLimit the breaker is tripped and chain is empty
+	failures int
+	tripped  bool
+
+	// promptDumped tracks whether the dump file has been started this run
+	promptDumped bool
+
+	// traceStarted tracks whether the trace file has been started this run
+	traceStarted bool
+}
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		log.Println("Warning: GEMINI_API_KEY not found. AI analysis will use fallback rules.")
-		return &AIAnalyzer{aiEnabled: false}
+func New(cfg Config) *AIAnalyzer {
+	if cfg.MaxFindings <= 0 {
+		cfg.MaxFindings = DefaultMaxFindings
+	}
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = DefaultMaxItems
+	}
+	if cfg.CloseWaitThreshold <= 0 {
+		cfg.CloseWaitThreshold = DefaultCloseWaitThreshold
+	}
+	if cfg.TimeWaitThreshold <= 0 {
+		cfg.TimeWaitThreshold = DefaultTimeWaitThreshold
+	}
+	if cfg.FailureLimit <= 0 {
+		cfg.FailureLimit = DefaultFailureLimit
 	}
 
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		log.Printf("Warning: Failed to initialize Gemini client: %v. Using fallback analysis.\n", err)
-		return &AIAnalyzer{aiEnabled: false}
+	// Offline, dry and rules-only runs never talk to the API, so don't even
+	// create a client
+	if cfg.NoAI || cfg.DumpPrompt != "" || cfg.Mode == ModeRules {
+		return &AIAnalyzer{config: cfg}
 	}
 
-	model := client.GenerativeModel("gemini-2.5-flash")
-	model.SetTemperature(0.3) // Lower temperature for more consistent analysis
+	// Load API keys from the given env file, or ./.env if there is one
+	if cfg.EnvFile != "" {
+		if err := godotenv.Load(cfg.EnvFile); err != nil {
+			log.Printf("Warning: failed to load %s: %v\n", cfg.EnvFile, err)
+		}
+	} else {
+		_ = godotenv.Load()
+	}
 
-	return &AIAnalyzer{
-		client:    client,
-		model:     model,
-		aiEnabled: true,
+	providers := newProviders(cfg)
+	if cfg.AIRate > 0 {
+		for i, provider := range providers {
+			providers[i] = newRateLimited(provider, cfg.AIRate)
+		}
 	}
+	return &AIAnalyzer{providers: providers, config: cfg}
 }
 
-// AnalyzeAndWarn generates warnings based on process and system metrics
-func (a *AIAnalyzer) AnalyzeAndWarn(data *models.InspectionData) []string {
-	if a.aiEnabled {
-		return a.analyzeWithAI(data)
+// AnalyzeAndWarn generates findings based on process and system metrics,
+// and says where they came from: models.SourceAI when a provider answered,
+// models.SourceMerged when its answer was merged with the rules' (--mode
+// both) and models.SourceRules when the rules alone were used. No AI call
+// outlives ctx, so an inspection's --timeout covers the analysis too.
+func (a *AIAnalyzer) AnalyzeAndWarn(ctx context.Context, data *models.InspectionData) ([]models.Finding, string) {
+	if a.config.DumpPrompt != "" {
+		if err := a.dumpPrompt(data); err != nil {
+			log.Printf("Warning: failed to dump prompt: %v\n", err)
+		}
 	}
-	return a.analyzeWithRules(data)
-}
 
-func (a *AIAnalyzer) analyzeWithAI(data *models.InspectionData) []string {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// A kernel thread's report is too thin for the model to add anything
+	var findings []models.Finding
+	source := models.SourceRules
+	if providers := a.chain(); len(providers) > 0 && !data.Process.KernelThread {
+		findings, source = a.analyzeWithAI(ctx, data, providers)
+	} else {
+		findings = a.analyzeWithRules(data)
+	}
+	return append(findings, a.baselineFindings(ctx, data)...), source
+}
 
+// analyzeWithAI asks each provider in turn until one gives a usable answer,
+// falling back to the rules when the whole chain fails or ctx ends first
+func (a *AIAnalyzer) analyzeWithAI(ctx context.Context, data *models.InspectionData, providers []AIProvider) ([]models.Finding, string) {
 	prompt := a.buildAnalysisPrompt(data)
 
-	resp, err := a.model.GenerateContent(ctx, genai.Text(prompt))
+	for _, provider := range providers {
+		findings, err := a.askProvider(ctx, provider, prompt, data.Process.PID)
+		if err != nil {
+			log.Printf("AI analysis (%s) failed: %v.\n", provider.Name(), err)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		a.recordAnswer(true)
+		if a.config.Verbose {
+			log.Printf("AI analysis answered by %s\n", provider.Name())
+		}
+		if a.config.Mode == ModeBoth {
+			return mergeFindings(findings, a.analyzeWithRules(data)), models.SourceMerged
+		}
+		return findings, models.SourceAI
+	}
+
+	log.Println("No AI provider answered. Falling back to rule-based analysis.")
+	a.recordAnswer(false)
+	return a.analyzeWithRules(data), models.SourceRules
+}
+
+// askProvider sends the prompt to one provider and parses its reply. A reply
+// with neither findings nor a HEALTHY verdict counts as unusable.
+func (a *AIAnalyzer) askProvider(ctx context.Context, provider AIProvider, prompt string, pid int32) (findings []models.Finding, err error) {
+	var aiResponse string
+	defer func(started time.Time) {
+		a.traceExchange(traceRecord{
+			Provider: provider.Name(),
+			PIDs:     []int32{pid},
+			Prompt:   prompt,
+			Response: aiResponse,
+			Findings: map[int32][]models.Finding{pid: findings},
+		}, started, err)
+	}(time.Now())
+
+	aiResponse, err = a.generate(ctx, provider, prompt)
 	if err != nil {
-		log.Printf("AI analysis failed: %v. Falling back to rule-based analysis.\n", err)
-		return a.analyzeWithRules(data)
+		return nil, err
+	}
+	if a.config.ExplainAI {
+		fmt.Fprintf(os.Stderr, "--- raw response from %s ---\n%s\n--- end of response ---\n",
+			provider.Name(), strings.TrimRight(aiResponse, "\n"))
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		log.Println("No AI response received. Falling back to rule-based analysis.")
-		return a.analyzeWithRules(data)
+	// Parse AI response, falling back to the line format for models that
+	// ignore the schema
+	if a.config.Structured {
+		if findings, err := a.parseStructuredResponse(aiResponse); err == nil {
+			return findings, nil
+		}
+	}
+	findings = a.parseAIResponse(aiResponse)
+	if findings == nil {
+		return nil, fmt.Errorf("response contained no recognizable findings")
 	}
+	return findings, nil
+}
 
-	// Parse AI response
-	aiResponse := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
-	return a.parseAIResponse(aiResponse)
+// dumpPrompt writes the prompt that would have been sent to the model. A dump
+// file is truncated on the first inspection and appended to afterwards, so
+// batch and watch runs keep every prompt.
+func (a *AIAnalyzer) dumpPrompt(data *models.InspectionData) error {
+	return a.writePrompt(a.buildAnalysisPrompt(data))
 }
 
-func (a *AIAnalyzer) buildAnalysisPrompt(data *models.InspectionData) string {
-	processAge := time.Since(data.Process.CreateTime)
+// writePrompt appends prompt to the dump file, or writes it to stderr
+func (a *AIAnalyzer) writePrompt(prompt string) error {
+	prompt += "\n"
+
+	// Serialized so concurrent prompts neither interleave nor both truncate
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
+	if a.config.DumpPrompt == "-" {
+		_, err := fmt.Fprint(os.Stderr, prompt)
+		return err
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !a.promptDumped {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(a.config.DumpPrompt, flags, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	a.promptDumped = true
+	_, err = file.WriteString(prompt)
+	return err
+}
+
+func (a *AIAnalyzer) buildAnalysisPrompt(data *models.InspectionData) string {
 	prompt := fmt.Sprintf(`You are a senior system administrator and DevOps expert analyzing a running process. Provide intelligent analysis with specific warnings and actionable recommendations.
 
-PROCESS INFORMATION:
+%sPROCESS INFORMATION:
 - PID: %d
 - Name: %s
 - Status: %s
+- Scheduler: %s
 - Command: %s
+- TTY: %s
+- Container: %s
+- Root Filesystem: %s
 - Process Age: %s
-- CPU Usage: %.2f%%
+- CPU Usage: %s
+- CPU Time (cumulative): %.1fs user, %.1fs system
 - Memory RSS: %s (%.2f%% of system)
 - Memory VMS: %s
-- Open Files: %d
-- Network Connections: %d
+- Memory Peak RSS: %s
+- Memory Breakdown: %s
+- Cgroup Memory Limit: %s
+- OOM Killer: %s
+- Open Files: %s
+- Deleted-but-open Files: %d (holding %s)
+- Network Connections: %d (by state: %s)
+- Network Throughput: %s
+- Disk I/O: %s
 - Child Processes: %d
-
+- Threads: %d
+%s%s%s
 SYSTEM CONTEXT:
-- CPU Cores: %d
-- System CPU Usage: %.2f%%
-- Total Memory: %s
-- Used Memory: %s (%.2f%%)
-- Free Memory: %s
-
+- Host: %s
+%s
 ANALYSIS GUIDELINES:
 
 1. RESOURCE USAGE ASSESSMENT:
    - Evaluate if CPU/memory usage is appropriate for this process type
-   - Consider normal vs abnormal patterns for system processes, web servers, databases, etc.
+   - Consider normal vs abnormal patterns for the process type given above (e.g. a database is expected to hold much of the memory and many files, a web server many connections)
    - Flag resource exhaustion risks before they become critical
+   - A large share of CPU time spent in the kernel (system vs user) often means syscall-heavy behavior such as busy polling or tiny I/O
 
 2. PROCESS HEALTH INDICATORS:
    - Check for zombie/stopped processes that need intervention
+   - A process without a TTY is likely a daemon; tailor logging and supervision advice accordingly
    - Assess if file descriptor or connection counts indicate leaks
+   - Many CLOSE_WAIT sockets mean the application isn't closing connections the peer already closed; many TIME_WAIT suggests connection churn without keep-alive or pooling
+   - Compare current RSS with the peak RSS: a process sitting at its peak may still be growing, while one far below its peak has released memory
    - Evaluate if child process count suggests fork bombs or runaway spawning
 
 3. SYSTEM-WIDE IMPACT:
    - Consider how this process affects overall system stability
    - Flag if system resources are constrained and may cause OOM kills
+   - Under a cgroup memory limit, judge memory against the limit rather than host memory: the cgroup is OOM-killed at its limit even with host memory free
    - Identify if the system needs scaling (vertical or horizontal)
 
 4. PREVENTIVE MEASURES & BEST PRACTICES:
@@ -133,78 +418,148 @@ ANALYSIS GUIDELINES:
    - Prioritize immediate actions vs long-term improvements
    - Include investigation steps for unclear issues
 
-FORMAT YOUR RESPONSE:
-- Each warning/recommendation on a separate line
-- Start warnings with "WARNING:" for issues requiring attention
-- Start recommendations with "RECOMMEND:" for preventive measures and best practices
-- If no issues found, respond with "HEALTHY: No issues detected"
-- Maximum 7 items total (warnings + recommendations)
-- Order by priority: critical warnings first, then recommendations
-
-EXAMPLES:
-
-WARNING: High CPU usage (85%%) may indicate performance bottleneck or infinite loop
-RECOMMEND: Set CPU limits using systemd (CPUQuota=80%%) to prevent system-wide impact
-WARNING: Memory usage at 92%% - risk of OOM killer terminating processes
-RECOMMEND: Add swap space or increase RAM; monitor with 'vmstat 1' for memory pressure
-WARNING: 1500 open files detected - possible file descriptor leak
-RECOMMEND: Investigate with 'lsof -p PID' and set ulimit -n to prevent exhaustion
-RECOMMEND: Enable process monitoring with systemd watchdog or supervisord for auto-restart
-RECOMMEND: Configure log rotation to prevent disk space exhaustion
-HEALTHY: No issues detected
-
-YOUR ANALYSIS:`,
+%sYOUR ANALYSIS:`,
+		a.formatProcessType(data.Process),
 		data.Process.PID,
 		data.Process.Name,
 		data.Process.Status,
+		formatScheduler(data.Process.Scheduler),
 		data.Process.CommandLine,
-		processAge.Round(time.Second),
-		data.Process.CPUPercent,
+		formatTerminal(data.Process.Terminal),
+		formatContainer(data.Process),
+		formatRoot(data.Process.Root),
+		formatAge(data.Process),
+		a.formatProcessCPU(data.Process.CPUPercent),
+		data.Process.CPUTimeUser,
+		data.Process.CPUTimeSystem,
 		formatBytes(data.Process.MemoryRSS),
 		data.Process.MemoryPercent,
 		formatBytes(data.Process.MemoryVMS),
-		data.Process.OpenFiles,
+		formatPeak(data.Process.MemoryPeakRSS),
+		formatMemoryBreakdown(data.Process),
+		formatCgroupMemory(data.Process.CgroupMemory),
+		formatOOM(data.Process.OOM),
+		formatOpenFiles(data.Process),
+		len(data.Process.DeletedFiles),
+		formatBytes(data.Process.DeletedFilesSize()),
 		data.Process.Connections,
+		a.formatConnectionStates(data.Process),
+		formatNetRate(data.Process),
+		formatDiskRate(data.Process),
 		data.Process.Children,
-		data.System.CPUCores,
-		data.System.CPUUsage,
-		formatBytes(data.System.MemoryTotal),
-		formatBytes(data.System.MemoryUsed),
-		data.System.MemoryPercent,
-		formatBytes(data.System.MemoryFree),
+		data.Process.NumThreads,
+		formatRestarts(data.Restarts)+formatStall(data.Stalled)+formatHeavyWrites(data.HeavyWrites)+formatFDGrowth(data.FDGrowth)+formatCommandChange(data.CommandChanged)+formatChildChanges(data.ChildChanges),
+		a.promptDetails(data.Process),
+		a.formatTreeTotals(data.TreeTotals),
+		formatHost(data.Host),
+		formatSystemContext(data.System),
+		a.responseFormat(),
 	)
 
 	return prompt
 }
 
-func (a *AIAnalyzer) parseAIResponse(response string) []string {
-	var warnings []string
-	lines := strings.Split(response, "\n")
+// responseFormat tells the model how to lay out its answer: a JSON document
+// matching findingsSchema in structured mode, prefixed lines otherwise
+func (a *AIAnalyzer) responseFormat() string {
+	if a.config.Structured {
+		return fmt.Sprintf(structuredFormat, categoryList(), a.config.MaxFindings)
+	}
+	return fmt.Sprintf(lineFormat, categoryList(), a.config.MaxFindings)
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "WARNING:") {
-			warning := strings.TrimSpace(strings.TrimPrefix(line, "WARNING:"))
-			if warning != "" {
-				warnings = append(warnings, "⚠ "+warning)
-			}
-		} else if strings.HasPrefix(line, "RECOMMEND:") {
-			recommendation := strings.TrimSpace(strings.TrimPrefix(line, "RECOMMEND:"))
-			if recommendation != "" {
-				warnings = append(warnings, "→ "+recommendation)
-			}
-		} else if strings.HasPrefix(line, "HEALTHY:") {
-			// If AI says it's healthy, return empty warnings
-			return []string{}
+// categoryList spells out aiCategories for the prompt: "cpu", "memory", ...
+// or "config"
+func categoryList() string {
+	quoted := make([]string, len(aiCategories))
+	for i, category := range aiCategories {
+		quoted[i] = strconv.Quote(category)
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+const lineFormat = `FORMAT YOUR RESPONSE:
+- Each warning/recommendation on a separate line
+- Start warnings with "WARNING:" for issues requiring attention
+- Start recommendations with "RECOMMEND:" for preventive measures and best practices
+- Tag each warning and recommendation with its category in brackets before the colon, one of %s
+- If no issues found, respond with "HEALTHY: No issues detected"
+- Maximum %d items total (warnings + recommendations)
+- Order by priority: critical warnings first, then recommendations
+
+EXAMPLES:
+
+WARNING [cpu]: High CPU usage (85%%) may indicate performance bottleneck or infinite loop
+RECOMMEND [cpu]: Set CPU limits using systemd (CPUQuota=80%%) to prevent system-wide impact
+WARNING [memory]: Memory usage at 92%% - risk of OOM killer terminating processes
+RECOMMEND [memory]: Add swap space or increase RAM; monitor with 'vmstat 1' for memory pressure
+WARNING [process_health]: 1500 open files detected - possible file descriptor leak
+RECOMMEND [config]: Investigate with 'lsof -p PID' and set ulimit -n to prevent exhaustion
+RECOMMEND [process_health]: Enable process monitoring with systemd watchdog or supervisord for auto-restart
+RECOMMEND [disk]: Configure log rotation to prevent disk space exhaustion
+HEALTHY: No issues detected
+
+`
+
+// aiLinePattern finds WARNING/RECOMMEND/HEALTHY items, each optionally
+// tagged with a category ("WARNING [cpu]:"), anywhere in the response,
+// tolerating list markers, markdown emphasis and code fences that models
+// like to add around the requested format
+var aiLinePattern = regexp.MustCompile(`(?m)^[\s>*#\-\d.)` + "`" + `]*(WARNING|RECOMMEND|HEALTHY)\**(?:\s*\[([\w ]*)\])?\**:\**\s*(.*)$`)
+
+func (a *AIAnalyzer) parseAIResponse(response string) []models.Finding {
+	var warnings []models.Finding
+	seen := make(map[string]bool)
+	healthy := false
+
+	for _, match := range aiLinePattern.FindAllStringSubmatch(response, -1) {
+		kind := match[1]
+		text := strings.TrimSpace(strings.Trim(match[3], "*`"))
+
+		if kind == "HEALTHY" {
+			healthy = true
+			continue
+		}
+
+		// Drop empty, runaway and repeated lines
+		if text == "" || len(text) > maxFindingLength {
+			continue
+		}
+		key := kind + ":" + strings.ToLower(text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		finding := models.Finding{
+			Kind:     models.KindWarning,
+			Severity: models.SeverityWarning,
+			Category: parseCategory(match[2]),
+			Message:  text,
+			Source:   models.SourceAI,
+		}
+		if kind == "RECOMMEND" {
+			finding.Kind = models.KindRecommendation
+			finding.Severity = models.SeverityInfo
 		}
+		warnings = append(warnings, finding)
+	}
+
+	// A HEALTHY verdict only counts when the model didn't also report issues
+	if healthy && len(warnings) == 0 {
+		return []models.Finding{}
+	}
+
+	if len(warnings) > a.config.MaxFindings {
+		warnings = warnings[:a.config.MaxFindings]
 	}
 
 	return warnings
 }
 
 // Fallback rule-based analysis (original implementation)
-func (a *AIAnalyzer) analyzeWithRules(data *models.InspectionData) []string {
-	var warnings []string
+func (a *AIAnalyzer) analyzeWithRules(data *models.InspectionData) []models.Finding {
+	var warnings []models.Finding
 
 	// Analyze CPU usage
 	warnings = append(warnings, a.analyzeCPU(data)...)
@@ -215,133 +570,831 @@ func (a *AIAnalyzer) analyzeWithRules(data *models.InspectionData) []string {
 	// Analyze process behavior
 	warnings = append(warnings, a.analyzeProcess(data)...)
 
+	// Analyze privileges
+	warnings = append(warnings, a.analyzeSecurity(data.Process)...)
+
+	// Analyze resource limits
+	warnings = append(warnings, a.analyzeLimits(data.Process)...)
+
+	// Analyze thread counts against the cores they share
+	warnings = append(warnings, a.analyzeThreads(data)...)
+
+	// Analyze the Go runtime, for Go programs
+	warnings = append(warnings, a.analyzeGoRuntime(data)...)
+
 	// Analyze system health
-	warnings = append(warnings, a.analyzeSystem(data)...)
+	warnings = append(warnings, a.analyzeSystem(data.System)...)
 
-	return warnings
+	return a.applyAllowlist(data.Process, warnings)
 }
 
-func (a *AIAnalyzer) analyzeCPU(data *models.InspectionData) []string {
-	var warnings []string
+// ruleFinding builds a rule-based warning along with the evidence that fired
+// it, filed under the rule's category
+func ruleFinding(severity models.Severity, rule, message string, evidence ...models.Evidence) models.Finding {
+	return models.Finding{
+		Kind:     models.KindWarning,
+		Severity: severity,
+		Category: ruleCategories[rule],
+		Rule:     rule,
+		DocURL:   RuleDocURL(rule),
+		Message:  message,
+		Source:   models.SourceRules,
+		Evidence: evidence,
+	}
+}
 
-	// High process CPU usage
-	if data.Process.CPUPercent > 80 {
-		warnings = append(warnings, fmt.Sprintf(
-			"High CPU usage detected: Process consuming %.2f%% CPU - investigate for performance bottlenecks",
-			data.Process.CPUPercent))
-	} else if data.Process.CPUPercent > 50 {
-		warnings = append(warnings, fmt.Sprintf(
-			"Moderate CPU usage: Process using %.2f%% CPU - monitor for sustained high usage",
-			data.Process.CPUPercent))
+// above records that metric exceeded threshold
+func above(metric string, value, threshold float64) models.Evidence {
+	return models.Evidence{Metric: metric, Value: value, Operator: ">", Threshold: threshold}
+}
+
+// below records that metric fell under threshold
+func below(metric string, value, threshold float64) models.Evidence {
+	return models.Evidence{Metric: metric, Value: value, Operator: "<", Threshold: threshold}
+}
+
+func (a *AIAnalyzer) analyzeCPU(data *models.InspectionData) []models.Finding {
+	var warnings []models.Finding
+
+	// High process CPU usage, per core or of the whole machine by CPUMode
+	cores := runtime.NumCPU()
+	cpuPercent := a.config.CPUMode.Scale(data.Process.CPUPercent, cores)
+	usage := a.config.CPUMode.Describe(data.Process.CPUPercent, cores)
+	limits := a.Thresholds(data.Process)
+	if cpuPercent > limits.CPUCritical {
+		warnings = append(warnings, ruleFinding(models.SeverityCritical, RuleHighCPU, fmt.Sprintf(
+			"High CPU usage detected: Process consuming %s CPU - investigate for performance bottlenecks",
+			usage),
+			above("cpu_percent", cpuPercent, limits.CPUCritical)))
+	} else if cpuPercent > limits.CPUWarning {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleHighCPU, fmt.Sprintf(
+			"Moderate CPU usage: Process using %s CPU - monitor for sustained high usage",
+			usage),
+			above("cpu_percent", cpuPercent, limits.CPUWarning)))
 	}
 
-	// High system CPU usage
-	if data.System.CPUUsage > 90 {
-		warnings = append(warnings, fmt.Sprintf(
-			"Critical system CPU load: %.2f%% usage - immediate attention required",
-			data.System.CPUUsage))
-	} else if data.System.CPUUsage > 75 {
-		warnings = append(warnings, fmt.Sprintf(
-			"High system CPU load: %.2f%% usage - consider load balancing",
-			data.System.CPUUsage))
+	// Disproportionate kernel time; only meaningful once the process has
+	// accumulated enough CPU time for the ratio to be stable
+	totalTime := data.Process.CPUTimeUser + data.Process.CPUTimeSystem
+	if totalTime >= 10 {
+		systemShare := data.Process.CPUTimeSystem / totalTime * 100
+		if systemShare > 50 {
+			warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleKernelCPU, fmt.Sprintf(
+				"High kernel CPU time: %.0f%% of CPU time spent in system calls - profile with 'strace -c -p %d' for syscall-heavy behavior",
+				systemShare, data.Process.PID),
+				above("system_time_percent", systemShare, 50)))
+		}
+	}
+
+	// A busy real-time task starves every normal process on its cores, since
+	// the scheduler runs it whenever it is runnable. Judged per core: that is
+	// how much of a CPU is taken away from everything else.
+	if scheduler := data.Process.Scheduler; scheduler != nil && scheduler.RealTime() {
+		percent := data.Process.CPUPercent
+		severity, threshold := models.SeverityWarning, 50.0
+		if percent > 90 {
+			severity, threshold = models.SeverityCritical, 90
+		}
+		if percent > threshold {
+			warnings = append(warnings, ruleFinding(severity, RuleRealtimeCPU, fmt.Sprintf(
+				"Real-time process using %.0f%% CPU: under %s it preempts every normal process on its cores - check it isn't spinning, or move it to SCHED_OTHER with 'chrt -o -p 0 %d'",
+				percent, scheduler, data.Process.PID),
+				above("cpu_percent", percent, threshold)))
+		}
 	}
 
 	return warnings
 }
 
-func (a *AIAnalyzer) analyzeMemory(data *models.InspectionData) []string {
-	var warnings []string
+func (a *AIAnalyzer) analyzeMemory(data *models.InspectionData) []models.Finding {
+	var warnings []models.Finding
 
-	// High process memory usage
-	if data.Process.MemoryPercent > 10 {
-		warnings = append(warnings, fmt.Sprintf(
-			"High memory usage: Process using %.2f%% of system memory (%s RSS)",
-			data.Process.MemoryPercent, formatBytes(data.Process.MemoryRSS)))
+	// High process memory usage, relative to what the process type needs.
+	// Shared memory segments are left out: they belong to every process
+	// attached to them, and a database's buffer pool is meant to be large.
+	limits := a.Thresholds(data.Process)
+	own := data.Process.OwnMemoryPercent()
+	if own > limits.MemoryPercent {
+		shm := ""
+		if data.Process.MemoryShmem > 0 {
+			shm = fmt.Sprintf(", not counting %s of shared memory segments", formatBytes(data.Process.MemoryShmem))
+		}
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleHighMemory, fmt.Sprintf(
+			"High memory usage: Process using %.2f%% of system memory (%s RSS%s)",
+			own, formatBytes(data.Process.MemoryRSS), shm),
+			above("memory_percent", float64(own), float64(limits.MemoryPercent))))
+	} else if data.Process.MemoryPercent > limits.MemoryPercent && data.Process.ShmemDominated() {
+		warnings = append(warnings, ruleFinding(models.SeverityInfo, RuleSharedMemory, fmt.Sprintf(
+			"RSS of %s (%.2f%% of system memory) is mostly shared memory segments (%s), such as a database buffer pool - shared by every attached process, so not counted as this one's usage",
+			formatBytes(data.Process.MemoryRSS), data.Process.MemoryPercent, formatBytes(data.Process.MemoryShmem)),
+			above("memory_percent", float64(data.Process.MemoryPercent), float64(limits.MemoryPercent)),
+			models.Evidence{Metric: "memory_shmem", Value: float64(data.Process.MemoryShmem), Operator: ">=", Threshold: float64(data.Process.MemoryRSS) / 2}))
 	}
 
-	// Memory leak detection (simplified)
-	if data.Process.MemoryVMS > data.Process.MemoryRSS*3 {
-		warnings = append(warnings, fmt.Sprintf(
+	// Memory leak detection (simplified). Virtual size is held against RSS,
+	// not private memory: private pages are a small share of any process's
+	// address space, leaky or not. Shared memory segments are mapped whole
+	// but touched as needed, so their users' virtual memory always dwarfs
+	// what is resident.
+	if data.Process.MemoryVMS > data.Process.MemoryRSS*3 && !data.Process.ShmemDominated() {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleMemoryLeak, fmt.Sprintf(
 			"Potential memory leak: Virtual memory (%s) significantly exceeds RSS (%s)",
-			formatBytes(data.Process.MemoryVMS), formatBytes(data.Process.MemoryRSS)))
+			formatBytes(data.Process.MemoryVMS), formatBytes(data.Process.MemoryRSS)),
+			above("memory_vms", float64(data.Process.MemoryVMS), float64(data.Process.MemoryRSS*3))))
 	}
 
-	// System memory pressure
-	if data.System.MemoryPercent > 90 {
-		warnings = append(warnings, fmt.Sprintf(
-			"Critical memory pressure: System at %.2f%% - risk of OOM kills",
-			data.System.MemoryPercent))
-	} else if data.System.MemoryPercent > 80 {
-		warnings = append(warnings, fmt.Sprintf(
-			"High memory usage: System at %.2f%% - consider memory optimization",
-			data.System.MemoryPercent))
+	// The OOM killer is close and this process ranks high among its victims
+	if oom := data.Process.OOM; oom != nil && oom.Risk == models.OOMRiskHigh && data.System != nil {
+		severity := models.SeverityWarning
+		if data.System.MemoryPercent >= 95 {
+			severity = models.SeverityCritical
+		}
+		warnings = append(warnings, ruleFinding(severity, RuleOOMRisk, fmt.Sprintf(
+			"High OOM-kill risk: oom_score %d with system memory at %.1f%% - the OOM killer is likely to pick this process; cap its memory or lower oom_score_adj (now %d) to protect it",
+			oom.Score, data.System.MemoryPercent, oom.Adj),
+			models.Evidence{Metric: "oom_score", Value: float64(oom.Score), Operator: ">=", Threshold: 200},
+			models.Evidence{Metric: "system_memory_percent", Value: data.System.MemoryPercent, Operator: ">=", Threshold: 80}))
+	}
+
+	// A container is OOM-killed at its cgroup limit however much memory
+	// the host has free
+	if cgroup := data.Process.CgroupMemory; cgroup != nil {
+		percent := cgroup.Percent()
+		severity, threshold := models.SeverityWarning, 80.0
+		if percent > 90 {
+			severity, threshold = models.SeverityCritical, 90
+		}
+		if percent > threshold {
+			warnings = append(warnings, ruleFinding(severity, RuleCgroupMemory, fmt.Sprintf(
+				"Near cgroup memory limit: %s of %s (%.1f%%) in %s - the kernel OOM-kills inside the cgroup at its limit regardless of free host memory; raise the limit or reduce usage",
+				formatBytes(cgroup.WorkingSet), formatBytes(cgroup.Limit), percent, cgroup.Path),
+				above("cgroup_memory_percent", percent, threshold)))
+		}
 	}
 
 	return warnings
 }
 
-func (a *AIAnalyzer) analyzeProcess(data *models.InspectionData) []string {
-	var warnings []string
+func (a *AIAnalyzer) analyzeProcess(data *models.InspectionData) []models.Finding {
+	var warnings []models.Finding
+	limits := a.Thresholds(data.Process)
 
 	// Check process age
-	processAge := time.Since(data.Process.CreateTime)
-	if processAge < time.Minute {
-		warnings = append(warnings, "Recently started process - monitor for stability during initialization")
+	if processAge, known := data.Process.Age(); known && processAge < time.Minute {
+		warnings = append(warnings, ruleFinding(models.SeverityInfo, RuleRecentStart,
+			"Recently started process - monitor for stability during initialization",
+			below("age_seconds", processAge.Round(time.Second).Seconds(), 60)))
+	}
+
+	// Check for zombie or stopped processes (procfs reports single letters)
+	switch strings.ToLower(data.Process.Status) {
+	case "z", "zombie":
+		warnings = append(warnings, ruleFinding(models.SeverityCritical, RuleZombie,
+			"Zombie process detected - parent should reap this process"))
+	case "t", "stopped":
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleStopped,
+			"Process is currently stopped - may need manual intervention"))
+	}
+
+	// Staying in D state without CPU progress means waiting on I/O that
+	// never completes, e.g. a dead NFS server or failing disk
+	if stall := data.Stalled; stall != nil {
+		warnings = append(warnings, ruleFinding(models.SeverityCritical, RuleStalled, fmt.Sprintf(
+			"Process appears stalled in uninterruptible sleep (D state) for %s with no CPU progress - "+
+				"check /proc/%d/stack and /proc/%d/wchan for where it is blocked, dmesg for hung task warnings, "+
+				"and the disk or network filesystem it is waiting on",
+			time.Since(stall.Since).Round(time.Second), data.Process.PID, data.Process.PID),
+			models.Evidence{Metric: "stalled_samples", Value: float64(stall.Samples), Operator: ">=", Threshold: models.StallSamples}))
+	}
+
+	// Every thread waiting for a lock while none runs or even wakes up over
+	// the sampling window is what a deadlock looks like from outside
+	if deadlocked(data.Process) {
+		wait, pid := data.Process.Wait, data.Process.PID
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleDeadlock, fmt.Sprintf(
+			"Possible deadlock: all %d threads blocked on locks (mostly %s) with no CPU use or context switches - "+
+				"dump every thread's stack to see which locks they wait on and who holds them: "+
+				"gdb -p %d -batch -ex 'thread apply all bt' for native code, dlv attach %d then goroutines -t (or kill -QUIT %d) for Go, "+
+				"jstack %d for Java, py-spy dump --pid %d for Python; cat /proc/%d/task/*/stack shows the kernel side",
+			wait.Total, wait.Channel, pid, pid, pid, pid, pid, pid),
+			models.Evidence{Metric: "threads_on_locks", Value: float64(wait.OnLocks), Operator: ">=", Threshold: float64(wait.Total)},
+			models.Evidence{Metric: "voluntary_switch_rate", Value: *data.Process.ContextSwitches.VoluntaryRate, Operator: "<=", Threshold: 0}))
+	}
+
+	// Repeated restarts while watching suggest a crash loop
+	if restarts := data.Restarts; restarts != nil {
+		severity := models.SeverityWarning
+		if restarts.Count >= flappingRestarts {
+			severity = models.SeverityCritical
+		}
+		times := "times"
+		if restarts.Count == 1 {
+			times = "time"
+		}
+		warnings = append(warnings, ruleFinding(severity, RuleRestarts, fmt.Sprintf(
+			"Process restarted %d %s in the last %s - check its logs and exit status for a crash loop",
+			restarts.Count, times, time.Since(restarts.Since).Round(time.Second)),
+			above("restarts", float64(restarts.Count), 0)))
+	}
+
+	// A different command under the same name or port means the numbers
+	// before and after describe different programs
+	if change := data.CommandChanged; change != nil {
+		warnings = append(warnings, ruleFinding(models.SeverityInfo, RuleCommandChanged, fmt.Sprintf(
+			"Process was relaunched as a different command at %s (hash %s, was %s under PID %d) - a redeploy, changed arguments or a reused PID; "+
+				"compare metrics with earlier samples accordingly",
+			change.At.Format(time.TimeOnly), change.Hash, change.PreviousHash, change.PreviousPID)))
 	}
 
-	// Check for zombie or stopped processes
-	status := strings.ToLower(data.Process.Status)
-	if status == "zombie" {
-		warnings = append(warnings, "Zombie process detected - parent should reap this process")
-	} else if status == "stopped" {
-		warnings = append(warnings, "Process is currently stopped - may need manual intervention")
+	// The binary was upgraded or removed under the running process, which
+	// keeps running the old code until restarted
+	if state := data.Process.ExecutableState; state != "" {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleStaleBinary, fmt.Sprintf(
+			"Running an outdated binary: %s has been %s since the process started - restart it to pick up the new version",
+			data.Process.Executable, state)))
 	}
 
 	// High number of open files
-	if data.Process.OpenFiles > 1000 {
-		warnings = append(warnings, fmt.Sprintf(
+	if data.Process.OpenFiles > limits.OpenFiles {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleFDLeak, fmt.Sprintf(
 			"High file descriptor usage: %d open files - check for file descriptor leaks",
-			data.Process.OpenFiles))
+			data.Process.OpenFiles),
+			above("open_files", float64(data.Process.OpenFiles), float64(limits.OpenFiles))))
+	}
+
+	// A count climbing steadily tick after tick ends in EMFILE; projecting
+	// it warns while there is still time to look
+	if growth := data.FDGrowth; growth != nil {
+		severity := models.SeverityWarning
+		if growth.TimeLeft() < fdExhaustionCritical {
+			severity = models.SeverityCritical
+		}
+		warnings = append(warnings, ruleFinding(severity, RuleFDExhaustion, fmt.Sprintf(
+			"Open files climbing steadily at %.1f/min (%d of %d) - at the current rate, FD limit reached in ~%s; "+
+				"see what keeps being opened with 'ls -l /proc/%d/fd' or 'lsof -p %d' before it fails with \"too many open files\"",
+			growth.Rate*60, growth.OpenFiles, growth.Limit, growth.TimeLeft().Round(time.Second), data.Process.PID, data.Process.PID),
+			above("open_files_per_sec", growth.Rate, 0)))
+	}
+
+	// Deleted files still held open keep consuming disk space
+	if deleted := len(data.Process.DeletedFiles); deleted > 0 {
+		size := data.Process.DeletedFilesSize()
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleDeletedFiles, fmt.Sprintf(
+			"Holding %d deleted files totaling %s - restart or reopen logs to reclaim space",
+			deleted, formatBytes(size)),
+			above("deleted_files", float64(deleted), 0)))
 	}
 
 	// High number of network connections
-	if data.Process.Connections > 100 {
-		warnings = append(warnings, fmt.Sprintf(
+	if data.Process.Connections > limits.Connections {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleConnectionLeak, fmt.Sprintf(
 			"High network connections: %d active connections - monitor for connection leaks",
-			data.Process.Connections))
+			data.Process.Connections),
+			above("connections", float64(data.Process.Connections), float64(limits.Connections))))
+	}
+
+	// Heavy traffic in the process's network namespace
+	if rx, tx := data.Process.NetRxRate, data.Process.NetTxRate; rx != nil && tx != nil {
+		const highThroughput = 100 * 1024 * 1024 // bytes/sec
+		if rate := max(*rx, *tx); rate > highThroughput {
+			warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleHighThroughput, fmt.Sprintf(
+				"High network throughput: %s/s in, %s/s out - check for bulk transfers or traffic loops",
+				formatBytes(uint64(*rx)), formatBytes(uint64(*tx))),
+				above("net_bytes_per_sec", rate, highThroughput)))
+		}
+	}
+
+	// Writing heavily tick after tick, rather than in a burst, fills disks
+	// and starves everything else sharing the device
+	if writes := data.HeavyWrites; writes != nil {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleHeavyWrites, fmt.Sprintf(
+			"Sustained heavy disk writes: %s/s on average for %s - check for runaway logging, temp files or a "+
+				"write-amplifying workload, and how fast the disk is filling",
+			formatBytes(uint64(writes.Rate)), time.Since(writes.Since).Round(time.Second)),
+			above("disk_write_bytes_per_sec", writes.Rate, models.HeavyWriteRate)))
+	}
+
+	// Connection-state leaks are far more specific than the raw count
+	if closeWait := data.Process.ConnectionStates["CLOSE_WAIT"]; closeWait > a.config.CloseWaitThreshold {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleCloseWait, fmt.Sprintf(
+			"%d connections stuck in CLOSE_WAIT - the application isn't closing sockets after the peer hung up; check for missing Close() calls or leaked response bodies",
+			closeWait),
+			above("close_wait", float64(closeWait), float64(a.config.CloseWaitThreshold))))
+	}
+	if timeWait := data.Process.ConnectionStates["TIME_WAIT"]; timeWait > a.config.TimeWaitThreshold {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleTimeWait, fmt.Sprintf(
+			"%d connections in TIME_WAIT - heavy connection churn; enable keep-alive or connection pooling",
+			timeWait),
+			above("time_wait", float64(timeWait), float64(a.config.TimeWaitThreshold))))
 	}
 
 	// Many child processes
-	if data.Process.Children > 50 {
-		warnings = append(warnings, fmt.Sprintf(
+	if data.Process.Children > limits.Children {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleManyChildren, fmt.Sprintf(
 			"Many child processes: %d children - ensure proper process management",
-			data.Process.Children))
+			data.Process.Children),
+			above("children", float64(data.Process.Children), float64(limits.Children))))
 	}
 
 	return warnings
 }
 
-func (a *AIAnalyzer) analyzeSystem(data *models.InspectionData) []string {
-	var warnings []string
+// AnalyzeSystem runs the host-level rules on their own, for inspections that
+// don't target a process
+func (a *AIAnalyzer) AnalyzeSystem(sys *models.SystemInfo) []models.Finding {
+	return a.analyzeSystem(sys)
+}
+
+func (a *AIAnalyzer) analyzeSystem(sys *models.SystemInfo) []models.Finding {
+	if sys == nil {
+		return nil
+	}
+	var warnings []models.Finding
+
+	// High system CPU usage
+	limits := a.Thresholds(nil)
+	if sys.CPUUsage > limits.SystemCPUCritical {
+		warnings = append(warnings, ruleFinding(models.SeverityCritical, RuleSystemCPU, fmt.Sprintf(
+			"Critical system CPU load: %.2f%% usage - immediate attention required",
+			sys.CPUUsage),
+			above("system_cpu_usage", sys.CPUUsage, limits.SystemCPUCritical)))
+	} else if sys.CPUUsage > limits.SystemCPUWarning {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleSystemCPU, fmt.Sprintf(
+			"High system CPU load: %.2f%% usage - consider load balancing",
+			sys.CPUUsage),
+			above("system_cpu_usage", sys.CPUUsage, limits.SystemCPUWarning)))
+	}
+
+	// System memory pressure
+	if sys.MemoryPercent > limits.SystemMemoryCritical {
+		warnings = append(warnings, ruleFinding(models.SeverityCritical, RuleSystemMemory, fmt.Sprintf(
+			"Critical memory pressure: System at %.2f%% - risk of OOM kills",
+			sys.MemoryPercent),
+			above("system_memory_percent", sys.MemoryPercent, limits.SystemMemoryCritical)))
+	} else if sys.MemoryPercent > limits.SystemMemoryWarning {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleSystemMemory, fmt.Sprintf(
+			"High memory usage: System at %.2f%% - consider memory optimization",
+			sys.MemoryPercent),
+			above("system_memory_percent", sys.MemoryPercent, limits.SystemMemoryWarning)))
+	}
 
 	// Low core count with high usage
-	if data.System.CPUCores <= 2 && data.System.CPUUsage > 60 {
-		warnings = append(warnings, fmt.Sprintf(
+	if sys.CPUCores <= 2 && sys.CPUUsage > 60 {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleFewCores, fmt.Sprintf(
 			"Limited CPU resources: Only %d cores with %.2f%% usage - consider scaling up",
-			data.System.CPUCores, data.System.CPUUsage))
+			sys.CPUCores, sys.CPUUsage),
+			models.Evidence{Metric: "cpu_cores", Value: float64(sys.CPUCores), Operator: "<=", Threshold: 2},
+			above("system_cpu_usage", sys.CPUUsage, 60)))
 	}
 
 	// Low available memory
-	freeMemoryPercent := float64(data.System.MemoryFree) / float64(data.System.MemoryTotal) * 100
+	freeMemoryPercent := float64(sys.MemoryFree) / float64(sys.MemoryTotal) * 100
 	if freeMemoryPercent < 10 {
-		warnings = append(warnings, fmt.Sprintf(
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleLowFreeMemory, fmt.Sprintf(
 			"Low free memory: Only %.1f%% free (%s) - system may become unstable",
-			freeMemoryPercent, formatBytes(data.System.MemoryFree)))
+			freeMemoryPercent, formatBytes(sys.MemoryFree)),
+			below("free_memory_percent", freeMemoryPercent, 10)))
+	}
+
+	// Heavy swapping slows everything down well before memory runs out
+	if sys.SwapTotal > 0 && sys.SwapPercent > 80 {
+		warnings = append(warnings, ruleFinding(models.SeverityCritical, RuleHeavySwap, fmt.Sprintf(
+			"Swap nearly exhausted: %.1f%% used (%s) - the OOM killer is likely next",
+			sys.SwapPercent, formatBytes(sys.SwapUsed)),
+			above("swap_percent", sys.SwapPercent, 80)))
+	} else if sys.SwapTotal > 0 && sys.SwapPercent > 50 {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleHeavySwap, fmt.Sprintf(
+			"Heavy swap usage: %.1f%% used (%s) - the system is short on RAM",
+			sys.SwapPercent, formatBytes(sys.SwapUsed)),
+			above("swap_percent", sys.SwapPercent, 50)))
+	}
+
+	// Root filesystem filling up
+	if sys.DiskTotal > 0 && sys.DiskPercent > 90 {
+		warnings = append(warnings, ruleFinding(models.SeverityCritical, RuleDiskFull, fmt.Sprintf(
+			"Root filesystem at %.1f%% - writes will start failing soon; clean up logs or expand the volume",
+			sys.DiskPercent),
+			above("disk_percent", sys.DiskPercent, 90)))
+	} else if sys.DiskTotal > 0 && sys.DiskPercent > 80 {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleDiskFull, fmt.Sprintf(
+			"Root filesystem at %.1f%% - plan cleanup or expansion",
+			sys.DiskPercent),
+			above("disk_percent", sys.DiskPercent, 80)))
+	}
+
+	// Load well beyond what the cores can run means tasks are queueing
+	if sys.CPUCores > 0 {
+		perCore := sys.Load1 / float64(sys.CPUCores)
+		if perCore > 2 {
+			warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleLoadAverage, fmt.Sprintf(
+				"High load average: %.2f on %d cores - runnable tasks are queueing for CPU or blocked on I/O",
+				sys.Load1, sys.CPUCores),
+				above("load_per_core", perCore, 2)))
+		}
 	}
 
 	return warnings
 }
 
+// formatProcessCPU states the CPU usage with its scale, so the model can't
+// mistake a multi-core figure over 100% for an error, or a normalized one for
+// a single core
+func (a *AIAnalyzer) formatProcessCPU(percent float64) string {
+	cores := runtime.NumCPU()
+	if a.config.CPUMode == models.CPUModeNormalized {
+		return fmt.Sprintf("%.2f%% of all %d cores combined (%.1f cores busy)", a.config.CPUMode.Scale(percent, cores), cores, percent/100)
+	}
+	return fmt.Sprintf("%.2f%% (100%% = one core, %d cores available)", percent, cores)
+}
+
+func formatNetRate(proc *models.ProcessInfo) string {
+	if proc.NetRxRate == nil || proc.NetTxRate == nil {
+		return "unavailable"
+	}
+	return fmt.Sprintf("%s/s received, %s/s sent (whole network namespace, host-wide unless containerized)",
+		formatBytes(uint64(*proc.NetRxRate)), formatBytes(uint64(*proc.NetTxRate)))
+}
+
+func formatDiskRate(proc *models.ProcessInfo) string {
+	if proc.DiskReadRate == nil || proc.DiskWriteRate == nil {
+		return "unavailable"
+	}
+	return fmt.Sprintf("%s/s read, %s/s written",
+		formatBytes(uint64(*proc.DiskReadRate)), formatBytes(uint64(*proc.DiskWriteRate)))
+}
+
+func formatMemoryBreakdown(proc *models.ProcessInfo) string {
+	shm := ""
+	if proc.MemoryShmem > 0 {
+		shm = fmt.Sprintf("; %s of RSS is shared memory segments (shm), owned by every attached process rather than this one", formatBytes(proc.MemoryShmem))
+	}
+	if proc.MemoryPrivate == 0 {
+		if shm != "" {
+			return "unavailable" + shm
+		}
+		return "unavailable"
+	}
+	return fmt.Sprintf("%s private, %s shared, %s swapped (RSS counts shared pages, so private+swap is the real cost)%s",
+		formatBytes(proc.MemoryPrivate), formatBytes(proc.MemoryShared), formatBytes(proc.MemorySwap), shm)
+}
+
+// formatScheduler notes that real-time policies preempt everything else,
+// which a bare policy name leaves the model to know
+func formatScheduler(scheduler *models.Scheduler) string {
+	if scheduler == nil {
+		return "unavailable"
+	}
+	if scheduler.RealTime() {
+		return scheduler.String() + ", real-time: it runs ahead of every normal process on its CPUs"
+	}
+	return scheduler.String()
+}
+
+func formatCgroupMemory(cgroup *models.CgroupMemory) string {
+	if cgroup == nil {
+		return "none (bound by host memory only)"
+	}
+	return fmt.Sprintf("%s of %s (%.1f%%) in cgroup %s, excluding reclaimable page cache (%s with it)",
+		formatBytes(cgroup.WorkingSet), formatBytes(cgroup.Limit), cgroup.Percent(), cgroup.Path, formatBytes(cgroup.Usage))
+}
+
+func formatOOM(oom *models.OOMScore) string {
+	if oom == nil {
+		return "unavailable"
+	}
+	return fmt.Sprintf("oom_score %d of 1000 (oom_score_adj %d), %s risk given system memory pressure", oom.Score, oom.Adj, oom.Risk)
+}
+
+// formatAge is how long the process has run, or says it is unknown rather
+// than letting the model reason about a bogus start time
+func formatAge(proc *models.ProcessInfo) string {
+	age, known := proc.Age()
+	if !known {
+		return "unknown"
+	}
+	return age.Round(time.Second).String()
+}
+
+// formatTrend tells the model which way CPU and memory were heading in the
+// moments after the reading; empty without --trend
+func formatTrend(proc *models.ProcessInfo) string {
+	if proc.CPUTrend == "" && proc.MemoryTrend == "" {
+		return ""
+	}
+	describe := func(trend models.Trend) string {
+		switch trend {
+		case models.TrendUp:
+			return "rising"
+		case models.TrendDown:
+			return "falling"
+		case models.TrendSteady:
+			return "steady"
+		}
+		return "unknown"
+	}
+	return fmt.Sprintf("- Immediate Trend: CPU %s, memory %s (two readings moments apart)\n",
+		describe(proc.CPUTrend), describe(proc.MemoryTrend))
+}
+
+func formatPeak(peak uint64) string {
+	if peak == 0 {
+		return "unavailable"
+	}
+	return formatBytes(peak)
+}
+
+// formatOpenFiles describes the descriptors, or on Windows the handles,
+// which aren't comparable with them
+func formatOpenFiles(proc *models.ProcessInfo) string {
+	if proc.Handles > 0 {
+		return fmt.Sprintf("not tracked on Windows; %d handles held (files, registry keys, events, ...)", proc.Handles)
+	}
+	if proc.DescriptorsSkipped {
+		return fmt.Sprintf("%d (limit: %s; too many to list, so types, deleted files and connections were not read)",
+			proc.OpenFiles, formatLimit(proc.MaxOpenFiles))
+	}
+	return fmt.Sprintf("%d (limit: %s; by type: %s)",
+		proc.OpenFiles, formatLimit(proc.MaxOpenFiles), formatOpenFileTypes(proc.OpenFileTypes))
+}
+
+func formatLimit(limit int) string {
+	if limit == 0 {
+		return "unknown/unlimited"
+	}
+	return fmt.Sprintf("%d", limit)
+}
+
+// formatTreeTotals adds the process-plus-descendants totals to the prompt so
+// the assessment covers the whole group; empty without --include-children
+func (a *AIAnalyzer) formatTreeTotals(totals *models.TreeTotals) string {
+	if totals == nil {
+		return ""
+	}
+	return fmt.Sprintf(`
+PROCESS TREE TOTALS (this process plus %d descendants; judge resource usage on these):
+- CPU Usage: %s
+- Memory RSS: %s
+- Open Files: %d
+- Network Connections: %d
+`, totals.Processes-1, a.formatProcessCPU(totals.CPUPercent), formatBytes(totals.MemoryRSS), totals.OpenFiles, totals.Connections)
+}
+
+// formatRestarts tells the model the process is crash looping; empty unless
+// restarts were seen in watch mode
+func formatRestarts(restarts *models.RestartInfo) string {
+	if restarts == nil {
+		return ""
+	}
+	return fmt.Sprintf("- Restarts: %d in the last %s (previous PID %d)\n",
+		restarts.Count, time.Since(restarts.Since).Round(time.Second), restarts.PreviousPID)
+}
+
+// formatCommandChange tells the model the watched process is no longer the
+// program it was; empty unless its command hash changed in watch mode
+func formatCommandChange(change *models.CommandChange) string {
+	if change == nil {
+		return ""
+	}
+	return fmt.Sprintf("- Command changed: relaunched with a different executable or arguments at %s (previous PID %d)\n",
+		change.At.Format(time.TimeOnly), change.PreviousPID)
+}
+
+// formatExecutableState tells the model the process runs an outdated
+// binary; empty unless its executable changed on disk
+func formatExecutableState(proc *models.ProcessInfo) string {
+	if proc.ExecutableState == "" {
+		return ""
+	}
+	return fmt.Sprintf("- Executable: %s has been %s since the process started, so it still runs the old code\n",
+		proc.Executable, proc.ExecutableState)
+}
+
+// deadlocked reports whether every thread of proc waits for a lock and
+// none ran or was woken over the sampling window. Without a window, as with
+// --cpu-interval 0, there is no telling, and it reports false.
+func deadlocked(proc *models.ProcessInfo) bool {
+	switches := proc.ContextSwitches
+	if proc.Wait == nil || !proc.Wait.AllOnLocks() || switches == nil || switches.VoluntaryRate == nil || switches.InvoluntaryRate == nil {
+		return false
+	}
+	return proc.CPUPercent == 0 && *switches.VoluntaryRate == 0 && *switches.InvoluntaryRate == 0
+}
+
+// formatWait tells the model where the threads are blocked, and whether it
+// looks like a deadlock
+func formatWait(proc *models.ProcessInfo) string {
+	if proc.Wait == nil {
+		return ""
+	}
+	line := "- Wait Channel: " + proc.Wait.String()
+	if deadlocked(proc) {
+		line += ", with no CPU use or context switches over the sample: a possible deadlock"
+	}
+	return line + "\n"
+}
+
+// formatStall tells the model the process is stuck in D state; empty
+// unless a stall was seen in watch mode
+func formatStall(stall *models.StallInfo) string {
+	if stall == nil {
+		return ""
+	}
+	return fmt.Sprintf("- Stalled: in uninterruptible sleep (D state) with no CPU progress for %s over %d samples\n",
+		time.Since(stall.Since).Round(time.Second), stall.Samples)
+}
+
+// formatHeavyWrites tells the model the process keeps writing heavily;
+// empty unless that was seen in watch mode
+func formatHeavyWrites(writes *models.HeavyWrites) string {
+	if writes == nil {
+		return ""
+	}
+	return fmt.Sprintf("- Heavy Disk Writes: %s/s on average for %s over %d samples\n",
+		formatBytes(uint64(writes.Rate)), time.Since(writes.Since).Round(time.Second), writes.Samples)
+}
+
+// formatFDGrowth tells the model the open-file count is climbing toward
+// its limit; empty unless that was seen in watch mode
+func formatFDGrowth(growth *models.FDGrowth) string {
+	if growth == nil {
+		return ""
+	}
+	return fmt.Sprintf("- Open File Growth: %.1f/min steadily over %d samples, %d of the %d limit, reached in ~%s at this rate\n",
+		growth.Rate*60, growth.Samples, growth.OpenFiles, growth.Limit, growth.TimeLeft().Round(time.Second))
+}
+
+// formatChildChanges tells the model how fast the process's children come
+// and go; empty unless watching with --follow-children
+func formatChildChanges(changes *models.ChildChanges) string {
+	if changes == nil {
+		return ""
+	}
+	return fmt.Sprintf("- Child Churn: %d children spawned and %d exited over %s of watching (%d new, %d gone since the last sample)\n",
+		changes.TotalSpawned, changes.TotalExited, time.Since(changes.Since).Round(time.Second), len(changes.Spawned), len(changes.Exited))
+}
+
+// formatSystemContext lists the host metrics, or says they couldn't be
+// collected so the model doesn't read missing figures as zeros
+func formatSystemContext(sys *models.SystemInfo) string {
+	if sys == nil {
+		return "- System metrics: unavailable (collection failed); judge the process on its own figures\n"
+	}
+	return fmt.Sprintf(`- CPU Cores: %d
+- System CPU Usage: %.2f%%
+- Total Memory: %s
+- Used Memory: %s (%.2f%%)
+- Free Memory: %s
+- Swap: %s used of %s (%.2f%%)
+- Load Average: %.2f, %.2f, %.2f
+- Root Disk: %s used of %s (%.2f%%)
+%s`,
+		sys.CPUCores,
+		sys.CPUUsage,
+		formatBytes(sys.MemoryTotal),
+		formatBytes(sys.MemoryUsed),
+		sys.MemoryPercent,
+		formatBytes(sys.MemoryFree),
+		formatBytes(sys.SwapUsed),
+		formatBytes(sys.SwapTotal),
+		sys.SwapPercent,
+		sys.Load1,
+		sys.Load5,
+		sys.Load15,
+		formatBytes(sys.DiskUsed),
+		formatBytes(sys.DiskTotal),
+		sys.DiskPercent,
+		formatSystemSamples(sys),
+	)
+}
+
+// formatSystemSamples notes the spread behind averaged system readings, as
+// a prompt line, or "" for a single reading
+func formatSystemSamples(sys *models.SystemInfo) string {
+	if sys.Samples == nil {
+		return ""
+	}
+	cpu, memory := sys.Samples.CPU, sys.Samples.Memory
+	return fmt.Sprintf("- The CPU and memory figures above average %d one-second readings: CPU min %.2f%%, max %.2f%%; memory min %.2f%%, max %.2f%%\n",
+		sys.Samples.Count, cpu.Min, cpu.Max, memory.Min, memory.Max)
+}
+
+func formatHost(host *models.HostInfo) string {
+	if host == nil {
+		return "unknown"
+	}
+	return host.String()
+}
+
+func formatOpenFileTypes(types *models.OpenFileTypes) string {
+	if types == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d files, %d sockets, %d pipes, %d anon inodes, %d other",
+		types.Files, types.Sockets, types.Pipes, types.AnonInodes, types.Other)
+}
+
+func (a *AIAnalyzer) formatConnectionStates(proc *models.ProcessInfo) string {
+	if proc.DescriptorsSkipped {
+		return "not read"
+	}
+	if len(proc.ConnectionStates) == 0 {
+		return "none"
+	}
+	var parts []string
+	for _, state := range proc.SortedConnectionStates() {
+		parts = append(parts, fmt.Sprintf("%s=%d", state.State, state.Count))
+	}
+	return strings.Join(a.capItems(parts), ", ")
+}
+
+// promptDetails lists per-item data (argv, deleted files) for the prompt,
+// each capped at MaxItems so a pathological process can't blow up its size
+func (a *AIAnalyzer) promptDetails(proc *models.ProcessInfo) string {
+	var details strings.Builder
+
+	details.WriteString(a.formatAllowance(proc))
+	details.WriteString(formatGoRuntime(proc))
+	details.WriteString(formatRuntimeEnv(proc))
+	details.WriteString(formatTrend(proc))
+	details.WriteString(formatContextSwitches(proc))
+	details.WriteString(formatWait(proc))
+	details.WriteString(formatPower(proc))
+	details.WriteString(a.formatHotThreads(proc))
+	details.WriteString(formatExecutableState(proc))
+	details.WriteString(formatSecurity(proc))
+	details.WriteString(formatNamespaces(proc))
+	details.WriteString(formatLimits(proc))
+
+	if len(proc.Restricted) > 0 {
+		details.WriteString("- Unreadable without elevated privileges (shown as empty or zero): " + strings.Join(proc.Restricted, ", ") + "\n")
+	}
+
+	if len(proc.CommandLineArgs) > 1 {
+		args := make([]string, len(proc.CommandLineArgs))
+		for i, arg := range proc.CommandLineArgs {
+			args[i] = strconv.Quote(arg)
+		}
+		details.WriteString("- Arguments:\n")
+		for _, arg := range a.capItems(args) {
+			details.WriteString("  - " + arg + "\n")
+		}
+	}
+
+	if len(proc.DeletedFiles) > 0 {
+		files := make([]string, len(proc.DeletedFiles))
+		for i, file := range proc.DeletedFiles {
+			files[i] = fmt.Sprintf("%s (%s)", file.Path, formatBytes(file.Size))
+		}
+		details.WriteString("- Deleted files held open:\n")
+		for _, file := range a.capItems(files) {
+			details.WriteString("  - " + file + "\n")
+		}
+	}
+
+	return details.String()
+}
+
+// capItems keeps the first MaxItems entries and summarizes the rest as a count
+func (a *AIAnalyzer) capItems(items []string) []string {
+	if len(items) <= a.config.MaxItems {
+		return items
+	}
+	kept := append([]string{}, items[:a.config.MaxItems]...)
+	return append(kept, fmt.Sprintf("... and %d more", len(items)-a.config.MaxItems))
+}
+
+func formatContainer(proc *models.ProcessInfo) string {
+	switch {
+	case proc.ContainerID == "":
+		return "none detected"
+	case proc.ContainerImage != "":
+		return fmt.Sprintf("%s (image %s)", proc.ContainerName, proc.ContainerImage)
+	default:
+		return proc.ContainerID
+	}
+}
+
+// formatRoot tells the model when the process sees a different filesystem
+// than the host, which explains files it can't find
+func formatRoot(root string) string {
+	switch root {
+	case "":
+		return "the host's"
+	case models.RootChrooted:
+		return "chrooted to a directory outside the host's view; host paths may not exist for it"
+	case models.RootContainer:
+		return "the container's own, in a separate mount namespace; host paths may not exist for it"
+	}
+	return fmt.Sprintf("chrooted to %s; the paths it opens resolve under that directory", root)
+}
+
+func formatTerminal(terminal string) string {
+	if terminal == "" {
+		return "none (detached, likely a daemon)"
+	}
+	return terminal
+}
+
 func formatBytes(bytes uint64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -352,13 +1405,50 @@ func formatBytes(bytes uint64) string {
 		div *= unit
 		exp++
 	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// Close cleans up the AI client
+// chain returns the providers to try, in order
+func (a *AIAnalyzer) chain() []AIProvider {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.tripped {
+		return nil
+	}
+	return a.providers
+}
+
+// recordAnswer feeds the circuit breaker whether the provider chain answered.
+// Batches and watch runs would otherwise pay every provider's timeout for
+// each process while the API is down.
+func (a *AIAnalyzer) recordAnswer(answered bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if answered {
+		a.failures = 0
+		return
+	}
+	a.failures++
+	if a.failures >= a.config.FailureLimit && !a.tripped {
+		a.tripped = true
+		log.Printf("AI failed %d times in a row. Using rule-based analysis for the rest of this run.\n", a.failures)
+	}
+}
+
+// Close cleans up the AI clients
 func (a *AIAnalyzer) Close() error {
-	if a.client != nil {
-		return a.client.Close()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []error
+	for _, provider := range a.providers {
+		if err := provider.Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return nil
+	// Closed providers are dropped, so closing again is a no-op and any
+	// later analysis falls back to the rules
+	a.providers = nil
+	return errors.Join(errs...)
 }
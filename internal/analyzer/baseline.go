@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"inspektor/internal/baseline"
+	"inspektor/internal/models"
+)
+
+// analyzeBaseline compares the process against its expected profile, if the
+// baseline file has one for it. Absolute thresholds miss a service that has
+// doubled its usual footprint while still looking "normal" in general.
+func (a *AIAnalyzer) analyzeBaseline(data *models.InspectionData) []models.Finding {
+	profile, ok := a.config.Baseline.Lookup(data.Process.Name)
+	if !ok {
+		return nil
+	}
+
+	proc := data.Process
+	metrics := []struct {
+		metric string
+		label  string
+		value  float64
+		r      *baseline.Range
+	}{
+		{"cpu_percent", "CPU %", proc.CPUPercent, profile.CPUPercent},
+		{"memory_mb", "Memory (MB)", float64(proc.MemoryRSS) / (1024 * 1024), profile.MemoryMB},
+		{"open_files", "Open files", float64(proc.OpenFiles), profile.OpenFiles},
+		{"connections", "Connections", float64(proc.Connections), profile.Connections},
+		{"children", "Child processes", float64(proc.Children), profile.Children},
+	}
+
+	var warnings []models.Finding
+	for _, m := range metrics {
+		if m.r == nil {
+			continue
+		}
+
+		var bound float64
+		var direction string
+		var evidence models.Evidence
+		switch {
+		case m.r.Max != nil && m.value > *m.r.Max:
+			bound, direction = *m.r.Max, "above"
+			evidence = above(m.metric, m.value, bound)
+		case m.r.Min != nil && m.value < *m.r.Min:
+			bound, direction = *m.r.Min, "below"
+			evidence = below(m.metric, m.value, bound)
+		default:
+			continue
+		}
+
+		// Deviation relative to the bound it crossed; a zero bound has no
+		// meaningful ratio, so it is only reported as an absolute difference
+		deviation := "n/a"
+		severity := models.SeverityWarning
+		if bound != 0 {
+			ratio := math.Abs(m.value-bound) / bound * 100
+			deviation = fmt.Sprintf("%.0f%%", ratio)
+			if ratio >= 100 {
+				severity = models.SeverityCritical
+			}
+		}
+
+		finding := ruleFinding(severity, RuleBaseline, fmt.Sprintf(
+			"%s %s %s baseline: %.1f vs expected %s %.1f (deviation %s)",
+			m.label, direction, proc.Name, m.value, boundName(direction), bound, deviation),
+			evidence)
+		finding.Source = models.SourceBaseline
+		warnings = append(warnings, finding)
+	}
+
+	return warnings
+}
+
+// baselineFindings reports the process's deviations from its baseline
+// profile. With BaselineAI the model judges whether they are concerning for
+// this kind of process, and its findings, filed under the baseline category,
+// replace the threshold comparison; deviations it finds unremarkable are kept
+// at info. The comparison stands alone offline or when no provider answers.
+func (a *AIAnalyzer) baselineFindings(ctx context.Context, data *models.InspectionData) []models.Finding {
+	deviations := a.analyzeBaseline(data)
+	providers := a.chain()
+	if !a.config.BaselineAI || len(deviations) == 0 || len(providers) == 0 || data.Process.KernelThread {
+		return deviations
+	}
+
+	prompt := a.buildBaselinePrompt(data, deviations)
+	for _, provider := range providers {
+		findings, err := a.askProvider(ctx, provider, prompt, data.Process.PID)
+		if err != nil {
+			log.Printf("AI baseline judgement (%s) failed: %v.\n", provider.Name(), err)
+			continue
+		}
+		if len(findings) == 0 {
+			for i := range deviations {
+				deviations[i].Severity = models.SeverityInfo
+				deviations[i].Message += " - judged expected by the AI"
+			}
+			return deviations
+		}
+		for i := range findings {
+			findings[i].Category = ruleCategories[RuleBaseline]
+		}
+		return findings
+	}
+	log.Println("No AI provider judged the baseline deviations. Falling back to the threshold comparison.")
+	return deviations
+}
+
+// buildBaselinePrompt asks the model whether the deviations from the
+// baseline are a regression worth acting on, given what the process is
+func (a *AIAnalyzer) buildBaselinePrompt(data *models.InspectionData, deviations []models.Finding) string {
+	profile, _ := a.config.Baseline.Lookup(data.Process.Name)
+	proc := data.Process
+
+	var expected strings.Builder
+	for _, m := range []struct {
+		label string
+		value float64
+		r     *baseline.Range
+	}{
+		{"CPU %", proc.CPUPercent, profile.CPUPercent},
+		{"Memory (MB)", float64(proc.MemoryRSS) / (1024 * 1024), profile.MemoryMB},
+		{"Open files", float64(proc.OpenFiles), profile.OpenFiles},
+		{"Connections", float64(proc.Connections), profile.Connections},
+		{"Child processes", float64(proc.Children), profile.Children},
+	} {
+		if m.r != nil {
+			fmt.Fprintf(&expected, "- %s: %.1f (expected %s)\n", m.label, m.value, formatRange(m.r))
+		}
+	}
+	var flagged strings.Builder
+	for _, deviation := range deviations {
+		fmt.Fprintf(&flagged, "- %s\n", deviation.Message)
+	}
+
+	return fmt.Sprintf(`You are a senior system administrator reviewing whether a process has regressed from its known-good resource profile.
+
+%sPROCESS: %s (PID %d), running for %s
+
+SYSTEM CONTEXT:
+%s
+BASELINE (expected ranges from the operator's profile) AND ACTUAL VALUES:
+%s
+DEVIATIONS FOUND BY THRESHOLD COMPARISON:
+%s
+TASK:
+Judge whether each deviation is a real, concerning regression for this type of process, or explainable (e.g. a cache warming up, a traffic peak, a recent start, a baseline drawn too tight). Report concerning deviations as warnings, with the likely cause and how to confirm it; report a baseline that looks wrong as a recommendation to adjust it. If none of the deviations are concerning, answer HEALTHY.
+
+%sYOUR ANALYSIS:`,
+		a.formatProcessType(proc),
+		proc.Name, proc.PID, formatAge(proc),
+		formatSystemContext(data.System),
+		expected.String(),
+		flagged.String(),
+		a.responseFormat(),
+	)
+}
+
+// formatRange spells out a baseline range, e.g. "10.0-50.0" or "at most 50.0"
+func formatRange(r *baseline.Range) string {
+	switch {
+	case r.Min != nil && r.Max != nil:
+		return fmt.Sprintf("%.1f-%.1f", *r.Min, *r.Max)
+	case r.Max != nil:
+		return fmt.Sprintf("at most %.1f", *r.Max)
+	case r.Min != nil:
+		return fmt.Sprintf("at least %.1f", *r.Min)
+	}
+	return "any"
+}
+
+func boundName(direction string) string {
+	if direction == "above" {
+		return "max"
+	}
+	return "min"
+}
@@ -0,0 +1,318 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// maxBatchSize caps how many processes share one prompt; larger batches are
+// split so each prompt stays small enough to be answered in full
+const maxBatchSize = 20
+
+// AnalyzeBatch generates findings for several inspections at once, keyed by
+// PID. Each group of up to maxBatchSize processes takes a single AI call,
+// which also lets the model report patterns shared across processes, such as
+// workers that all leak memory alike; those findings are given to every
+// process they name, with Processes listing them all. Without a provider, or
+// when none answers, each process falls back to the rules.
+func (a *AIAnalyzer) AnalyzeBatch(batch []*models.InspectionData) map[int32][]models.Finding {
+	results := make(map[int32][]models.Finding, len(batch))
+	// Kernel threads are left to the rules, as in AnalyzeAndWarn
+	var userland []*models.InspectionData
+	for _, data := range batch {
+		if data.Process.KernelThread {
+			results[data.Process.PID] = append(a.analyzeWithRules(data), a.baselineFindings(context.Background(), data)...)
+		} else {
+			userland = append(userland, data)
+		}
+	}
+
+	for chunk := range slices.Chunk(userland, maxBatchSize) {
+		if a.config.DumpPrompt != "" {
+			if err := a.writePrompt(a.buildBatchPrompt(chunk)); err != nil {
+				log.Printf("Warning: failed to dump prompt: %v\n", err)
+			}
+		}
+
+		var findings map[int32][]models.Finding
+		if providers := a.chain(); len(providers) > 0 {
+			findings = a.analyzeBatchWithAI(chunk, providers)
+		} else {
+			findings = make(map[int32][]models.Finding, len(chunk))
+			for _, data := range chunk {
+				findings[data.Process.PID] = a.analyzeWithRules(data)
+			}
+		}
+		for _, data := range chunk {
+			results[data.Process.PID] = append(findings[data.Process.PID], a.baselineFindings(context.Background(), data)...)
+		}
+	}
+	return results
+}
+
+// analyzeBatchWithAI asks each provider in turn until one gives a usable
+// answer for the whole chunk, falling back to the rules when the chain fails
+func (a *AIAnalyzer) analyzeBatchWithAI(chunk []*models.InspectionData, providers []AIProvider) map[int32][]models.Finding {
+	prompt := a.buildBatchPrompt(chunk)
+	pids := make([]int32, len(chunk))
+	for idx, data := range chunk {
+		pids[idx] = data.Process.PID
+	}
+
+	for _, provider := range providers {
+		findings, err := a.askProviderBatch(provider, prompt, pids)
+		if err != nil {
+			log.Printf("AI batch analysis (%s) failed: %v.\n", provider.Name(), err)
+			continue
+		}
+		a.recordAnswer(true)
+		if a.config.Verbose {
+			log.Printf("AI batch analysis of %d processes answered by %s\n", len(chunk), provider.Name())
+		}
+		if a.config.Mode == ModeBoth {
+			for _, data := range chunk {
+				findings[data.Process.PID] = mergeFindings(findings[data.Process.PID], a.analyzeWithRules(data))
+			}
+		}
+		return findings
+	}
+
+	log.Println("No AI provider answered. Falling back to rule-based analysis.")
+	a.recordAnswer(false)
+	findings := make(map[int32][]models.Finding, len(chunk))
+	for _, data := range chunk {
+		findings[data.Process.PID] = a.analyzeWithRules(data)
+	}
+	return findings
+}
+
+// askProviderBatch sends a batch prompt to one provider and splits its reply
+// by PID. Processes the reply doesn't mention are taken to be healthy, but a
+// reply that mentions none of them counts as unusable.
+func (a *AIAnalyzer) askProviderBatch(provider AIProvider, prompt string, pids []int32) (findings map[int32][]models.Finding, err error) {
+	var aiResponse string
+	defer func(started time.Time) {
+		a.traceExchange(traceRecord{
+			Provider: provider.Name(),
+			PIDs:     pids,
+			Prompt:   prompt,
+			Response: aiResponse,
+			Findings: findings,
+		}, started, err)
+	}(time.Now())
+
+	aiResponse, err = a.generate(context.Background(), provider, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if a.config.ExplainAI {
+		fmt.Fprintf(os.Stderr, "--- raw response from %s ---\n%s\n--- end of response ---\n",
+			provider.Name(), strings.TrimRight(aiResponse, "\n"))
+	}
+
+	var items []batchFinding
+	if a.config.Structured {
+		items, err = parseStructuredBatch(aiResponse)
+	}
+	if !a.config.Structured || err != nil {
+		items = parseBatchLines(aiResponse)
+	}
+	findings = a.assignBatchFindings(items, pids)
+	if len(findings) == 0 {
+		return nil, fmt.Errorf("response contained no recognizable findings for these processes")
+	}
+	return findings, nil
+}
+
+// batchFinding is one finding of a batch reply and the processes it is about
+type batchFinding struct {
+	pids    []int32
+	finding models.Finding
+}
+
+// assignBatchFindings gives each finding to the processes it names, skipping
+// PIDs that weren't in the batch, and caps each process at MaxFindings. A
+// HEALTHY verdict is a finding-less entry, so every process the model
+// answered for gets an entry.
+func (a *AIAnalyzer) assignBatchFindings(items []batchFinding, pids []int32) map[int32][]models.Finding {
+	findings := make(map[int32][]models.Finding, len(pids))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		named := slices.DeleteFunc(slices.Clone(item.pids), func(pid int32) bool { return !slices.Contains(pids, pid) })
+		if len(named) > 1 {
+			item.finding.Processes = named
+		}
+		for _, pid := range named {
+			if item.finding.Message == "" {
+				if findings[pid] == nil {
+					findings[pid] = []models.Finding{}
+				}
+				continue
+			}
+			key := fmt.Sprintf("%d:%s:%s", pid, item.finding.Kind, strings.ToLower(item.finding.Message))
+			if seen[key] || len(findings[pid]) >= a.config.MaxFindings {
+				continue
+			}
+			seen[key] = true
+			findings[pid] = append(findings[pid], item.finding)
+		}
+	}
+	return findings
+}
+
+// batchLinePattern finds "[PID 1234] WARNING [cpu]: ..." items, where a
+// finding shared by several processes lists them all ("[PIDs 1234,
+// 1240]"), with the same tolerance for markdown as aiLinePattern
+var batchLinePattern = regexp.MustCompile(`(?m)^[\s>*#\-\d.)` + "`" + `]*\[PIDs? ([\d,\s]+)\]\s*\**(WARNING|RECOMMEND|HEALTHY)\**(?:\s*\[([\w ]*)\])?\**:\**\s*(.*)$`)
+
+// parseBatchLines reads a line-format batch reply; nil when no line matched
+func parseBatchLines(response string) []batchFinding {
+	var items []batchFinding
+	for _, match := range batchLinePattern.FindAllStringSubmatch(response, -1) {
+		pids := parsePIDList(match[1])
+		kind := match[2]
+		category := parseCategory(match[3])
+		text := strings.TrimSpace(strings.Trim(match[4], "*`"))
+		if len(pids) == 0 || (kind != "HEALTHY" && (text == "" || len(text) > maxFindingLength)) {
+			continue
+		}
+
+		item := batchFinding{pids: pids}
+		switch kind {
+		case "WARNING":
+			item.finding = models.Finding{Kind: models.KindWarning, Severity: models.SeverityWarning, Category: category, Message: text, Source: models.SourceAI}
+		case "RECOMMEND":
+			item.finding = models.Finding{Kind: models.KindRecommendation, Severity: models.SeverityInfo, Category: category, Message: text, Source: models.SourceAI}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// parseStructuredBatch reads a findingsSchema batch reply, whose items name
+// their processes in pids
+func parseStructuredBatch(response string) ([]batchFinding, error) {
+	structured, err := decodeStructuredResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	items := []batchFinding{}
+	for _, item := range structured {
+		findings := structuredFindings([]structuredFinding{item})
+		if len(findings) == 0 {
+			// An item without a message or recommendation vouches for
+			// the processes it names
+			items = append(items, batchFinding{pids: item.PIDs})
+		}
+		for _, finding := range findings {
+			items = append(items, batchFinding{pids: item.PIDs, finding: finding})
+		}
+	}
+	return items, nil
+}
+
+// parsePIDList reads "1234, 1240" into PIDs, skipping anything else
+func parsePIDList(list string) []int32 {
+	var pids []int32
+	for _, field := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if pid, err := strconv.ParseInt(field, 10, 32); err == nil && pid > 0 {
+			pids = append(pids, int32(pid))
+		}
+	}
+	return pids
+}
+
+// buildBatchPrompt summarizes every process of the chunk, then the host they
+// share, and asks for findings keyed by PID
+func (a *AIAnalyzer) buildBatchPrompt(chunk []*models.InspectionData) string {
+	var prompt strings.Builder
+	prompt.WriteString("You are a senior system administrator and DevOps expert analyzing several processes running on the same host. " +
+		"Provide intelligent analysis with specific warnings and actionable recommendations, for each process and for patterns shared across them.\n\n")
+
+	for _, data := range chunk {
+		proc := data.Process
+		processType := a.classify(proc)
+		if processType == "" {
+			processType = "unknown"
+		}
+		fmt.Fprintf(&prompt, "PROCESS %d (%s):\n", proc.PID, proc.Name)
+		fmt.Fprintf(&prompt, "- Type: %s\n", processType)
+		fmt.Fprintf(&prompt, "- Status: %s, running for %s\n", proc.Status, formatAge(proc))
+		fmt.Fprintf(&prompt, "- Command: %s\n", proc.CommandLine)
+		fmt.Fprintf(&prompt, "- CPU Usage: %s\n", a.formatProcessCPU(proc.CPUPercent))
+		fmt.Fprintf(&prompt, "- Memory RSS: %s (%.2f%% of system), VMS %s, peak %s\n",
+			formatBytes(proc.MemoryRSS), proc.MemoryPercent, formatBytes(proc.MemoryVMS), formatPeak(proc.MemoryPeakRSS))
+		fmt.Fprintf(&prompt, "- Open Files: %s\n", formatOpenFiles(proc))
+		fmt.Fprintf(&prompt, "- Network Connections: %d (by state: %s)\n", proc.Connections, a.formatConnectionStates(proc))
+		fmt.Fprintf(&prompt, "- Child Processes: %d, Threads: %d\n", proc.Children, proc.NumThreads)
+		prompt.WriteString(formatGoRuntime(proc))
+		prompt.WriteString(formatExecutableState(proc))
+		prompt.WriteString(a.formatHotThreads(proc))
+		prompt.WriteString(formatRestarts(data.Restarts) + formatStall(data.Stalled) + formatHeavyWrites(data.HeavyWrites) + formatFDGrowth(data.FDGrowth) + formatCommandChange(data.CommandChanged) + formatChildChanges(data.ChildChanges))
+		if len(proc.DeletedFiles) > 0 {
+			fmt.Fprintf(&prompt, "- Deleted-but-open Files: %d (holding %s)\n", len(proc.DeletedFiles), formatBytes(proc.DeletedFilesSize()))
+		}
+		prompt.WriteString("\n")
+	}
+
+	fmt.Fprintf(&prompt, "SYSTEM CONTEXT:\n- Host: %s\n%s\n", formatHost(chunk[0].Host), formatSystemContext(chunk[0].System))
+
+	prompt.WriteString(`ANALYSIS GUIDELINES:
+- Judge each process against what is normal for its type, and flag resource exhaustion risks before they become critical
+- Look across the processes: several instances of the same program growing, leaking or erroring alike point at a common cause, so report that once for all of them rather than separately for each
+- Consider their combined load on the host as well as each one on its own
+
+`)
+	prompt.WriteString(a.batchResponseFormat())
+	prompt.WriteString("YOUR ANALYSIS:")
+	return prompt.String()
+}
+
+// batchResponseFormat tells the model how to key its answer by PID
+func (a *AIAnalyzer) batchResponseFormat() string {
+	if a.config.Structured {
+		return fmt.Sprintf(structuredBatchFormat, categoryList(), a.config.MaxFindings)
+	}
+	return fmt.Sprintf(batchLineFormat, categoryList(), a.config.MaxFindings)
+}
+
+const batchLineFormat = `FORMAT YOUR RESPONSE:
+- Each warning/recommendation on a separate line, starting with the PID it is about in brackets
+- A finding shared by several processes lists all of their PIDs: [PIDs 1234, 1240]
+- Start warnings with "WARNING:" for issues requiring attention
+- Start recommendations with "RECOMMEND:" for preventive measures and best practices
+- Tag each warning and recommendation with its category in brackets before the colon, one of %s
+- For a process with no issues, respond with "[PID 1234] HEALTHY: No issues detected"
+- Maximum %d items per process, ordered by priority: critical warnings first
+
+EXAMPLES:
+
+[PID 1234] WARNING [cpu]: High CPU usage (85%%) may indicate performance bottleneck or infinite loop
+[PID 1234] RECOMMEND [cpu]: Set CPU limits using systemd (CPUQuota=80%%) to prevent system-wide impact
+[PIDs 2001, 2002, 2003] WARNING [process_health]: All three workers hold over 900 open files - likely the same descriptor leak
+[PID 3050] HEALTHY: No issues detected
+
+`
+
+const structuredBatchFormat = `FORMAT YOUR RESPONSE:
+Respond with a single JSON object and nothing else:
+{"findings": [{"pids": [...], "severity": "...", "category": "...", "message": "...", "recommendation": "..."}]}
+
+- pids lists the PIDs the item is about: one for a single process, several for a pattern they share
+- severity is one of "info", "warning" or "critical"
+- category is one of %s
+- message describes an issue requiring attention; leave it empty for pure best-practice advice
+- recommendation is a specific, actionable fix or preventive measure; leave it empty if none
+- For a process with no issues, add an item with only its pid and empty message and recommendation
+- Maximum %d findings per process, ordered by priority with critical issues first
+
+`
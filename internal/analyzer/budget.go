@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// Budget caps the combined usage of a user's processes; a zero field is not
+// checked. CPUPercent is judged on the scale of Config.CPUMode, like the
+// per-process CPU rules.
+type Budget struct {
+	CPUPercent float64
+	MemoryRSS  uint64
+	Processes  int
+}
+
+// ParseBudget reads a budget such as "cpu=200,rss=4G,procs=50". Sizes for
+// rss accept K, M and G suffixes.
+func ParseBudget(spec string) (Budget, error) {
+	var budget Budget
+	for _, field := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return Budget{}, fmt.Errorf("invalid budget %q (expected e.g. cpu=200,rss=4G,procs=50)", field)
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "cpu":
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || percent <= 0 {
+				return Budget{}, fmt.Errorf("invalid CPU budget %q", value)
+			}
+			budget.CPUPercent = percent
+		case "rss":
+			size, err := parseSize(value)
+			if err != nil || size == 0 {
+				return Budget{}, fmt.Errorf("invalid rss budget %q", value)
+			}
+			budget.MemoryRSS = size
+		case "procs":
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				return Budget{}, fmt.Errorf("invalid procs budget %q", value)
+			}
+			budget.Processes = count
+		default:
+			return Budget{}, fmt.Errorf("unknown budget %q (expected cpu, rss or procs)", key)
+		}
+	}
+	return budget, nil
+}
+
+// parseSize reads a byte count with an optional K, M or G suffix
+func parseSize(value string) (uint64, error) {
+	value = strings.TrimSuffix(strings.ToUpper(value), "B")
+	multiplier := uint64(1)
+	if n := len(value); n > 0 {
+		if m, ok := map[byte]uint64{'K': 1 << 10, 'M': 1 << 20, 'G': 1 << 30}[value[n-1]]; ok {
+			multiplier, value = m, value[:n-1]
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return uint64(number * float64(multiplier)), nil
+}
+
+// AnalyzeUserBudget reports each part of Config.UserBudget that the
+// combined usage of a user's processes exceeds. Going over by half as much
+// again is critical.
+func (a *AIAnalyzer) AnalyzeUserBudget(user string, totals *models.TreeTotals) []models.Finding {
+	budget := a.config.UserBudget
+	cores := runtime.NumCPU()
+	checks := []struct {
+		metric string
+		label  string
+		value  float64
+		limit  float64
+		format func(float64) string
+	}{
+		{"user_cpu_percent", "CPU", a.config.CPUMode.Scale(totals.CPUPercent, cores), budget.CPUPercent,
+			func(v float64) string { return fmt.Sprintf("%.1f%%", v) }},
+		{"user_memory_rss", "memory", float64(totals.MemoryRSS), float64(budget.MemoryRSS),
+			func(v float64) string { return formatBytes(uint64(v)) }},
+		{"user_processes", "process count", float64(totals.Processes), float64(budget.Processes),
+			func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+	}
+
+	var warnings []models.Finding
+	for _, check := range checks {
+		if check.limit == 0 || check.value <= check.limit {
+			continue
+		}
+		severity := models.SeverityWarning
+		if check.value >= check.limit*1.5 {
+			severity = models.SeverityCritical
+		}
+		warnings = append(warnings, ruleFinding(severity, RuleUserBudget, fmt.Sprintf(
+			"User %s is over its %s budget: %s in use against %s allowed",
+			user, check.label, check.format(check.value), check.format(check.limit)),
+			above(check.metric, check.value, check.limit)))
+	}
+	return warnings
+}
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"inspektor/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Process types known to the built-in table; a types file may add others,
+// which are passed to the AI but keep the default rule thresholds
+const (
+	TypeWeb      = "web"
+	TypeDatabase = "database"
+	TypeCache    = "cache"
+	TypeQueue    = "queue"
+)
+
+// ProcessType says which processes belong to a type: those whose name or
+// executable starts with one of Names, or that listen on one of Ports
+type ProcessType struct {
+	Type  string   `yaml:"type"`
+	Names []string `yaml:"names"`
+	Ports []uint32 `yaml:"ports"`
+}
+
+// defaultProcessTypes is consulted after any user-supplied entries. Every
+// entry's names are tried before any ports, since a port says less about a
+// process than its name does.
+var defaultProcessTypes = []ProcessType{
+	{Type: TypeWeb, Names: []string{"nginx", "apache2", "httpd", "caddy", "haproxy", "envoy", "traefik", "lighttpd"}, Ports: []uint32{80, 443, 8080, 8443}},
+	{Type: TypeDatabase, Names: []string{"postgres", "mysqld", "mariadbd", "mongod", "clickhouse", "cockroach"}, Ports: []uint32{5432, 3306, 27017, 26257}},
+	{Type: TypeCache, Names: []string{"redis-server", "memcached", "valkey-server", "keydb-server"}, Ports: []uint32{6379, 11211}},
+	{Type: TypeQueue, Names: []string{"rabbitmq", "kafka", "nats-server", "mosquitto"}, Ports: []uint32{5672, 9092, 4222, 1883}},
+}
+
+// typeThresholds are the rule limits that depend on what a process is for;
+// the rest of models.Thresholds is the same for every type
+type typeThresholds struct {
+	memoryPercent float32
+	openFiles     int
+	connections   int
+	children      int
+}
+
+// thresholdsByType relaxes the limits a type legitimately exceeds: servers
+// hold many connections, databases many files, caches most of the memory,
+// and pre-forking web servers many workers
+var thresholdsByType = map[string]typeThresholds{
+	TypeWeb:      {memoryPercent: 10, openFiles: 10000, connections: 5000, children: 256},
+	TypeDatabase: {memoryPercent: 50, openFiles: 10000, connections: 500, children: 200},
+	TypeCache:    {memoryPercent: 60, openFiles: 10000, connections: 5000, children: 50},
+	TypeQueue:    {memoryPercent: 40, openFiles: 10000, connections: 5000, children: 50},
+}
+
+// LoadProcessTypes reads a YAML list of process types that extend, and take
+// precedence over, the built-in table
+func LoadProcessTypes(path string) ([]ProcessType, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process types: %w", err)
+	}
+
+	var types []ProcessType
+	if err := yaml.Unmarshal(content, &types); err != nil {
+		return nil, fmt.Errorf("failed to parse process types %s: %w", path, err)
+	}
+
+	for idx, t := range types {
+		if t.Type == "" {
+			return nil, fmt.Errorf("process types %s: entry %d has no type", path, idx+1)
+		}
+		if len(t.Names) == 0 && len(t.Ports) == 0 {
+			return nil, fmt.Errorf("process types %s: %s has neither names nor ports", path, t.Type)
+		}
+	}
+
+	return types, nil
+}
+
+// classify infers what kind of service a process is, or "" if unknown
+func (a *AIAnalyzer) classify(proc *models.ProcessInfo) string {
+	types := append(slices.Clone(a.config.ProcessTypes), defaultProcessTypes...)
+
+	names := []string{strings.ToLower(proc.Name)}
+	if proc.Executable != "" {
+		names = append(names, strings.ToLower(filepath.Base(proc.Executable)))
+	}
+	for _, t := range types {
+		for _, prefix := range t.Names {
+			for _, name := range names {
+				if strings.HasPrefix(name, strings.ToLower(prefix)) {
+					return t.Type
+				}
+			}
+		}
+	}
+
+	for _, t := range types {
+		for _, port := range proc.ListenPorts {
+			if slices.Contains(t.Ports, port) {
+				return t.Type
+			}
+		}
+	}
+
+	return ""
+}
+
+// formatProcessType is the type hint placed at the top of the prompt
+func (a *AIAnalyzer) formatProcessType(proc *models.ProcessInfo) string {
+	processType := a.classify(proc)
+	if processType == "" {
+		processType = "unknown"
+	}
+	hint := "PROCESS TYPE: " + processType
+	if len(proc.ListenPorts) > 0 {
+		hint += " (listening on " + strings.Join(a.capItems(proc.ListenPortLabels()), ", ") + ")"
+	}
+	// The services behind well-known ports say what role the process plays
+	// even when its name doesn't
+	if services := slices.Compact(slices.Sorted(maps.Values(proc.PortServices))); len(services) > 0 {
+		hint += "\nSERVICE ROLE: serves " + strings.Join(services, ", ") + " (inferred from its listening ports; tailor the advice to this service)"
+	}
+	return hint + "\n\n"
+}
@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// maxCommentaryLength rejects replies that ramble past a short commentary
+const maxCommentaryLength = 1200
+
+// Comparison is one side of a process comparison: the inspection and the
+// findings it produced
+type Comparison struct {
+	Data     *models.InspectionData
+	Findings []models.Finding
+}
+
+// CompareProcesses writes a short commentary on which of two processes looks
+// healthier. The AI providers are asked first; without one, or if none
+// answers, the verdict is drawn from health scores and warning counts.
+func (a *AIAnalyzer) CompareProcesses(first, second Comparison) string {
+	if providers := a.chain(); len(providers) > 0 {
+		prompt := buildComparePrompt(first, second)
+		for _, provider := range providers {
+			reply, err := a.generate(context.Background(), provider, prompt)
+			reply = strings.TrimSpace(reply)
+			if err == nil && reply != "" && len(reply) <= maxCommentaryLength {
+				a.recordAnswer(true)
+				return reply
+			}
+			if err == nil {
+				err = fmt.Errorf("unusable reply")
+			}
+			log.Printf("AI comparison (%s) failed: %v.\n", provider.Name(), err)
+		}
+		a.recordAnswer(false)
+	}
+	return compareWithRules(first, second)
+}
+
+// compareWithRules prefers the higher health score, then fewer warnings
+func compareWithRules(first, second Comparison) string {
+	firstWarnings, secondWarnings := countWarnings(first.Findings), countWarnings(second.Findings)
+	summary := fmt.Sprintf("health score %d vs %d, %d vs %d warnings",
+		first.Data.HealthScore, second.Data.HealthScore, firstWarnings, secondWarnings)
+
+	better := first
+	switch {
+	case first.Data.HealthScore != second.Data.HealthScore:
+		if second.Data.HealthScore > first.Data.HealthScore {
+			better = second
+		}
+	case firstWarnings != secondWarnings:
+		if secondWarnings < firstWarnings {
+			better = second
+		}
+	default:
+		return fmt.Sprintf("Both processes look equally healthy (%s).", summary)
+	}
+
+	return fmt.Sprintf("PID %d (%s) looks healthier (%s).",
+		better.Data.Process.PID, better.Data.Process.Name, summary)
+}
+
+func countWarnings(findings []models.Finding) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Kind == models.KindWarning {
+			count++
+		}
+	}
+	return count
+}
+
+// buildComparePrompt lays the two processes out side by side with their
+// differences and asks for a brief verdict
+func buildComparePrompt(first, second Comparison) string {
+	var prompt strings.Builder
+	prompt.WriteString("You are a senior system administrator comparing two running processes, for example the old and new instance of a deployment.\n\n")
+
+	for idx, side := range []Comparison{first, second} {
+		proc := side.Data.Process
+		fmt.Fprintf(&prompt, "PROCESS %d: PID %d (%s)\n", idx+1, proc.PID, proc.Name)
+		fmt.Fprintf(&prompt, "- Command: %s\n", proc.CommandLine)
+		fmt.Fprintf(&prompt, "- Status: %s, running for %s\n", proc.Status, formatAge(proc))
+		fmt.Fprintf(&prompt, "- CPU Usage: %.2f%%\n", proc.CPUPercent)
+		fmt.Fprintf(&prompt, "- Memory RSS: %s (%.2f%% of system)\n", formatBytes(proc.MemoryRSS), proc.MemoryPercent)
+		fmt.Fprintf(&prompt, "- Open Files: %d, Connections: %d, Threads: %d, Children: %d\n",
+			proc.OpenFiles, proc.Connections, proc.NumThreads, proc.Children)
+		fmt.Fprintf(&prompt, "- Health Score: %d/100\n", side.Data.HealthScore)
+		for _, finding := range side.Findings {
+			if finding.Kind == models.KindWarning {
+				fmt.Fprintf(&prompt, "- Warning (%s): %s\n", finding.Severity, finding.Message)
+			}
+		}
+		prompt.WriteString("\n")
+	}
+
+	prompt.WriteString("DIFFERENCES (process 2 minus process 1):\n")
+	for _, delta := range models.DiffProcesses(first.Data.Process, second.Data.Process) {
+		fmt.Fprintf(&prompt, "- %s: %+.2f\n", delta.Metric, delta.Delta)
+	}
+
+	prompt.WriteString(`
+In two to four plain sentences, say which process looks healthier and why, naming it by PID, and point out any difference worth investigating. Do not use markdown or lists.
+`)
+	return prompt.String()
+}
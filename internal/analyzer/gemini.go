@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// defaultGeminiModel is used when no --ai-model is given
+const defaultGeminiModel = "gemini-2.5-flash"
+
+// newGeminiClient creates the Gemini client; a variable so tests can see
+// whether one is ever created
+var newGeminiClient = genai.NewClient
+
+// geminiProvider talks to Google's Gemini API. The client is shared, but a
+// GenerativeModel carries mutable settings, so each request gets its own.
+type geminiProvider struct {
+	client     *genai.Client
+	modelName  string
+	structured bool
+}
+
+func newGeminiProvider(modelName string, cfg Config) (*geminiProvider, error) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY not found")
+	}
+
+	client, err := newGeminiClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Gemini client: %w", err)
+	}
+
+	if modelName == "" {
+		modelName = defaultGeminiModel
+	}
+
+	return &geminiProvider{client: client, modelName: modelName, structured: cfg.Structured}, nil
+}
+
+// newModel configures a model handle for one request
+func (g *geminiProvider) newModel() *genai.GenerativeModel {
+	model := g.client.GenerativeModel(g.modelName)
+	model.SetTemperature(0.3) // Lower temperature for more consistent analysis
+	if g.structured {
+		model.ResponseMIMEType = "application/json"
+		model.ResponseSchema = findingsSchema
+	}
+	return model
+}
+
+func (g *geminiProvider) Name() string { return ProviderGemini }
+
+func (g *geminiProvider) Timeout() time.Duration { return 30 * time.Second }
+
+func (g *geminiProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	resp, err := g.newModel().GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("no response received")
+	}
+
+	return fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0]), nil
+}
+
+func (g *geminiProvider) Close() error {
+	return g.client.Close()
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/version"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// goThreadLimit is how many OS threads a Go program may run before they are
+// worth a look: one per P to run Go code, plus room for the ones blocked in
+// syscalls or cgo calls
+func goThreadLimit(procs int) int {
+	return 4*procs + 20
+}
+
+// analyzeGoRuntime applies Go-specific checks to a process found to be a Go
+// program: threads piling up, and GOMAXPROCS not matching the CPUs it has
+func (a *AIAnalyzer) analyzeGoRuntime(data *models.InspectionData) []models.Finding {
+	goRuntime := data.Process.Go
+	if goRuntime == nil {
+		return nil
+	}
+	var warnings []models.Finding
+
+	cores := runtime.NumCPU()
+	if data.System != nil && data.System.CPUCores > 0 {
+		cores = data.System.CPUCores
+	}
+	procs := goRuntime.Procs(cores)
+
+	// Goroutines are multiplexed onto GOMAXPROCS threads; extra threads are
+	// only created for goroutines blocked in syscalls or cgo, and never freed
+	if limit := goThreadLimit(procs); int(data.Process.NumThreads) > limit {
+		warnings = append(warnings, ruleFinding(models.SeverityWarning, RuleGoThreads, fmt.Sprintf(
+			"Go program running %d OS threads for GOMAXPROCS %d - each goroutine blocked in a syscall or cgo call holds a thread; "+
+				"take a goroutine dump (kill -QUIT %d, or /debug/pprof/goroutine?debug=2) to find where they are stuck or leaking",
+			data.Process.NumThreads, procs, data.Process.PID),
+			above("threads", float64(data.Process.NumThreads), float64(limit))))
+	}
+
+	switch {
+	case goRuntime.GOMAXPROCS > cores:
+		warnings = append(warnings, ruleFinding(models.SeverityInfo, RuleGoMaxProcs, fmt.Sprintf(
+			"GOMAXPROCS is set to %d on a %d-core machine - the extra Ps only add scheduling overhead; unset it or lower it to %d",
+			goRuntime.GOMAXPROCS, cores, cores),
+			above("gomaxprocs", float64(goRuntime.GOMAXPROCS), float64(cores))))
+	case goRuntime.GOMAXPROCS == 0 && data.Process.ContainerID != "" && beforeGo125(goRuntime.Version):
+		warnings = append(warnings, ruleFinding(models.SeverityInfo, RuleGoMaxProcs, fmt.Sprintf(
+			"Go program built with %s in a container: before Go 1.25 the runtime sizes GOMAXPROCS to the host's %d cores, not the container's CPU limit - "+
+				"set GOMAXPROCS to the limit (or use go.uber.org/automaxprocs) to avoid CFS throttling",
+			goRuntime.Version, cores)))
+	}
+
+	return warnings
+}
+
+// beforeGo125 reports whether a toolchain predates Go 1.25, the first to
+// derive the default GOMAXPROCS from the cgroup CPU limit. Development
+// builds, which have no comparable version, are assumed to be recent.
+func beforeGo125(toolchain string) bool {
+	toolchain, _, _ = strings.Cut(toolchain, " ")
+	return version.IsValid(toolchain) && version.Compare(toolchain, "go1.25") < 0
+}
+
+// formatGoRuntime tells the model the process is a Go program, steering its
+// advice toward the Go runtime; empty for anything else
+func formatGoRuntime(proc *models.ProcessInfo) string {
+	if proc.Go == nil {
+		return ""
+	}
+	module := ""
+	if proc.Go.Module != "" {
+		module = ", module " + proc.Go.Module
+	}
+	return fmt.Sprintf("- Go Runtime: %s%s (this is a Go program: weigh goroutine leaks, OS threads vs GOMAXPROCS, GOGC/GOMEMLIMIT "+
+		"and heap growth, and suggest pprof endpoints or a goroutine dump where it would help)\n", proc.Go.Describe(), module)
+}
+
+// formatRuntimeEnv lists the runtime tuning variables the process was started
+// with, so advice about heap sizes or thread pools starts from its settings
+func formatRuntimeEnv(proc *models.ProcessInfo) string {
+	if len(proc.RuntimeEnv) == 0 {
+		return ""
+	}
+	var env strings.Builder
+	env.WriteString("- Runtime Environment (tuning variables it was started with; judge heap, GC and thread pool settings against its usage and limits):\n")
+	for _, name := range slices.Sorted(maps.Keys(proc.RuntimeEnv)) {
+		env.WriteString("  - " + name + "=" + proc.RuntimeEnv[name] + "\n")
+	}
+	return env.String()
+}
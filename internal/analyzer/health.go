@@ -0,0 +1,113 @@
+package analyzer
+
+import (
+	"math"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// healthComponent is one weighted input to the health score. Pressure maps a
+// metric onto 0 (no concern) .. 1 (worst case) and the component deducts up
+// to weight points from a perfect score of 100.
+type healthComponent struct {
+	name     string
+	weight   float64
+	pressure func(data *models.InspectionData) float64
+}
+
+// healthComponents documents the scoring weights. They add up to 100 so a
+// process maxing out every dimension scores 0.
+//
+//	cpu           25  process CPU between 50% and 100%
+//	memory        15  process share of RAM between 5% and 25%
+//	system_memory 15  host memory usage between 70% and 95%
+//	swap          15  host swap usage between 20% and 80%
+//	fd_usage      10  open files between 50% and 95% of the NOFILE limit
+//	connections    5  connection count between 50 and 500
+//	status        15  zombie (full) or stopped (two thirds)
+var healthComponents = []healthComponent{
+	{"cpu", 25, func(d *models.InspectionData) float64 {
+		return ramp(d.Process.CPUPercent, 50, 100)
+	}},
+	{"memory", 15, func(d *models.InspectionData) float64 {
+		return ramp(float64(d.Process.OwnMemoryPercent()), 5, 25)
+	}},
+	{"system_memory", 15, func(d *models.InspectionData) float64 {
+		if d.System == nil {
+			return 0
+		}
+		return ramp(d.System.MemoryPercent, 70, 95)
+	}},
+	{"swap", 15, func(d *models.InspectionData) float64 {
+		if d.System == nil || d.System.SwapTotal == 0 {
+			return 0
+		}
+		return ramp(d.System.SwapPercent, 20, 80)
+	}},
+	{"fd_usage", 10, func(d *models.InspectionData) float64 {
+		if d.Process.MaxOpenFiles == 0 {
+			return 0
+		}
+		return ramp(float64(d.Process.OpenFiles)/float64(d.Process.MaxOpenFiles)*100, 50, 95)
+	}},
+	{"connections", 5, func(d *models.InspectionData) float64 {
+		return ramp(float64(d.Process.Connections), 50, 500)
+	}},
+	{"status", 15, func(d *models.InspectionData) float64 {
+		switch strings.ToLower(d.Process.Status) {
+		case "z", "zombie":
+			return 1
+		case "t", "stopped":
+			return 2.0 / 3.0
+		}
+		return 0
+	}},
+}
+
+// HealthScore rates the inspected process from 0 (critical) to 100 (healthy).
+// The score is a deterministic function of the collected metrics so it can be
+// trended and compared across runs. Host metrics that couldn't be collected
+// add no pressure, so the score then rests on the process alone.
+func HealthScore(data *models.InspectionData) int {
+	if data == nil || data.Process == nil {
+		return 0
+	}
+
+	score := 100.0
+	for _, component := range HealthComponents(data) {
+		score -= component.Deduction
+	}
+
+	return int(math.Round(math.Max(0, math.Min(100, score))))
+}
+
+// HealthComponents breaks the health score down into what each input took
+// off it, for --explain-health; every component is listed, those that cost
+// nothing with a zero deduction
+func HealthComponents(data *models.InspectionData) []models.HealthComponent {
+	if data == nil || data.Process == nil {
+		return nil
+	}
+
+	components := make([]models.HealthComponent, len(healthComponents))
+	for idx, component := range healthComponents {
+		components[idx] = models.HealthComponent{
+			Name:      component.name,
+			Weight:    component.weight,
+			Deduction: component.weight * component.pressure(data),
+		}
+	}
+	return components
+}
+
+// ramp maps value linearly onto 0..1 between low and high, clamping outside
+func ramp(value, low, high float64) float64 {
+	if value <= low {
+		return 0
+	}
+	if value >= high {
+		return 1
+	}
+	return (value - low) / (high - low)
+}
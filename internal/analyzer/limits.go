@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// limitWarnPercent and limitCriticalPercent are the shares of a soft limit
+// in use that raise a warning and a critical finding
+const (
+	limitWarnPercent     = 80
+	limitCriticalPercent = 95
+)
+
+// limitAdvice says what happens at each limit and how to raise it
+var limitAdvice = map[string]string{
+	"nofile":  "opening files and sockets will fail with EMFILE; raise LimitNOFILE= (ulimit -n) or look for a descriptor leak",
+	"nproc":   "fork and thread creation will fail; raise LimitNPROC= (ulimit -u)",
+	"memlock": "locking more memory will fail; raise LimitMEMLOCK= (ulimit -l)",
+	"stack":   "deeper recursion will crash it with SIGSEGV; raise LimitSTACK= (ulimit -s) or reduce recursion depth",
+	"cpu":     "the kernel sends SIGXCPU and kills it at the hard limit; raise LimitCPU= (ulimit -t)",
+}
+
+// analyzeLimits flags resource limits the process is close to hitting
+func (a *AIAnalyzer) analyzeLimits(proc *models.ProcessInfo) []models.Finding {
+	var warnings []models.Finding
+	for _, limit := range proc.Limits {
+		percent, ok := limit.UsagePercent()
+		if !ok || percent <= limitWarnPercent {
+			continue
+		}
+		severity := models.SeverityWarning
+		if percent >= limitCriticalPercent {
+			severity = models.SeverityCritical
+		}
+		warnings = append(warnings, ruleFinding(severity, RuleLimit, fmt.Sprintf(
+			"%s limit %.0f%% used (%d of %d) - %s",
+			limit.Resource, percent, *limit.Used, *limit.Soft, limitAdvice[limit.Resource]),
+			above(limit.Resource+"_limit_percent", percent, limitWarnPercent)))
+	}
+	return warnings
+}
+
+// formatLimits lists the resource limits and their usage for the prompt
+func formatLimits(proc *models.ProcessInfo) string {
+	if len(proc.Limits) == 0 {
+		return ""
+	}
+	value := func(v *uint64) string {
+		if v == nil {
+			return "unlimited"
+		}
+		return fmt.Sprintf("%d", *v)
+	}
+	entries := make([]string, len(proc.Limits))
+	for idx, limit := range proc.Limits {
+		used := "?"
+		if limit.Used != nil {
+			used = value(limit.Used)
+		}
+		entries[idx] = fmt.Sprintf("%s %s/%s/%s", limit.Resource, used, value(limit.Soft), value(limit.Hard))
+	}
+	return "- Resource Limits (used/soft/hard; stack and memlock in bytes, cpu in seconds): " + strings.Join(entries, ", ") + "\n"
+}
@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"testing"
+
+	"inspektor/internal/models"
+)
+
+func TestMemoryLeakRule(t *testing.T) {
+	const mib = 1024 * 1024
+	tests := []struct {
+		name string
+		proc models.ProcessInfo
+		want bool
+	}{
+		{
+			// An idle `sleep`: a tiny private footprint is normal and
+			// mustn't be held against the virtual size
+			name: "idle process",
+			proc: models.ProcessInfo{MemoryRSS: 1800 * 1024, MemoryVMS: 2400 * 1024, MemoryPrivate: 100 * 1024},
+			want: false,
+		},
+		{
+			name: "virtual size far above RSS",
+			proc: models.ProcessInfo{MemoryRSS: 10 * mib, MemoryVMS: 100 * mib, MemoryPrivate: 8 * mib},
+			want: true,
+		},
+		{
+			name: "shared memory segments dominate",
+			proc: models.ProcessInfo{MemoryRSS: 10 * mib, MemoryVMS: 100 * mib, MemoryShmem: 8 * mib},
+			want: false,
+		},
+	}
+
+	a := New(Config{NoAI: true})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := &models.InspectionData{Process: &test.proc}
+			got := false
+			for _, finding := range a.analyzeMemory(data) {
+				if finding.Rule == RuleMemoryLeak {
+					got = true
+				}
+			}
+			if got != test.want {
+				t.Errorf("memory_leak raised = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
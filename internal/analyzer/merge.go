@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+
+	"inspektor/internal/models"
+)
+
+// categoryKeywords infers the category of an AI warning that came without
+// one (the line format has no category field). The first category with a
+// matching keyword wins, so the more specific areas come first.
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{"security", []string{"privilege", "capabilit", "as root", "seccomp"}},
+	{"network", []string{"connection", "socket", "close_wait", "time_wait", "network", "throughput"}},
+	{"disk", []string{"disk", "deleted file", "filesystem"}},
+	{"process_health", []string{"zombie", "stopped", "restart", "file descriptor", "open files", "child process"}},
+	{"memory", []string{"memory", "rss", "swap", "oom", "leak"}},
+	{"cpu", []string{"cpu", "load average", "cores"}},
+}
+
+// mergeFindings combines AI and rule findings for ModeBoth. A rule warning
+// that reports the same issue as an AI warning, meaning the same category, is
+// folded into it: the more specific message is kept, at the higher of the two
+// severities, along with the rule's ID and evidence so --fail-on and
+// --explain still work. Each AI warning absorbs at most one rule warning, so
+// distinct issues in one category both survive.
+func mergeFindings(ai, rules []models.Finding) []models.Finding {
+	merged := make([]models.Finding, 0, len(ai)+len(rules))
+	absorbed := make([]bool, len(rules))
+
+	for _, finding := range ai {
+		category := finding.Category
+		if category == "" {
+			category = inferCategory(finding.Message)
+		}
+		if finding.Kind != models.KindWarning || category == "" {
+			merged = append(merged, finding)
+			continue
+		}
+
+		for idx, rule := range rules {
+			if absorbed[idx] || rule.Kind != models.KindWarning || rule.Category != category {
+				continue
+			}
+			absorbed[idx] = true
+
+			if specificity(rule.Message) > specificity(finding.Message) {
+				finding.Message = rule.Message
+			}
+			if rule.Severity.AtLeast(finding.Severity) {
+				finding.Severity = rule.Severity
+			}
+			finding.Category = category
+			finding.Rule = rule.Rule
+			finding.DocURL = rule.DocURL
+			finding.Evidence = rule.Evidence
+			finding.Source = models.SourceMerged
+			break
+		}
+		merged = append(merged, finding)
+	}
+
+	for idx, rule := range rules {
+		if !absorbed[idx] {
+			merged = append(merged, rule)
+		}
+	}
+	return merged
+}
+
+// inferCategory guesses a finding's category from its wording, or "" if
+// nothing matches
+func inferCategory(message string) string {
+	message = strings.ToLower(message)
+	for _, entry := range categoryKeywords {
+		for _, keyword := range entry.keywords {
+			if strings.Contains(message, keyword) {
+				return entry.category
+			}
+		}
+	}
+	return ""
+}
+
+// specificity scores how concrete a message is: every figure it quotes
+// counts for more than its length
+func specificity(message string) int {
+	figures := 0
+	for _, word := range strings.Fields(message) {
+		if strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			figures++
+		}
+	}
+	return figures*100 + len(message)
+}
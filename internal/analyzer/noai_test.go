@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// countGeminiClients replaces the Gemini client constructor for the test
+// with one that only counts its calls
+func countGeminiClients(t *testing.T) *int {
+	t.Helper()
+	calls := new(int)
+	original := newGeminiClient
+	newGeminiClient = func(ctx context.Context, opts ...option.ClientOption) (*genai.Client, error) {
+		*calls++
+		return nil, errors.New("no client in tests")
+	}
+	t.Cleanup(func() { newGeminiClient = original })
+	return calls
+}
+
+func TestNoAICreatesNoClient(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	calls := countGeminiClients(t)
+
+	a := New(Config{NoAI: true, Provider: ProviderGemini})
+	if *calls != 0 {
+		t.Errorf("--no-ai created %d Gemini client(s)", *calls)
+	}
+	if len(a.chain()) != 0 {
+		t.Errorf("--no-ai left %d provider(s) in the chain", len(a.chain()))
+	}
+
+	// The same config without --no-ai does reach the constructor, so the
+	// check above can fail
+	New(Config{Provider: ProviderGemini})
+	if *calls != 1 {
+		t.Errorf("Gemini client created %d times without --no-ai, want 1", *calls)
+	}
+}
+
+func TestNoAIReadsNoEnvFile(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("INSPEKTOR_NOAI_TEST=loaded\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("INSPEKTOR_NOAI_TEST")
+
+	New(Config{NoAI: true, EnvFile: envFile})
+	if value, ok := os.LookupEnv("INSPEKTOR_NOAI_TEST"); ok {
+		os.Unsetenv("INSPEKTOR_NOAI_TEST")
+		t.Errorf("--no-ai loaded the env file (INSPEKTOR_NOAI_TEST=%s)", value)
+	}
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultOllamaHost is where a local Ollama server listens by default
+	defaultOllamaHost = "http://localhost:11434"
+
+	// defaultOllamaModel is used when neither --ai-model nor OLLAMA_MODEL is set
+	defaultOllamaModel = "llama3"
+)
+
+// ollamaProvider runs the analysis on a local Ollama server, so no process
+// data leaves the machine
+type ollamaProvider struct {
+	host       string
+	model      string
+	structured bool
+	client     *http.Client
+}
+
+func newOllamaProvider(model string, cfg Config) *ollamaProvider {
+	host := os.Getenv("OLLAMA_HOST")
+	if host == "" {
+		host = defaultOllamaHost
+	}
+	// OLLAMA_HOST is commonly given without a scheme (e.g. "127.0.0.1:11434")
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+
+	if model == "" {
+		model = os.Getenv("OLLAMA_MODEL")
+	}
+	if model == "" {
+		model = defaultOllamaModel
+	}
+
+	return &ollamaProvider{
+		host:       strings.TrimRight(host, "/"),
+		model:      model,
+		structured: cfg.Structured,
+		client:     &http.Client{},
+	}
+}
+
+func (o *ollamaProvider) Name() string { return ProviderOllama }
+
+// Timeout is generous since local models often run on CPU
+func (o *ollamaProvider) Timeout() time.Duration { return 2 * time.Minute }
+
+func (o *ollamaProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	request := map[string]any{
+		"model":   o.model,
+		"prompt":  prompt,
+		"stream":  false,
+		"options": map[string]any{"temperature": 0.3},
+	}
+	if o.structured {
+		request["format"] = "json"
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.host+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ollama unreachable at %s: %w", o.host, err)
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Response string `json:"response"`
+		Error    string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("failed to decode ollama response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ollama returned %s: %s", resp.Status, reply.Error)
+	}
+
+	return reply.Response, nil
+}
+
+func (o *ollamaProvider) Close() error { return nil }
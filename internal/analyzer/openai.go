@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultOpenAIBaseURL can be overridden with OPENAI_BASE_URL to reach
+	// any OpenAI-compatible endpoint
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	// defaultOpenAIModel is used when no model is given
+	defaultOpenAIModel = "gpt-4o-mini"
+)
+
+// openAIProvider talks to the OpenAI chat completions API
+type openAIProvider struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	structured bool
+	client     *http.Client
+}
+
+func newOpenAIProvider(model string, cfg Config) (*openAIProvider, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY not found")
+	}
+
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+
+	return &openAIProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		structured: cfg.Structured,
+		client:     &http.Client{},
+	}, nil
+}
+
+func (o *openAIProvider) Name() string { return ProviderOpenAI }
+
+func (o *openAIProvider) Timeout() time.Duration { return 30 * time.Second }
+
+func (o *openAIProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	request := map[string]any{
+		"model":       o.model,
+		"temperature": 0.3,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+	}
+	if o.structured {
+		request["response_format"] = map[string]string{"type": "json_object"}
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("openai unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("failed to decode openai response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := resp.Status
+		if reply.Error != nil {
+			message += ": " + reply.Error.Message
+		}
+		return "", fmt.Errorf("openai returned %s", message)
+	}
+	if len(reply.Choices) == 0 {
+		return "", fmt.Errorf("no response received")
+	}
+
+	return reply.Choices[0].Message.Content, nil
+}
+
+func (o *openAIProvider) Close() error { return nil }
@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"inspektor/internal/models"
+)
+
+func TestParseAIResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string
+	}{
+		{
+			name:     "plain lines",
+			response: "WARNING [cpu]: High CPU\nRECOMMEND [cpu]: Set a quota",
+			want:     []string{"warning/cpu: High CPU", "recommendation/cpu: Set a quota"},
+		},
+		{
+			name:     "code fence",
+			response: "```\nWARNING [memory]: RSS keeps growing\n```",
+			want:     []string{"warning/memory: RSS keeps growing"},
+		},
+		{
+			name: "prose before and after",
+			response: "Sure! Here is my analysis of the process.\n\n" +
+				"- **WARNING [disk]:** Log file is 4 GB\n" +
+				"1. RECOMMEND: Rotate the logs\n\n" +
+				"Let me know if you need anything else.",
+			want: []string{"warning/disk: Log file is 4 GB", "recommendation/" + fallbackCategory + ": Rotate the logs"},
+		},
+		{
+			name:     "duplicates dropped",
+			response: "WARNING: Leak\nWARNING: leak\nRECOMMEND: Leak",
+			want:     []string{"warning/" + fallbackCategory + ": Leak", "recommendation/" + fallbackCategory + ": Leak"},
+		},
+		{
+			name:     "runaway line dropped",
+			response: "WARNING: " + strings.Repeat("x", maxFindingLength+1) + "\nWARNING: Short",
+			want:     []string{"warning/" + fallbackCategory + ": Short"},
+		},
+		{
+			name:     "healthy",
+			response: "HEALTHY: No issues detected",
+			want:     []string{},
+		},
+		{
+			name:     "healthy overruled by an issue",
+			response: "HEALTHY: No issues detected\nWARNING [cpu]: Busy",
+			want:     []string{"warning/cpu: Busy"},
+		},
+		{
+			name:     "truncated mid-line",
+			response: "WARNING [cpu]: Busy\nRECOMMEND [cpu]: Lower the",
+			want:     []string{"warning/cpu: Busy", "recommendation/cpu: Lower the"},
+		},
+		{
+			name:     "no items",
+			response: "I could not analyze this process.",
+			want:     []string{},
+		},
+	}
+
+	a := &AIAnalyzer{config: Config{MaxFindings: DefaultMaxFindings}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := describeFindings(a.parseAIResponse(test.response))
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("parseAIResponse(%q) = %q, want %q", test.response, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseAIResponseCap(t *testing.T) {
+	a := &AIAnalyzer{config: Config{MaxFindings: 2}}
+	response := "WARNING: One\nWARNING: Two\nWARNING: Three"
+	if got := a.parseAIResponse(response); len(got) != 2 {
+		t.Errorf("parseAIResponse kept %d findings, want 2", len(got))
+	}
+}
+
+func TestParseStructuredResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "bare document",
+			response: `{"findings": [{"severity": "critical", "category": "memory", "message": "Near OOM", "recommendation": "Raise the limit"}]}`,
+			want:     []string{"warning/memory: Near OOM", "recommendation/memory: Raise the limit"},
+		},
+		{
+			name:     "code fence",
+			response: "```json\n{\"findings\": [{\"severity\": \"info\", \"category\": \"cpu\", \"message\": \"Idle\"}]}\n```",
+			want:     []string{"warning/cpu: Idle"},
+		},
+		{
+			name:     "empty findings",
+			response: `{"findings": []}`,
+			want:     []string{},
+		},
+		{
+			name:     "unknown category and severity",
+			response: `{"findings": [{"severity": "urgent", "category": "gpu", "message": "Hot"}]}`,
+			want:     []string{"warning/" + fallbackCategory + ": Hot"},
+		},
+		{
+			name:     "truncated",
+			response: `{"findings": [{"severity": "warning", "category": "cpu", "mess`,
+			wantErr:  true,
+		},
+		{
+			name:     "prose instead of JSON",
+			response: "The process looks fine.",
+			wantErr:  true,
+		},
+		{
+			name:     "missing findings array",
+			response: `{"result": "ok"}`,
+			wantErr:  true,
+		},
+	}
+
+	a := &AIAnalyzer{config: Config{MaxFindings: DefaultMaxFindings}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings, err := a.parseStructuredResponse(test.response)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseStructuredResponse(%q) succeeded, want an error", test.response)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStructuredResponse(%q): %v", test.response, err)
+			}
+			got := describeFindings(findings)
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("parseStructuredResponse(%q) = %q, want %q", test.response, got, test.want)
+			}
+		})
+	}
+}
+
+// describeFindings renders findings as "kind/category: message" for
+// comparison
+func describeFindings(findings []models.Finding) []string {
+	described := []string{}
+	for _, finding := range findings {
+		described = append(described, string(finding.Kind)+"/"+finding.Category+": "+finding.Message)
+	}
+	return described
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// AIProvider sends a prompt to a language model and returns its text reply
+type AIProvider interface {
+	// Name identifies the provider in logs and messages
+	Name() string
+	// Generate returns the model's reply to prompt
+	Generate(ctx context.Context, prompt string) (string, error)
+	// Timeout bounds a single Generate call
+	Timeout() time.Duration
+	// Close releases the client; Generate must be safe to call concurrently
+	// until then
+	Close() error
+}
+
+// Provider names accepted by Config.Provider. ProviderRules ends a chain
+// explicitly; rule-based analysis is always the last resort anyway.
+const (
+	ProviderGemini = "gemini"
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+	ProviderRules  = "rules"
+)
+
+// newProviders builds the ordered provider chain from Config.Provider, a
+// comma-separated list of names with optional ":model" suffixes (e.g.
+// "gemini,openai:gpt-4o,rules"). Providers that can't be set up are skipped
+// with a warning. With no explicit choice, a Gemini key wins, then a
+// configured Ollama host; an empty chain means rules only.
+func newProviders(cfg Config) []AIProvider {
+	spec := cfg.Provider
+	if spec == "" {
+		switch {
+		case os.Getenv("GEMINI_API_KEY") != "":
+			spec = ProviderGemini
+		case os.Getenv("OLLAMA_HOST") != "":
+			spec = ProviderOllama
+		default:
+			log.Println("Warning: GEMINI_API_KEY not found. AI analysis will use fallback rules.")
+			return nil
+		}
+	}
+
+	entries := strings.Split(spec, ",")
+	var providers []AIProvider
+	for _, entry := range entries {
+		name, model, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if name == ProviderRules {
+			break
+		}
+		// --ai-model only makes sense when there is a single provider
+		if model == "" && len(entries) == 1 {
+			model = cfg.Model
+		}
+
+		provider, err := newProvider(name, model, cfg)
+		if err != nil {
+			log.Printf("Warning: %v. Skipping %s provider.\n", err, name)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+func newProvider(name, model string, cfg Config) (AIProvider, error) {
+	switch name {
+	case ProviderGemini:
+		return newGeminiProvider(model, cfg)
+	case ProviderOllama:
+		return newOllamaProvider(model, cfg), nil
+	case ProviderOpenAI:
+		return newOpenAIProvider(model, cfg)
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q (expected %s, %s, %s or %s)",
+			name, ProviderGemini, ProviderOpenAI, ProviderOllama, ProviderRules)
+	}
+}
+
+// aiTimeout bounds one call to provider: --ai-timeout when set, otherwise
+// the provider's own default, and never more than the overall --timeout
+func (a *AIAnalyzer) aiTimeout(provider AIProvider) time.Duration {
+	timeout := cmp.Or(a.config.AITimeout, provider.Timeout())
+	if a.config.Timeout > 0 {
+		timeout = min(timeout, a.config.Timeout)
+	}
+	return timeout
+}
+
+// generate asks provider for a reply to prompt within aiTimeout, or what is
+// left of ctx's deadline when that comes first, saying so plainly when the
+// deadline is what failed the call
+func (a *AIAnalyzer) generate(ctx context.Context, provider AIProvider, prompt string) (string, error) {
+	timeout := a.aiTimeout(provider)
+	flag := "--ai-timeout"
+	if timeout == a.config.Timeout {
+		flag = "--timeout"
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout, flag = max(time.Until(deadline), 0), "--timeout"
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	reply, err := provider.Generate(ctx, prompt)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("no reply within %s (raise %s to wait longer)", timeout.Round(time.Millisecond), flag)
+	}
+	return reply, err
+}
+
+// ProviderStatus is the outcome of a connectivity check against one provider
+type ProviderStatus struct {
+	Name    string
+	Latency time.Duration
+	Err     error
+}
+
+// pingPrompt is the trivial request used to check that a provider answers
+const pingPrompt = "Reply with the single word OK."
+
+// AIDisabled reports whether the analyzer was built with NoAI
+func (a *AIAnalyzer) AIDisabled() bool {
+	return a.config.NoAI
+}
+
+// CheckProviders sends a trivial prompt to every configured provider in
+// chain order. An empty result means no provider is configured.
+func (a *AIAnalyzer) CheckProviders() []ProviderStatus {
+	providers := a.chain()
+	statuses := make([]ProviderStatus, 0, len(providers))
+	for _, provider := range providers {
+		started := time.Now()
+		reply, err := a.generate(context.Background(), provider, pingPrompt)
+		if err == nil && strings.TrimSpace(reply) == "" {
+			err = fmt.Errorf("empty reply")
+		}
+		statuses = append(statuses, ProviderStatus{Name: provider.Name(), Latency: time.Since(started), Err: err})
+	}
+	return statuses
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// stalledProvider never answers; Generate returns only once its context ends
+type stalledProvider struct{}
+
+func (stalledProvider) Name() string           { return "stalled" }
+func (stalledProvider) Timeout() time.Duration { return time.Minute }
+func (stalledProvider) Close() error           { return nil }
+
+func (stalledProvider) Generate(ctx context.Context, prompt string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+// captureLog sends the standard logger to a buffer for the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestAnalysisEndsWithInspectionDeadline(t *testing.T) {
+	logged := captureLog(t)
+
+	a := New(Config{NoAI: true, AITimeout: time.Minute})
+	a.providers = []AIProvider{stalledProvider{}}
+
+	// Less of the inspection's budget is left than --ai-timeout allows
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	data := &models.InspectionData{Process: &models.ProcessInfo{PID: 42, Name: "worker"}}
+	_, source := a.AnalyzeAndWarn(ctx, data)
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("analysis took %s, past the inspection's deadline", elapsed)
+	}
+	if source != models.SourceRules {
+		t.Errorf("source = %q, want %q", source, models.SourceRules)
+	}
+	if !strings.Contains(logged.String(), "Falling back to rule-based analysis") {
+		t.Errorf("no rule-based fallback note in the log:\n%s", logged)
+	}
+	if !strings.Contains(logged.String(), "raise --timeout") {
+		t.Errorf("the failure doesn't name --timeout:\n%s", logged)
+	}
+}
+
+func TestGenerateKeepsAITimeoutWhenShorter(t *testing.T) {
+	a := New(Config{NoAI: true, AITimeout: 20 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := a.generate(ctx, stalledProvider{}, "prompt")
+	if err == nil || !strings.Contains(err.Error(), "raise --ai-timeout") {
+		t.Errorf("err = %v, want one naming --ai-timeout", err)
+	}
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimited spaces out the calls to a provider so batch, --top-n and
+// watch runs stay within its per-minute quota instead of drawing 429s. One
+// limiter per provider is shared by every inspection of the run, since
+// each provider is built once per analyzer.
+type rateLimited struct {
+	AIProvider
+	limiter *rate.Limiter
+	perMin  float64
+}
+
+// newRateLimited wraps provider in a token bucket refilled at perMinute
+// requests per minute. The bucket holds a single token: quotas are counted
+// per minute, and a burst at the start of a run is what trips them.
+func newRateLimited(provider AIProvider, perMinute float64) *rateLimited {
+	return &rateLimited{
+		AIProvider: provider,
+		limiter:    rate.NewLimiter(rate.Limit(perMinute/time.Minute.Seconds()), 1),
+		perMin:     perMinute,
+	}
+}
+
+// Generate waits for the provider's turn, for as long as ctx allows, then
+// sends prompt
+func (r *rateLimited) Generate(ctx context.Context, prompt string) (string, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return "", fmt.Errorf("waiting for the %.0f requests/minute AI rate limit: %w", r.perMin, err)
+	}
+	return r.AIProvider.Generate(ctx, prompt)
+}
@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"maps"
+	"slices"
+
+	"inspektor/internal/models"
+)
+
+// Rule IDs name each rule-based check. They are stable across releases, so
+// scripts and --fail-on can match on them even when messages are reworded.
+const (
+	RuleHighCPU                = "high_cpu"
+	RuleKernelCPU              = "kernel_cpu"
+	RuleRealtimeCPU            = "realtime_cpu"
+	RuleHighMemory             = "high_memory"
+	RuleMemoryLeak             = "memory_leak"
+	RuleOOMRisk                = "oom_risk"
+	RuleCgroupMemory           = "cgroup_memory"
+	RuleSharedMemory           = "shared_memory"
+	RuleRecentStart            = "recent_start"
+	RuleZombie                 = "zombie"
+	RuleStopped                = "stopped"
+	RuleRestarts               = "restarts"
+	RuleStalled                = "stalled"
+	RuleDeadlock               = "possible_deadlock"
+	RuleStaleBinary            = "stale_binary"
+	RuleFDLeak                 = "fd_leak"
+	RuleFDExhaustion           = "fd_exhaustion"
+	RuleCommandChanged         = "command_changed"
+	RuleDeletedFiles           = "deleted_files"
+	RuleConnectionLeak         = "connection_leak"
+	RuleHighThroughput         = "high_throughput"
+	RuleHeavyWrites            = "heavy_writes"
+	RuleCloseWait              = "close_wait"
+	RuleTimeWait               = "time_wait"
+	RuleManyChildren           = "many_children"
+	RuleLimit                  = "limit"
+	RuleThreadOversubscription = "thread_oversubscription"
+	RuleGoThreads              = "go_threads"
+	RuleGoMaxProcs             = "go_maxprocs"
+	RuleSystemCPU              = "system_cpu"
+	RuleSystemMemory           = "system_memory"
+	RuleFewCores               = "few_cores"
+	RuleLowFreeMemory          = "low_free_memory"
+	RuleHeavySwap              = "heavy_swap"
+	RuleDiskFull               = "disk_full"
+	RuleLoadAverage            = "load_average"
+	RuleBaseline               = "baseline"
+	RulePrivileged             = "privileged"
+	RuleUserBudget             = "user_budget"
+	RuleAllowlisted            = "allowlisted"
+)
+
+// ruleCategories files each rule under the broader area it reports on, the
+// same categories the AI is asked to use
+var ruleCategories = map[string]string{
+	RuleHighCPU:                "cpu",
+	RuleKernelCPU:              "cpu",
+	RuleRealtimeCPU:            "cpu",
+	RuleHighMemory:             "memory",
+	RuleMemoryLeak:             "memory",
+	RuleOOMRisk:                "memory",
+	RuleCgroupMemory:           "memory",
+	RuleSharedMemory:           "memory",
+	RuleRecentStart:            "process_health",
+	RuleZombie:                 "process_health",
+	RuleStopped:                "process_health",
+	RuleRestarts:               "process_health",
+	RuleStalled:                "process_health",
+	RuleDeadlock:               "process_health",
+	RuleStaleBinary:            "process_health",
+	RuleFDLeak:                 "process_health",
+	RuleFDExhaustion:           "process_health",
+	RuleCommandChanged:         "process_health",
+	RuleDeletedFiles:           "disk",
+	RuleConnectionLeak:         "network",
+	RuleHighThroughput:         "network",
+	RuleHeavyWrites:            "disk",
+	RuleCloseWait:              "network",
+	RuleTimeWait:               "network",
+	RuleManyChildren:           "process_health",
+	RuleLimit:                  "process_health",
+	RuleThreadOversubscription: "cpu",
+	RuleGoThreads:              "process_health",
+	RuleGoMaxProcs:             "cpu",
+	RuleSystemCPU:              "cpu",
+	RuleSystemMemory:           "memory",
+	RuleFewCores:               "cpu",
+	RuleLowFreeMemory:          "memory",
+	RuleHeavySwap:              "memory",
+	RuleDiskFull:               "disk",
+	RuleLoadAverage:            "cpu",
+	RuleBaseline:               "baseline",
+	RulePrivileged:             "security",
+	RuleUserBudget:             "budget",
+	RuleAllowlisted:            "policy",
+}
+
+// docsURL is where each rule's cause and remediation are written up, one
+// section per rule ID
+const docsURL = "https://github.com/fayezzouari/inspektor/blob/main/docs/rules.md"
+
+// RuleDocURL links to the documentation of a rule, or is empty for an
+// unknown one
+func RuleDocURL(rule string) string {
+	if _, known := ruleCategories[rule]; !known {
+		return ""
+	}
+	return docsURL + "#" + rule
+}
+
+// Rules returns every rule ID, sorted
+func Rules() []string {
+	return slices.Sorted(maps.Keys(ruleCategories))
+}
+
+// aiCategories are the categories the model must file each finding under,
+// so AI findings filter and group like rule findings do
+var aiCategories = []string{"cpu", "memory", "disk", "network", "process_health", "security", "config"}
+
+// fallbackCategory files an AI finding whose category is missing or not one
+// of aiCategories
+const fallbackCategory = "general"
+
+// Categories returns every category a finding can report under, from rules
+// or the AI, sorted
+func Categories() []string {
+	categories := append(slices.Collect(maps.Values(ruleCategories)), aiCategories...)
+	categories = append(categories, fallbackCategory)
+	slices.Sort(categories)
+	return slices.Compact(categories)
+}
+
+// RuleFindings runs only the rule-based process and system checks, whatever
+// providers are configured. Unlike AI findings, their rules and categories
+// are deterministic, which policy checks such as --fail-on rely on.
+func (a *AIAnalyzer) RuleFindings(data *models.InspectionData) []models.Finding {
+	return a.analyzeWithRules(data)
+}
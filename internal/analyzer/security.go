@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// analyzeSecurity flags overly privileged processes from their effective
+// capabilities and seccomp mode, which are only known on Linux
+func (a *AIAnalyzer) analyzeSecurity(proc *models.ProcessInfo) []models.Finding {
+	if !proc.HasCapability("CAP_SYS_ADMIN") {
+		return nil
+	}
+
+	message := "Process holds CAP_SYS_ADMIN, which is close to full root - drop it (e.g. systemd CapabilityBoundingSet=) unless it is really needed"
+	if proc.HasAllCapabilities() {
+		message = fmt.Sprintf("Process runs with all %d capabilities (root or a privileged container) - run it as an unprivileged user with only the capabilities it needs",
+			len(proc.Capabilities))
+	}
+	if proc.Seccomp == "disabled" {
+		message += "; no seccomp filter restricts its syscalls either"
+	}
+
+	return []models.Finding{ruleFinding(models.SeverityWarning, RulePrivileged, message)}
+}
+
+// formatSecurity describes capabilities and seccomp for the prompt
+func formatSecurity(proc *models.ProcessInfo) string {
+	if proc.Seccomp == "" {
+		return ""
+	}
+	capabilities := "none"
+	if proc.HasAllCapabilities() {
+		capabilities = fmt.Sprintf("all (%d)", len(proc.Capabilities))
+	} else if len(proc.Capabilities) > 0 {
+		capabilities = strings.Join(proc.Capabilities, ", ")
+	}
+	return fmt.Sprintf("- Effective Capabilities: %s\n- Seccomp: %s\n", capabilities, proc.Seccomp)
+}
+
+// formatNamespaces tells the model which namespaces the process shares with
+// the host (PID 1) and which are its own, e.g. a container with host
+// networking; empty where they couldn't be read
+func formatNamespaces(proc *models.ProcessInfo) string {
+	if len(proc.Namespaces) == 0 {
+		return ""
+	}
+	return "- Namespaces (host = shared with PID 1): " + proc.NamespaceSummary() + "\n"
+}
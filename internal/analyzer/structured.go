@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"inspektor/internal/models"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// findingsSchema is the response contract for structured mode. Each item is
+// a problem (message) with an optional fix (recommendation); either may be
+// empty, so pure best-practice advice fits as well.
+var findingsSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"findings": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"severity": {
+						Type: genai.TypeString,
+						Enum: []string{
+							string(models.SeverityInfo),
+							string(models.SeverityWarning),
+							string(models.SeverityCritical),
+						},
+					},
+					"category":       {Type: genai.TypeString, Enum: aiCategories},
+					"message":        {Type: genai.TypeString},
+					"recommendation": {Type: genai.TypeString},
+					// pids names the processes an item is about; only
+					// asked for when several share one prompt
+					"pids": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeInteger}},
+				},
+				Required: []string{"severity", "category", "message"},
+			},
+		},
+	},
+	Required: []string{"findings"},
+}
+
+const structuredFormat = `FORMAT YOUR RESPONSE:
+Respond with a single JSON object and nothing else:
+{"findings": [{"severity": "...", "category": "...", "message": "...", "recommendation": "..."}]}
+
+- severity is one of "info", "warning" or "critical"
+- category is one of %s
+- message describes an issue requiring attention; leave it empty for pure best-practice advice
+- recommendation is a specific, actionable fix or preventive measure; leave it empty if none
+- If no issues are found, return {"findings": []}
+- Maximum %d findings, ordered by priority with critical issues first
+
+`
+
+// structuredFinding mirrors one item of findingsSchema
+type structuredFinding struct {
+	Severity       string  `json:"severity"`
+	Category       string  `json:"category"`
+	Message        string  `json:"message"`
+	Recommendation string  `json:"recommendation"`
+	PIDs           []int32 `json:"pids,omitempty"`
+}
+
+// parseStructuredResponse decodes a findingsSchema document. A message becomes
+// a warning and a recommendation becomes a recommendation, both sharing the
+// item's category.
+func (a *AIAnalyzer) parseStructuredResponse(response string) ([]models.Finding, error) {
+	items, err := decodeStructuredResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := structuredFindings(items)
+	if len(findings) > a.config.MaxFindings {
+		findings = findings[:a.config.MaxFindings]
+	}
+
+	return findings, nil
+}
+
+// decodeStructuredResponse reads the items of a findingsSchema document
+func decodeStructuredResponse(response string) ([]structuredFinding, error) {
+	// Tolerate a markdown code fence around the document
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.Trim(response, "`\n ")
+
+	var doc struct {
+		Findings []structuredFinding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(response), &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode structured response: %w", err)
+	}
+	if doc.Findings == nil {
+		return nil, fmt.Errorf("structured response has no findings array")
+	}
+	return doc.Findings, nil
+}
+
+// structuredFindings turns items into warnings and recommendations, dropping
+// empty, runaway and repeated ones
+func structuredFindings(items []structuredFinding) []models.Finding {
+	findings := []models.Finding{}
+	seen := make(map[string]bool)
+	add := func(finding models.Finding) {
+		key := string(finding.Kind) + ":" + strings.ToLower(finding.Message)
+		if finding.Message == "" || len(finding.Message) > maxFindingLength || seen[key] {
+			return
+		}
+		seen[key] = true
+		findings = append(findings, finding)
+	}
+
+	for _, item := range items {
+		category := parseCategory(item.Category)
+		add(models.Finding{
+			Kind:     models.KindWarning,
+			Severity: parseSeverity(item.Severity),
+			Category: category,
+			Message:  strings.TrimSpace(item.Message),
+			Source:   models.SourceAI,
+		})
+		add(models.Finding{
+			Kind:     models.KindRecommendation,
+			Severity: models.SeverityInfo,
+			Category: category,
+			Message:  strings.TrimSpace(item.Recommendation),
+			Source:   models.SourceAI,
+		})
+	}
+	return findings
+}
+
+// parseSeverity maps a model-supplied severity onto ours, treating anything
+// unrecognised as a plain warning
+func parseSeverity(severity string) models.Severity {
+	switch s := models.Severity(strings.ToLower(strings.TrimSpace(severity))); s {
+	case models.SeverityInfo, models.SeverityWarning, models.SeverityCritical:
+		return s
+	default:
+		return models.SeverityWarning
+	}
+}
+
+// parseCategory maps a model-supplied category onto aiCategories, filing
+// anything missing or unrecognised under fallbackCategory
+func parseCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if slices.Contains(aiCategories, category) {
+		return category
+	}
+	return fallbackCategory
+}
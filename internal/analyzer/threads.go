@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"fmt"
+	"runtime"
+
+	"inspektor/internal/models"
+)
+
+// threadsPerCore is how many threads per CPU core a process may run before
+// they can no longer all be doing useful work
+const threadsPerCore = 50
+
+// preemptionsPerCore is the rate of involuntary context switches, per second
+// and per core, at which threads are clearly fighting over the CPUs
+const preemptionsPerCore = 1000
+
+// analyzeThreads flags a process running far more threads than the machine
+// has cores. Go programs are left to analyzeGoRuntime, which knows how many
+// threads the runtime actually needs.
+func (a *AIAnalyzer) analyzeThreads(data *models.InspectionData) []models.Finding {
+	proc := data.Process
+	if proc.Go != nil {
+		return nil
+	}
+
+	cores := runtime.NumCPU()
+	if data.System != nil && data.System.CPUCores > 0 {
+		cores = data.System.CPUCores
+	}
+	limit := cores * threadsPerCore
+	if int(proc.NumThreads) <= limit {
+		return nil
+	}
+
+	ratio := float64(proc.NumThreads) / float64(cores)
+	evidence := []models.Evidence{above("threads", float64(proc.NumThreads), float64(limit))}
+	message := fmt.Sprintf("%d threads on %d cores (%.0f per core) - at most %d can run at once, the rest only wait and cost memory for their stacks",
+		proc.NumThreads, cores, ratio, cores)
+
+	// Frequent preemption shows the threads are runnable, not just parked
+	// in a pool, and that the switching itself is eating CPU time
+	if switches := proc.ContextSwitches; switches != nil && switches.InvoluntaryRate != nil {
+		if threshold := float64(cores * preemptionsPerCore); *switches.InvoluntaryRate > threshold {
+			message += fmt.Sprintf("; %.0f involuntary context switches/sec show them contending for the CPUs", *switches.InvoluntaryRate)
+			evidence = append(evidence, above("involuntary_switches_per_sec", *switches.InvoluntaryRate, threshold))
+		}
+	}
+	message += fmt.Sprintf(" - right-size the thread pool to around %d-%d threads for CPU-bound work, or move blocking I/O to async calls", cores, 2*cores)
+
+	return []models.Finding{ruleFinding(models.SeverityWarning, RuleThreadOversubscription, message, evidence...)}
+}
+
+// formatContextSwitches gives the model the scheduler's view of the process;
+// empty where the platform doesn't count switches
+func formatContextSwitches(proc *models.ProcessInfo) string {
+	switches := proc.ContextSwitches
+	if switches == nil {
+		return ""
+	}
+	if switches.VoluntaryRate == nil || switches.InvoluntaryRate == nil {
+		return fmt.Sprintf("- Context Switches: %d voluntary, %d involuntary since start\n", switches.Voluntary, switches.Involuntary)
+	}
+	return fmt.Sprintf("- Context Switches: %.0f/sec voluntary (blocking), %.0f/sec involuntary (preempted)\n",
+		*switches.VoluntaryRate, *switches.InvoluntaryRate)
+}
+
+// formatPower gives the model the process's estimated power draw, so it
+// can weigh efficiency improvements; empty without --power
+func formatPower(proc *models.ProcessInfo) string {
+	power := proc.PowerEstimate
+	if power == nil {
+		return ""
+	}
+	if power.Watts == nil || power.PackageWatts == nil {
+		return fmt.Sprintf("- Power (estimate): %.1f%% of the host's busy CPU time, no energy counters for watts\n", power.CPUShare)
+	}
+	return fmt.Sprintf("- Power (estimate, by CPU time share): ~%.1f W of the CPU packages' %.1f W (%.1f%% of busy CPU time)\n",
+		*power.Watts, *power.PackageWatts, power.CPUShare)
+}
+
+// formatHotThreads points the model at the thread burning the most CPU, and
+// how much of the process's usage it accounts for; empty without --threads
+// or when no thread was measured over the sampling window
+func (a *AIAnalyzer) formatHotThreads(proc *models.ProcessInfo) string {
+	if len(proc.HotThreads) == 0 || proc.HotThreads[0].CPUPercent == nil {
+		return ""
+	}
+	hottest := proc.HotThreads[0]
+	share := ""
+	if proc.CPUPercent > 0 {
+		share = fmt.Sprintf(", %.0f%% of the process's CPU", min(*hottest.CPUPercent/proc.CPUPercent*100, 100))
+	}
+	line := fmt.Sprintf("- Hottest Thread: TID %d (%s), state %s, at %s%s",
+		hottest.TID, hottest.Name, hottest.State, a.formatProcessCPU(*hottest.CPUPercent), share)
+	if len(proc.HotThreads) > 1 && proc.HotThreads[1].CPUPercent != nil {
+		next := proc.HotThreads[1]
+		line += fmt.Sprintf("; next TID %d (%s) at %.1f%%", next.TID, next.Name, *next.CPUPercent)
+	}
+	return line + "\n"
+}
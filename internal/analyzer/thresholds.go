@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"inspektor/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ThresholdOverrides replace built-in thresholds for every process,
+// whatever its type; a field left unset keeps the built-in value
+type ThresholdOverrides struct {
+	CPUWarning    *float64 `yaml:"cpu_warning"`
+	CPUCritical   *float64 `yaml:"cpu_critical"`
+	MemoryPercent *float32 `yaml:"memory_percent"`
+	OpenFiles     *int     `yaml:"open_files"`
+	Connections   *int     `yaml:"connections"`
+	Children      *int     `yaml:"children"`
+
+	SystemCPUWarning     *float64 `yaml:"system_cpu_warning"`
+	SystemCPUCritical    *float64 `yaml:"system_cpu_critical"`
+	SystemMemoryWarning  *float64 `yaml:"system_memory_warning"`
+	SystemMemoryCritical *float64 `yaml:"system_memory_critical"`
+}
+
+// LoadThresholds reads threshold overrides from a YAML file
+func LoadThresholds(path string) (ThresholdOverrides, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ThresholdOverrides{}, fmt.Errorf("failed to read thresholds: %w", err)
+	}
+
+	var overrides ThresholdOverrides
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	// An empty file overrides nothing
+	if err := decoder.Decode(&overrides); err != nil && !errors.Is(err, io.EOF) {
+		return ThresholdOverrides{}, fmt.Errorf("failed to parse thresholds %s: %w", path, err)
+	}
+
+	limits := overrides.apply(models.DefaultThresholds)
+	if limits.CPUWarning >= limits.CPUCritical || limits.SystemCPUWarning >= limits.SystemCPUCritical ||
+		limits.SystemMemoryWarning >= limits.SystemMemoryCritical {
+		return ThresholdOverrides{}, fmt.Errorf("thresholds %s: each warning level must be below its critical level", path)
+	}
+	return overrides, nil
+}
+
+// apply sets the overridden fields of limits
+func (o ThresholdOverrides) apply(limits models.Thresholds) models.Thresholds {
+	set(&limits.CPUWarning, o.CPUWarning)
+	set(&limits.CPUCritical, o.CPUCritical)
+	set(&limits.MemoryPercent, o.MemoryPercent)
+	set(&limits.OpenFiles, o.OpenFiles)
+	set(&limits.Connections, o.Connections)
+	set(&limits.Children, o.Children)
+	set(&limits.SystemCPUWarning, o.SystemCPUWarning)
+	set(&limits.SystemCPUCritical, o.SystemCPUCritical)
+	set(&limits.SystemMemoryWarning, o.SystemMemoryWarning)
+	set(&limits.SystemMemoryCritical, o.SystemMemoryCritical)
+	return limits
+}
+
+func set[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// Thresholds returns the limits the rules judge the process by: the
+// built-in ones, relaxed for its type, then any overrides. A nil process
+// gets the limits of an untyped one, which is all system-wide checks need.
+func (a *AIAnalyzer) Thresholds(proc *models.ProcessInfo) models.Thresholds {
+	limits := models.DefaultThresholds
+	if proc != nil {
+		if relaxed, ok := thresholdsByType[a.classify(proc)]; ok {
+			limits.MemoryPercent = relaxed.memoryPercent
+			limits.OpenFiles = relaxed.openFiles
+			limits.Connections = relaxed.connections
+			limits.Children = relaxed.children
+		}
+	}
+	return a.config.Thresholds.apply(limits)
+}
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// SummarizeTop writes one short summary of the host's health from its
+// heaviest processes and the system-level findings. The AI providers are
+// asked first; without one, or if none answers, the summary is assembled
+// from the rankings.
+func (a *AIAnalyzer) SummarizeTop(sys *models.SystemInfo, top *models.TopConsumers, findings []models.Finding) string {
+	if providers := a.chain(); len(providers) > 0 {
+		prompt := buildTopPrompt(sys, top, findings)
+		for _, provider := range providers {
+			reply, err := a.generate(context.Background(), provider, prompt)
+			reply = strings.TrimSpace(reply)
+			if err == nil && reply != "" && len(reply) <= maxCommentaryLength {
+				a.recordAnswer(true)
+				return reply
+			}
+			if err == nil {
+				err = fmt.Errorf("unusable reply")
+			}
+			log.Printf("AI summary (%s) failed: %v.\n", provider.Name(), err)
+		}
+		a.recordAnswer(false)
+	}
+	return summarizeTopWithRules(top, findings)
+}
+
+// summarizeTopWithRules names the heaviest CPU and memory consumers and the
+// system-level warnings, if any
+func summarizeTopWithRules(top *models.TopConsumers, findings []models.Finding) string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "%d processes running.", top.Processes)
+	if len(top.ByCPU) > 0 {
+		heaviest := top.ByCPU[0]
+		fmt.Fprintf(&summary, " Heaviest CPU user is %s (PID %d) at %.1f%%.", heaviest.Name, heaviest.PID, heaviest.CPUPercent)
+	}
+	if len(top.ByMemory) > 0 {
+		heaviest := top.ByMemory[0]
+		fmt.Fprintf(&summary, " Largest memory user is %s (PID %d) with %s RSS.", heaviest.Name, heaviest.PID, formatBytes(heaviest.MemoryRSS))
+	}
+
+	switch warnings := countWarnings(findings); warnings {
+	case 0:
+		summary.WriteString(" No system-level issues found.")
+	case 1:
+		summary.WriteString(" 1 system-level warning needs attention.")
+	default:
+		fmt.Fprintf(&summary, " %d system-level warnings need attention.", warnings)
+	}
+	return summary.String()
+}
+
+// buildTopPrompt lays out the host's state and rankings and asks for a brief
+// verdict on what, if anything, is wrong with it
+func buildTopPrompt(sys *models.SystemInfo, top *models.TopConsumers, findings []models.Finding) string {
+	var prompt strings.Builder
+	prompt.WriteString("You are a senior system administrator looking at the heaviest processes on a host to find out what, if anything, is wrong with it.\n\n")
+
+	prompt.WriteString("SYSTEM:\n")
+	fmt.Fprintf(&prompt, "- Processes: %d\n", top.Processes)
+	fmt.Fprintf(&prompt, "- CPU Cores: %d, System CPU Usage: %.2f%%\n", sys.CPUCores, sys.CPUUsage)
+	fmt.Fprintf(&prompt, "- Memory: %s used of %s (%.2f%%)\n", formatBytes(sys.MemoryUsed), formatBytes(sys.MemoryTotal), sys.MemoryPercent)
+	fmt.Fprintf(&prompt, "- Swap: %s used of %s (%.2f%%)\n", formatBytes(sys.SwapUsed), formatBytes(sys.SwapTotal), sys.SwapPercent)
+	fmt.Fprintf(&prompt, "- Load Average: %.2f, %.2f, %.2f\n", sys.Load1, sys.Load5, sys.Load15)
+	fmt.Fprintf(&prompt, "- Root Disk: %s used of %s (%.2f%%)\n", formatBytes(sys.DiskUsed), formatBytes(sys.DiskTotal), sys.DiskPercent)
+	prompt.WriteString(formatSystemSamples(sys))
+	for _, finding := range findings {
+		if finding.Kind == models.KindWarning {
+			fmt.Fprintf(&prompt, "- Warning (%s): %s\n", finding.Severity, finding.Message)
+		}
+	}
+
+	rankings := []struct {
+		title string
+		usage []models.ProcessUsage
+		value func(models.ProcessUsage) string
+	}{
+		{"TOP BY CPU", top.ByCPU, func(u models.ProcessUsage) string { return fmt.Sprintf("%.1f%%", u.CPUPercent) }},
+		{"TOP BY MEMORY (RSS)", top.ByMemory, func(u models.ProcessUsage) string { return formatBytes(u.MemoryRSS) }},
+		{"TOP BY OPEN FILES", top.ByOpenFiles, func(u models.ProcessUsage) string { return fmt.Sprint(u.OpenFiles) }},
+		{"TOP BY CONNECTIONS", top.ByConnections, func(u models.ProcessUsage) string { return fmt.Sprint(u.Connections) }},
+	}
+	for _, ranking := range rankings {
+		fmt.Fprintf(&prompt, "\n%s:\n", ranking.title)
+		for _, usage := range ranking.usage {
+			self := ""
+			if usage.Self {
+				self = " [inspektor itself, sampling]"
+			}
+			fmt.Fprintf(&prompt, "- %s (PID %d)%s: %s\n", usage.Name, usage.PID, self, ranking.value(usage))
+		}
+	}
+
+	prompt.WriteString(`
+In two to four plain sentences, summarize the overall health of this host, naming any process that stands out by PID and what to look into first. Do not use markdown or lists.
+`)
+	return prompt.String()
+}
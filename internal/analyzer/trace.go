@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// traceRecord is one exchange with a provider as --ai-trace writes it: the
+// prompt exactly as sent, the reply exactly as received and what it was
+// parsed into, keyed by PID so single and batch prompts read the same
+type traceRecord struct {
+	Time       time.Time                  `json:"time"`
+	Provider   string                     `json:"provider"`
+	PIDs       []int32                    `json:"pids"`
+	Prompt     string                     `json:"prompt"`
+	Response   string                     `json:"response"`
+	Findings   map[int32][]models.Finding `json:"findings"`
+	Error      string                     `json:"error,omitempty"`
+	DurationMS int64                      `json:"duration_ms"`
+}
+
+// traceExchange appends one exchange to the trace file, if there is one. The
+// prompt is built from the collected data after command lines and
+// environments were redacted, so secrets masked in the report are masked in
+// the trace too. Like a prompt dump, the file is truncated on the first
+// exchange and appended to afterwards.
+func (a *AIAnalyzer) traceExchange(record traceRecord, started time.Time, err error) {
+	if a.config.AITrace == "" {
+		return
+	}
+	record.Time = started
+	record.DurationMS = time.Since(started).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+	}
+	line, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		log.Printf("Warning: failed to write AI trace: %v\n", marshalErr)
+		return
+	}
+
+	// Serialized so concurrent exchanges neither interleave nor both truncate
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !a.traceStarted {
+		flags |= os.O_TRUNC
+	}
+	file, openErr := os.OpenFile(a.config.AITrace, flags, 0o600)
+	if openErr != nil {
+		log.Printf("Warning: failed to write AI trace: %v\n", openErr)
+		return
+	}
+	defer file.Close()
+
+	a.traceStarted = true
+	if _, writeErr := file.Write(append(line, '\n')); writeErr != nil {
+		log.Printf("Warning: failed to write AI trace: %v\n", writeErr)
+	}
+}
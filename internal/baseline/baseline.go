@@ -0,0 +1,65 @@
+package baseline
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Range is the expected band for a metric; either bound may be omitted
+type Range struct {
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+}
+
+// Profile describes the normal resource footprint of one service
+type Profile struct {
+	CPUPercent  *Range `yaml:"cpu_percent"`
+	MemoryMB    *Range `yaml:"memory_mb"`
+	OpenFiles   *Range `yaml:"open_files"`
+	Connections *Range `yaml:"connections"`
+	Children    *Range `yaml:"children"`
+}
+
+// Profiles maps process names to their expected profile
+type Profiles map[string]Profile
+
+// Load reads a YAML baseline file keyed by process name
+func Load(path string) (Profiles, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var profiles Profiles
+	if err := yaml.Unmarshal(content, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+
+	for name, profile := range profiles {
+		for metric, r := range profile.ranges() {
+			if r != nil && r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+				return nil, fmt.Errorf("baseline %s: %s.%s has min above max", path, name, metric)
+			}
+		}
+	}
+
+	return profiles, nil
+}
+
+// Lookup returns the profile for a process name, if one exists
+func (p Profiles) Lookup(name string) (Profile, bool) {
+	profile, ok := p[name]
+	return profile, ok
+}
+
+func (p Profile) ranges() map[string]*Range {
+	return map[string]*Range{
+		"cpu_percent": p.CPUPercent,
+		"memory_mb":   p.MemoryMB,
+		"open_files":  p.OpenFiles,
+		"connections": p.Connections,
+		"children":    p.Children,
+	}
+}
@@ -2,6 +2,10 @@ package display
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
@@ -25,8 +29,12 @@ var processingStyle = lipgloss.NewStyle().
 	Align(lipgloss.Center).
 	MarginTop(1)
 
-// ShowBanner displays the INSPEKTOR banner with a processing message
+// ShowBanner displays the INSPEKTOR banner with a processing message.
+// Nothing is written when stdout isn't a terminal, keeping logs clean.
 func ShowBanner(message string) {
+	if !stdoutIsTerminal() {
+		return
+	}
 	fmt.Println()
 	fmt.Println(bannerStyle.Render(banner))
 	fmt.Println()
@@ -36,11 +44,80 @@ func ShowBanner(message string) {
 	}
 }
 
-// ShowProcessingAnimation displays an animated processing message
+// SpinnerStyle selects the frames of the processing animation
+type SpinnerStyle string
+
+const (
+	// SpinnerDots is the braille spinner
+	SpinnerDots SpinnerStyle = "dots"
+	// SpinnerLine is plain ASCII, for fonts that render braille as boxes
+	SpinnerLine SpinnerStyle = "line"
+	// SpinnerArrow turns an arrow through eight directions
+	SpinnerArrow SpinnerStyle = "arrow"
+	// SpinnerNone prints the message once, without animating, so captured
+	// logs get one line instead of hundreds of redraws
+	SpinnerNone SpinnerStyle = "none"
+)
+
+// AllSpinnerStyles are the values accepted by --spinner
+var AllSpinnerStyles = []SpinnerStyle{SpinnerDots, SpinnerLine, SpinnerArrow, SpinnerNone}
+
+// spinnerFrames are the frames of each animated style
+var spinnerFrames = map[SpinnerStyle][]string{
+	SpinnerDots:  {"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	SpinnerLine:  {"-", "\\", "|", "/"},
+	SpinnerArrow: {"←", "↖", "↑", "↗", "→", "↘", "↓", "↙"},
+}
+
+// spinner applies to every processing animation, like the color profile
+var spinner = SpinnerDots
+
+// SetSpinner switches the style of every processing animation
+func SetSpinner(style SpinnerStyle) error {
+	if !slices.Contains(AllSpinnerStyles, style) {
+		return fmt.Errorf("invalid spinner %q (expected dots, line, arrow or none)", style)
+	}
+	spinner = style
+	return nil
+}
+
+// ANSI sequences used by the spinner
+const (
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+	clearLine  = "\r\033[K"
+)
+
+// ShowProcessingAnimation displays an animated processing message, in the
+// style set by SetSpinner, until done is signalled. Nothing is written when
+// stdout isn't a terminal, and the cursor is restored even if the user
+// interrupts mid-spin.
 func ShowProcessingAnimation(message string, done chan bool) {
-	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	if !stdoutIsTerminal() {
+		<-done
+		return
+	}
+	if spinner == SpinnerNone {
+		fmt.Fprintln(terminal(), lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Render(message))
+		<-done
+		return
+	}
+
+	frames := spinnerFrames[spinner]
+	if symbols.plain {
+		frames = spinnerFrames[SpinnerLine]
+	}
 	i := 0
 
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupted)
+
+	out := terminal()
+	fmt.Fprint(out, hideCursor)
+	cursorHidden.Store(true)
+	defer cursorHidden.Store(false)
+
 	ticker := time.NewTicker(80 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -48,14 +125,26 @@ func ShowProcessingAnimation(message string, done chan bool) {
 		select {
 		case <-done:
 			// Clear the line
-			fmt.Print("\r\033[K")
+			fmt.Fprint(out, clearLine+showCursor)
 			return
+		case <-interrupted:
+			exitInterrupted()
 		case <-ticker.C:
 			frame := frames[i%len(frames)]
-			fmt.Printf("\r%s %s",
+			fmt.Fprintf(out, "\r%s %s",
 				lipgloss.NewStyle().Foreground(lipgloss.Color("#8B5CF6")).Render(frame),
 				lipgloss.NewStyle().Foreground(lipgloss.Color("#64748B")).Render(message))
 			i++
 		}
 	}
 }
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal rather
+// than a pipe or file; a paged report counts as going to the terminal
+func stdoutIsTerminal() bool {
+	info, err := terminal().Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,37 @@
+package display
+
+import "inspektor/internal/models"
+
+// changes indexes the metric deltas since f.Previous by metric name; nil
+// outside watch mode
+func (f *Formatter) changes(proc *models.ProcessInfo) map[string]models.MetricDelta {
+	if f.Previous == nil {
+		return nil
+	}
+	changes := make(map[string]models.MetricDelta)
+	for _, delta := range models.DiffProcesses(f.Previous, proc) {
+		changes[delta.Metric] = delta
+	}
+	return changes
+}
+
+// significant reports whether delta moved by at least its diff threshold
+func (f *Formatter) significant(delta models.MetricDelta) bool {
+	if f.DiffThresholds == nil {
+		return delta.Significant(models.DefaultDiffThresholds)
+	}
+	return delta.Significant(f.DiffThresholds)
+}
+
+// changeMark flags a metric that went up or down since the previous sample
+// by at least its diff threshold
+func (f *Formatter) changeMark(delta models.MetricDelta) string {
+	switch {
+	case !f.significant(delta):
+		return ""
+	case delta.Delta > 0:
+		return " " + statusWarningStyle.Render(symbols.up)
+	default:
+		return " " + statusGoodStyle.Render(symbols.down)
+	}
+}
@@ -0,0 +1,169 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"inspektor/internal/models"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FormatComparison renders two processes side by side with the difference
+// of each metric (second minus first), the worse value of each in red and
+// the better in green, then a verdict on which is healthier and the
+// commentary
+func (f *Formatter) FormatComparison(first, second TableRow, commentary string) string {
+	var output strings.Builder
+
+	a, b := first.Data.Process, second.Data.Process
+	output.WriteString(titleStyle.Render(fmt.Sprintf("INSPEKTOR - Compare %d (%s) with %d (%s)", a.PID, a.Name, b.PID, b.Name)) + "\n")
+	output.WriteString(f.separator())
+	output.WriteString(f.formatHost(first.Data.Host))
+
+	deltas := make(map[string]models.MetricDelta)
+	for _, delta := range models.DiffProcesses(a, b) {
+		deltas[delta.Metric] = delta
+	}
+
+	seconds := func(v float64) string { return fmt.Sprintf("%.0fs", v) }
+	percent := func(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+	cpu := func(v float64) string { return percent(f.scaleCPU(v)) }
+	bytes := func(v float64) string { return formatBytes(uint64(v)) }
+	count := func(v float64) string { return fmt.Sprintf("%.0f", v) }
+
+	// CPU time grows with age as much as with load, so it isn't ranked
+	rows := []struct {
+		label  string
+		metric string
+		format func(float64) string
+		ranked bool
+	}{
+		{"CPU Usage", "cpu_percent", cpu, true},
+		{"CPU Time", "cpu_time", seconds, false},
+		{"Memory", "memory_rss", bytes, true},
+		{"Memory %", "memory_percent", percent, true},
+		{"Virtual Memory", "memory_vms", bytes, true},
+		{"Open Files", "open_files", count, true},
+		{"Connections", "connections", count, true},
+		{"Child Processes", "children", count, true},
+		{"Threads", "threads", count, true},
+	}
+
+	table := [][]string{{"", fmt.Sprintf("PID %d", a.PID), fmt.Sprintf("PID %d", b.PID), "DELTA"}}
+	table = append(table, []string{"Status", a.Status, b.Status, ""})
+	table = append(table, []string{"Started", f.formatStarted(a, f.formatTime), f.formatStarted(b, f.formatTime), ""})
+	if a.CommandHash != "" && b.CommandHash != "" {
+		same := "="
+		if a.CommandHash != b.CommandHash {
+			same = statusWarningStyle.Render("differs")
+		}
+		table = append(table, []string{"Command Hash", a.CommandHash, b.CommandHash, same})
+	}
+	for _, row := range rows {
+		delta := deltas[row.metric]
+		cells := []string{row.format(delta.Before), row.format(delta.After), f.signedDelta(delta, row.format)}
+		if row.ranked {
+			cells = f.rankCells(delta, cells, false)
+		}
+		table = append(table, append([]string{row.label}, cells...))
+	}
+	analyzed := !first.Data.TimedOut && !second.Data.TimedOut
+	if analyzed {
+		health := models.MetricDelta{Before: float64(first.Data.HealthScore), After: float64(second.Data.HealthScore)}
+		health.Delta = health.After - health.Before
+		cells := f.rankCells(health, []string{count(health.Before), count(health.After), f.signedDelta(health, count)}, true)
+		table = append(table, append([]string{"Health Score"}, cells...))
+	}
+	warnings := models.MetricDelta{Before: float64(countWarnings(first.Findings)), After: float64(countWarnings(second.Findings))}
+	warnings.Delta = warnings.After - warnings.Before
+	cells := f.rankCells(warnings, []string{count(warnings.Before), count(warnings.After), f.signedDelta(warnings, count)}, false)
+	table = append(table, append([]string{"Warnings"}, cells...))
+
+	output.WriteString(f.section(" COMPARISON "))
+	output.WriteString("\n")
+	output.WriteString(renderColumns(table))
+	if analyzed {
+		output.WriteString(contentStyle.Render(keyStyle.Render("Verdict:") + " " + verdict(first.Data, second.Data)))
+		output.WriteString("\n\n")
+	}
+
+	if commentary != "" {
+		output.WriteString(f.section(" COMMENTARY "))
+		output.WriteString("\n")
+		output.WriteString(contentStyle.Render(valueStyle.Render(commentary)))
+		output.WriteString("\n\n")
+	}
+
+	return f.fit(output.String())
+}
+
+// rankCells colors the before, after and delta cells of one metric: the worse
+// of the two values red and the better green, with the delta in the color of
+// the second process. Higher is worse unless lowerIsWorse is set, as for
+// the health score. Differences below the diff threshold stay uncolored.
+func (f *Formatter) rankCells(delta models.MetricDelta, cells []string, lowerIsWorse bool) []string {
+	if !f.significant(delta) {
+		return cells
+	}
+	// The second process is worse when it is higher on a higher-is-worse
+	// metric, or lower on a score
+	firstStyle, secondStyle := statusGoodStyle, statusWarningStyle
+	if (delta.Delta > 0) == lowerIsWorse {
+		firstStyle, secondStyle = secondStyle, firstStyle
+	}
+	return []string{firstStyle.Render(cells[0]), secondStyle.Render(cells[1]), secondStyle.Render(cells[2])}
+}
+
+// verdict names the process with the higher health score
+func verdict(first, second *models.InspectionData) string {
+	a, b := first.Process, second.Process
+	switch {
+	case first.HealthScore > second.HealthScore:
+		return statusGoodStyle.Render(fmt.Sprintf("PID %d (%s) looks healthier", a.PID, a.DisplayName())) +
+			valueStyle.Render(fmt.Sprintf(", health %d vs %d", first.HealthScore, second.HealthScore))
+	case second.HealthScore > first.HealthScore:
+		return statusGoodStyle.Render(fmt.Sprintf("PID %d (%s) looks healthier", b.PID, b.DisplayName())) +
+			valueStyle.Render(fmt.Sprintf(", health %d vs %d", second.HealthScore, first.HealthScore))
+	default:
+		return valueStyle.Render(fmt.Sprintf("both look equally healthy, health %d", first.HealthScore))
+	}
+}
+
+// signedDelta renders a change with an explicit sign, or "=" when it is
+// below the diff threshold
+func (f *Formatter) signedDelta(delta models.MetricDelta, format func(float64) string) string {
+	switch {
+	case !f.significant(delta):
+		return "="
+	case delta.Delta > 0:
+		return "+" + format(delta.Delta)
+	default:
+		return "-" + format(-delta.Delta)
+	}
+}
+
+// renderColumns aligns rows into columns, the first row being the header
+func renderColumns(rows [][]string) string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for col, cell := range row {
+			widths[col] = max(widths[col], lipgloss.Width(cell))
+		}
+	}
+
+	var out strings.Builder
+	for idx, row := range rows {
+		var line strings.Builder
+		for col, cell := range row {
+			line.WriteString(cell + strings.Repeat(" ", widths[col]-lipgloss.Width(cell)+2))
+		}
+		text := "  " + strings.TrimRight(line.String(), " ")
+		if idx == 0 {
+			text = tableHeaderStyle.Render(text)
+		}
+		out.WriteString(text + "\n")
+	}
+	out.WriteString("\n")
+	return out.String()
+}
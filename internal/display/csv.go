@@ -0,0 +1,96 @@
+package display
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// csvHeader names the columns of FormatCSV. Values are unformatted, bytes as
+// byte counts and CPU per core, so spreadsheets can compute with them.
+var csvHeader = []string{
+	"pid", "name", "user", "status", "cpu_percent", "memory_rss", "memory_percent",
+	"threads", "open_files", "connections", "health_score", "started", "command",
+}
+
+// FormatCSV renders a header row and one row per inspection with the key
+// metrics, for --format csv. encoding/csv quotes fields as needed, so
+// command lines containing commas or quotes stay in one column.
+func FormatCSV(datas []*models.InspectionData) []byte {
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	_ = w.Write(csvHeader)
+	for _, data := range datas {
+		proc := data.Process
+		started := ""
+		if !proc.CreateTime.IsZero() {
+			started = proc.CreateTime.Format(time.RFC3339)
+		}
+		health := ""
+		if !data.TimedOut {
+			health = strconv.Itoa(data.HealthScore)
+		}
+		_ = w.Write([]string{
+			fmt.Sprint(proc.PID),
+			proc.Name,
+			proc.Username,
+			proc.Status,
+			strconv.FormatFloat(proc.CPUPercent, 'f', 2, 64),
+			fmt.Sprint(proc.MemoryRSS),
+			strconv.FormatFloat(float64(proc.MemoryPercent), 'f', 2, 32),
+			fmt.Sprint(proc.NumThreads),
+			fmt.Sprint(proc.OpenFiles),
+			fmt.Sprint(proc.Connections),
+			health,
+			started,
+			proc.CommandLine,
+		})
+	}
+	w.Flush()
+	return out.Bytes()
+}
+
+// FormatTopCSV renders the --top-n rankings as CSV, one row per ranked
+// process, with the ranking it belongs to and its place in it
+func FormatTopCSV(top *models.TopConsumers) []byte {
+	rankings := []struct {
+		name  string
+		usage []models.ProcessUsage
+	}{
+		{"cpu", top.ByCPU},
+		{"memory", top.ByMemory},
+		{"open_files", top.ByOpenFiles},
+		{"connections", top.ByConnections},
+	}
+	if top.Score != "" {
+		rankings = append(rankings, struct {
+			name  string
+			usage []models.ProcessUsage
+		}{top.Score, top.ByScore})
+	}
+
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	_ = w.Write([]string{"ranking", "rank", "pid", "name", "cpu_percent", "memory_rss", "open_files", "connections", "score"})
+	for _, ranking := range rankings {
+		for rank, usage := range ranking.usage {
+			_ = w.Write([]string{
+				ranking.name,
+				strconv.Itoa(rank + 1),
+				fmt.Sprint(usage.PID),
+				usage.Name,
+				strconv.FormatFloat(usage.CPUPercent, 'f', 2, 64),
+				fmt.Sprint(usage.MemoryRSS),
+				fmt.Sprint(usage.OpenFiles),
+				fmt.Sprint(usage.Connections),
+				strconv.FormatFloat(usage.Score, 'f', 2, 64),
+			})
+		}
+	}
+	w.Flush()
+	return out.Bytes()
+}
@@ -1,60 +1,311 @@
 package display
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"inspektor/internal/models"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-type Formatter struct{}
+type Formatter struct {
+	// Verbose adds detail sections such as the list of deleted open files
+	Verbose bool
+
+	// Explain appends the evidence behind each finding to its message
+	Explain bool
+	// ExplainHealth lists what took points off the health score under it
+	ExplainHealth bool
+	// GroupBy buckets findings under headers in text reports; "category"
+	// groups them by area, empty keeps the flat warnings and
+	// recommendations lists
+	GroupBy string
+
+	// MinSeverity is the active finding filter, used to word the empty result
+	MinSeverity models.Severity
+
+	// TimeFormat selects how timestamps render: "rfc3339", "unix", a Go
+	// time layout, or empty for the compact default
+	TimeFormat string
+
+	// Width forces the layout width, wrapping longer lines; zero keeps the
+	// natural width with a defaultWidth separator
+	Width int
+
+	// Borderless drops separators and section highlights for the plainest
+	// structured text
+	Borderless bool
+
+	// Previous is the last watch sample of the same process; when set,
+	// resource metrics that changed since then are marked with ↑ or ↓
+	Previous *models.ProcessInfo
+
+	// DiffThresholds says how far each metric must move before a watch
+	// sample or comparison shows it as changed; nil uses
+	// models.DefaultDiffThresholds
+	DiffThresholds models.DiffThresholds
+
+	// CPUMode shows per-process CPU per core (raw, the default) or as a
+	// share of all cores (normalized)
+	CPUMode models.CPUMode
+
+	// MaxFindings caps how many findings a report lists, keeping the most
+	// severe and collapsing the rest into a count; zero lists them all
+	MaxFindings int
+
+	// WarningsDiverted means the findings go to a channel of their own, so
+	// the report doesn't claim there are none
+	WarningsDiverted bool
+
+	// Layout orders and hides the rows of the process, resources and system
+	// sections; nil keeps the built-in order
+	Layout *Layout
+
+	// Thresholds gives the limits the analyzer judges a process by (nil for
+	// system-wide ones), so values are colored exactly when they raise a
+	// finding; nil uses models.DefaultThresholds
+	Thresholds func(proc *models.ProcessInfo) models.Thresholds
+}
+
+// DefaultMaxFindings is how many findings a text report lists before
+// collapsing the rest
+const DefaultMaxFindings = 10
+
+// defaultWidth is the separator length when no width is forced
+const defaultWidth = 60
+
+// defaultTimeLayout is the compact timestamp used when no format is chosen
+const defaultTimeLayout = "Jan 02, 15:04:05"
 
 func NewFormatter() *Formatter {
 	return &Formatter{}
 }
 
+// FormatTitle renders the report title identifying the process
+func (f *Formatter) FormatTitle(data *models.InspectionData) string {
+	title := fmt.Sprintf("INSPEKTOR - Process %d (%s)", data.Process.PID, data.Process.DisplayName())
+	return titleStyle.Render(title) + "\n"
+}
+
 func (f *Formatter) FormatReport(data *models.InspectionData) string {
 	var output strings.Builder
 
 	// Title with process name
-	title := fmt.Sprintf("INSPEKTOR - Process %d (%s)", data.Process.PID, data.Process.Name)
-	output.WriteString(titleStyle.Render(title))
-	output.WriteString("\n")
-	output.WriteString(separatorStyle.Render(strings.Repeat("─", 60)))
-	output.WriteString("\n")
+	output.WriteString(f.FormatTitle(data))
+	output.WriteString(f.separator())
+
+	// Health score up front as the at-a-glance verdict
+	if !data.TimedOut {
+		output.WriteString(contentStyle.Render(
+			keyStyle.Render("Health Score:") + " " + f.formatHealthScore(data.HealthScore, data.HealthBand)))
+		output.WriteString("\n")
+		if f.ExplainHealth {
+			output.WriteString(contentStyle.Render(
+				keyStyle.Render("Deductions:") + " " + valueStyle.Render(describeHealthComponents(data.HealthComponents))))
+			output.WriteString("\n")
+		}
+	}
+	output.WriteString(f.formatHost(data.Host))
 
-	// Process Overview - most important info first
-	output.WriteString(f.formatProcessOverview(data.Process))
+	// Explain empty fields before they're mistaken for real values
+	if hint := permissionHint(data.Process); hint != "" {
+		output.WriteString(warningItemStyle.Render(symbols.warning + " " + hint))
+		output.WriteString("\n")
+	}
+
+	// Process Overview - most important info first; a kernel thread has
+	// only its state and CPU use to show
+	if data.Process.KernelThread {
+		output.WriteString(f.formatKernelThread(data.Process))
+	} else {
+		output.WriteString(f.formatProcessOverview(data.Process))
+	}
+	if data.Restarts != nil {
+		output.WriteString(contentStyle.Render(
+			keyStyle.Render("Restarts:") + " " + statusWarningStyle.Render(f.formatRestarts(data.Restarts))))
+		output.WriteString("\n")
+	}
+	if data.CommandChanged != nil {
+		output.WriteString(contentStyle.Render(
+			keyStyle.Render("Command Changed:") + " " + statusWarningStyle.Render(f.formatCommandChange(data.CommandChanged))))
+		output.WriteString("\n")
+	}
 
 	// Resource Usage - key metrics
-	output.WriteString(f.formatResourceMetrics(data.Process))
+	if !data.Process.KernelThread {
+		output.WriteString(f.formatResourceMetrics(data.Process))
+	}
+
+	if data.TreeTotals != nil {
+		output.WriteString(f.formatTreeTotals(data.Process, data.TreeTotals))
+	}
+	if data.ChildChanges != nil {
+		output.WriteString(f.formatChildChanges(data.ChildChanges))
+	}
+	if len(data.Process.RuntimeEnv) > 0 {
+		output.WriteString(f.formatRuntimeEnv(data.Process.RuntimeEnv))
+	}
+	if len(data.Process.HotThreads) > 0 {
+		output.WriteString(f.formatHotThreads(data.Process.HotThreads))
+	}
 
-	// System Context
-	output.WriteString(f.formatSystemContext(data.System))
+	if f.Verbose && len(data.Process.DeletedFiles) > 0 {
+		output.WriteString(f.formatDeletedFiles(data.Process.DeletedFiles))
+	}
 
-	return output.String()
+	if f.Verbose && len(data.Process.Limits) > 0 {
+		output.WriteString(f.formatLimits(data.Process.Limits))
+	}
+
+	if f.Verbose && data.Process.Details != nil {
+		output.WriteString(f.formatPeers(data.Process.Details.Connections))
+	}
+
+	// System Context (missing when collection timed out before reaching it
+	// or failed, which is said so its figures aren't taken as absent)
+	if data.System != nil {
+		output.WriteString(f.formatSystemContext(data.System))
+	}
+	if data.Top != nil {
+		output.WriteString(f.formatHostTop(data.Process.PID, data.Top))
+	}
+	for _, failure := range data.CollectionErrors {
+		output.WriteString(warningItemStyle.Render(fmt.Sprintf("%s No %s data: %s", symbols.warning, failure.Collector, failure.Error)))
+		output.WriteString("\n")
+	}
+
+	return f.fit(output.String())
+}
+
+// FormatSystemReport renders the host overview on its own, for --system
+func (f *Formatter) FormatSystemReport(host *models.HostInfo, sys *models.SystemInfo) string {
+	var output strings.Builder
+
+	output.WriteString(titleStyle.Render(fmt.Sprintf("INSPEKTOR - System (%s)", host.Hostname)) + "\n")
+	output.WriteString(f.separator())
+	output.WriteString(f.formatHost(host))
+	output.WriteString(f.formatSystemContext(sys))
+
+	return f.fit(output.String())
+}
+
+// separator is the rule under a report title, omitted when borderless
+func (f *Formatter) separator() string {
+	if f.Borderless {
+		return ""
+	}
+	width := defaultWidth
+	if f.Width > 0 {
+		width = f.Width
+	}
+	return separatorStyle.Render(strings.Repeat(symbols.rule, width)) + "\n"
+}
+
+// section renders a section header, as a plain bold title when borderless
+func (f *Formatter) section(title string) string {
+	return f.heading(sectionStyle, title)
+}
+
+func (f *Formatter) heading(style lipgloss.Style, title string) string {
+	if f.Borderless {
+		return plainHeadingStyle.Render(strings.TrimSpace(title))
+	}
+	return style.Render(title)
+}
+
+// fit wraps rendered output to the forced width, if any
+func (f *Formatter) fit(output string) string {
+	if f.Width <= 0 {
+		return output
+	}
+	return lipgloss.NewStyle().Width(f.Width).Render(output)
+}
+
+// formatHost is the compact line identifying the machine in the header
+func (f *Formatter) formatHost(host *models.HostInfo) string {
+	if host == nil {
+		return ""
+	}
+	return contentStyle.Render(keyStyle.Render("Host:")+" "+valueStyle.Render(host.String())) + "\n"
 }
 
 func (f *Formatter) formatProcessOverview(proc *models.ProcessInfo) string {
 	var content strings.Builder
 
-	content.WriteString(sectionStyle.Render(" PROCESS "))
+	content.WriteString(f.section(" PROCESS "))
 	content.WriteString("\n")
 
-	// Most important info in a clean table format
-	items := []struct {
-		key   string
-		value string
-	}{
-		{"Status", f.formatStatus(proc.Status)},
-		{"Command", proc.CommandLine},
-		{"Executable", proc.Executable},
-		{"Working Dir", proc.WorkingDir},
-		{"Started", proc.CreateTime.Format("Jan 02, 15:04:05")},
+	// Most important info in a clean table format, unless a layout says
+	// otherwise
+	items := []row{
+		{key: "Status", value: f.formatStatus(proc.Status)},
+		{key: "Scheduler", value: formatScheduler(proc.Scheduler)},
+		{key: "Wait Channel", value: formatWait(proc.Wait)},
+		{key: "User", value: proc.Username},
+		{key: "TTY", value: f.formatTerminal(proc.Terminal)},
+		{key: "Process Group", value: f.formatSession(proc)},
+		{key: "Container", value: f.formatContainer(proc)},
+		{key: "Root", value: formatRoot(proc.Root)},
+		{key: "Capabilities", value: f.formatCapabilities(proc)},
+		{key: "Seccomp", value: f.formatSeccomp(proc)},
+		{key: "Namespaces", value: f.formatNamespaces(proc)},
+		{key: "Command", value: proc.CommandLine},
+		{key: "Executable", value: f.formatExecutable(proc)},
+		{key: "Command Hash", value: f.formatCommandHash(proc)},
+		{key: "Go Runtime", value: formatGoRuntime(proc.Go)},
+		{key: "Working Dir", value: proc.WorkingDir},
+		{key: "Listening", value: strings.Join(proc.ListenPortLabels(), ", ")},
+		{key: "Started", value: f.formatStarted(proc, f.formatTime)},
 	}
 
+	for _, item := range f.Layout.arrange("process", items) {
+		if item.value != "" {
+			content.WriteString(contentStyle.Render(
+				keyStyle.Render(item.key+":") + " " + valueStyle.Render(item.value)))
+			content.WriteString("\n")
+		}
+	}
+
+	// The joined command line is ambiguous when arguments contain spaces, so
+	// verbose output lists argv one entry per line
+	if f.Verbose && len(proc.CommandLineArgs) > 0 {
+		content.WriteString(contentStyle.Render(keyStyle.Render("Arguments:")))
+		content.WriteString("\n")
+		for index, arg := range proc.CommandLineArgs {
+			content.WriteString(contentStyle.Render(
+				keyStyle.Render("") + " " + valueStyle.Render(fmt.Sprintf("[%d] %s", index, formatArg(arg)))))
+			content.WriteString("\n")
+		}
+	}
+
+	return content.String()
+}
+
+// formatKernelThread stands in for the process and resource sections for a
+// kernel thread, which has no command line, memory, files or sockets to show
+func (f *Formatter) formatKernelThread(proc *models.ProcessInfo) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" KERNEL THREAD "))
+	content.WriteString("\n")
+
+	items := []row{
+		{key: "Status", value: f.formatStatus(proc.Status)},
+		{key: "Scheduler", value: formatScheduler(proc.Scheduler)},
+		{key: "Wait Channel", value: formatWait(proc.Wait)},
+		{key: "Started", value: f.formatStarted(proc, f.formatTime)},
+		{key: "CPU Usage", value: f.formatProcessCPU(proc.CPUPercent)},
+		{key: "CPU Time", value: f.formatCPUTime(proc.CPUTimeUser, proc.CPUTimeSystem)},
+	}
 	for _, item := range items {
 		if item.value != "" {
 			content.WriteString(contentStyle.Render(
@@ -62,32 +313,382 @@ func (f *Formatter) formatProcessOverview(proc *models.ProcessInfo) string {
 			content.WriteString("\n")
 		}
 	}
+	content.WriteString(contentStyle.Render(keyStyle.Render("") + " " +
+		valueStyle.Render("Runs inside the kernel: no executable, memory or open files of its own")))
+	content.WriteString("\n")
 
 	return content.String()
 }
 
-func (f *Formatter) formatResourceMetrics(proc *models.ProcessInfo) string {
+// FormatGroupTotals renders the combined usage of every process inspected
+// in a control group or for a user, shown after their individual reports
+func (f *Formatter) FormatGroupTotals(kind, name string, totals *models.TreeTotals) string {
 	var content strings.Builder
 
-	content.WriteString(sectionStyle.Render(" RESOURCES "))
+	content.WriteString(f.section(" " + strings.ToUpper(kind) + " TOTALS "))
 	content.WriteString("\n")
 
-	// Key metrics with visual indicators
 	items := []struct {
 		key   string
 		value string
 	}{
-		{"CPU Usage", f.formatCPUUsage(proc.CPUPercent)},
-		{"Memory", f.formatMemoryUsage(proc.MemoryRSS, proc.MemoryPercent)},
-		{"Virtual Memory", formatBytes(proc.MemoryVMS)},
-		{"Open Files", f.formatCount(proc.OpenFiles, 100)},
-		{"Connections", f.formatCount(proc.Connections, 50)},
-		{"Child Processes", f.formatCount(proc.Children, 10)},
+		{kind, valueStyle.Render(name)},
+		{"Processes", valueStyle.Render(fmt.Sprintf("%d", totals.Processes))},
+		{"CPU Usage", f.formatProcessCPU(totals.CPUPercent)},
+		{"Memory", valueStyle.Render(formatBytes(totals.MemoryRSS))},
+		{"Open Files", f.formatCount(totals.OpenFiles, 1000)},
+		{"Connections", f.formatCount(totals.Connections, 500)},
+	}
+	for _, item := range items {
+		content.WriteString(contentStyle.Render(keyStyle.Render(item.key+":") + " " + item.value))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	return f.fit(content.String())
+}
+
+// formatLimits lists the process's resource limits with how much of each
+// soft limit is in use, highlighting the ones close to it
+func (f *Formatter) formatLimits(limits []models.Limit) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" LIMITS "))
+	content.WriteString("\n")
+
+	for _, limit := range limits {
+		value := func(v *uint64) string {
+			switch {
+			case v == nil:
+				return "unlimited"
+			case limit.Resource == "stack" || limit.Resource == "memlock":
+				return formatBytes(*v)
+			case limit.Resource == "cpu":
+				return fmt.Sprintf("%ds", *v)
+			}
+			return fmt.Sprintf("%d", *v)
+		}
+
+		line := fmt.Sprintf("soft %s, hard %s", value(limit.Soft), value(limit.Hard))
+		if limit.Used != nil {
+			line = fmt.Sprintf("%s used, %s", value(limit.Used), line)
+		}
+		rendered := valueStyle.Render(line)
+		if percent, ok := limit.UsagePercent(); ok {
+			usage := fmt.Sprintf(" (%.0f%%)", percent)
+			switch {
+			case percent > 80:
+				usage = statusWarningStyle.Render(usage)
+			case percent > 50:
+				usage = metricStyle.Render(usage)
+			default:
+				usage = valueStyle.Render(usage)
+			}
+			rendered += usage
+		}
+
+		content.WriteString(contentStyle.Render(keyStyle.Render(limit.Resource+":") + " " + rendered))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// formatRestarts summarizes the restarts seen while watching
+func (f *Formatter) formatRestarts(restarts *models.RestartInfo) string {
+	return fmt.Sprintf("%d in the last %s (last at %s, previously PID %d)",
+		restarts.Count, time.Since(restarts.Since).Round(time.Second),
+		f.formatTime(restarts.Last), restarts.PreviousPID)
+}
+
+// formatCommandChange says when the watched process's command hash last
+// changed, and from what
+func (f *Formatter) formatCommandChange(change *models.CommandChange) string {
+	return fmt.Sprintf("at %s, hash %s (was %s, PID %d)",
+		f.formatTime(change.At), change.Hash, change.PreviousHash, change.PreviousPID)
+}
+
+// permissionHint explains which details were unreadable and how to get them;
+// empty when everything could be read
+func permissionHint(proc *models.ProcessInfo) string {
+	if len(proc.Restricted) == 0 {
+		return ""
+	}
+	fields := strings.ReplaceAll(strings.Join(proc.Restricted, ", "), "_", " ")
+	// Root can still be refused, e.g. by a security module or a container
+	// boundary, and sudo won't help then
+	if os.Geteuid() == 0 {
+		return fmt.Sprintf("Permission denied reading %s, even as root (likely a security module or container boundary).", fields)
+	}
+	owner := "another user"
+	if proc.Username != "" {
+		owner = proc.Username
+	}
+	return fmt.Sprintf("Permission denied reading %s. Run with sudo to read full details for PID %d owned by %s.",
+		fields, proc.PID, owner)
+}
+
+// formatArg quotes an argument when whitespace or emptiness would otherwise
+// make it ambiguous
+func formatArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\n") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
+func (f *Formatter) formatResourceMetrics(proc *models.ProcessInfo) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" RESOURCES "))
+	content.WriteString("\n")
+
+	// Key metrics with visual indicators; metric names the value that
+	// change marks are based on
+	items := []row{
+		{"CPU Usage", f.formatProcessCPU(proc.CPUPercent) + formatTrend(proc.CPUTrend), "cpu_percent"},
+		{"CPU Time", f.formatCPUTime(proc.CPUTimeUser, proc.CPUTimeSystem), "cpu_time"},
+		{"Memory", f.formatMemoryUsage(proc) + formatTrend(proc.MemoryTrend), "memory_rss"},
+		{"Peak Memory", f.formatPeakMemory(proc.MemoryRSS, proc.MemoryPeakRSS), ""},
+		{"Memory Breakdown", f.formatMemoryBreakdown(proc), ""},
+		{"Cgroup Memory", formatCgroupMemory(proc.CgroupMemory), ""},
+		{"OOM Risk", f.formatOOMRisk(proc.OOM), ""},
+		{"Virtual Memory", formatBytes(proc.MemoryVMS), "memory_vms"},
+		{"Open Files", f.formatOpenFiles(proc), "open_files"},
+		{"Handles", formatHandles(proc.Handles), ""},
+		{"Deleted Files", f.formatDeletedSummary(proc), ""},
+		{"Connections", f.formatConnections(proc), "connections"},
+		{"Network I/O", f.formatNetRate(proc), ""},
+		{"Disk I/O", f.formatDiskRate(proc), ""},
+		{"Child Processes", f.formatCount(proc.Children, f.limits(proc).Children), "children"},
+		{"Threads", valueStyle.Render(fmt.Sprintf("%d", proc.NumThreads)), "threads"},
+		{"Context Switches", formatContextSwitches(proc.ContextSwitches), ""},
+		{"Power (est.)", formatPower(proc.PowerEstimate), ""},
+	}
+
+	changes := f.changes(proc)
+	for _, item := range f.Layout.arrange("resources", items) {
+		if item.value == "" {
+			continue
+		}
+		content.WriteString(contentStyle.Render(
+			keyStyle.Render(item.key+":") + " " + item.value + f.changeMark(changes[item.metric])))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// maxChildChanges bounds how many spawned or exited children a tick lists,
+// so a fork storm doesn't push the rest of the report off screen
+const maxChildChanges = 10
+
+// formatChildChanges notes the children that appeared and went away since
+// the last tick, and the churn since watching began, for --follow-children
+func (f *Formatter) formatChildChanges(changes *models.ChildChanges) string {
+	var content strings.Builder
+
+	content.WriteString(contentStyle.Render(
+		keyStyle.Render("Child Churn:") + " " + valueStyle.Render(fmt.Sprintf("%d spawned, %d exited in the last %s",
+			changes.TotalSpawned, changes.TotalExited, time.Since(changes.Since).Round(time.Second)))))
+	content.WriteString("\n")
+
+	lines := func(children []models.ChildProcess, describe func(models.ChildProcess) string, style lipgloss.Style) {
+		for index, child := range children {
+			if index == maxChildChanges {
+				content.WriteString(contentStyle.Render(metricStyle.Render(fmt.Sprintf("... and %d more", len(children)-index))))
+				content.WriteString("\n")
+				break
+			}
+			content.WriteString(contentStyle.Render(style.Render(describe(child))))
+			content.WriteString("\n")
+		}
+	}
+	lines(changes.Spawned, func(child models.ChildProcess) string {
+		return fmt.Sprintf("+ new child %d (%s) appeared", child.PID, child.Name)
+	}, statusWarningStyle)
+	lines(changes.Exited, func(child models.ChildProcess) string {
+		return fmt.Sprintf("- child %d (%s) exited", child.PID, child.Name)
+	}, metricStyle)
+
+	return content.String()
+}
+
+// formatRuntimeEnv lists the runtime tuning variables the process was
+// started with, by name
+func (f *Formatter) formatRuntimeEnv(env map[string]string) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" RUNTIME "))
+	content.WriteString("\n")
+
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		content.WriteString(contentStyle.Render(keyStyle.Render(name+":") + " " + valueStyle.Render(formatArg(env[name]))))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// formatHotThreads lists the busiest threads, top -H style: CPU over the
+// sampling window, state, and CPU time since each thread started
+func (f *Formatter) formatHotThreads(threads []models.ThreadUsage) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" HOT THREADS "))
+	content.WriteString("\n")
+
+	for _, thread := range threads {
+		usage := metricStyle.Render("n/a")
+		if thread.CPUPercent != nil {
+			usage = f.formatProcessCPU(*thread.CPUPercent)
+		}
+		line := usage + ", " + f.formatStatus(thread.State) +
+			valueStyle.Render(fmt.Sprintf(", %.1fs user, %.1fs sys", thread.CPUTimeUser, thread.CPUTimeSystem))
+		content.WriteString(contentStyle.Render(keyStyle.Render(fmt.Sprintf("%d %s:", thread.TID, thread.Name)) + " " + line))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// formatTreeTotals shows the process-plus-descendants totals next to the
+// process's own figures, for --include-children
+func (f *Formatter) formatTreeTotals(self *models.ProcessInfo, totals *models.TreeTotals) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" WITH CHILDREN "))
+	content.WriteString("\n")
+
+	items := []struct {
+		key   string
+		total string
+		self  string
+	}{
+		{"CPU Usage", f.formatProcessCPU(totals.CPUPercent), fmt.Sprintf("%.1f%%", f.scaleCPU(self.CPUPercent))},
+		{"Memory", valueStyle.Render(formatBytes(totals.MemoryRSS)), formatBytes(self.MemoryRSS)},
+		{"Open Files", f.formatCount(totals.OpenFiles, 1000), fmt.Sprintf("%d", self.OpenFiles)},
+		{"Connections", f.formatCount(totals.Connections, 500), fmt.Sprintf("%d", self.Connections)},
 	}
 
+	content.WriteString(contentStyle.Render(
+		keyStyle.Render("Processes:") + " " + valueStyle.Render(fmt.Sprintf("%d (1 + %d descendants)", totals.Processes, totals.Processes-1))))
+	content.WriteString("\n")
 	for _, item := range items {
 		content.WriteString(contentStyle.Render(
-			keyStyle.Render(item.key+":") + " " + item.value))
+			keyStyle.Render(item.key+":") + " " + item.total + " " + valueStyle.Render("(self "+item.self+")")))
+		content.WriteString("\n")
+	}
+
+	if totals.Truncated {
+		content.WriteString(contentStyle.Render(metricStyle.Render("Totals cover a partial tree: size limit reached")))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// formatConnections shows the connection count with its per-state breakdown,
+// e.g. "12 (ESTABLISHED 8, CLOSE_WAIT 3, LISTEN 1)"
+func (f *Formatter) formatConnections(proc *models.ProcessInfo) string {
+	if proc.DescriptorsSkipped {
+		return metricStyle.Render("not read (too many descriptors)")
+	}
+	count := f.formatCount(proc.Connections, f.limits(proc).Connections)
+	if len(proc.ConnectionStates) == 0 {
+		return count
+	}
+
+	var parts []string
+	for _, state := range proc.SortedConnectionStates() {
+		parts = append(parts, fmt.Sprintf("%s %d", state.State, state.Count))
+	}
+	return count + " " + valueStyle.Render("("+strings.Join(parts, ", ")+")")
+}
+
+// formatNetRate shows receive/transmit throughput; the counters cover the
+// whole network namespace, which the label makes explicit
+func (f *Formatter) formatNetRate(proc *models.ProcessInfo) string {
+	if proc.NetRxRate == nil || proc.NetTxRate == nil {
+		return ""
+	}
+	return valueStyle.Render(fmt.Sprintf("%s %s/s  %s %s/s (net namespace)",
+		symbols.received, formatBytes(uint64(*proc.NetRxRate)), symbols.sent, formatBytes(uint64(*proc.NetTxRate))))
+}
+
+// formatDiskRate shows how fast the process reads from and writes to storage
+func (f *Formatter) formatDiskRate(proc *models.ProcessInfo) string {
+	if proc.DiskReadRate == nil || proc.DiskWriteRate == nil {
+		return ""
+	}
+	return valueStyle.Render(fmt.Sprintf("%s/s read, %s/s write",
+		formatBytes(uint64(*proc.DiskReadRate)), formatBytes(uint64(*proc.DiskWriteRate))))
+}
+
+// formatDeletedSummary totals deleted-but-open files; empty when there are none
+func (f *Formatter) formatDeletedSummary(proc *models.ProcessInfo) string {
+	if len(proc.DeletedFiles) == 0 {
+		return ""
+	}
+	return statusWarningStyle.Render(fmt.Sprintf("%d holding %s",
+		len(proc.DeletedFiles), formatBytes(proc.DeletedFilesSize())))
+}
+
+// formatDeletedFiles lists each deleted-but-open file for verbose output
+func (f *Formatter) formatDeletedFiles(files []models.DeletedFile) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" DELETED FILES "))
+	content.WriteString("\n")
+
+	for _, file := range files {
+		content.WriteString(contentStyle.Render(fmt.Sprintf("%s %s %s",
+			keyStyle.Render(fmt.Sprintf("fd %d:", file.FD)),
+			f.truncateString(file.Path, 50),
+			valueStyle.Render(formatBytes(file.Size)))))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// maxPeers caps the connection list, which a busy server could make endless
+const maxPeers = 50
+
+// formatPeers lists the connected sockets with their peers' hostnames and
+// services where --resolve found them; empty when nothing is connected
+func (f *Formatter) formatPeers(connections []models.Connection) string {
+	var peers []models.Connection
+	for _, conn := range connections {
+		if conn.Proto != "unix" && conn.Remote != "" {
+			peers = append(peers, conn)
+		}
+	}
+	if len(peers) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(f.section(" CONNECTIONS "))
+	content.WriteString("\n")
+
+	for _, conn := range peers[:min(len(peers), maxPeers)] {
+		remote := conn.Remote
+		if conn.RemoteHost != "" {
+			remote = conn.RemoteHost + " (" + conn.Remote + ")"
+		}
+		line := fmt.Sprintf("%s %s %s", conn.Local, symbols.arrow, remote)
+		if conn.Service != "" {
+			line += " " + metricStyle.Render(conn.Service)
+		}
+		if conn.State != "" && conn.State != "NONE" {
+			line += " " + valueStyle.Render(conn.State)
+		}
+		content.WriteString(contentStyle.Render(keyStyle.Render(conn.Proto+":") + " " + line))
+		content.WriteString("\n")
+	}
+	if len(peers) > maxPeers {
+		content.WriteString(contentStyle.Render(valueStyle.Render(fmt.Sprintf("... and %d more", len(peers)-maxPeers))))
 		content.WriteString("\n")
 	}
 
@@ -97,16 +698,152 @@ func (f *Formatter) formatResourceMetrics(proc *models.ProcessInfo) string {
 func (f *Formatter) formatSystemContext(sys *models.SystemInfo) string {
 	var content strings.Builder
 
-	content.WriteString(sectionStyle.Render(" SYSTEM "))
+	content.WriteString(f.section(" SYSTEM "))
 	content.WriteString("\n")
 
+	var cpuSpread, memorySpread string
+	if samples := sys.Samples; samples != nil {
+		cpuSpread = formatSpread(samples.Count, samples.CPU)
+		memorySpread = formatSpread(samples.Count, samples.Memory)
+	}
+	// Say when inspektor's own sampling was noticeable enough to take out
+	if sys.SelfCPUUsage >= 0.1 {
+		cpuSpread += metricStyle.Render(fmt.Sprintf(" (excluding inspektor's own %.1f%%)", sys.SelfCPUUsage))
+	}
+
+	items := []row{
+		{key: "CPU", value: fmt.Sprintf("%d cores, %s", sys.CPUCores, f.formatCPUUsage(sys.CPUUsage)) + cpuSpread},
+		{key: "Memory", value: f.formatSystemMemory(sys.MemoryUsed, sys.MemoryTotal, sys.MemoryPercent) + memorySpread},
+		{key: "Swap", value: f.formatSwap(sys.SwapUsed, sys.SwapTotal, sys.SwapPercent)},
+		{key: "Load Average", value: f.formatLoad(sys)},
+		{key: "Disk (/)", value: f.formatDisk(sys)},
+		{key: "CPU Model", value: f.truncateString(sys.CPUModel, 50)},
+	}
+
+	for _, item := range f.Layout.arrange("system", items) {
+		if item.value == "" {
+			continue
+		}
+		content.WriteString(contentStyle.Render(
+			keyStyle.Render(item.key+":") + " " + item.value))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+func (f *Formatter) FormatTree(tree *models.ProcessTree) string {
+	var content strings.Builder
+
+	content.WriteString(f.section(" PROCESS TREE "))
+	content.WriteString("\n")
+
+	// Subtree totals first so the aggregate footprint is visible at a glance
+	summary := fmt.Sprintf("%d descendants, %s CPU, %s RSS total",
+		tree.Descendants, f.formatProcessCPU(tree.TotalCPU), valueStyle.Render(formatBytes(tree.TotalRSS)))
+	content.WriteString(contentStyle.Render(keyStyle.Render("Subtree:") + " " + summary))
+	content.WriteString("\n")
+
+	var lines []string
+	f.formatTreeNode(tree.Root, 0, "", true, true, &lines)
+	for _, line := range lines {
+		content.WriteString("  " + line + "\n")
+	}
+
+	if tree.Truncated {
+		content.WriteString(contentStyle.Render(metricStyle.Render("Tree truncated: depth or size limit reached")))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	return f.fit(content.String())
+}
+
+// formatTreeNode renders node and its subtree. Sessions group the tree:
+// the root and every process that starts a session other than its parent's
+// (a daemon detaching, a new login shell) are labeled with theirs.
+func (f *Formatter) formatTreeNode(node *models.ProcessNode, parentSID int32, prefix string, isLast, isRoot bool, lines *[]string) {
+	branch := ""
+	childPrefix := ""
+	if !isRoot {
+		if isLast {
+			branch = symbols.lastBranch
+			childPrefix = prefix + "   "
+		} else {
+			branch = symbols.branch
+			childPrefix = prefix + symbols.pipe
+		}
+	}
+
+	label := fmt.Sprintf("%s %s  %s  %s",
+		valueStyle.Render(fmt.Sprintf("%d", node.PID)),
+		valueStyle.Render(node.Name),
+		f.formatProcessCPU(node.CPUPercent),
+		valueStyle.Render(formatBytes(node.MemoryRSS)))
+	if session := sessionLabel(node, parentSID); session != "" {
+		label += "  " + footerStyle.Render("["+session+"]")
+	}
+	*lines = append(*lines, separatorStyle.UnsetMargins().Render(prefix+branch)+label)
+
+	for idx, child := range node.Children {
+		f.formatTreeNode(child, node.SID, childPrefix, idx == len(node.Children)-1, false, lines)
+	}
+}
+
+// sessionLabel names the node's session, e.g. "session 4321 leader", when
+// it differs from its parent's; empty otherwise or where unknown
+func sessionLabel(node *models.ProcessNode, parentSID int32) string {
+	if node.SID == 0 || node.SID == parentSID {
+		return ""
+	}
+	if node.SID == node.PID {
+		return fmt.Sprintf("session %d leader", node.SID)
+	}
+	return fmt.Sprintf("session %d", node.SID)
+}
+
+// FormatTimeout explains why a report is partial and has no analysis
+func (f *Formatter) FormatTimeout() string {
+	return warningItemStyle.Render(symbols.warning+" Collection timed out: showing partial results, analysis skipped") + "\n\n"
+}
+
+// FormatDuration is the footer saying how long the inspection took
+func (f *Formatter) FormatDuration(elapsed time.Duration) string {
+	return footerStyle.Render(fmt.Sprintf("Inspection completed in %s", formatElapsed(elapsed))) + "\n"
+}
+
+// formatElapsed rounds to what a reader cares about: milliseconds under a
+// second, tenths of a second above
+func formatElapsed(elapsed time.Duration) string {
+	if elapsed < time.Second {
+		return elapsed.Round(time.Millisecond).String()
+	}
+	return fmt.Sprintf("%.1fs", elapsed.Seconds())
+}
+
+// FormatHistory renders recent CPU and memory readings as sparklines next to
+// the latest value
+func (f *Formatter) FormatHistory(cpuHistory []float64, memHistory []uint64) string {
+	if len(cpuHistory) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+
+	content.WriteString(f.section(" HISTORY "))
+	content.WriteString("\n")
+
+	memValues := make([]float64, len(memHistory))
+	for idx, v := range memHistory {
+		memValues[idx] = float64(v)
+	}
+
 	items := []struct {
 		key   string
 		value string
 	}{
-		{"CPU", fmt.Sprintf("%d cores, %s", sys.CPUCores, f.formatCPUUsage(sys.CPUUsage))},
-		{"Memory", f.formatSystemMemory(sys.MemoryUsed, sys.MemoryTotal, sys.MemoryPercent)},
-		{"CPU Model", f.truncateString(sys.CPUModel, 50)},
+		{"CPU Usage", f.formatProcessCPU(cpuHistory[len(cpuHistory)-1]) + "  " + metricStyle.Render(Sparkline(cpuHistory))},
+		{"Memory", valueStyle.Render(formatBytes(memHistory[len(memHistory)-1])) + "  " + metricStyle.Render(Sparkline(memValues))},
 	}
 
 	for _, item := range items {
@@ -115,34 +852,62 @@ func (f *Formatter) formatSystemContext(sys *models.SystemInfo) string {
 		content.WriteString("\n")
 	}
 
-	return content.String()
+	return f.fit(content.String())
 }
 
-func (f *Formatter) FormatWarnings(warnings []string) string {
-	if len(warnings) == 0 {
-		return successMessageStyle.Render("✓ All systems healthy") + "\n\n"
+func (f *Formatter) FormatFindings(findings []models.Finding) string {
+	if len(findings) == 0 && f.WarningsDiverted {
+		return ""
+	}
+	if len(findings) == 0 {
+		// With a filter active, lesser findings may still exist
+		if f.MinSeverity.AtLeast(models.SeverityWarning) {
+			return successMessageStyle.Render(fmt.Sprintf("%s No findings at %s severity or above", symbols.ok, f.MinSeverity)) + "\n\n"
+		}
+		return successMessageStyle.Render(symbols.ok+" All systems healthy") + "\n\n"
 	}
 
 	var output strings.Builder
 
+	shown := models.MostSevere(findings, f.MaxFindings)
+	if f.GroupBy == GroupByCategory {
+		output.WriteString(f.formatFindingsByCategory(shown))
+	} else {
+		output.WriteString(f.formatFindingLists(shown))
+	}
+
+	// An incident can raise dozens of findings; the lesser ones are only
+	// counted so the list stays scannable
+	if hidden := len(findings) - len(shown); hidden > 0 {
+		output.WriteString(metricStyle.Render(fmt.Sprintf("  ... and %d more lower-priority items (--all-findings lists them)", hidden)) + "\n\n")
+	}
+
+	// The gist, without counting
+	output.WriteString(valueStyle.Render("  Summary: "+models.CountFindings(findings).String()) + "\n\n")
+
+	return f.fit(output.String())
+}
+
+// formatFindingLists renders findings as two numbered lists, warnings then
+// recommendations
+func (f *Formatter) formatFindingLists(shown []models.Finding) string {
+	var output strings.Builder
+
 	// Separate warnings and recommendations
 	var actualWarnings []string
 	var recommendations []string
 
-	for _, item := range warnings {
-		if strings.HasPrefix(item, "⚠") {
-			actualWarnings = append(actualWarnings, item)
-		} else if strings.HasPrefix(item, "→") {
-			recommendations = append(recommendations, item)
+	for _, finding := range shown {
+		if finding.Kind == models.KindRecommendation {
+			recommendations = append(recommendations, symbols.recommend+" "+f.formatFindingMessage(finding))
 		} else {
-			// Fallback for items without prefix
-			actualWarnings = append(actualWarnings, item)
+			actualWarnings = append(actualWarnings, symbols.warning+" "+f.formatFindingMessage(finding))
 		}
 	}
 
 	// Display warnings first
 	if len(actualWarnings) > 0 {
-		output.WriteString(warningHeaderStyle.Render(" WARNINGS "))
+		output.WriteString(f.heading(warningHeaderStyle, " WARNINGS "))
 		output.WriteString("\n")
 
 		for i, warning := range actualWarnings {
@@ -155,19 +920,7 @@ func (f *Formatter) FormatWarnings(warnings []string) string {
 
 	// Display recommendations
 	if len(recommendations) > 0 {
-		recommendHeaderStyle := lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#3B82F6")).
-			Background(lipgloss.Color("#1E3A8A")).
-			Padding(0, 2).
-			MarginTop(1).
-			MarginBottom(1)
-
-		recommendItemStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#60A5FA")).
-			PaddingLeft(2)
-
-		output.WriteString(recommendHeaderStyle.Render(" RECOMMENDATIONS "))
+		output.WriteString(f.heading(recommendHeaderStyle, " RECOMMENDATIONS "))
 		output.WriteString("\n")
 
 		for i, rec := range recommendations {
@@ -181,6 +934,111 @@ func (f *Formatter) FormatWarnings(warnings []string) string {
 	return output.String()
 }
 
+// GroupByCategory is the --group-by mode that buckets findings by category
+const GroupByCategory = "category"
+
+// categoryOrder is the order category groups are shown in; categories not
+// listed follow in alphabetical order
+var categoryOrder = []string{"cpu", "memory", "disk", "network", "process_health", "security", "config", "baseline", "general"}
+
+// categoryLabels name the finding categories as section headers show them
+var categoryLabels = map[string]string{
+	"cpu":            "CPU",
+	"memory":         "MEMORY",
+	"disk":           "DISK",
+	"network":        "NETWORK",
+	"process_health": "PROCESS HEALTH",
+	"security":       "SECURITY",
+	"config":         "CONFIG",
+	"baseline":       "BASELINE",
+	"general":        "GENERAL",
+}
+
+// formatFindingsByCategory renders findings under one section per category,
+// for --group-by category: warnings first within each, numbered per section
+func (f *Formatter) formatFindingsByCategory(shown []models.Finding) string {
+	groups := make(map[string][]models.Finding)
+	for _, finding := range shown {
+		category := cmp.Or(finding.Category, "general")
+		groups[category] = append(groups[category], finding)
+	}
+	order := slices.Sorted(maps.Keys(groups))
+	slices.SortStableFunc(order, func(a, b string) int {
+		return cmp.Compare(categoryRank(a), categoryRank(b))
+	})
+
+	var output strings.Builder
+	for _, category := range order {
+		label := cmp.Or(categoryLabels[category], strings.ToUpper(strings.ReplaceAll(category, "_", " ")))
+		output.WriteString(f.section(" " + label + " "))
+		output.WriteString("\n")
+
+		// Warnings ahead of recommendations, each kind in its original order
+		group := groups[category]
+		slices.SortStableFunc(group, func(a, b models.Finding) int {
+			return cmp.Compare(boolRank(a.Kind == models.KindRecommendation), boolRank(b.Kind == models.KindRecommendation))
+		})
+		for i, finding := range group {
+			prefix := fmt.Sprintf("  %d. ", i+1)
+			if finding.Kind == models.KindRecommendation {
+				output.WriteString(recommendItemStyle.Render(prefix + symbols.recommend + " " + f.formatFindingMessage(finding)))
+			} else {
+				output.WriteString(warningItemStyle.Render(prefix + symbols.warning + " " + f.formatFindingMessage(finding)))
+			}
+			output.WriteString("\n")
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+// categoryRank places a category in categoryOrder, unlisted ones last
+func categoryRank(category string) int {
+	if rank := slices.Index(categoryOrder, category); rank >= 0 {
+		return rank
+	}
+	return len(categoryOrder)
+}
+
+// boolRank orders false before true
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// FormatFindingLine renders one finding as a single uncolored line, for the
+// --warnings-to channel
+func (f *Formatter) FormatFindingLine(finding models.Finding) string {
+	return fmt.Sprintf("%s: %s", finding.Severity, f.formatFindingMessage(finding))
+}
+
+// formatFindingMessage appends where the finding came from and the
+// triggering evidence in explain mode
+func (f *Formatter) formatFindingMessage(finding models.Finding) string {
+	message := finding.Message
+	if len(finding.Processes) > 0 {
+		pids := make([]string, len(finding.Processes))
+		for idx, pid := range finding.Processes {
+			pids[idx] = fmt.Sprint(pid)
+		}
+		message += " (PIDs " + strings.Join(pids, ", ") + ")"
+	}
+	if !f.Explain {
+		return message
+	}
+
+	details := []string{"source=" + finding.Source}
+	for _, e := range finding.Evidence {
+		details = append(details, e.String())
+	}
+	if finding.DocURL != "" {
+		details = append(details, "docs="+finding.DocURL)
+	}
+	return fmt.Sprintf("%s [%s]", message, strings.Join(details, ", "))
+}
+
 // Helper functions for better formatting
 func (f *Formatter) formatStatus(status string) string {
 	switch strings.ToLower(status) {
@@ -188,45 +1046,544 @@ func (f *Formatter) formatStatus(status string) string {
 		return statusGoodStyle.Render("Running")
 	case "s", "sleeping":
 		return valueStyle.Render("Sleeping")
+	case "i", "idle":
+		// Kernel threads waiting for work sleep in this state, which unlike
+		// D doesn't count towards the load average
+		return valueStyle.Render("Idle")
 	case "z", "zombie":
 		return statusWarningStyle.Render("Zombie")
 	case "t", "stopped":
 		return statusWarningStyle.Render("Stopped")
+	case "d", "disk-sleep":
+		// Blocked in the kernel, usually on I/O; it can't even be killed
+		// until the wait ends
+		return metricStyle.Render("Uninterruptible sleep (D)")
 	default:
 		return valueStyle.Render(status)
 	}
 }
 
+// formatStarted shows when the process started, in the given time format,
+// and how long ago
+func (f *Formatter) formatStarted(proc *models.ProcessInfo, format func(time.Time) string) string {
+	age, known := proc.Age()
+	if !known {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s (up %s)", format(proc.CreateTime), formatAge(age))
+}
+
+// formatAge renders an uptime in its two largest units, e.g. "3d 4h" or
+// "12m 5s"
+func formatAge(age time.Duration) string {
+	days, hours := int(age.Hours())/24, int(age.Hours())%24
+	minutes, seconds := int(age.Minutes())%60, int(age.Seconds())%60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+// formatTime renders a timestamp in the configured format. Timestamps keep
+// their own location, so UTC output is a matter of converting at collection.
+func (f *Formatter) formatTime(t time.Time) string {
+	switch strings.ToLower(f.TimeFormat) {
+	case "":
+		return t.Format(defaultTimeLayout)
+	case "rfc3339":
+		return t.Format(time.RFC3339)
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	default:
+		return t.Format(f.TimeFormat)
+	}
+}
+
+func (f *Formatter) formatTerminal(terminal string) string {
+	if terminal == "" {
+		return valueStyle.Render("none")
+	}
+	return valueStyle.Render(terminal)
+}
+
+// limits returns the thresholds for proc, or the system-wide ones for nil
+func (f *Formatter) limits(proc *models.ProcessInfo) models.Thresholds {
+	if f.Thresholds == nil {
+		return models.DefaultThresholds
+	}
+	return f.Thresholds(proc)
+}
+
 func (f *Formatter) formatCPUUsage(percent float64) string {
-	usage := fmt.Sprintf("%.1f%%", percent)
-	if percent > 80 {
+	limits := f.limits(nil)
+	return colorCPU(percent, fmt.Sprintf("%.1f%%", percent), limits.SystemCPUWarning, limits.SystemCPUCritical)
+}
+
+// formatProcessCPU renders a process's CPU usage in the chosen mode, colored
+// by the value shown so the thresholds read the same in either mode
+func (f *Formatter) formatProcessCPU(percent float64) string {
+	cores := runtime.NumCPU()
+	limits := f.limits(nil)
+	return colorCPU(f.CPUMode.Scale(percent, cores), f.CPUMode.Describe(percent, cores), limits.CPUWarning, limits.CPUCritical)
+}
+
+// scaleCPU is a process's CPU usage as a number in the chosen mode
+func (f *Formatter) scaleCPU(percent float64) float64 {
+	return f.CPUMode.Scale(percent, runtime.NumCPU())
+}
+
+// colorCPU marks usage as a warning above warning and critical above
+// critical, like the CPU rules
+func colorCPU(percent float64, usage string, warning, critical float64) string {
+	if percent > critical {
 		return statusWarningStyle.Render(usage)
-	} else if percent > 50 {
+	} else if percent > warning {
 		return metricStyle.Render(usage)
 	}
 	return valueStyle.Render(usage)
 }
 
-func (f *Formatter) formatMemoryUsage(rss uint64, percent float32) string {
-	memory := fmt.Sprintf("%s (%.1f%%)", formatBytes(rss), percent)
-	if percent > 10 {
+func (f *Formatter) formatMemoryUsage(proc *models.ProcessInfo) string {
+	memory := fmt.Sprintf("%s (%.1f%%)", formatBytes(proc.MemoryRSS), proc.MemoryPercent)
+	if proc.ShmemDominated() {
+		memory = fmt.Sprintf("%s (%.1f%%, %s shm)", formatBytes(proc.MemoryRSS), proc.MemoryPercent, formatBytes(proc.MemoryShmem))
+	}
+	// Colored by the process's own share, like the high_memory rule
+	limit, own := f.limits(proc).MemoryPercent, proc.OwnMemoryPercent()
+	if own > limit {
 		return statusWarningStyle.Render(memory)
-	} else if percent > 5 {
+	} else if own > limit/2 {
 		return metricStyle.Render(memory)
 	}
 	return valueStyle.Render(memory)
 }
 
+// formatExecutable flags an executable replaced or deleted since the
+// process started, as it still runs the old version
+func (f *Formatter) formatExecutable(proc *models.ProcessInfo) string {
+	if proc.ExecutableState == "" {
+		return proc.Executable
+	}
+	return proc.Executable + " " + statusWarningStyle.Render("("+proc.ExecutableState.Describe()+")")
+}
+
+// formatContainer names the process's container as "name (image)", falling
+// back to the short container ID when it couldn't be resolved
+// formatCommandHash shows the command hash, and the executable's digest when
+// --hash-binary read it, in verbose output only; the default report has no
+// room for fingerprints
+func (f *Formatter) formatCommandHash(proc *models.ProcessInfo) string {
+	if !f.Verbose || proc.CommandHash == "" {
+		return ""
+	}
+	if proc.BinarySHA256 != "" {
+		return fmt.Sprintf("%s (binary sha256 %s)", proc.CommandHash, proc.BinarySHA256)
+	}
+	return proc.CommandHash
+}
+
+func (f *Formatter) formatContainer(proc *models.ProcessInfo) string {
+	switch {
+	case proc.ContainerName != "" && proc.ContainerImage != "":
+		return fmt.Sprintf("%s (%s)", proc.ContainerName, proc.ContainerImage)
+	case proc.ContainerName != "":
+		return proc.ContainerName
+	case len(proc.ContainerID) > 12:
+		return proc.ContainerID[:12]
+	default:
+		return proc.ContainerID
+	}
+}
+
+// formatRoot shows a root directory other than the host's, e.g.
+// "/srv/jail (chrooted)"; empty for the host's
+func formatRoot(root string) string {
+	switch root {
+	case "", models.RootChrooted:
+		return root
+	case models.RootContainer:
+		return "container filesystem (own mount namespace)"
+	}
+	return root + " (chrooted)"
+}
+
+// formatCapabilities lists the effective capabilities in verbose mode,
+// collapsing a full set to "all"; empty where they couldn't be read
+func (f *Formatter) formatCapabilities(proc *models.ProcessInfo) string {
+	if !f.Verbose || proc.Seccomp == "" {
+		return ""
+	}
+	return capabilitySummary(proc)
+}
+
+func (f *Formatter) formatSeccomp(proc *models.ProcessInfo) string {
+	if !f.Verbose || proc.Seccomp == "" {
+		return ""
+	}
+	return proc.Seccomp
+}
+
+// formatScheduler shows the scheduling policy, e.g. "SCHED_FIFO (rt prio
+// 50)"; empty where it couldn't be read
+func formatScheduler(scheduler *models.Scheduler) string {
+	if scheduler == nil {
+		return ""
+	}
+	return scheduler.String()
+}
+
+// formatWait shows where most threads are blocked in the kernel,
+// highlighted when every one is waiting for a lock
+func formatWait(wait *models.WaitState) string {
+	if wait == nil {
+		return ""
+	}
+	if wait.AllOnLocks() {
+		return statusWarningStyle.Render(wait.String())
+	}
+	return wait.String()
+}
+
+// formatSession shows the process group and session in verbose mode, e.g.
+// "PGID: 4321 SID: 4321 (session leader)"
+func (f *Formatter) formatSession(proc *models.ProcessInfo) string {
+	if !f.Verbose || proc.SID == 0 {
+		return ""
+	}
+	session := fmt.Sprintf("PGID: %d SID: %d", proc.PGID, proc.SID)
+	if proc.SessionLeader() {
+		session += " (session leader)"
+	}
+	return session
+}
+
+// formatNamespaces shows which namespaces are the host's in verbose mode
+func (f *Formatter) formatNamespaces(proc *models.ProcessInfo) string {
+	if !f.Verbose {
+		return ""
+	}
+	return proc.NamespaceSummary()
+}
+
+func capabilitySummary(proc *models.ProcessInfo) string {
+	switch {
+	case len(proc.Capabilities) == 0:
+		return "none"
+	case proc.HasAllCapabilities():
+		return fmt.Sprintf("all (%d)", len(proc.Capabilities))
+	default:
+		return strings.Join(proc.Capabilities, ", ")
+	}
+}
+
+// formatCPUTime shows cumulative CPU time since start, which is distinct from
+// the instantaneous CPU Usage percentage above it
+func (f *Formatter) formatCPUTime(user, system float64) string {
+	seconds := func(s float64) string {
+		return time.Duration(s * float64(time.Second)).Round(time.Second).String()
+	}
+	return valueStyle.Render(fmt.Sprintf("%s user, %s sys (total since start)",
+		seconds(user), seconds(system)))
+}
+
+// formatMemoryBreakdown splits RSS into private, shared and swapped memory in
+// verbose mode; private+swap is closer to the process's real cost
+func (f *Formatter) formatMemoryBreakdown(proc *models.ProcessInfo) string {
+	if !f.Verbose || proc.MemoryPrivate == 0 {
+		return ""
+	}
+	breakdown := fmt.Sprintf("%s private, %s shared, %s swap",
+		formatBytes(proc.MemoryPrivate), formatBytes(proc.MemoryShared), formatBytes(proc.MemorySwap))
+	if proc.MemoryShmem > 0 {
+		breakdown += fmt.Sprintf("; %s in shm segments", formatBytes(proc.MemoryShmem))
+	}
+	return valueStyle.Render(breakdown)
+}
+
+// formatOOMRisk shows how likely the OOM killer is to take the process,
+// e.g. "high (oom_score 812, adj 0)"; empty off Linux
+func (f *Formatter) formatOOMRisk(oom *models.OOMScore) string {
+	if oom == nil {
+		return ""
+	}
+	score := fmt.Sprintf("oom_score %d, adj %d", oom.Score, oom.Adj)
+	switch oom.Risk {
+	case "":
+		return valueStyle.Render(score)
+	case models.OOMRiskHigh:
+		return statusWarningStyle.Render(fmt.Sprintf("%s (%s)", oom.Risk, score))
+	case models.OOMRiskModerate:
+		return metricStyle.Render(fmt.Sprintf("%s (%s)", oom.Risk, score))
+	default:
+		return valueStyle.Render(fmt.Sprintf("%s (%s)", oom.Risk, score))
+	}
+}
+
+// formatCgroupMemory shows usage against the cgroup memory limit, e.g.
+// "1.6 GB of 2.0 GB limit (80.0%)", highlighted from 80%; empty without a
+// limit
+func formatCgroupMemory(cgroup *models.CgroupMemory) string {
+	if cgroup == nil {
+		return ""
+	}
+	usage := describeCgroupMemory(cgroup)
+	switch {
+	case cgroup.Percent() >= 90:
+		return statusWarningStyle.Render(usage)
+	case cgroup.Percent() >= 80:
+		return metricStyle.Render(usage)
+	}
+	return valueStyle.Render(usage)
+}
+
+// describeCgroupMemory is formatCgroupMemory without the styling
+func describeCgroupMemory(cgroup *models.CgroupMemory) string {
+	return fmt.Sprintf("%s of %s limit (%.1f%%)", formatBytes(cgroup.WorkingSet), formatBytes(cgroup.Limit), cgroup.Percent())
+}
+
+// formatPeakMemory shows the RSS high-water mark and how far below it the
+// process currently is; empty when the platform doesn't report a peak
+func (f *Formatter) formatPeakMemory(rss, peak uint64) string {
+	if peak == 0 {
+		return ""
+	}
+	if rss >= peak {
+		return metricStyle.Render(formatBytes(peak) + " (at peak)")
+	}
+	return valueStyle.Render(fmt.Sprintf("%s (current is %.0f%% of peak)",
+		formatBytes(peak), float64(rss)/float64(peak)*100))
+}
+
+// formatSpread follows an averaged system reading with the range it came from
+func formatSpread(count int, stats models.SampleStats) string {
+	return valueStyle.Render(fmt.Sprintf(" avg of %d (min %.1f%%, max %.1f%%)", count, stats.Min, stats.Max))
+}
+
 func (f *Formatter) formatSystemMemory(used, total uint64, percent float64) string {
 	memory := fmt.Sprintf("%s / %s (%.1f%%)", formatBytes(used), formatBytes(total), percent)
-	if percent > 85 {
+	limits := f.limits(nil)
+	if percent > limits.SystemMemoryCritical {
 		return statusWarningStyle.Render(memory)
-	} else if percent > 70 {
+	} else if percent > limits.SystemMemoryWarning {
 		return metricStyle.Render(memory)
 	}
 	return valueStyle.Render(memory)
 }
 
+// formatLoad shows the 1/5/15 minute load averages, highlighted relative to
+// the core count; empty where the platform has no load average
+func (f *Formatter) formatLoad(sys *models.SystemInfo) string {
+	if sys.Load1 == 0 && sys.Load5 == 0 && sys.Load15 == 0 {
+		return ""
+	}
+	text := fmt.Sprintf("%.2f, %.2f, %.2f", sys.Load1, sys.Load5, sys.Load15)
+	perCore := sys.Load1 / float64(max(sys.CPUCores, 1))
+	if perCore > 2 {
+		return statusWarningStyle.Render(text)
+	} else if perCore > 1 {
+		return metricStyle.Render(text)
+	}
+	return valueStyle.Render(text)
+}
+
+func (f *Formatter) formatDisk(sys *models.SystemInfo) string {
+	if sys.DiskTotal == 0 {
+		return ""
+	}
+	return f.formatSystemMemory(sys.DiskUsed, sys.DiskTotal, sys.DiskPercent)
+}
+
+// healthComponentLabels name the health score's inputs as reports show them
+var healthComponentLabels = map[string]string{
+	"cpu":           "CPU",
+	"memory":        "memory",
+	"system_memory": "system memory",
+	"swap":          "swap",
+	"fd_usage":      "FD usage",
+	"connections":   "connections",
+	"status":        "status",
+}
+
+// describeHealthComponents lists the components that took points off the
+// health score, largest first, e.g. "swap: -15, CPU: -10, FD usage: -2.5"
+func describeHealthComponents(components []models.HealthComponent) string {
+	var deductions []models.HealthComponent
+	for _, component := range components {
+		if math.Round(component.Deduction*10) > 0 {
+			deductions = append(deductions, component)
+		}
+	}
+	if len(deductions) == 0 {
+		return "none"
+	}
+	slices.SortStableFunc(deductions, func(a, b models.HealthComponent) int {
+		return cmp.Compare(b.Deduction, a.Deduction)
+	})
+
+	parts := make([]string, len(deductions))
+	for idx, component := range deductions {
+		label := cmp.Or(healthComponentLabels[component.Name], component.Name)
+		parts[idx] = fmt.Sprintf("%s: -%s", label, strconv.FormatFloat(math.Round(component.Deduction*10)/10, 'f', -1, 64))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatHealthScore shows the score followed by its band as a colored
+// label: green Healthy, amber Degraded or red Critical
+func (f *Formatter) formatHealthScore(score int, band models.HealthBand) string {
+	text := fmt.Sprintf("%d/100", score)
+	color := healthBandColors[band]
+	label := lipgloss.NewStyle().Bold(true).Foreground(color)
+	if !f.Borderless {
+		label = label.Foreground(lipgloss.Color("#0F172A")).Background(color).Padding(0, 1)
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(color).Render(text) + "  " + label.Render(band.Label())
+}
+
+// healthBandColors are the palette colors of the health bands
+var healthBandColors = map[models.HealthBand]lipgloss.Color{
+	models.HealthBandHealthy:  successColor,
+	models.HealthBandDegraded: accentColor,
+	models.HealthBandCritical: warningColor,
+}
+
+func (f *Formatter) formatSwap(used, total uint64, percent float64) string {
+	if total == 0 {
+		return valueStyle.Render("none")
+	}
+	swap := fmt.Sprintf("%s / %s (%.1f%%)", formatBytes(used), formatBytes(total), percent)
+	if percent > 50 {
+		return statusWarningStyle.Render(swap)
+	} else if percent > 20 {
+		return metricStyle.Render(swap)
+	}
+	return valueStyle.Render(swap)
+}
+
+// formatOpenFiles shows the descriptor count against its limit, followed in
+// verbose mode by the breakdown by type. Windows processes have no
+// descriptors; their handle count is shown instead.
+func (f *Formatter) formatOpenFiles(proc *models.ProcessInfo) string {
+	if proc.Handles > 0 {
+		return ""
+	}
+	text := f.formatDescriptorCount(proc.OpenFiles, proc.MaxOpenFiles, f.limits(proc).OpenFiles)
+	if proc.DescriptorsSkipped {
+		text += " " + metricStyle.Render("(detail skipped: too many descriptors)")
+	}
+	if f.Verbose && proc.OpenFileTypes != nil {
+		text += " " + valueStyle.Render("("+formatOpenFileTypes(proc.OpenFileTypes)+")")
+	}
+	return text
+}
+
+// formatGoRuntime shows the toolchain and GOMAXPROCS of a Go program; empty
+// for anything else
+func formatGoRuntime(goRuntime *models.GoRuntime) string {
+	if goRuntime == nil {
+		return ""
+	}
+	return goRuntime.Describe()
+}
+
+// formatHandles shows a Windows process's handle count. Busy processes
+// routinely hold thousands, so unlike open files it isn't colored.
+func formatHandles(handles uint32) string {
+	if handles == 0 {
+		return ""
+	}
+	return valueStyle.Render(fmt.Sprintf("%d", handles))
+}
+
+// formatTrend appends the --trend arrow to a value, highlighting a metric
+// on the move
+func formatTrend(trend models.Trend) string {
+	switch trend {
+	case "":
+		return ""
+	case models.TrendSteady:
+		return " " + valueStyle.Render(trendArrow(trend))
+	}
+	return " " + metricStyle.Render(trendArrow(trend))
+}
+
+// formatContextSwitches shows the switch rates over the sampling window, or
+// the totals since start when the window was too short to measure
+func formatContextSwitches(switches *models.ContextSwitches) string {
+	if switches == nil {
+		return ""
+	}
+	return valueStyle.Render(describeContextSwitches(switches))
+}
+
+// formatPower shows the --power estimate; empty without one
+func formatPower(power *models.PowerEstimate) string {
+	if power == nil {
+		return ""
+	}
+	return valueStyle.Render(describePower(power))
+}
+
+// describePower is formatPower without the styling; the estimate is in
+// watts where energy counters were read, else the CPU time share alone
+func describePower(power *models.PowerEstimate) string {
+	if power.Watts == nil || power.PackageWatts == nil {
+		return fmt.Sprintf("%.1f%% of busy CPU time (no energy counters for watts)", power.CPUShare)
+	}
+	return fmt.Sprintf("~%.1f W of %.1f W CPU package draw (%.1f%% of busy CPU time)",
+		*power.Watts, *power.PackageWatts, power.CPUShare)
+}
+
+// describeContextSwitches is formatContextSwitches without the styling
+func describeContextSwitches(switches *models.ContextSwitches) string {
+	if switches.VoluntaryRate == nil || switches.InvoluntaryRate == nil {
+		return fmt.Sprintf("%d voluntary, %d involuntary since start", switches.Voluntary, switches.Involuntary)
+	}
+	return fmt.Sprintf("%.0f/s voluntary, %.0f/s involuntary", *switches.VoluntaryRate, *switches.InvoluntaryRate)
+}
+
+// formatDescriptorCount colors the count against its rlimit where known,
+// otherwise against threshold
+func (f *Formatter) formatDescriptorCount(count, limit, threshold int) string {
+	if limit == 0 {
+		return f.formatCount(count, threshold)
+	}
+	text := fmt.Sprintf("%d / %d", count, limit)
+	ratio := float64(count) / float64(limit)
+	if ratio > 0.8 {
+		return statusWarningStyle.Render(text)
+	} else if ratio > 0.5 {
+		return metricStyle.Render(text)
+	}
+	return valueStyle.Render(text)
+}
+
+// formatOpenFileTypes lists the non-zero descriptor kinds, e.g.
+// "40 files, 42 sockets, 6 pipes"
+func formatOpenFileTypes(types *models.OpenFileTypes) string {
+	var parts []string
+	for _, kind := range []struct {
+		count int
+		label string
+	}{
+		{types.Files, "files"},
+		{types.Sockets, "sockets"},
+		{types.Pipes, "pipes"},
+		{types.AnonInodes, "anon inodes"},
+		{types.Other, "other"},
+	} {
+		if kind.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", kind.count, kind.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (f *Formatter) formatCount(count, threshold int) string {
 	countStr := fmt.Sprintf("%d", count)
 	if count > threshold {
@@ -243,16 +1600,3 @@ func (f *Formatter) truncateString(s string, maxLen int) string {
 	}
 	return valueStyle.Render(s[:maxLen-3] + "...")
 }
-
-func formatBytes(bytes uint64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
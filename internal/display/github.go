@@ -0,0 +1,46 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// annotationLevels maps finding severities to GitHub Actions workflow
+// commands; anything unrecognized is annotated as a warning
+var annotationLevels = map[models.Severity]string{
+	models.SeverityCritical: "error",
+	models.SeverityWarning:  "warning",
+	models.SeverityInfo:     "notice",
+}
+
+// annotationEscaper and annotationPropertyEscaper encode what the workflow
+// command syntax would otherwise read as the end of a message or property
+var (
+	annotationEscaper         = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	annotationPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// FormatGitHubAnnotations renders each finding as a GitHub Actions workflow
+// command (::error::, ::warning:: or ::notice::), for --format github.
+// subject says what the finding is about, e.g. "PID 42 (nginx)" or "host",
+// and the annotation title names the rule that fired it.
+func (f *Formatter) FormatGitHubAnnotations(subject string, findings []models.Finding) string {
+	var out strings.Builder
+	for _, finding := range findings {
+		level, ok := annotationLevels[finding.Severity]
+		if !ok {
+			level = "warning"
+		}
+		title := "inspektor"
+		if finding.Rule != "" {
+			title += " " + finding.Rule
+		} else if finding.Category != "" {
+			title += " " + finding.Category
+		}
+		message := fmt.Sprintf("%s: %s", subject, f.formatFindingMessage(finding))
+		fmt.Fprintf(&out, "::%s title=%s::%s\n", level, annotationPropertyEscaper.Replace(title), annotationEscaper.Replace(message))
+	}
+	return out.String()
+}
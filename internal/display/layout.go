@@ -0,0 +1,95 @@
+package display
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layout orders the rows of the text report's sections. Each list names a
+// section's rows by their label, in the order to show them; rows left out
+// are hidden, and a section left out keeps its built-in order.
+type Layout struct {
+	Process   []string `yaml:"process"`
+	Resources []string `yaml:"resources"`
+	System    []string `yaml:"system"`
+}
+
+// layoutRows are the labels each section's rows go by, in their built-in
+// order; a row only shows when it has a value
+var layoutRows = map[string][]string{
+	"process": {"Status", "Scheduler", "Wait Channel", "User", "TTY", "Process Group", "Container", "Root", "Capabilities", "Seccomp",
+		"Namespaces", "Command", "Executable", "Command Hash", "Go Runtime", "Working Dir", "Listening", "Started"},
+	"resources": {"CPU Usage", "CPU Time", "Memory", "Peak Memory", "Memory Breakdown", "Cgroup Memory", "OOM Risk",
+		"Virtual Memory", "Open Files", "Handles", "Deleted Files", "Connections", "Network I/O", "Disk I/O",
+		"Child Processes", "Threads", "Context Switches", "Power (est.)"},
+	"system": {"CPU", "Memory", "Swap", "Load Average", "Disk (/)", "CPU Model"},
+}
+
+// LoadLayout reads a report layout from a YAML file. Labels match
+// regardless of case and are stored as the report spells them.
+func LoadLayout(path string) (*Layout, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+
+	var layout Layout
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	// An empty file keeps every section as built in
+	if err := decoder.Decode(&layout); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse layout %s: %w", path, err)
+	}
+
+	for section, rows := range map[string]*[]string{"process": &layout.Process, "resources": &layout.Resources, "system": &layout.System} {
+		for index, label := range *rows {
+			known := slices.IndexFunc(layoutRows[section], func(row string) bool { return strings.EqualFold(row, label) })
+			if known < 0 {
+				return nil, fmt.Errorf("layout %s: %s has no %q row (known: %s)", path, section, label, strings.Join(layoutRows[section], ", "))
+			}
+			(*rows)[index] = layoutRows[section][known]
+		}
+	}
+	return &layout, nil
+}
+
+// row is one key-value line of a report section; metric names the value
+// watch mode's change marks are based on, if any
+type row struct {
+	key, value, metric string
+}
+
+// arrange orders rows as the layout lists them for the section, dropping
+// those it leaves out; without a layout for the section rows are returned
+// as they are
+func (l *Layout) arrange(section string, rows []row) []row {
+	if l == nil {
+		return rows
+	}
+	var order []string
+	switch section {
+	case "process":
+		order = l.Process
+	case "resources":
+		order = l.Resources
+	case "system":
+		order = l.System
+	}
+	if order == nil {
+		return rows
+	}
+	arranged := make([]row, 0, len(order))
+	for _, key := range order {
+		if index := slices.IndexFunc(rows, func(r row) bool { return r.key == key }); index >= 0 {
+			arranged = append(arranged, rows[index])
+		}
+	}
+	return arranged
+}
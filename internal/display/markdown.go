@@ -0,0 +1,369 @@
+package display
+
+import (
+	"fmt"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// FormatMarkdown renders an inspection as plain Markdown for pasting into
+// tickets and chat: a header with the host and time, metric tables and
+// bulleted findings. It never emits ANSI styling, regardless of color settings.
+func (f *Formatter) FormatMarkdown(data *models.InspectionData, findings []models.Finding, at time.Time) string {
+	var out strings.Builder
+	proc := data.Process
+
+	fmt.Fprintf(&out, "# Inspektor report: %s (PID %d)\n\n", markdownEscape(proc.DisplayName()), proc.PID)
+	if data.Host != nil {
+		fmt.Fprintf(&out, "- **Host:** %s\n", markdownEscape(data.Host.String()))
+	}
+	fmt.Fprintf(&out, "- **Generated:** %s\n", f.formatMarkdownTime(at))
+	if !data.TimedOut {
+		fmt.Fprintf(&out, "- **Health score:** %d/100 (%s)\n", data.HealthScore, data.HealthBand.Label())
+		if f.ExplainHealth {
+			fmt.Fprintf(&out, "- **Health deductions:** %s\n", describeHealthComponents(data.HealthComponents))
+		}
+	}
+	out.WriteString("\n")
+
+	if hint := permissionHint(proc); hint != "" {
+		out.WriteString("> " + markdownEscape(hint) + "\n\n")
+	}
+
+	// A kernel thread has only its state and CPU use to show
+	if proc.KernelThread {
+		writeMarkdownTable(&out, "Kernel Thread", [][2]string{
+			{"Status", proc.Status},
+			{"Scheduler", formatScheduler(proc.Scheduler)},
+			{"Wait Channel", markdownWait(proc.Wait)},
+			{"Started", f.formatStarted(proc, f.formatMarkdownTime)},
+			{"CPU Usage", f.CPUMode.Describe(proc.CPUPercent, runtime.NumCPU())},
+			{"CPU Time", fmt.Sprintf("%.0fs user, %.0fs sys", proc.CPUTimeUser, proc.CPUTimeSystem)},
+		})
+	} else {
+		writeMarkdownTable(&out, "Process", [][2]string{
+			{"Status", proc.Status},
+			{"Scheduler", formatScheduler(proc.Scheduler)},
+			{"Wait Channel", markdownWait(proc.Wait)},
+			{"User", proc.Username},
+			{"Container", f.formatContainer(proc)},
+			{"Root", formatRoot(proc.Root)},
+			{"Capabilities", markdownCapabilities(proc)},
+			{"Seccomp", proc.Seccomp},
+			{"Command", proc.CommandLine},
+			{"Executable", markdownExecutable(proc)},
+			{"Command Hash", proc.CommandHash},
+			{"Go Runtime", formatGoRuntime(proc.Go)},
+			{"Working Dir", proc.WorkingDir},
+			{"Listening", strings.Join(proc.ListenPortLabels(), ", ")},
+			{"Started", f.formatStarted(proc, f.formatMarkdownTime)},
+			{"Restarts", markdownRestarts(data.Restarts)},
+			{"Command Changed", markdownCommandChange(data.CommandChanged)},
+		})
+
+		// Windows processes hold handles rather than file descriptors
+		descriptors := [2]string{"Open Files", fmt.Sprintf("%d", proc.OpenFiles)}
+		connections := fmt.Sprintf("%d", proc.Connections)
+		if proc.DescriptorsSkipped {
+			descriptors[1] += " (detail skipped: too many descriptors)"
+			connections = "not read (too many descriptors)"
+		}
+		if proc.Handles > 0 {
+			descriptors = [2]string{"Handles", fmt.Sprintf("%d", proc.Handles)}
+		}
+		metrics := [][2]string{
+			{"CPU Usage", strings.TrimSpace(f.CPUMode.Describe(proc.CPUPercent, runtime.NumCPU()) + " " + trendArrow(proc.CPUTrend))},
+			{"CPU Time", fmt.Sprintf("%.0fs user, %.0fs sys", proc.CPUTimeUser, proc.CPUTimeSystem)},
+			{"Memory", strings.TrimSpace(fmt.Sprintf("%s (%.1f%%) %s", formatBytes(proc.MemoryRSS), proc.MemoryPercent, trendArrow(proc.MemoryTrend)))},
+			{"Virtual Memory", formatBytes(proc.MemoryVMS)},
+			descriptors,
+			{"Connections", connections},
+			{"Child Processes", fmt.Sprintf("%d", proc.Children)},
+			{"Threads", fmt.Sprintf("%d", proc.NumThreads)},
+		}
+		if proc.CgroupMemory != nil {
+			metrics = append(metrics, [2]string{"Cgroup Memory", describeCgroupMemory(proc.CgroupMemory)})
+		}
+		if proc.ContextSwitches != nil {
+			metrics = append(metrics, [2]string{"Context Switches", describeContextSwitches(proc.ContextSwitches)})
+		}
+		if proc.PowerEstimate != nil {
+			metrics = append(metrics, [2]string{"Power (est.)", describePower(proc.PowerEstimate)})
+		}
+		if proc.MemoryPeakRSS > 0 {
+			metrics = append(metrics, [2]string{"Peak Memory", formatBytes(proc.MemoryPeakRSS)})
+		}
+		if proc.MemoryShmem > 0 {
+			metrics = append(metrics, [2]string{"Shared Memory Segments", formatBytes(proc.MemoryShmem)})
+		}
+		if proc.OpenFileTypes != nil {
+			metrics = append(metrics, [2]string{"Open File Types", formatOpenFileTypes(proc.OpenFileTypes)})
+		}
+		if proc.MaxOpenFiles > 0 {
+			metrics = append(metrics, [2]string{"Open File Limit", fmt.Sprintf("%d", proc.MaxOpenFiles)})
+		}
+		if len(proc.DeletedFiles) > 0 {
+			metrics = append(metrics, [2]string{"Deleted Files", fmt.Sprintf("%d holding %s",
+				len(proc.DeletedFiles), formatBytes(proc.DeletedFilesSize()))})
+		}
+		if proc.NetRxRate != nil && proc.NetTxRate != nil {
+			metrics = append(metrics, [2]string{"Network I/O", fmt.Sprintf("%s/s in, %s/s out (net namespace)",
+				formatBytes(uint64(*proc.NetRxRate)), formatBytes(uint64(*proc.NetTxRate)))})
+		}
+		if proc.DiskReadRate != nil && proc.DiskWriteRate != nil {
+			metrics = append(metrics, [2]string{"Disk I/O", fmt.Sprintf("%s/s read, %s/s write",
+				formatBytes(uint64(*proc.DiskReadRate)), formatBytes(uint64(*proc.DiskWriteRate)))})
+		}
+		writeMarkdownTable(&out, "Resources", metrics)
+	}
+
+	if totals := data.TreeTotals; totals != nil {
+		writeMarkdownTable(&out, "With Children", [][2]string{
+			{"Processes", fmt.Sprintf("%d", totals.Processes)},
+			{"CPU Usage", fmt.Sprintf("%s (self %.1f%%)", f.CPUMode.Describe(totals.CPUPercent, runtime.NumCPU()), f.scaleCPU(proc.CPUPercent))},
+			{"Memory", fmt.Sprintf("%s (self %s)", formatBytes(totals.MemoryRSS), formatBytes(proc.MemoryRSS))},
+			{"Open Files", fmt.Sprintf("%d (self %d)", totals.OpenFiles, proc.OpenFiles)},
+			{"Connections", fmt.Sprintf("%d (self %d)", totals.Connections, proc.Connections)},
+		})
+	}
+
+	if changes := data.ChildChanges; changes != nil {
+		fmt.Fprintf(&out, "## Child Processes\n\n%d spawned, %d exited in the last %s\n\n",
+			changes.TotalSpawned, changes.TotalExited, time.Since(changes.Since).Round(time.Second))
+		for _, child := range changes.Spawned {
+			fmt.Fprintf(&out, "- new child %d (%s) appeared\n", child.PID, markdownEscape(child.Name))
+		}
+		for _, child := range changes.Exited {
+			fmt.Fprintf(&out, "- child %d (%s) exited\n", child.PID, markdownEscape(child.Name))
+		}
+		if len(changes.Spawned)+len(changes.Exited) > 0 {
+			out.WriteString("\n")
+		}
+	}
+
+	if len(proc.RuntimeEnv) > 0 {
+		var env [][2]string
+		for _, name := range slices.Sorted(maps.Keys(proc.RuntimeEnv)) {
+			env = append(env, [2]string{name, proc.RuntimeEnv[name]})
+		}
+		writeMarkdownTable(&out, "Runtime", env)
+	}
+
+	if len(proc.HotThreads) > 0 {
+		f.writeMarkdownThreads(&out, proc.HotThreads)
+	}
+
+	if sys := data.System; sys != nil {
+		writeMarkdownTable(&out, "System", [][2]string{
+			{"CPU", fmt.Sprintf("%d cores, %.1f%%", sys.CPUCores, sys.CPUUsage)},
+			{"Memory", fmt.Sprintf("%s / %s (%.1f%%)", formatBytes(sys.MemoryUsed), formatBytes(sys.MemoryTotal), sys.MemoryPercent)},
+			{"Swap", fmt.Sprintf("%s / %s (%.1f%%)", formatBytes(sys.SwapUsed), formatBytes(sys.SwapTotal), sys.SwapPercent)},
+			{"Load Average", fmt.Sprintf("%.2f, %.2f, %.2f", sys.Load1, sys.Load5, sys.Load15)},
+			{"CPU Model", sys.CPUModel},
+		})
+	}
+	if data.Top != nil {
+		writeMarkdownTop(&out, data.Process.PID, data.Top)
+	}
+	for _, failure := range data.CollectionErrors {
+		fmt.Fprintf(&out, "> No %s data: %s\n\n", failure.Collector, markdownEscape(failure.Error))
+	}
+
+	if data.Tree != nil {
+		fmt.Fprintf(&out, "## Process Tree\n\n%d descendants, %.1f%% CPU, %s RSS total\n\n",
+			data.Tree.Descendants, data.Tree.TotalCPU, formatBytes(data.Tree.TotalRSS))
+		writeMarkdownTree(&out, data.Tree.Root, 0, 0)
+		out.WriteString("\n")
+	}
+
+	if data.TimedOut {
+		out.WriteString("> Collection timed out: showing partial results, analysis skipped\n")
+		return out.String()
+	}
+
+	var warnings, recommendations []string
+	for _, finding := range findings {
+		message := markdownEscape(f.formatFindingMessage(finding))
+		if finding.Kind == models.KindRecommendation {
+			recommendations = append(recommendations, "- "+message)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("- **%s** %s", finding.Severity, message))
+		}
+	}
+
+	if len(warnings) == 0 && len(recommendations) == 0 && !f.WarningsDiverted {
+		out.WriteString("No findings.\n")
+	}
+	if len(warnings) > 0 {
+		out.WriteString("## Warnings\n\n" + strings.Join(warnings, "\n") + "\n\n")
+	}
+	if len(recommendations) > 0 {
+		out.WriteString("## Recommendations\n\n" + strings.Join(recommendations, "\n") + "\n\n")
+	}
+	if len(findings) > 0 {
+		out.WriteString("**Summary:** " + models.CountFindings(findings).String() + "\n")
+	}
+
+	return out.String()
+}
+
+// FormatGroupMarkdown renders the combined usage of a control group's or a
+// user's processes as a Markdown table, followed by any findings about the
+// group as a whole
+func (f *Formatter) FormatGroupMarkdown(kind, name string, totals *models.TreeTotals, findings []models.Finding) string {
+	var out strings.Builder
+	writeMarkdownTable(&out, kind+" Totals", [][2]string{
+		{kind, name},
+		{"Processes", fmt.Sprintf("%d", totals.Processes)},
+		{"CPU Usage", f.CPUMode.Describe(totals.CPUPercent, runtime.NumCPU())},
+		{"Memory", formatBytes(totals.MemoryRSS)},
+		{"Open Files", fmt.Sprintf("%d", totals.OpenFiles)},
+		{"Connections", fmt.Sprintf("%d", totals.Connections)},
+	})
+	if len(findings) > 0 {
+		out.WriteString("## Warnings\n\n")
+		for _, finding := range findings {
+			fmt.Fprintf(&out, "- **%s** %s\n", finding.Severity, markdownEscape(f.formatFindingMessage(finding)))
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// formatMarkdownTime uses the configured time format, defaulting to RFC 3339
+// so a pasted report carries the full date and zone
+func (f *Formatter) formatMarkdownTime(t time.Time) string {
+	if f.TimeFormat == "" {
+		return t.Format(time.RFC3339)
+	}
+	return f.formatTime(t)
+}
+
+// markdownCapabilities is empty where capabilities couldn't be read, so the
+// row is skipped
+func markdownCapabilities(proc *models.ProcessInfo) string {
+	if proc.Seccomp == "" {
+		return ""
+	}
+	return capabilitySummary(proc)
+}
+
+// markdownExecutable notes an executable changed on disk since the process
+// started
+func markdownExecutable(proc *models.ProcessInfo) string {
+	if proc.ExecutableState == "" {
+		return proc.Executable
+	}
+	return fmt.Sprintf("%s (%s)", proc.Executable, proc.ExecutableState.Describe())
+}
+
+// markdownWait is empty unless some thread is blocked in the kernel
+func markdownWait(wait *models.WaitState) string {
+	if wait == nil {
+		return ""
+	}
+	return wait.String()
+}
+
+// markdownCommandChange is empty unless the command hash changed while
+// watching
+func markdownCommandChange(change *models.CommandChange) string {
+	if change == nil {
+		return ""
+	}
+	return fmt.Sprintf("hash %s, was %s (previously PID %d)", change.Hash, change.PreviousHash, change.PreviousPID)
+}
+
+// markdownRestarts is empty unless restarts were seen while watching
+func markdownRestarts(restarts *models.RestartInfo) string {
+	if restarts == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d in the last %s (previously PID %d)",
+		restarts.Count, time.Since(restarts.Since).Round(time.Second), restarts.PreviousPID)
+}
+
+// writeMarkdownTable writes a two-column section, skipping empty values
+func writeMarkdownTable(out *strings.Builder, title string, rows [][2]string) {
+	fmt.Fprintf(out, "## %s\n\n| Field | Value |\n|---|---|\n", title)
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		fmt.Fprintf(out, "| %s | %s |\n", row[0], markdownCell(row[1]))
+	}
+	out.WriteString("\n")
+}
+
+// writeMarkdownTop lists the host's heaviest processes, bolding the inspected
+// one
+func writeMarkdownTop(out *strings.Builder, pid int32, top *models.TopConsumers) {
+	fmt.Fprintf(out, "## Top on Host\n\n%d processes\n\n| Rank | By CPU | By Memory |\n|---|---|---|\n", top.Processes)
+	entry := func(usage models.ProcessUsage, value string) string {
+		name := usage.DisplayName()
+		if usage.Self {
+			name += " [inspektor]"
+		}
+		cell := markdownCell(fmt.Sprintf("%d %s (%s)", usage.PID, name, value))
+		if usage.PID == pid {
+			cell = "**" + cell + "**"
+		}
+		return cell
+	}
+	for rank := range max(len(top.ByCPU), len(top.ByMemory)) {
+		var byCPU, byMemory string
+		if rank < len(top.ByCPU) {
+			byCPU = entry(top.ByCPU[rank], fmt.Sprintf("%.1f%%", top.ByCPU[rank].CPUPercent))
+		}
+		if rank < len(top.ByMemory) {
+			byMemory = entry(top.ByMemory[rank], formatBytes(top.ByMemory[rank].MemoryRSS))
+		}
+		fmt.Fprintf(out, "| %d | %s | %s |\n", rank+1, byCPU, byMemory)
+	}
+	out.WriteString("\n")
+}
+
+// writeMarkdownThreads lists the busiest threads, busiest first
+func (f *Formatter) writeMarkdownThreads(out *strings.Builder, threads []models.ThreadUsage) {
+	out.WriteString("## Hot Threads\n\n| TID | Name | State | CPU | CPU Time |\n|---|---|---|---|---|\n")
+	for _, thread := range threads {
+		usage := "n/a"
+		if thread.CPUPercent != nil {
+			usage = f.CPUMode.Describe(*thread.CPUPercent, runtime.NumCPU())
+		}
+		fmt.Fprintf(out, "| %d | %s | %s | %s | %.1fs user, %.1fs sys |\n",
+			thread.TID, markdownCell(thread.Name), thread.State, usage, thread.CPUTimeUser, thread.CPUTimeSystem)
+	}
+	out.WriteString("\n")
+}
+
+func writeMarkdownTree(out *strings.Builder, node *models.ProcessNode, parentSID int32, depth int) {
+	session := ""
+	if label := sessionLabel(node, parentSID); label != "" {
+		session = " (" + label + ")"
+	}
+	fmt.Fprintf(out, "%s- %d %s: %.1f%% CPU, %s%s\n", strings.Repeat("  ", depth),
+		node.PID, markdownEscape(node.Name), node.CPUPercent, formatBytes(node.MemoryRSS), session)
+	for _, child := range node.Children {
+		writeMarkdownTree(out, child, node.SID, depth+1)
+	}
+}
+
+// markdownCell keeps a value inside its table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return markdownEscape(strings.ReplaceAll(s, "|", `\|`))
+}
+
+var markdownEscaper = strings.NewReplacer("*", `\*`, "_", `\_`, "`", "\\`")
+
+// markdownEscape stops process-controlled text (names, command lines) from
+// being read as emphasis or code
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
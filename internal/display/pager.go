@@ -0,0 +1,116 @@
+package display
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+)
+
+var (
+	// outputMu guards screen and releasePager, which the interrupt handler
+	// reads from its own goroutine
+	outputMu sync.Mutex
+	// screen is the terminal stdout pointed at before StartPager redirected
+	// it; nil when output isn't being paged
+	screen *os.File
+	// releasePager hands back what StartPager buffered and points stdout at
+	// the terminal again; nil when nothing is being buffered
+	releasePager func() []byte
+)
+
+// terminal is where interactive output (the spinner) goes and whose size
+// layouts follow: the real terminal, even while the report is buffered
+func terminal() *os.File {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	if screen != nil {
+		return screen
+	}
+	return os.Stdout
+}
+
+// StartPager buffers everything written to stdout until the returned
+// function is called, then shows it through $PAGER (less -R by default, so
+// colors survive). With force unset the pager is only used when the output
+// is taller than the terminal. Nothing is buffered when stdout isn't a
+// terminal; the returned function is then a no-op.
+func StartPager(force bool) func() {
+	if !stdoutIsTerminal() {
+		return func() {}
+	}
+	release, ok := bufferStdout()
+	if !ok {
+		return func() {}
+	}
+
+	return func() {
+		output := release()
+
+		_, height, err := term.GetSize(os.Stdout.Fd())
+		if !force && (err != nil || bytes.Count(output, []byte("\n")) < height) {
+			os.Stdout.Write(output)
+			return
+		}
+		if err := page(output); err != nil {
+			os.Stdout.Write(output)
+		}
+	}
+}
+
+// bufferStdout points stdout at a pipe and returns the function that points
+// it back and hands over what was written meanwhile. Either the report or an
+// interrupt takes the output back, whichever comes first; the other gets
+// nothing.
+func bufferStdout() (func() []byte, bool) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, false
+	}
+	captured := make(chan []byte, 1)
+	go func() {
+		output, _ := io.ReadAll(reader)
+		captured <- output
+	}()
+
+	var once sync.Once
+	release := func() (output []byte) {
+		once.Do(func() {
+			outputMu.Lock()
+			os.Stdout, screen = screen, nil
+			releasePager = nil
+			outputMu.Unlock()
+
+			writer.Close()
+			output = <-captured
+			reader.Close()
+		})
+		return output
+	}
+
+	outputMu.Lock()
+	screen, os.Stdout = os.Stdout, writer
+	releasePager = release
+	outputMu.Unlock()
+	return release, true
+}
+
+// page runs $PAGER, or less -R, with output on its stdin
+func page(output []byte) error {
+	command := strings.TrimSpace(os.Getenv("PAGER"))
+	if command == "" {
+		command = "less -R"
+	}
+	fields := strings.Fields(command)
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	paging.Store(true)
+	defer paging.Store(false)
+	return cmd.Run()
+}
@@ -0,0 +1,52 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Self-test check outcomes
+const (
+	CheckPass = "pass"
+	CheckFail = "fail"
+	CheckSkip = "skip"
+)
+
+// CheckResult is the outcome of one self-test check
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// FormatSelfTest renders one line per check followed by a summary
+func (f *Formatter) FormatSelfTest(results []CheckResult) string {
+	var output strings.Builder
+
+	output.WriteString(f.section(" SELF-TEST "))
+	output.WriteString("\n")
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+		var status string
+		switch result.Status {
+		case CheckPass:
+			status = statusGoodStyle.Render("PASS")
+		case CheckFail:
+			status = statusWarningStyle.Render("FAIL")
+		default:
+			status = metricStyle.Render("SKIP")
+		}
+		output.WriteString(fmt.Sprintf("  %s  %-12s %s\n", status, result.Name, valueStyle.Render(result.Detail)))
+	}
+
+	summary := fmt.Sprintf("%d passed, %d failed, %d skipped", counts[CheckPass], counts[CheckFail], counts[CheckSkip])
+	if counts[CheckFail] > 0 {
+		output.WriteString("\n" + warningItemStyle.UnsetPaddingLeft().Render(symbols.fail+" "+summary) + "\n")
+	} else {
+		output.WriteString("\n" + statusGoodStyle.Render(symbols.ok+" "+summary) + "\n")
+	}
+
+	return output.String()
+}
@@ -0,0 +1,74 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+var (
+	// cursorHidden is set while the spinner has the cursor hidden
+	cursorHidden atomic.Bool
+	// paging is set while the pager runs; it handles Ctrl-C itself
+	paging atomic.Bool
+	// cleanup runs before an interrupted inspektor exits
+	cleanup atomic.Pointer[func()]
+	// interrupted is set once an interrupt started shutting down
+	interrupted atomic.Bool
+	shutdown    sync.Once
+)
+
+// HandleInterrupts makes SIGINT and SIGTERM end inspektor cleanly instead of
+// mid-write: the cursor is shown again, a report buffered for the pager is
+// written out unpaged, onExit runs (closing the AI clients) and the process
+// exits with 130, the shell's code for an interrupt. While the pager runs
+// the signal is left to it. A second signal exits at once, in case onExit
+// hangs.
+func HandleInterrupts(onExit func()) {
+	cleanup.Store(&onExit)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for range signals {
+			if paging.Load() {
+				continue
+			}
+			if interrupted.Load() {
+				os.Exit(130)
+			}
+			go exitInterrupted()
+		}
+	}()
+}
+
+// exitInterrupted restores the terminal, flushes buffered output, runs the
+// cleanup and exits with 130. Only the first call does anything; it never
+// returns.
+func exitInterrupted() {
+	shutdown.Do(func() {
+		interrupted.Store(true)
+		restoreOutput()
+		if onExit := cleanup.Load(); onExit != nil {
+			(*onExit)()
+		}
+		os.Exit(130)
+	})
+	select {}
+}
+
+// restoreOutput shows the cursor again and writes out, unpaged, what
+// StartPager has buffered so far
+func restoreOutput() {
+	if cursorHidden.Load() {
+		fmt.Fprint(terminal(), clearLine+showCursor)
+	}
+	outputMu.Lock()
+	release := releasePager
+	outputMu.Unlock()
+	if release != nil {
+		terminal().Write(release())
+	}
+}
@@ -0,0 +1,103 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// useStdout points stdout at a file for the test and returns it
+func useStdout(t *testing.T) *os.File {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdout
+	os.Stdout = file
+	t.Cleanup(func() {
+		os.Stdout = original
+		file.Close()
+	})
+	return file
+}
+
+// written is what the test wrote to file
+func written(t *testing.T, file *os.File) string {
+	t.Helper()
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// Run with -race: an interrupt restores the output from the signal
+// goroutine while the report may be finishing and the spinner drawing
+func TestInterruptWhilePaging(t *testing.T) {
+	file := useStdout(t)
+	release, ok := bufferStdout()
+	if !ok {
+		t.Fatal("couldn't buffer stdout")
+	}
+	if terminal() != file {
+		t.Fatal("terminal() isn't the real stdout while buffering")
+	}
+	fmt.Println("report so far")
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		restoreOutput()
+	}()
+	go func() {
+		defer wg.Done()
+		// The report finishing at the same time; at most one side gets
+		// the output
+		if output := release(); output != nil {
+			terminal().Write(output)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			terminal()
+		}
+	}()
+	wg.Wait()
+
+	if got := strings.Count(written(t, file), "report so far"); got != 1 {
+		t.Errorf("buffered report written %d times, want once", got)
+	}
+	if os.Stdout != file {
+		t.Error("stdout wasn't pointed back at the terminal")
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	if screen != nil || releasePager != nil {
+		t.Error("pager state left behind after the output was restored")
+	}
+}
+
+func TestInterruptShowsCursor(t *testing.T) {
+	file := useStdout(t)
+	cursorHidden.Store(true)
+	defer cursorHidden.Store(false)
+
+	restoreOutput()
+	if got := written(t, file); got != clearLine+showCursor {
+		t.Errorf("wrote %q, want the cursor shown again", got)
+	}
+}
+
+func TestInterruptWithoutPager(t *testing.T) {
+	file := useStdout(t)
+	restoreOutput()
+	if got := written(t, file); got != "" {
+		t.Errorf("wrote %q with nothing buffered and the cursor visible", got)
+	}
+}
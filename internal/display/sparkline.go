@@ -0,0 +1,48 @@
+package display
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// DisableColor strips all ANSI styling from rendered output and switches
+// glyph-based widgets like sparklines and change marks to plain ASCII
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	// Terminals without color support frequently lack block glyphs too
+	symbols.sparkRamp = asciiSymbols.sparkRamp
+	symbols.up, symbols.down = asciiSymbols.up, asciiSymbols.down
+}
+
+// Sparkline renders values as a compact bar graph scaled between the minimum
+// and maximum of the series. Flat series render at the lowest level.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	var b strings.Builder
+	span := hi - lo
+	top := len(symbols.sparkRamp) - 1
+	for _, v := range values {
+		level := 0
+		if span > 0 {
+			level = int((v - lo) / span * float64(top))
+		}
+		b.WriteRune(symbols.sparkRamp[level])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,30 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	lowest := string(symbols.sparkRamp[0])
+	highest := string(symbols.sparkRamp[len(symbols.sparkRamp)-1])
+
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"single value", []float64{42}, lowest},
+		{"flat line", []float64{3, 3, 3, 3}, strings.Repeat(lowest, 4)},
+		{"flat zeros", []float64{0, 0, 0}, strings.Repeat(lowest, 3)},
+		{"rising", []float64{1, 2}, lowest + highest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Sparkline(test.values); got != test.want {
+				t.Errorf("Sparkline(%v) = %q, want %q", test.values, got, test.want)
+			}
+		})
+	}
+}
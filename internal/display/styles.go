@@ -68,6 +68,19 @@ var (
 		Foreground(warningColor).
 		PaddingLeft(2)
 	
+	// Recommendation section
+	recommendHeaderStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#3B82F6")).
+		Background(lipgloss.Color("#1E3A8A")).
+		Padding(0, 2).
+		MarginTop(1).
+		MarginBottom(1)
+
+	recommendItemStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#60A5FA")).
+		PaddingLeft(2)
+	
 	// Success message
 	successMessageStyle = lipgloss.NewStyle().
 		Foreground(successColor).
@@ -80,8 +93,16 @@ var (
 		PaddingLeft(2).
 		MarginBottom(1)
 	
+	// Section headers in borderless mode
+	plainHeadingStyle = lipgloss.NewStyle().
+		Bold(true).
+		MarginTop(1)
+
 	separatorStyle = lipgloss.NewStyle().
 		Foreground(mutedColor).
 		MarginTop(1).
 		MarginBottom(1)
+
+	footerStyle = lipgloss.NewStyle().
+		Foreground(mutedColor)
 )
\ No newline at end of file
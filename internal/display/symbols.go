@@ -0,0 +1,109 @@
+package display
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"inspektor/internal/models"
+)
+
+// symbolSet is every non-ASCII glyph the output draws with, so terminals
+// and logs that can't take unicode get ASCII stand-ins from one swap
+type symbolSet struct {
+	warning, recommend, ok, fail string
+	// arrow points from one thing to another: a finding's advice, a local
+	// address to its peer, a steady trend
+	arrow    string
+	up, down string
+	// received and sent label network throughput
+	received, sent string
+	// rule is repeated into separators
+	rule                     string
+	branch, lastBranch, pipe string
+	sparkRamp                []rune
+	// plain limits the spinner to the ASCII line frames
+	plain bool
+}
+
+var (
+	unicodeSymbols = symbolSet{
+		warning:    "⚠",
+		recommend:  "→",
+		ok:         "✓",
+		fail:       "✗",
+		arrow:      "→",
+		up:         "↑",
+		down:       "↓",
+		received:   "↓",
+		sent:       "↑",
+		rule:       "─",
+		branch:     "├─ ",
+		lastBranch: "└─ ",
+		pipe:       "│  ",
+		sparkRamp:  []rune("▁▂▃▄▅▆▇█"),
+	}
+	asciiSymbols = symbolSet{
+		warning:    "[!]",
+		recommend:  "->",
+		ok:         "[OK]",
+		fail:       "[FAIL]",
+		arrow:      "->",
+		up:         "^",
+		down:       "v",
+		received:   "in",
+		sent:       "out",
+		rule:       "-",
+		branch:     "|- ",
+		lastBranch: "`- ",
+		pipe:       "|  ",
+		sparkRamp:  []rune("_.-~=+*#"),
+		plain:      true,
+	}
+
+	// symbols is what all output draws with
+	symbols = unicodeSymbols
+)
+
+// UseASCII switches every glyph of the output, the spinner included, to
+// plain ASCII
+func UseASCII() {
+	symbols = asciiSymbols
+}
+
+// LocaleSupportsUnicode reports whether the locale is a UTF-8 one, going by
+// LC_ALL, LC_CTYPE and LANG in the order the C library does. With none set
+// it can't tell and says yes, as Windows terminals and many containers set
+// none yet render unicode fine.
+func LocaleSupportsUnicode() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return true
+}
+
+// FormatSuccess renders a one-line success notice, such as the process a
+// selector resolved to
+func FormatSuccess(message string) string {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#22C55E")).
+		Bold(true).
+		Render(symbols.ok + " " + message)
+}
+
+// trendArrow renders a trend as up, down or steady, or nothing when unknown
+func trendArrow(trend models.Trend) string {
+	switch trend {
+	case models.TrendUp:
+		return symbols.up
+	case models.TrendDown:
+		return symbols.down
+	case models.TrendSteady:
+		return symbols.arrow
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"inspektor/internal/models"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+)
+
+// TableRow is one inspected process in the table view
+type TableRow struct {
+	Data     *models.InspectionData
+	Findings []models.Finding
+}
+
+// SortColumns are the single columns accepted by --sort-by; it also takes a
+// score expression (see models.ParseScoreExpr)
+var SortColumns = []string{"cpu", "rss", "threads", "conn", "health", "pid", "name", "pressure"}
+
+// defaultTableWidth is assumed when stdout isn't a terminal
+const defaultTableWidth = 120
+
+const maxNameWidth = 20
+
+var tableHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(primaryColor)
+
+// SortTableRows orders rows by the given column or score expression.
+// Resource columns and scores sort heaviest first, health worst first, and
+// pid/name ascending.
+func SortTableRows(rows []TableRow, by string) {
+	less := map[string]func(a, b *models.InspectionData) bool{
+		"cpu":     func(a, b *models.InspectionData) bool { return a.Process.CPUPercent > b.Process.CPUPercent },
+		"rss":     func(a, b *models.InspectionData) bool { return a.Process.MemoryRSS > b.Process.MemoryRSS },
+		"threads": func(a, b *models.InspectionData) bool { return a.Process.NumThreads > b.Process.NumThreads },
+		"conn":    func(a, b *models.InspectionData) bool { return a.Process.Connections > b.Process.Connections },
+		"health":  func(a, b *models.InspectionData) bool { return a.HealthScore < b.HealthScore },
+		"pid":     func(a, b *models.InspectionData) bool { return a.Process.PID < b.Process.PID },
+		"name":    func(a, b *models.InspectionData) bool { return a.Process.Name < b.Process.Name },
+	}[by]
+	if less == nil {
+		sortTableRowsByScore(rows, by)
+		return
+	}
+	sort.SliceStable(rows, func(a, b int) bool { return less(rows[a].Data, rows[b].Data) })
+}
+
+// sortTableRowsByScore orders rows by a score expression, highest first
+func sortTableRowsByScore(rows []TableRow, spec string) {
+	expr, err := models.ParseScoreExpr(spec)
+	if err != nil {
+		return
+	}
+	procs := make([]*models.ProcessInfo, len(rows))
+	for idx, row := range rows {
+		procs[idx] = row.Data.Process
+	}
+	scores := expr.Scores(procs)
+
+	order := make([]int, len(rows))
+	for idx := range order {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	sorted := make([]TableRow, len(rows))
+	for idx, from := range order {
+		sorted[idx] = rows[from]
+	}
+	copy(rows, sorted)
+}
+
+// FormatTable renders one row per process, like ps with the analysis added:
+// the health score and how many warnings each process produced. The command
+// column takes whatever terminal width is left and is truncated to fit.
+func (f *Formatter) FormatTable(rows []TableRow) string {
+	header := []string{"PID", "NAME", "CPU%", "RSS", "THREADS", "CONN", "STATUS", "HEALTH", "WARN"}
+	cells := make([][]string, len(rows))
+	for idx, row := range rows {
+		proc := row.Data.Process
+		health := "-"
+		if !row.Data.TimedOut {
+			health = fmt.Sprintf("%d", row.Data.HealthScore)
+		}
+		cells[idx] = []string{
+			fmt.Sprintf("%d", proc.PID),
+			truncate(proc.DisplayName(), maxNameWidth),
+			fmt.Sprintf("%.1f", f.scaleCPU(proc.CPUPercent)),
+			formatBytes(proc.MemoryRSS),
+			fmt.Sprintf("%d", proc.NumThreads),
+			fmt.Sprintf("%d", proc.Connections),
+			proc.Status,
+			health,
+			fmt.Sprintf("%d", countWarnings(row.Findings)),
+		}
+	}
+
+	widths := make([]int, len(header))
+	for col, title := range header {
+		widths[col] = len(title)
+		for _, row := range cells {
+			widths[col] = max(widths[col], lipgloss.Width(row[col]))
+		}
+	}
+
+	// Whatever is left of the line goes to the command
+	used := 0
+	for _, width := range widths {
+		used += width + 2
+	}
+	commandWidth := terminalWidth() - used
+
+	var out strings.Builder
+	line := padColumns(header, widths)
+	if commandWidth > 0 {
+		line += "COMMAND"
+	}
+	out.WriteString(tableHeaderStyle.Render(strings.TrimRight(line, " ")) + "\n")
+
+	for idx, row := range cells {
+		line := padColumns(row, widths)
+		if commandWidth > 0 {
+			line += truncate(rows[idx].Data.Process.CommandLine, commandWidth)
+		}
+		out.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	return out.String()
+}
+
+// textColumns are the NAME and STATUS columns, which align left
+var textColumns = map[int]bool{1: true, 6: true}
+
+// padColumns left-aligns text columns and right-aligns the numeric ones
+func padColumns(values []string, widths []int) string {
+	var line strings.Builder
+	for col, value := range values {
+		pad := strings.Repeat(" ", widths[col]-lipgloss.Width(value))
+		if textColumns[col] {
+			line.WriteString(value + pad)
+		} else {
+			line.WriteString(pad + value)
+		}
+		line.WriteString("  ")
+	}
+	return line.String()
+}
+
+func countWarnings(findings []models.Finding) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Kind != models.KindRecommendation {
+			count++
+		}
+	}
+	return count
+}
+
+// truncate shortens s to at most width characters, marking the cut
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// terminalWidth is the width of stdout, or defaultTableWidth when it isn't a
+// terminal (e.g. piped to a file)
+func terminalWidth() int {
+	if width, _, err := term.GetSize(terminal().Fd()); err == nil && width > 0 {
+		return width
+	}
+	return defaultTableWidth
+}
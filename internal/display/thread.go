@@ -0,0 +1,57 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// FormatThread renders a single thread inspected with --tid
+func (f *Formatter) FormatThread(thread *models.ThreadInfo) string {
+	var output strings.Builder
+
+	output.WriteString(titleStyle.Render(fmt.Sprintf("INSPEKTOR - Thread %d (%s) of Process %d (%s)",
+		thread.TID, thread.Name, thread.PID, thread.ProcessName)) + "\n")
+	output.WriteString(f.separator())
+
+	output.WriteString(f.section(" THREAD "))
+	output.WriteString("\n")
+
+	usage := metricStyle.Render("n/a")
+	if thread.CPUPercent != nil {
+		usage = f.formatProcessCPU(*thread.CPUPercent)
+	}
+	started := ""
+	if !thread.CreateTime.IsZero() {
+		started = valueStyle.Render(fmt.Sprintf("%s (up %s)", f.formatTime(thread.CreateTime),
+			formatAge(max(time.Since(thread.CreateTime), 0))))
+	}
+	processor := ""
+	if thread.Processor != nil {
+		processor = valueStyle.Render(fmt.Sprintf("CPU %d", *thread.Processor))
+	}
+
+	items := []struct {
+		key   string
+		value string
+	}{
+		{"Status", f.formatStatus(thread.State)},
+		{"CPU Usage", usage},
+		{"CPU Time", f.formatCPUTime(thread.CPUTimeUser, thread.CPUTimeSystem)},
+		{"Context Switches", formatContextSwitches(thread.ContextSwitches)},
+		{"Priority", valueStyle.Render(fmt.Sprintf("%d (nice %d)", thread.Priority, thread.Nice))},
+		{"Last Ran On", processor},
+		{"Started", started},
+	}
+	for _, item := range items {
+		if item.value == "" {
+			continue
+		}
+		output.WriteString(contentStyle.Render(keyStyle.Render(item.key+":") + " " + item.value))
+		output.WriteString("\n")
+	}
+
+	return f.fit(output.String())
+}
@@ -0,0 +1,104 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// FormatTop renders the host overview followed by one compact ranking per
+// resource and the summary
+func (f *Formatter) FormatTop(host *models.HostInfo, sys *models.SystemInfo, top *models.TopConsumers, summary string) string {
+	var output strings.Builder
+
+	output.WriteString(titleStyle.Render(fmt.Sprintf("INSPEKTOR - Top processes (%s)", host.Hostname)) + "\n")
+	output.WriteString(f.separator())
+	output.WriteString(f.formatHost(host))
+	output.WriteString(f.formatSystemContext(sys))
+	output.WriteString("\n")
+
+	rankings := []struct {
+		title  string
+		column string
+		usage  []models.ProcessUsage
+		value  func(models.ProcessUsage) string
+	}{
+		{" TOP CPU ", "CPU%", top.ByCPU, func(u models.ProcessUsage) string { return fmt.Sprintf("%.1f", f.scaleCPU(u.CPUPercent)) }},
+		{" TOP MEMORY ", "RSS", top.ByMemory, func(u models.ProcessUsage) string { return formatBytes(u.MemoryRSS) }},
+		{" TOP OPEN FILES ", "FILES", top.ByOpenFiles, func(u models.ProcessUsage) string { return fmt.Sprint(u.OpenFiles) }},
+		{" TOP CONNECTIONS ", "CONN", top.ByConnections, func(u models.ProcessUsage) string { return fmt.Sprint(u.Connections) }},
+	}
+	if top.Score != "" {
+		rankings = append(rankings, struct {
+			title  string
+			column string
+			usage  []models.ProcessUsage
+			value  func(models.ProcessUsage) string
+		}{" TOP " + strings.ToUpper(top.Score) + " ", "SCORE", top.ByScore, func(u models.ProcessUsage) string { return fmt.Sprintf("%.2f", u.Score) }})
+	}
+	for _, ranking := range rankings {
+		output.WriteString(f.section(ranking.title))
+		output.WriteString("\n")
+		if len(ranking.usage) == 0 {
+			output.WriteString(contentStyle.Render(valueStyle.Render("None")))
+			output.WriteString("\n\n")
+			continue
+		}
+		table := [][]string{{"PID", "NAME", ranking.column}}
+		for _, usage := range ranking.usage {
+			table = append(table, []string{fmt.Sprint(usage.PID), usageName(usage), ranking.value(usage)})
+		}
+		output.WriteString(renderColumns(table))
+	}
+
+	output.WriteString(f.section(" SUMMARY "))
+	output.WriteString("\n")
+	output.WriteString(contentStyle.Render(valueStyle.Render(summary)))
+	output.WriteString("\n\n")
+
+	return f.fit(output.String())
+}
+
+// formatHostTop lists the host's heaviest processes by CPU and memory below a
+// single-process report, marking the inspected one, so it shows whether that
+// process is what loads the host or is competing with something heavier
+func (f *Formatter) formatHostTop(pid int32, top *models.TopConsumers) string {
+	var output strings.Builder
+
+	output.WriteString(f.section(fmt.Sprintf(" TOP ON HOST (%d PROCESSES) ", top.Processes)))
+	output.WriteString("\n")
+
+	rankings := []struct {
+		column string
+		usage  []models.ProcessUsage
+		value  func(models.ProcessUsage) string
+	}{
+		{"CPU%", top.ByCPU, func(u models.ProcessUsage) string { return fmt.Sprintf("%.1f", f.scaleCPU(u.CPUPercent)) }},
+		{"RSS", top.ByMemory, func(u models.ProcessUsage) string { return formatBytes(u.MemoryRSS) }},
+	}
+	for _, ranking := range rankings {
+		table := [][]string{{"PID", "NAME", ranking.column}}
+		for _, usage := range ranking.usage {
+			name := usageName(usage)
+			if usage.PID == pid {
+				name += " " + statusWarningStyle.Render("(this process)")
+			}
+			table = append(table, []string{fmt.Sprint(usage.PID), name, ranking.value(usage)})
+		}
+		output.WriteString(renderColumns(table))
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// usageName is a ranked process's name, marking inspektor's own process
+// (ranked with --include-self) so its sampling isn't mistaken for load
+func usageName(usage models.ProcessUsage) string {
+	name := truncate(usage.DisplayName(), maxNameWidth)
+	if usage.Self {
+		name += " " + metricStyle.Render("(inspektor)")
+	}
+	return name
+}
@@ -0,0 +1,57 @@
+package display
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ByteUnits selects how byte counts are rendered
+type ByteUnits string
+
+const (
+	// UnitsBinary uses powers of 1024: KiB, MiB, GiB
+	UnitsBinary ByteUnits = "binary"
+	// UnitsSI uses powers of 1000: kB, MB, GB, matching tools such as df -H
+	UnitsSI ByteUnits = "si"
+	// UnitsRaw prints exact byte counts, for parsing the text output
+	UnitsRaw ByteUnits = "raw"
+)
+
+// AllByteUnits are the values accepted by --units
+var AllByteUnits = []ByteUnits{UnitsBinary, UnitsSI, UnitsRaw}
+
+// byteUnits applies to all rendered output, like the color profile
+var byteUnits = UnitsBinary
+
+// SetByteUnits switches how every formatter renders byte counts
+func SetByteUnits(units ByteUnits) error {
+	if !slices.Contains(AllByteUnits, units) {
+		return fmt.Errorf("invalid units %q (expected binary, si or raw)", units)
+	}
+	byteUnits = units
+	return nil
+}
+
+func formatBytes(bytes uint64) string {
+	switch byteUnits {
+	case UnitsRaw:
+		return fmt.Sprintf("%d B", bytes)
+	case UnitsSI:
+		return scaleBytes(bytes, 1000, "kMGTPE", "B")
+	}
+	return scaleBytes(bytes, 1024, "KMGTPE", "iB")
+}
+
+// scaleBytes renders bytes in the largest unit of the given base, with one
+// decimal
+func scaleBytes(bytes, unit uint64, prefixes, suffix string) string {
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := unit, 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %c%s", float64(bytes)/float64(div), prefixes[exp], suffix)
+}
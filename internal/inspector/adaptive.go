@@ -0,0 +1,58 @@
+package inspector
+
+import (
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// DefaultMaxInterval is the longest --interval-adaptive stretches the watch
+// interval to when no --interval-max is given
+const DefaultMaxInterval = 30 * time.Second
+
+// adaptiveInterval lengthens the watch interval while the process holds
+// steady and drops back to the shortest as soon as it moves, for
+// --interval-adaptive. The rates a tick reports are measured from the
+// previous tick's retained readings, so they stay averages over however long
+// the interval has grown.
+type adaptiveInterval struct {
+	min, max   time.Duration
+	current    time.Duration
+	thresholds models.DiffThresholds
+}
+
+// newAdaptiveInterval starts at shortest, never stretching past longest
+func newAdaptiveInterval(shortest, longest time.Duration, thresholds models.DiffThresholds) *adaptiveInterval {
+	if thresholds == nil {
+		thresholds = models.DefaultDiffThresholds
+	}
+	return &adaptiveInterval{min: shortest, max: max(shortest, longest), current: shortest, thresholds: thresholds}
+}
+
+// next returns the interval to wait after a tick that read current, doubling
+// it when nothing moved since previous and resetting it otherwise. A first
+// tick, a new instance or a timed-out collection has nothing steady to go
+// by and resets it too.
+func (a *adaptiveInterval) next(previous, current *models.ProcessInfo, timedOut bool) time.Duration {
+	if timedOut || previous == nil || !steady(previous, current, a.thresholds) {
+		a.current = a.min
+	} else {
+		a.current = min(2*a.current, a.max)
+	}
+	return a.current
+}
+
+// steady reports whether the process kept its state and no metric moved by
+// its diff threshold. Cumulative CPU time is left out: it grows on every
+// tick of a busy process, which CPU usage already covers.
+func steady(previous, current *models.ProcessInfo, thresholds models.DiffThresholds) bool {
+	if previous.Status != current.Status {
+		return false
+	}
+	for _, delta := range models.DiffProcesses(previous, current) {
+		if delta.Metric != "cpu_time" && delta.Significant(thresholds) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package inspector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// auditEntry is one line of the --audit-log: who inspected what, when, and
+// what was found
+type auditEntry struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user"`
+	// SudoUser is who ran inspektor through sudo, when it was
+	SudoUser string               `json:"sudo_user,omitempty"`
+	Target   auditTarget          `json:"target"`
+	Findings models.FindingCounts `json:"findings"`
+}
+
+// auditTarget is the inspected process, and the port it was found by
+type auditTarget struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+	Port int    `json:"port,omitempty"`
+}
+
+// audit appends a line for the inspection to the audit log. It is best
+// effort: a log that can't be written never fails the inspection, and is
+// only reported with --verbose.
+func (i *Inspector) audit(data *models.InspectionData, findings []models.Finding, opts Options) {
+	if opts.AuditLog == "" {
+		return
+	}
+
+	entry := auditEntry{
+		Time:     time.Now().UTC(),
+		User:     invokingUser(),
+		SudoUser: os.Getenv("SUDO_USER"),
+		Target:   auditTarget{PID: data.Process.PID, Name: data.Process.Name},
+		Findings: models.CountFindings(findings),
+	}
+	if opts.watchPort != nil {
+		entry.Target.Port = opts.watchPort.Port
+	}
+	if err := i.appendAudit(opts.AuditLog, entry); err != nil && opts.Verbose {
+		fmt.Fprintf(os.Stderr, "Warning: audit log not written: %v\n", err)
+	}
+}
+
+// appendAudit writes entry as one JSON line. Appends are serialized so
+// concurrent batch workers never interleave lines.
+func (i *Inspector) appendAudit(path string, entry auditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	i.auditMu.Lock()
+	defer i.auditMu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// invokingUser names the user running inspektor, falling back to the
+// numeric ID when it has no name
+func invokingUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return strconv.Itoa(os.Getuid())
+}
@@ -0,0 +1,342 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"inspektor/internal/display"
+	"inspektor/internal/models"
+)
+
+// batchEntry is one element of the JSON array emitted in batch mode. Entries
+// that couldn't be parsed or resolved carry only the target and an error.
+type batchEntry struct {
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Target    string     `json:"target"`
+	*models.InspectionData
+	Findings []models.Finding `json:"findings,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// InspectBatch inspects every target in order. A target is a bare PID or a
+// pid=, name=, port= or user= selector; blank targets are skipped and a target that
+// fails to resolve is reported on its own without aborting the batch.
+func (i *Inspector) InspectBatch(targets []string, opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	if opts.OutputDir != "" {
+		dir, err := newOutputDir(opts.OutputDir)
+		if err != nil {
+			return err
+		}
+		opts.dir = dir
+	}
+
+	if opts.decorated() {
+		display.ShowBanner("")
+	}
+
+	// JSON Lines streams each entry as soon as it's ready and --output-dir
+	// saves it to its own file; the other modes collect entries (or table
+	// rows) for a single document at the end
+	var entries []batchEntry
+	var rows []display.TableRow
+	emit := func(entry batchEntry) error {
+		if opts.dir != nil {
+			if entry.InspectionData == nil {
+				return nil
+			}
+			return opts.dir.write(entry.InspectionData, entry.Findings)
+		}
+		if !opts.JSONLines {
+			entries = append(entries, entry)
+			return nil
+		}
+		now := opts.now()
+		entry.Timestamp = &now
+		return writeJSON(entry, opts)
+	}
+
+	// report emits an analyzed inspection and renders it in text mode
+	report := func(entry batchEntry) error {
+		if opts.Quiet && len(entry.Findings) == 0 {
+			return nil
+		}
+		if err := emit(entry); err != nil {
+			return err
+		}
+		if opts.Table || opts.CSV {
+			rows = append(rows, display.TableRow{Data: entry.InspectionData, Findings: entry.Findings})
+		} else if !opts.JSON && opts.dir == nil {
+			i.render(entry.InspectionData, entry.Findings, opts)
+		}
+		return nil
+	}
+
+	// With --batch-analysis every entry, failed ones included so the order
+	// holds, waits in pending until the whole batch has been collected
+	var pending []batchEntry
+	fail := func(entry batchEntry) error {
+		if opts.BatchAnalysis {
+			pending = append(pending, entry)
+			return nil
+		}
+		return emit(entry)
+	}
+
+	// Every target is resolved up front so the processes can be inspected
+	// several at a time while still being reported in order
+	var jobs []batchJob
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		pids, err := i.resolveTarget(target, opts)
+		if err != nil {
+			jobs = append(jobs, batchJob{target: target, err: err})
+			continue
+		}
+		for _, pid := range pids {
+			jobs = append(jobs, batchJob{target: target, pid: pid})
+		}
+	}
+
+	results := i.inspectJobs(jobs, opts)
+	for index, job := range jobs {
+		if job.err != nil {
+			if err := fail(batchEntry{Target: job.target, Error: job.err.Error()}); err != nil {
+				return err
+			}
+			if !opts.JSON || opts.dir != nil {
+				fmt.Fprintf(os.Stderr, "Skipping %q: %v\n", job.target, job.err)
+			}
+			continue
+		}
+
+		result := <-results[index]
+		if result.err != nil {
+			if err := fail(batchEntry{Target: job.target, Error: result.err.Error()}); err != nil {
+				return err
+			}
+			if !opts.JSON || opts.dir != nil {
+				fmt.Fprintf(os.Stderr, "Skipping PID %d (%s): %v\n", job.pid, job.target, result.err)
+			}
+			continue
+		}
+
+		if opts.group != nil {
+			opts.group.add(result.data.Process)
+		}
+
+		entry := batchEntry{Target: job.target, InspectionData: result.data, Findings: result.findings}
+		if opts.BatchAnalysis {
+			pending = append(pending, entry)
+			continue
+		}
+		if err := report(entry); err != nil {
+			return err
+		}
+	}
+
+	if len(pending) > 0 {
+		var batch []*models.InspectionData
+		for _, entry := range pending {
+			if entry.InspectionData != nil {
+				batch = append(batch, entry.InspectionData)
+			}
+		}
+		var done chan bool
+		if opts.decorated() {
+			done = make(chan bool)
+			go display.ShowProcessingAnimation(fmt.Sprintf("Analyzing %d processes...", len(batch)), done)
+		}
+		findings := i.analyzeBatch(batch, opts)
+		if done != nil {
+			done <- true
+			close(done)
+			time.Sleep(100 * time.Millisecond) // Give time to clear the animation
+		}
+		for _, entry := range pending {
+			if entry.InspectionData == nil {
+				if err := emit(entry); err != nil {
+					return err
+				}
+				continue
+			}
+			entry.Findings = findings[entry.Process.PID]
+			if err := report(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Table && len(rows) > 0 {
+		display.SortTableRows(rows, opts.SortBy)
+		fmt.Print(i.formatter.FormatTable(rows))
+	}
+	if opts.CSV && len(rows) > 0 {
+		datas := make([]*models.InspectionData, len(rows))
+		for idx, row := range rows {
+			datas[idx] = row.Data
+		}
+		if _, err := os.Stdout.Write(display.FormatCSV(datas)); err != nil {
+			return err
+		}
+	}
+
+	if report := opts.group; report != nil {
+		if err := i.outputGroup(report, entries, opts); err != nil {
+			return err
+		}
+	}
+	if opts.dir != nil {
+		fmt.Print(opts.dir.summary())
+		return nil
+	}
+	if opts.group != nil {
+		return nil
+	}
+
+	if opts.JSON && !opts.JSONLines {
+		// Quiet mode emits nothing when there is nothing to report
+		if opts.Quiet && len(entries) == 0 {
+			return nil
+		}
+		// Always emit an array, even when every target failed
+		if entries == nil {
+			entries = []batchEntry{}
+		}
+		return writeJSON(entries, opts)
+	}
+
+	return nil
+}
+
+// batchJob is one process of a batch to inspect, or a target that failed to
+// resolve to any
+type batchJob struct {
+	target string
+	pid    int32
+	err    error
+}
+
+// batchResult is the outcome of inspecting one batchJob
+type batchResult struct {
+	data     *models.InspectionData
+	findings []models.Finding
+	err      error
+}
+
+// DefaultConcurrency is how many processes a batch inspects at once: enough
+// to overlap the CPU sampling windows and AI round trips without loading
+// the host or tripping provider rate limits
+const DefaultConcurrency = 4
+
+// inspectJobs inspects the jobs with up to opts.Concurrency running at once.
+// Each job's result arrives on its own channel, buffered so no worker waits
+// on the caller, which can then report them in order as they complete. Jobs
+// that failed to resolve get an empty result without being run.
+func (i *Inspector) inspectJobs(jobs []batchJob, opts Options) []chan batchResult {
+	results := make([]chan batchResult, len(jobs))
+	for index := range results {
+		results[index] = make(chan batchResult, 1)
+	}
+
+	queue := make(chan int)
+	for range min(max(opts.Concurrency, 1), max(len(jobs), 1)) {
+		go func() {
+			for index := range queue {
+				results[index] <- i.inspectJob(jobs[index], opts)
+			}
+		}()
+	}
+	go func() {
+		for index := range jobs {
+			queue <- index
+		}
+		close(queue)
+	}()
+	return results
+}
+
+// inspectJob collects one process and, unless the batch is analyzed as a
+// whole, analyzes it too; the AI circuit breaker is shared by all workers
+// through the one analyzer
+func (i *Inspector) inspectJob(job batchJob, opts Options) batchResult {
+	if job.err != nil {
+		return batchResult{}
+	}
+
+	ctx, cancel := opts.inspectionContext(context.Background())
+	defer cancel()
+
+	started := time.Now()
+	data, err := i.sourceFor(opts).Collect(ctx, job.pid)
+	if err != nil {
+		return batchResult{err: err}
+	}
+	var findings []models.Finding
+	if !opts.BatchAnalysis {
+		findings = i.analyze(ctx, data, opts)
+	}
+	data.DurationMS = time.Since(started).Milliseconds()
+	return batchResult{data: data, findings: findings}
+}
+
+// InspectByName inspects every process whose name matches
+func (i *Inspector) InspectByName(name string, opts Options) error {
+	return i.InspectBatch([]string{"name=" + name}, opts)
+}
+
+// resolveTarget turns a batch target into the PIDs it refers to. Port targets
+// may carry their own protocol ("port=53/udp"), overriding opts.Proto.
+func (i *Inspector) resolveTarget(target string, opts Options) ([]int32, error) {
+	kind, value, found := strings.Cut(target, "=")
+	if !found {
+		kind, value = "pid", target
+	}
+	value = strings.TrimSpace(value)
+
+	switch strings.TrimSpace(kind) {
+	case "pid":
+		pid, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || pid <= 0 {
+			return nil, fmt.Errorf("invalid PID: %s", value)
+		}
+		return []int32{int32(pid)}, nil
+	case "port":
+		query := PortQuery{Proto: opts.Proto, Address: opts.BindAddress}
+		portValue, proto, hasProto := strings.Cut(value, "/")
+		if hasProto {
+			query.Proto = proto
+		}
+		port, err := strconv.Atoi(portValue)
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid port: %s", value)
+		}
+		query.Port = port
+		// A batch takes every process listening on the port
+		listeners, err := i.findListeners(query)
+		if err != nil {
+			return nil, err
+		}
+		return listenerPIDs(listeners), nil
+	case "name":
+		if value == "" {
+			return nil, fmt.Errorf("empty process name")
+		}
+		return i.findProcessesByName(value, opts)
+	case "user":
+		if value == "" {
+			return nil, fmt.Errorf("empty user name")
+		}
+		return i.findProcessesByUser(value, opts)
+	default:
+		return nil, fmt.Errorf("unknown selector %q (expected pid=, name=, port= or user=)", kind)
+	}
+}
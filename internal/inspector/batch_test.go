@@ -0,0 +1,87 @@
+package inspector
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"inspektor/internal/analyzer"
+	"inspektor/internal/models"
+)
+
+// Run with -race: the workers share the Inspector, its analyzer and the
+// --fail-on record
+func TestInspectJobsOrder(t *testing.T) {
+	const count = 12
+	source := &fakeSource{processes: map[int32]models.ProcessInfo{}, delay: map[int32]time.Duration{}}
+	var jobs []batchJob
+	for n := range count {
+		pid := int32(1000 + n)
+		// Earlier jobs take longer, so they complete last
+		source.processes[pid] = models.ProcessInfo{Name: fmt.Sprintf("worker-%d", n), CPUPercent: 99}
+		source.delay[pid] = time.Duration(count-n) * 2 * time.Millisecond
+		jobs = append(jobs, batchJob{target: fmt.Sprint(pid), pid: pid})
+	}
+	// A target that didn't resolve is passed over in place
+	jobs = append(jobs, batchJob{target: "name=missing", err: errors.New("no process named missing")})
+
+	insp := newFakeInspector(source)
+	defer insp.Close()
+
+	results := insp.inspectJobs(jobs, Options{Concurrency: 4, FailOn: []string{analyzer.RuleHighCPU}})
+	if len(results) != len(jobs) {
+		t.Fatalf("got %d results for %d jobs", len(results), len(jobs))
+	}
+	for index, job := range jobs {
+		result := <-results[index]
+		if job.err != nil {
+			if result.data != nil || result.err != nil {
+				t.Errorf("job %d (%s) was run: %+v", index, job.target, result)
+			}
+			continue
+		}
+		if result.err != nil {
+			t.Fatalf("job %d: %v", index, result.err)
+		}
+		if result.data.Process.PID != job.pid {
+			t.Errorf("result %d is PID %d, want %d", index, result.data.Process.PID, job.pid)
+		}
+		if !hasRule(result.findings, analyzer.RuleHighCPU) {
+			t.Errorf("job %d wasn't analyzed: %v", index, result.findings)
+		}
+	}
+	if err := insp.FailOnError(); err == nil {
+		t.Error("--fail-on matches from the workers weren't recorded")
+	}
+}
+
+// Run with -race: concurrent inspections share one analyzer, as a server
+// handling several requests would
+func TestConcurrentEvaluate(t *testing.T) {
+	source := &fakeSource{processes: map[int32]models.ProcessInfo{
+		1: {Name: "idle"},
+		2: {Name: "busy", CPUPercent: 99},
+	}}
+	insp := newFakeInspector(source)
+	defer insp.Close()
+
+	var wg sync.WaitGroup
+	for n := range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pid := int32(n%2 + 1)
+			result, err := insp.Evaluate(pid, Options{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got := hasRule(result.Findings, analyzer.RuleHighCPU); got != (pid == 2) {
+				t.Errorf("PID %d: %s raised = %v", pid, analyzer.RuleHighCPU, got)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,101 @@
+package inspector
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// cgroupRoot is where the cgroup filesystem is mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// InspectByCgroup inspects every process in a control group and the groups
+// below it, e.g. all containers of a Kubernetes pod, then reports their
+// combined usage. The path is relative to /sys/fs/cgroup, as listed in
+// /proc/<pid>/cgroup, or an absolute path under it.
+func (i *Inspector) InspectByCgroup(path string, opts Options) error {
+	dir, err := cgroupDir(path)
+	if err != nil {
+		return err
+	}
+
+	pids, err := cgroupProcesses(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list processes of cgroup %s: %w", path, err)
+	}
+
+	// Run inside the group, as in a pod's debug container, inspektor would
+	// count itself towards the totals
+	if !opts.IncludeSelf {
+		self := int32(os.Getpid())
+		pids = slices.DeleteFunc(pids, func(pid int32) bool { return pid == self })
+	}
+
+	targets := make([]string, len(pids))
+	for idx, pid := range pids {
+		targets[idx] = fmt.Sprintf("pid=%d", pid)
+	}
+	opts.group = &groupReport{Cgroup: path, Totals: &models.TreeTotals{}}
+	return i.InspectBatch(targets, opts)
+}
+
+// cgroupDir finds the directory for a cgroup path, trying the cgroup v2
+// unified hierarchy first, then the hybrid layout's unified and systemd
+// hierarchies. A path may also name a v1 controller, e.g. memory/docker.
+func cgroupDir(path string) (string, error) {
+	candidates := []string{path}
+	if !strings.HasPrefix(path, cgroupRoot+"/") {
+		candidates = []string{
+			filepath.Join(cgroupRoot, path),
+			filepath.Join(cgroupRoot, "unified", path),
+			filepath.Join(cgroupRoot, "systemd", path),
+		}
+	}
+
+	for _, dir := range candidates {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("cgroup %s not found under %s", path, cgroupRoot)
+}
+
+// cgroupProcesses lists the PIDs in dir and every cgroup below it. Under
+// cgroup v2 only leaf groups hold processes, so a pod's own cgroup.procs is
+// empty and its containers' processes are found in the child groups.
+func cgroupProcesses(dir string) ([]int32, error) {
+	var pids []int32
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip subgroups we can't read rather than failing the walk
+			if path != dir && entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.Name() != "cgroup.procs" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		pids = append(pids, parsePIDList(string(content))...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(pids)
+	pids = slices.Compact(pids)
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("control group has no processes")
+	}
+	return pids, nil
+}
@@ -0,0 +1,70 @@
+package inspector
+
+import (
+	"slices"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// childTracker remembers a watched process's descendants from tick to tick,
+// for --follow-children, so workers a supervisor spawns or loses show up as
+// they come and go
+type childTracker struct {
+	since   time.Time
+	known   map[int32]string
+	spawned int
+	exited  int
+}
+
+// observe compares the descendants in tree with the previous tick's. The
+// first tick only takes the baseline. A truncated walk may have missed
+// descendants that are still alive, so none are reported as exited then.
+func (t *childTracker) observe(tree *models.ProcessTree) *models.ChildChanges {
+	current := make(map[int32]string)
+	var walk func(nodes []*models.ProcessNode)
+	walk = func(nodes []*models.ProcessNode) {
+		for _, node := range nodes {
+			current[node.PID] = node.Name
+			walk(node.Children)
+		}
+	}
+	walk(tree.Root.Children)
+
+	if t.known == nil {
+		t.since, t.known = time.Now(), current
+		return &models.ChildChanges{Since: t.since}
+	}
+
+	changes := &models.ChildChanges{Since: t.since}
+	for pid, name := range current {
+		if _, ok := t.known[pid]; !ok {
+			changes.Spawned = append(changes.Spawned, models.ChildProcess{PID: pid, Name: name})
+		}
+	}
+	if !tree.Truncated {
+		for pid, name := range t.known {
+			if _, ok := current[pid]; !ok {
+				changes.Exited = append(changes.Exited, models.ChildProcess{PID: pid, Name: name})
+			}
+		}
+		t.known = current
+	} else {
+		for pid, name := range current {
+			t.known[pid] = name
+		}
+	}
+	byPID := func(a, b models.ChildProcess) int { return int(a.PID - b.PID) }
+	slices.SortFunc(changes.Spawned, byPID)
+	slices.SortFunc(changes.Exited, byPID)
+
+	t.spawned += len(changes.Spawned)
+	t.exited += len(changes.Exited)
+	changes.TotalSpawned, changes.TotalExited = t.spawned, t.exited
+	return changes
+}
+
+// reset forgets the previous instance's children after a restart
+func (t *childTracker) reset() {
+	*t = childTracker{}
+}
@@ -0,0 +1,913 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	stdnet "net"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"inspektor/internal/models"
+	"inspektor/internal/redact"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/load"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+)
+
+// Collect gathers process and system data for a single PID without
+// analyzing or rendering anything
+func (c *Collector) Collect(ctx context.Context, pid int32) (*models.InspectionData, error) {
+	// Get process information
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get process: %w", err)
+	}
+
+	return c.collectFrom(ctx, proc)
+}
+
+// collectFrom gathers data for an already-opened process handle. Watch mode
+// reuses the same handle across ticks so CPU deltas can be computed.
+//
+// In strict mode partial data is an error: any failed collector or an
+// expired deadline fails the inspection rather than being reported.
+func (c *Collector) collectFrom(ctx context.Context, proc *process.Process) (*models.InspectionData, error) {
+	data, err := c.gather(ctx, proc)
+	if err != nil || !c.opts.Strict {
+		return data, err
+	}
+	if data.TimedOut {
+		return nil, fmt.Errorf("strict mode: collection timed out")
+	}
+	if failures := failed(append(data.CollectionErrors, data.Process.CollectionErrors...)); len(failures) > 0 {
+		details := make([]string, len(failures))
+		for idx, failure := range failures {
+			details[idx] = failure.Collector + ": " + failure.Error
+		}
+		return nil, fmt.Errorf("strict mode: %d collector(s) failed: %s", len(failures), strings.Join(details, "; "))
+	}
+	return data, nil
+}
+
+// gather runs the collection in stages, each bounded by ctx. When the
+// deadline expires the stages completed so far are returned with TimedOut
+// set, so callers can still show partial results.
+func (c *Collector) gather(ctx context.Context, proc *process.Process) (*models.InspectionData, error) {
+	data := &models.InspectionData{Host: c.host.get(ctx)}
+
+	// Collect process data
+	processInfo, err := withDeadline(ctx, func(ctx context.Context) (*models.ProcessInfo, error) {
+		return c.collectProcessInfo(ctx, proc)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect process info: %w", err)
+	}
+	data.Process = processInfo
+
+	// Descriptors are the slowest part on busy processes; kernel threads
+	// hold none
+	if !processInfo.KernelThread {
+		descriptors, err := withDeadline(ctx, func(ctx context.Context) (descriptorInfo, error) {
+			return c.collectDescriptors(ctx, proc), nil
+		})
+		if timedOut(err, data) {
+			return data, nil
+		}
+		descriptors.apply(data.Process)
+		data.Process.PortServices = portServices(data.Process.ListenPorts, c.opts.Services)
+	}
+
+	// Collect system data
+	systemInfo, err := withDeadline(ctx, func(ctx context.Context) (*models.SystemInfo, error) {
+		return c.CollectSystem(ctx)
+	})
+	if timedOut(err, data) {
+		return data, nil
+	}
+	// Host-wide calls can be blocked where per-process ones work (sandboxes,
+	// restricted containers); the process report stands without them
+	if err != nil {
+		data.CollectionErrors = append(data.CollectionErrors, models.CollectionError{Collector: "system", Error: err.Error()})
+	}
+	data.System = systemInfo
+	if data.Process.OOM != nil {
+		data.Process.OOM.Risk = models.OOMRisk(data.Process, systemInfo)
+	}
+
+	// Walk the descendant tree when it is shown or aggregated. On its own,
+	// --include-children covers every descendant rather than --tree-depth.
+	if c.opts.Tree || c.opts.IncludeChildren {
+		depth := c.opts.TreeDepth
+		if !c.opts.Tree {
+			depth = unlimitedTreeDepth
+		}
+		tree, err := withDeadline(ctx, func(ctx context.Context) (*models.ProcessTree, error) {
+			return c.collectTree(ctx, proc, depth, c.opts.IncludeChildren), nil
+		})
+		if timedOut(err, data) {
+			return data, nil
+		}
+		if c.opts.Tree {
+			data.Tree = tree
+		}
+		if c.opts.IncludeChildren {
+			data.TreeTotals = treeTotals(data.Process, tree)
+		}
+		if c.opts.children != nil {
+			data.ChildChanges = c.opts.children.observe(tree)
+		}
+	}
+
+	// The host's heaviest processes tell whether this one is the problem or
+	// a victim of another; like the system data, the report stands without
+	if c.opts.WithTop {
+		top, err := withDeadline(ctx, func(ctx context.Context) (*models.TopConsumers, error) {
+			return collectTop(ctx, withTopSize, c.opts)
+		})
+		if timedOut(err, data) {
+			return data, nil
+		}
+		if err != nil {
+			data.CollectionErrors = append(data.CollectionErrors, models.CollectionError{Collector: "top", Error: err.Error()})
+		}
+		data.Top = top
+	}
+
+	return data, nil
+}
+
+// withDeadline runs fn in the background and stops waiting once ctx is done,
+// so a wedged syscall can't hang the inspection. An abandoned stage finishes
+// (or stays blocked) on its own and its result is discarded.
+func withDeadline[T any](ctx context.Context, fn func(context.Context) (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn(ctx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// timedOut marks data as partial when err is a deadline expiry
+func timedOut(err error, data *models.InspectionData) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		data.TimedOut = true
+		return true
+	}
+	return false
+}
+
+func (c *Collector) collectProcessInfo(ctx context.Context, proc *process.Process) (*models.ProcessInfo, error) {
+	if isKernelThread(proc.Pid) {
+		return c.collectKernelThread(ctx, proc)
+	}
+
+	var failures collectionErrors
+	name, err := proc.NameWithContext(ctx)
+	failures.add("name", err)
+	exe, exeErr := proc.ExeWithContext(ctx)
+	failures.add("executable", exeErr)
+	// Secrets passed on the command line are masked before anything, the
+	// display, JSON or the AI prompt, can see them
+	cmdline, err := proc.CmdlineWithContext(ctx)
+	failures.add("command_line", err)
+	cmdArgs, _ := proc.CmdlineSliceWithContext(ctx)
+	cmdline, cmdArgs = c.opts.redactor().String(cmdline), c.opts.redactor().Args(cmdArgs)
+	cwd, cwdErr := proc.CwdWithContext(ctx)
+	failures.add("working_dir", cwdErr)
+	status, err := proc.StatusWithContext(ctx)
+	failures.add("status", err)
+	username, err := proc.UsernameWithContext(ctx)
+	failures.add("username", err)
+
+	// Controlling terminal; empty when the process is detached (daemon)
+	terminal, _ := proc.TerminalWithContext(ctx)
+	terminal = strings.TrimPrefix(terminal, "/dev/")
+	pgid, sid, ok := readSession(proc.Pid)
+	failures.unavailable("session", ok)
+
+	// CPU and Memory usage. Network, disk and context switch counters are
+	// read on both sides of the CPU sampling window so the rates cost no
+	// extra wait.
+	netBefore, netSupported := readNetSample(proc.Pid)
+	diskBefore, diskErr := readDiskSample(ctx, proc)
+	failures.add("disk_io", diskErr)
+	switchesBefore, switchesSupported := readSwitchSample(proc.Pid)
+	failures.unavailable("context_switches", switchesSupported)
+	var threadsBefore threadSample
+	threadsSupported := false
+	if c.opts.Threads > 0 {
+		threadsBefore, threadsSupported = readThreadSample(proc.Pid)
+		failures.unavailable("thread_cpu", threadsSupported)
+	}
+	var powerBefore powerSample
+	powerSupported := false
+	if c.opts.Power {
+		powerBefore, powerSupported = readPowerSample(ctx, proc)
+		failures.unavailable("power", powerSupported)
+	}
+	cpuPercent, err := sampleCPU(ctx, proc, c.opts)
+	failures.add("cpu_percent", err)
+	var power *models.PowerEstimate
+	if powerSupported {
+		if after, ok := readPowerSample(ctx, proc); ok {
+			power = estimatePower(baseline(&c.samples, sampledPower, proc.Pid, powerBefore, after), after)
+		}
+	}
+	var netRx, netTx *float64
+	if after, ok := readNetSample(proc.Pid); netSupported && ok {
+		if rx, tx, ok := counterRate(baseline(&c.samples, sampledNetwork, proc.Pid, netBefore, after), after); ok {
+			netRx, netTx = &rx, &tx
+		}
+	}
+	var diskRead, diskWrite *float64
+	if diskErr == nil {
+		if after, err := readDiskSample(ctx, proc); err == nil {
+			if read, write, ok := counterRate(baseline(&c.samples, sampledDisk, proc.Pid, diskBefore, after), after); ok {
+				diskRead, diskWrite = &read, &write
+			}
+		}
+	}
+	var switches *models.ContextSwitches
+	if after, ok := readSwitchSample(proc.Pid); switchesSupported && ok {
+		switches = &models.ContextSwitches{Voluntary: after.in, Involuntary: after.out}
+		if voluntary, involuntary, ok := counterRate(baseline(&c.samples, sampledSwitches, proc.Pid, switchesBefore, after), after); ok {
+			switches.VoluntaryRate, switches.InvoluntaryRate = &voluntary, &involuntary
+		}
+	}
+	var hotThreads []models.ThreadUsage
+	if threadsSupported {
+		if after, ok := readThreadSample(proc.Pid); ok {
+			hotThreads = hottestThreads(baseline(&c.samples, sampledThreads, proc.Pid, threadsBefore, after), after, c.opts.Threads)
+		}
+	}
+	cpuTimes, err := proc.TimesWithContext(ctx)
+	failures.add("cpu_times", err)
+	if err != nil {
+		cpuTimes = &cpu.TimesStat{}
+	}
+	memInfo, err := proc.MemoryInfoWithContext(ctx)
+	failures.add("memory", err)
+	if err != nil {
+		memInfo = &process.MemoryInfoStat{}
+	}
+	memPercent, err := proc.MemoryPercentWithContext(ctx)
+	failures.add("memory_percent", err)
+	var cpuTrend, memoryTrend models.Trend
+	if c.opts.Trend {
+		cpuTrend, memoryTrend = sampleTrend(ctx, proc, cpuPercent, memInfo.RSS)
+	}
+	numThreads, err := proc.NumThreadsWithContext(ctx)
+	failures.add("threads", err)
+
+	// Peak RSS and the memory breakdown are left at zero (omitted) where the
+	// platform doesn't track them
+	peakRSS, ok := readPeakRSS(proc.Pid)
+	failures.unavailable("memory_peak_rss", ok)
+	breakdown, ok := readMemoryBreakdown(proc.Pid)
+	failures.unavailable("memory_breakdown", ok)
+	shmem, ok := readShmem(proc.Pid)
+	failures.unavailable("memory_shmem", ok)
+	oom, ok := readOOMScore(proc.Pid)
+	failures.unavailable("oom_score", ok)
+	scheduler, ok := readScheduler(proc.Pid)
+	failures.unavailable("scheduler", ok)
+	wait, ok := readWaitState(proc.Pid)
+	failures.unavailable("wait_channel", ok)
+	cgroupMemory, ok := readCgroupMemory(proc.Pid)
+	failures.unavailable("cgroup_memory", ok)
+
+	// Process times; converted up front so both text and JSON honor --utc
+	createTime, err := proc.CreateTimeWithContext(ctx)
+	failures.add("create_time", err)
+	startedAt := startTime(createTime, c.host.bootTime(ctx))
+	if c.opts.UTC && !startedAt.IsZero() {
+		startedAt = startedAt.UTC()
+	}
+
+	// An executable replaced since the process started means an upgrade
+	// that hasn't taken effect
+	var exeState models.ExecutableState
+	if !startedAt.IsZero() {
+		exe, exeState = executableState(proc.Pid, exe, startedAt)
+	}
+
+	// Nothing to fingerprint when neither the executable nor the arguments
+	// are readable; the running image is read whole, so only on request
+	var hash, binary string
+	if c.opts.HashBinary && exe != "" {
+		binary, err = binaryHash(proc.Pid, exe)
+		failures.add("binary_hash", err)
+	}
+	if exe != "" || len(cmdArgs) > 0 {
+		hash = commandHash(exe, cmdArgs, binary)
+	}
+
+	info := &models.ProcessInfo{
+		PID:             proc.Pid,
+		Name:            name,
+		Executable:      exe,
+		ExecutableState: exeState,
+		CommandHash:     hash,
+		BinarySHA256:    binary,
+		CommandLine:     cmdline,
+		CommandLineArgs: cmdArgs,
+		WorkingDir:      cwd,
+		Status:          status,
+		Terminal:        terminal,
+		PGID:            pgid,
+		SID:             sid,
+		Username:        username,
+		CPUPercent:      cpuPercent,
+		CPUTimeUser:     cpuTimes.User,
+		CPUTimeSystem:   cpuTimes.System,
+		MemoryRSS:       memInfo.RSS,
+		MemoryVMS:       memInfo.VMS,
+		MemoryPeakRSS:   peakRSS,
+		MemoryShared:    breakdown.shared,
+		MemoryPrivate:   breakdown.private,
+		MemorySwap:      breakdown.swap,
+		MemoryShmem:     shmem,
+		MemoryPercent:   memPercent,
+		CPUTrend:        cpuTrend,
+		MemoryTrend:     memoryTrend,
+		Scheduler:       scheduler,
+		Wait:            wait,
+		CgroupMemory:    cgroupMemory,
+		OOM:             oom,
+		CreateTime:      startedAt,
+		NumThreads:      numThreads,
+		ContextSwitches: switches,
+		HotThreads:      hotThreads,
+		PowerEstimate:   power,
+		NetRxRate:       netRx,
+		NetTxRate:       netTx,
+		DiskReadRate:    diskRead,
+		DiskWriteRate:   diskWrite,
+	}
+
+	if permissionDenied(exeErr) {
+		info.Restricted = append(info.Restricted, "executable")
+	}
+	// Unreadable for other users' processes without privileges; the Go
+	// runtime is still recognized then, only without its GOMAXPROCS
+	environ, err := proc.EnvironWithContext(ctx)
+	if permissionDenied(err) {
+		info.Restricted = append(info.Restricted, "environment")
+	}
+	info.Go = readGoRuntime(proc, exe, environ)
+	info.RuntimeEnv = runtimeEnv(environ, c.opts.RuntimeEnv, c.opts.redactor())
+	if permissionDenied(cwdErr) {
+		info.Restricted = append(info.Restricted, "working_dir")
+	}
+	root, err := readRoot(proc.Pid)
+	if permissionDenied(err) {
+		info.Restricted = append(info.Restricted, "root")
+	} else {
+		failures.add("root", err)
+	}
+	info.Root = root
+
+	capabilities, seccomp, ok := readSecurity(proc.Pid)
+	failures.unavailable("capabilities", ok)
+	if ok {
+		info.Capabilities = capabilities
+		info.Seccomp = seccomp
+	}
+
+	namespaces, ok := readNamespaces(proc.Pid)
+	failures.unavailable("namespaces", ok)
+	if ok {
+		info.Namespaces = namespaces
+	}
+
+	// Container membership is best effort; the Docker lookup is opt-in since
+	// it needs the daemon socket
+	if id, ok := readContainerID(proc.Pid); ok {
+		info.ContainerID = id
+		if c.opts.Docker {
+			if name, image, err := lookupContainer(ctx, id); err == nil {
+				info.ContainerName = name
+				info.ContainerImage = image
+			}
+		}
+	}
+
+	info.CollectionErrors = failures
+	return info, nil
+}
+
+// collectionErrors records the collectors that failed for one process
+type collectionErrors []models.CollectionError
+
+// add records err against collector. Nil errors are ignored, as are the
+// ones gopsutil returns for collectors it doesn't implement on this platform:
+// neither is a failure to report.
+func (c *collectionErrors) add(collector string, err error) {
+	if err != nil && err.Error() != "not implemented yet" {
+		*c = append(*c, models.CollectionError{Collector: collector, Error: err.Error()})
+	}
+}
+
+// unavailableError is what unavailable records: there was nothing to read,
+// rather than reading it failed
+const unavailableError = "unavailable"
+
+// unavailable records a platform reader that came back empty-handed, unless
+// the platform has no such reader to begin with
+func (c *collectionErrors) unavailable(collector string, ok bool) {
+	if !ok && !unsupportedReaders[collector] {
+		c.add(collector, errors.New(unavailableError))
+	}
+}
+
+// failed leaves out the unavailable notes: the readers that came back
+// empty-handed, as /proc/<pid>/ns does in many containers, aren't failures
+// --strict should stop on
+func failed(all []models.CollectionError) []models.CollectionError {
+	var failures []models.CollectionError
+	for _, failure := range all {
+		if failure.Error != unavailableError {
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// startTime turns gopsutil's process creation time, in milliseconds, into
+// wall-clock time. It is normally counted from the epoch, but some kernels
+// and platforms count it from boot instead; a value that would put the start
+// before the host booted is taken as such and offset by the boot time. An
+// unknown creation time (0) gives the zero time rather than 1970.
+func startTime(createTime int64, bootTime uint64) time.Time {
+	switch {
+	case createTime <= 0:
+		return time.Time{}
+	case bootTime > 0 && createTime/1000 < int64(bootTime):
+		return afterBoot(bootTime, time.Duration(createTime)*time.Millisecond)
+	}
+	return time.UnixMilli(createTime)
+}
+
+// afterBoot is the wall-clock time since after a boot at bootTime, in Unix
+// seconds; the zero time when either is unknown
+func afterBoot(bootTime uint64, since time.Duration) time.Time {
+	if bootTime == 0 || since <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(bootTime), 0).Add(since)
+}
+
+// ticksDuration converts a count of clock ticks, as /proc/<pid>/stat counts
+// a start time, into a duration at hz ticks per second (USER_HZ)
+func ticksDuration(ticks uint64, hz float64) time.Duration {
+	if hz <= 0 {
+		return 0
+	}
+	return time.Duration(float64(ticks) / hz * float64(time.Second))
+}
+
+// sampleCPU measures the process's CPU usage. A single reading of the CPU
+// counters can only give the average since the process started, so by default
+// two readings are taken CPUInterval apart, like top does. Watch mode already
+// holds the previous reading on the handle and skips the extra wait.
+func sampleCPU(ctx context.Context, proc *process.Process, opts Options) (float64, error) {
+	switch {
+	case opts.cpuDelta:
+		return proc.PercentWithContext(ctx, 0)
+	case opts.CPUInterval > 0:
+		return proc.PercentWithContext(ctx, opts.CPUInterval)
+	default:
+		return proc.CPUPercentWithContext(ctx)
+	}
+}
+
+// descriptorInfo holds the results of the descriptor collection stage
+type descriptorInfo struct {
+	connections  int
+	connStates   map[string]int
+	listenPorts  []uint32
+	openFiles    int
+	handles      uint32
+	fileTypes    *models.OpenFileTypes
+	maxOpenFiles int
+	limits       []models.Limit
+	skipped      bool
+	children     int
+	deletedFiles []models.DeletedFile
+	details      *models.ProcessDetails
+	restricted   []string
+	failures     collectionErrors
+}
+
+func (d descriptorInfo) apply(info *models.ProcessInfo) {
+	info.Connections = d.connections
+	info.ConnectionStates = d.connStates
+	info.ListenPorts = d.listenPorts
+	info.OpenFiles = d.openFiles
+	info.DescriptorsSkipped = d.skipped
+	info.Handles = d.handles
+	info.OpenFileTypes = d.fileTypes
+	info.MaxOpenFiles = d.maxOpenFiles
+	info.Limits = d.limits
+	info.Children = d.children
+	info.DeletedFiles = d.deletedFiles
+	info.Details = d.details
+	info.Restricted = append(info.Restricted, d.restricted...)
+	info.CollectionErrors = append(info.CollectionErrors, d.failures...)
+}
+
+// collectDescriptors counts the process's descriptors, sockets and children.
+// For verbose JSON output it also keeps the individual descriptors and
+// sockets and reads the environment, which no other output reports.
+func (c *Collector) collectDescriptors(ctx context.Context, proc *process.Process) descriptorInfo {
+	// Connections and open files. Windows has no descriptors to list, and
+	// gopsutil can only find a process's files there by walking every
+	// handle on the system, so its handle count is read instead. A process
+	// holding more than DetailLimit descriptors only has them counted.
+	var failures collectionErrors
+	var connections []net.ConnectionStat
+	var connErr error
+	var openFiles []process.OpenFilesStat
+	var filesErr error
+	var handles uint32
+	descriptors, skipped := c.tooManyDescriptors(ctx, proc)
+	switch {
+	case skipped:
+		// Only the count is kept
+	case runtime.GOOS == "windows":
+		connections, connErr = proc.ConnectionsWithContext(ctx)
+		failures.add("connections", connErr)
+		var ok bool
+		handles, ok = readHandles(proc.Pid)
+		failures.unavailable("handles", ok)
+	default:
+		connections, connErr = proc.ConnectionsWithContext(ctx)
+		failures.add("connections", connErr)
+		openFiles, filesErr = proc.OpenFilesWithContext(ctx)
+		failures.add("open_files", filesErr)
+		descriptors = len(openFiles)
+	}
+	var restricted []string
+	if permissionDenied(filesErr) {
+		restricted = append(restricted, "open_files")
+	}
+	if permissionDenied(connErr) {
+		restricted = append(restricted, "connections")
+	}
+
+	// Child processes; a missing-children result is not a failure
+	children, err := proc.ChildrenWithContext(ctx)
+	if !errors.Is(err, process.ErrorNoChildren) {
+		failures.add("children", err)
+	}
+
+	// File descriptor limit (soft NOFILE); 0 when unknown or unlimited
+	maxOpenFiles := 0
+	rlimits, err := proc.RlimitWithContext(ctx)
+	failures.add("max_open_files", err)
+	for _, limit := range rlimits {
+		if limit.Resource == process.RLIMIT_NOFILE && limit.Soft > 0 && limit.Soft < math.MaxInt32 {
+			maxOpenFiles = int(limit.Soft)
+		}
+	}
+
+	// The wider set of limits, with the usage procfs doesn't report itself
+	limits, ok := readLimits(proc.Pid)
+	failures.unavailable("limits", ok)
+	for idx := range limits {
+		switch limits[idx].Resource {
+		case "nofile":
+			if filesErr == nil {
+				used := uint64(descriptors)
+				limits[idx].Used = &used
+			}
+		case "cpu":
+			if times, err := proc.TimesWithContext(ctx); err == nil {
+				used := uint64(times.User + times.System)
+				limits[idx].Used = &used
+			}
+		}
+	}
+
+	info := descriptorInfo{
+		connections:  len(connections),
+		connStates:   countConnectionStates(connections),
+		listenPorts:  listenPorts(connections),
+		openFiles:    descriptors,
+		skipped:      skipped,
+		handles:      handles,
+		fileTypes:    classifyOpenFiles(openFiles),
+		maxOpenFiles: maxOpenFiles,
+		limits:       limits,
+		children:     len(children),
+		deletedFiles: findDeletedFiles(proc.Pid, openFiles),
+		restricted:   restricted,
+		failures:     failures,
+	}
+	if c.opts.details || (c.opts.Verbose && (c.opts.JSON || c.opts.Resolve)) {
+		info.details = collectDetails(ctx, proc, openFiles, connections, c.opts.redactor())
+		if c.opts.Resolve {
+			c.names.annotate(ctx, info.details.Connections)
+		}
+	}
+	return info
+}
+
+// tooManyDescriptors counts the process's descriptors, which is cheap, to
+// tell whether listing them and its sockets would cost more than the
+// inspection is worth; the count is then all that is reported
+func (c *Collector) tooManyDescriptors(ctx context.Context, proc *process.Process) (int, bool) {
+	if c.opts.ForceDetail || c.opts.DetailLimit <= 0 || runtime.GOOS == "windows" {
+		return 0, false
+	}
+	count, err := proc.NumFDsWithContext(ctx)
+	if err != nil || int(count) <= c.opts.DetailLimit {
+		return 0, false
+	}
+	return int(count), true
+}
+
+// collectDetails lists the descriptors and sockets already read and adds the
+// redacted environment
+func collectDetails(ctx context.Context, proc *process.Process, openFiles []process.OpenFilesStat, connections []net.ConnectionStat, redactor *redact.Redactor) *models.ProcessDetails {
+	details := &models.ProcessDetails{}
+	for _, file := range openFiles {
+		details.OpenFiles = append(details.OpenFiles, models.OpenFile{FD: file.Fd, Path: file.Path})
+	}
+	for _, conn := range connections {
+		proto := socketProto(conn)
+		details.Connections = append(details.Connections, models.Connection{
+			FD:     conn.Fd,
+			Proto:  proto,
+			Local:  socketAddress(conn.Laddr, proto),
+			Remote: socketAddress(conn.Raddr, proto),
+			State:  conn.Status,
+		})
+	}
+	if environ, err := proc.EnvironWithContext(ctx); err == nil {
+		details.Environment = redactor.Environment(environ)
+	}
+	return details
+}
+
+// socketAddress renders an endpoint as host:port, or the path of a unix
+// socket; empty for an unset endpoint, such as a listener's peer
+func socketAddress(addr net.Addr, proto string) string {
+	switch {
+	case proto == "unix":
+		return addr.IP
+	case addr.Port == 0 && (addr.IP == "" || addr.IP == "0.0.0.0" || addr.IP == "::"):
+		return ""
+	}
+	return stdnet.JoinHostPort(addr.IP, strconv.Itoa(int(addr.Port)))
+}
+
+// socketProto names a socket's protocol the way ss does: tcp, udp, tcp6, ...
+func socketProto(conn net.ConnectionStat) string {
+	if conn.Family == syscall.AF_UNIX {
+		return "unix"
+	}
+	proto := "udp"
+	if conn.Type == syscall.SOCK_STREAM {
+		proto = "tcp"
+	}
+	if conn.Family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	return proto
+}
+
+// permissionDenied recognizes EACCES/EPERM, including from gopsutil calls
+// that flatten the underlying error into their message
+func permissionDenied(err error) bool {
+	return err != nil && (errors.Is(err, fs.ErrPermission) || strings.Contains(err.Error(), "permission denied"))
+}
+
+// countConnectionStates tallies connections by socket state
+func countConnectionStates(connections []net.ConnectionStat) map[string]int {
+	if len(connections) == 0 {
+		return nil
+	}
+	states := make(map[string]int)
+	for _, conn := range connections {
+		state := conn.Status
+		if state == "" {
+			state = "NONE"
+		}
+		states[state]++
+	}
+	return states
+}
+
+// listenPorts picks out the local ports of listening TCP sockets and of UDP
+// sockets with no peer
+func listenPorts(connections []net.ConnectionStat) []uint32 {
+	var ports []uint32
+	for _, conn := range connections {
+		listening := conn.Status == "LISTEN" ||
+			(conn.Type == syscall.SOCK_DGRAM && conn.Raddr.Port == 0)
+		if listening && conn.Laddr.Port != 0 {
+			ports = append(ports, conn.Laddr.Port)
+		}
+	}
+	slices.Sort(ports)
+	return slices.Compact(ports)
+}
+
+// classifyOpenFiles sorts descriptors by their link target: paths are files,
+// while sockets, pipes and anonymous inodes (eventfd, epoll, ...) show up as
+// "socket:[inode]", "pipe:[inode]" and "anon_inode:..."
+func classifyOpenFiles(openFiles []process.OpenFilesStat) *models.OpenFileTypes {
+	if len(openFiles) == 0 {
+		return nil
+	}
+	types := &models.OpenFileTypes{}
+	for _, file := range openFiles {
+		switch {
+		case strings.HasPrefix(file.Path, "socket:"):
+			types.Sockets++
+		case strings.HasPrefix(file.Path, "pipe:"):
+			types.Pipes++
+		case strings.HasPrefix(file.Path, "anon_inode:"):
+			types.AnonInodes++
+		case strings.HasPrefix(file.Path, "/"):
+			types.Files++
+		default:
+			types.Other++
+		}
+	}
+	return types
+}
+
+// findDeletedFiles picks out open files that have been unlinked, which the
+// kernel marks with a " (deleted)" suffix on the descriptor's link target.
+// Sizes come from the descriptor itself since the path no longer exists.
+func findDeletedFiles(pid int32, openFiles []process.OpenFilesStat) []models.DeletedFile {
+	var deleted []models.DeletedFile
+	for _, file := range openFiles {
+		path, found := strings.CutSuffix(file.Path, " (deleted)")
+		if !found {
+			continue
+		}
+		size, _ := descriptorSize(pid, file.Fd)
+		deleted = append(deleted, models.DeletedFile{FD: file.Fd, Path: path, Size: size})
+	}
+	return deleted
+}
+
+// unknownCPUModel stands in for a CPU model the platform doesn't report
+const unknownCPUModel = "unknown"
+
+// describeCPU names the CPU model and counts the cores in info, which some
+// containers and VMs leave empty and many ARM boards fill without a model
+// name. The model is then unknownCPUModel and the core count numCPU, the Go
+// runtime's.
+func describeCPU(info []cpu.InfoStat, numCPU int) (model string, cores int) {
+	if len(info) == 0 {
+		return unknownCPUModel, numCPU
+	}
+	model = unknownCPUModel
+	for _, entry := range info {
+		if entry.ModelName != "" {
+			model = entry.ModelName
+			break
+		}
+	}
+	return model, len(info)
+}
+
+// CollectSystem reads the host's resources, with no process. CPU and
+// memory are read WithSystemSamples times, each CPU reading spanning a
+// second, and reported as their average with the spread alongside, since a
+// single second is noisy.
+func (c *Collector) CollectSystem(ctx context.Context) (*models.SystemInfo, error) {
+	cpuInfo, err := cpu.InfoWithContext(ctx)
+	if err != nil {
+		cpuInfo = nil
+	}
+	cpuModel, cpuCores := describeCPU(cpuInfo, runtime.NumCPU())
+
+	samples := max(c.opts.SystemSamples, 1)
+	cpuReadings := make([]float64, 0, samples)
+	memReadings := make([]float64, 0, samples)
+	var memUsed uint64
+	var memInfo *mem.VirtualMemoryStat
+	var selfUsage float64
+	for range samples {
+		selfBefore, windowStart := selfCPUTime(), time.Now()
+		cpuPercent, err := cpu.PercentWithContext(ctx, time.Second, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(cpuPercent) == 0 {
+			return nil, errors.New("no CPU usage reported")
+		}
+		// What inspektor itself burned meanwhile, e.g. other batch workers,
+		// is not the host's load
+		if !c.opts.IncludeSelf {
+			own := (selfCPUTime() - selfBefore) / time.Since(windowStart).Seconds() / float64(cpuCores) * 100
+			own = min(max(own, 0), cpuPercent[0])
+			cpuPercent[0] -= own
+			selfUsage += own
+		}
+
+		// Memory information
+		memInfo, err = mem.VirtualMemoryWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		cpuReadings = append(cpuReadings, cpuPercent[0])
+		memReadings = append(memReadings, memInfo.UsedPercent)
+		memUsed += memInfo.Used
+	}
+	cpuStats, memStats := models.Summarize(cpuReadings), models.Summarize(memReadings)
+
+	// Swap is optional; hosts without swap simply report zeros
+	swapInfo, err := mem.SwapMemoryWithContext(ctx)
+	if err != nil {
+		swapInfo = &mem.SwapMemoryStat{}
+	}
+
+	// Load average isn't available everywhere (e.g. Windows) and the root
+	// filesystem may not be readable; both fall back to zeros
+	loadAvg, err := load.AvgWithContext(ctx)
+	if err != nil {
+		loadAvg = &load.AvgStat{}
+	}
+	diskUsage, err := disk.UsageWithContext(ctx, rootPath())
+	if err != nil {
+		diskUsage = &disk.UsageStat{}
+	}
+
+	sys := &models.SystemInfo{
+		CPUCores:      cpuCores,
+		CPUModel:      cpuModel,
+		CPUUsage:      cpuStats.Avg,
+		MemoryTotal:   memInfo.Total,
+		MemoryUsed:    memUsed / uint64(samples),
+		MemoryPercent: memStats.Avg,
+		MemoryFree:    memInfo.Free,
+		SwapTotal:     swapInfo.Total,
+		SwapUsed:      swapInfo.Used,
+		SwapPercent:   swapInfo.UsedPercent,
+		Load1:         loadAvg.Load1,
+		Load5:         loadAvg.Load5,
+		Load15:        loadAvg.Load15,
+		DiskTotal:     diskUsage.Total,
+		DiskUsed:      diskUsage.Used,
+		DiskPercent:   diskUsage.UsedPercent,
+	}
+	if samples > 1 {
+		sys.Samples = &models.SystemSamples{Count: samples, CPU: cpuStats, Memory: memStats}
+	}
+	sys.SelfCPUUsage = selfUsage / float64(samples)
+	return sys, nil
+}
+
+// selfCPUTime is the CPU time, user and system, inspektor has used so far in
+// seconds; zero if it can't be read, so nothing is subtracted
+func selfCPUTime() float64 {
+	self, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0
+	}
+	times, err := self.Times()
+	if err != nil {
+		return 0
+	}
+	return times.User + times.System
+}
+
+// rootPath is the filesystem whose usage is reported for the host
+func rootPath() string {
+	if runtime.GOOS == "windows" {
+		return `C:\`
+	}
+	return "/"
+}
@@ -0,0 +1,105 @@
+package inspector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+func TestStrictIgnoresUnavailable(t *testing.T) {
+	var failures collectionErrors
+	failures.unavailable("test_reader", false)
+	failures.add("cwd", errors.New("permission denied"))
+	failures.add("children", nil)
+
+	got := failed(failures)
+	want := []models.CollectionError{{Collector: "cwd", Error: "permission denied"}}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("failed(%v) = %v, want %v", failures, got, want)
+	}
+	if len(failures) != 2 {
+		t.Errorf("unavailable notes aren't reported anymore: %v", failures)
+	}
+}
+
+func TestStartTime(t *testing.T) {
+	// Booted 2024-01-01 00:00:00 UTC
+	const boot = 1704067200
+	bootedAt := time.Unix(boot, 0)
+
+	tests := []struct {
+		name       string
+		createTime int64
+		bootTime   uint64
+		want       time.Time
+	}{
+		{"since the epoch", (boot + 3600) * 1000, boot, bootedAt.Add(time.Hour)},
+		{"since boot", 90_500, boot, bootedAt.Add(90500 * time.Millisecond)},
+		{"boot time unknown", (boot + 60) * 1000, 0, bootedAt.Add(time.Minute)},
+		{"unknown", 0, boot, time.Time{}},
+		{"negative", -1, boot, time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := startTime(test.createTime, test.bootTime); !got.Equal(test.want) {
+				t.Errorf("startTime(%d, %d) = %s, want %s", test.createTime, test.bootTime, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTicksAfterBoot(t *testing.T) {
+	const boot = 1704067200
+	tests := []struct {
+		ticks uint64
+		hz    float64
+		want  time.Duration
+	}{
+		{ticks: 250, hz: 100, want: 2500 * time.Millisecond},
+		{ticks: 250, hz: 250, want: time.Second},
+		{ticks: 360000, hz: 100, want: time.Hour},
+		{ticks: 100, hz: 0, want: 0},
+	}
+	for _, test := range tests {
+		if got := ticksDuration(test.ticks, test.hz); got != test.want {
+			t.Errorf("ticksDuration(%d, %g) = %s, want %s", test.ticks, test.hz, got, test.want)
+		}
+	}
+
+	if got, want := afterBoot(boot, time.Hour), time.Unix(boot+3600, 0); !got.Equal(want) {
+		t.Errorf("afterBoot = %s, want %s", got, want)
+	}
+	if got := afterBoot(0, time.Hour); !got.IsZero() {
+		t.Errorf("afterBoot with an unknown boot time = %s, want zero", got)
+	}
+	if got := afterBoot(boot, 0); !got.IsZero() {
+		t.Errorf("afterBoot with an unknown start = %s, want zero", got)
+	}
+}
+
+func TestDescribeCPU(t *testing.T) {
+	tests := []struct {
+		name      string
+		info      []cpu.InfoStat
+		wantModel string
+		wantCores int
+	}{
+		{"empty", nil, unknownCPUModel, 8},
+		{"empty slice", []cpu.InfoStat{}, unknownCPUModel, 8},
+		{"no model names", []cpu.InfoStat{{}, {}}, unknownCPUModel, 2},
+		{"model on a later entry", []cpu.InfoStat{{}, {ModelName: "Cortex-A72"}}, "Cortex-A72", 2},
+		{"full", []cpu.InfoStat{{ModelName: "Xeon"}, {ModelName: "Xeon"}, {ModelName: "Xeon"}, {ModelName: "Xeon"}}, "Xeon", 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model, cores := describeCPU(test.info, 8)
+			if model != test.wantModel || cores != test.wantCores {
+				t.Errorf("describeCPU = %q, %d; want %q, %d", model, cores, test.wantModel, test.wantCores)
+			}
+		})
+	}
+}
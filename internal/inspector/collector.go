@@ -0,0 +1,145 @@
+package inspector
+
+import (
+	"context"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// DefaultTreeDepth bounds the descendant walk when no depth is given
+const DefaultTreeDepth = 5
+
+// Collector gathers a process's data and the host's around it, without
+// analyzing or rendering anything. It is the one collection entry point of
+// the single, batch, watch and compare inspections. Collectors derived from
+// one another with With share the earlier readings rates are measured
+// against, so a process sampled repeatedly gets its rates across samples.
+// A Collector is safe for concurrent use.
+type Collector struct {
+	opts Options
+	*collectorState
+}
+
+// source is what an Inspector's one-shot inspections collect through: its
+// Collector, or in tests a fake returning canned data. Watch mode reads the
+// live process handle tick after tick, so it always uses the Collector.
+type source interface {
+	Collect(ctx context.Context, pid int32) (*models.InspectionData, error)
+	CollectSystem(ctx context.Context) (*models.SystemInfo, error)
+	// withOptions is the source set up for one inspection's options
+	withOptions(opts Options) source
+}
+
+// collectorState is what a Collector shares with those derived from it
+type collectorState struct {
+	samples sampleState
+	names   hostNames
+	host    hostIdentity
+}
+
+// CollectorOption configures a Collector. Options are applied in order, so
+// a later one overrides an earlier one setting the same thing.
+type CollectorOption func(*Options)
+
+// Field is an optional part of the collection, left out unless asked for
+// with WithFields
+type Field string
+
+const (
+	// FieldThreads lists the DefaultHotThreads busiest threads
+	FieldThreads Field = "threads"
+	// FieldTrend takes a second quick reading of CPU and memory
+	FieldTrend Field = "trend"
+	// FieldPower estimates the process's share of the CPU's power draw
+	FieldPower Field = "power"
+	// FieldTree walks the descendants, DefaultTreeDepth deep
+	FieldTree Field = "tree"
+	// FieldChildren sums usage over every descendant
+	FieldChildren Field = "children"
+	// FieldTop lists the host's heaviest processes alongside
+	FieldTop Field = "top"
+)
+
+// NewCollector returns a Collector configured by options. Without any, it
+// samples CPU over DefaultCPUInterval and reads system CPU and memory once.
+func NewCollector(options ...CollectorOption) *Collector {
+	c := &Collector{
+		opts:           Options{CPUInterval: DefaultCPUInterval, SystemSamples: 1},
+		collectorState: &collectorState{},
+	}
+	for _, option := range options {
+		option(&c.opts)
+	}
+	return c
+}
+
+// With returns a Collector with options applied on top of c's, sharing c's
+// readings
+func (c *Collector) With(options ...CollectorOption) *Collector {
+	derived := &Collector{opts: c.opts, collectorState: c.collectorState}
+	for _, option := range options {
+		option(&derived.opts)
+	}
+	return derived
+}
+
+func (c *Collector) withOptions(opts Options) source {
+	return c.With(WithOptions(opts))
+}
+
+// WithFields adds optional parts to the collection
+func WithFields(fields ...Field) CollectorOption {
+	return func(opts *Options) {
+		for _, field := range fields {
+			switch field {
+			case FieldThreads:
+				opts.Threads = DefaultHotThreads
+			case FieldTrend:
+				opts.Trend = true
+			case FieldPower:
+				opts.Power = true
+			case FieldTree:
+				opts.Tree = true
+				opts.TreeDepth = DefaultTreeDepth
+			case FieldChildren:
+				opts.IncludeChildren = true
+			case FieldTop:
+				opts.WithTop = true
+			}
+		}
+	}
+}
+
+// WithCPUInterval sets the window process CPU usage is sampled over; zero
+// gives the average over the process's lifetime
+func WithCPUInterval(interval time.Duration) CollectorOption {
+	return func(opts *Options) {
+		opts.CPUInterval = interval
+	}
+}
+
+// WithSystemSamples averages system CPU and memory over this many
+// one-second readings
+func WithSystemSamples(samples int) CollectorOption {
+	return func(opts *Options) {
+		opts.SystemSamples = max(samples, 1)
+	}
+}
+
+// WithVerbose collects the full descriptor, socket and environment details
+// behind the summary counts, as verbose JSON output has them. It leaves the
+// output options alone.
+func WithVerbose(verbose bool) CollectorOption {
+	return func(opts *Options) {
+		opts.Verbose, opts.details = verbose, verbose
+	}
+}
+
+// WithOptions collects what an inspection's options ask for, replacing
+// anything set before
+func WithOptions(options Options) CollectorOption {
+	return func(opts *Options) {
+		*opts = options
+	}
+}
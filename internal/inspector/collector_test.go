@@ -0,0 +1,129 @@
+package inspector
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+func TestNewCollectorDefaults(t *testing.T) {
+	c := NewCollector()
+	if c.opts.CPUInterval != DefaultCPUInterval {
+		t.Errorf("CPUInterval = %s, want %s", c.opts.CPUInterval, DefaultCPUInterval)
+	}
+	if c.opts.SystemSamples != 1 {
+		t.Errorf("SystemSamples = %d, want 1", c.opts.SystemSamples)
+	}
+	if c.opts.Verbose || c.opts.Tree || c.opts.Threads != 0 || c.opts.Trend || c.opts.Power || c.opts.IncludeChildren || c.opts.WithTop {
+		t.Errorf("optional parts collected by default: %+v", c.opts)
+	}
+}
+
+func TestCollectorOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		option CollectorOption
+		check  func(Options) bool
+	}{
+		{"threads", WithFields(FieldThreads), func(o Options) bool { return o.Threads == DefaultHotThreads }},
+		{"trend", WithFields(FieldTrend), func(o Options) bool { return o.Trend }},
+		{"power", WithFields(FieldPower), func(o Options) bool { return o.Power }},
+		{"tree", WithFields(FieldTree), func(o Options) bool { return o.Tree && o.TreeDepth == DefaultTreeDepth }},
+		{"children", WithFields(FieldChildren), func(o Options) bool { return o.IncludeChildren }},
+		{"top", WithFields(FieldTop), func(o Options) bool { return o.WithTop }},
+		{"several fields", WithFields(FieldTrend, FieldPower), func(o Options) bool { return o.Trend && o.Power && !o.Tree }},
+		{"cpu interval", WithCPUInterval(time.Second), func(o Options) bool { return o.CPUInterval == time.Second }},
+		{"lifetime cpu", WithCPUInterval(0), func(o Options) bool { return o.CPUInterval == 0 }},
+		{"system samples", WithSystemSamples(5), func(o Options) bool { return o.SystemSamples == 5 }},
+		{"system samples floor", WithSystemSamples(0), func(o Options) bool { return o.SystemSamples == 1 }},
+		{"verbose", WithVerbose(true), func(o Options) bool { return o.Verbose && o.details && !o.JSON }},
+		{"options", WithOptions(Options{Tree: true, CPUInterval: time.Minute}), func(o Options) bool {
+			// Replaces everything, defaults included
+			return o.Tree && o.CPUInterval == time.Minute && o.SystemSamples == 0
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if c := NewCollector(test.option); !test.check(c.opts) {
+				t.Errorf("option not applied: %+v", c.opts)
+			}
+		})
+	}
+}
+
+func TestCollectorOptionsOrder(t *testing.T) {
+	c := NewCollector(WithCPUInterval(time.Second), WithCPUInterval(3*time.Second))
+	if c.opts.CPUInterval != 3*time.Second {
+		t.Errorf("CPUInterval = %s, want the later option's 3s", c.opts.CPUInterval)
+	}
+}
+
+func TestCollectorWith(t *testing.T) {
+	base := NewCollector(WithCPUInterval(time.Second))
+	derived := base.With(WithFields(FieldTrend))
+
+	if !derived.opts.Trend || derived.opts.CPUInterval != time.Second {
+		t.Errorf("derived options = %+v, want base's plus trend", derived.opts)
+	}
+	if base.opts.Trend {
+		t.Error("With changed the base collector's options")
+	}
+	if derived.collectorState != base.collectorState {
+		t.Error("derived collector doesn't share the base's readings")
+	}
+}
+
+func TestCollectHonorsOptions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("inspects the test process")
+	}
+	pid := int32(os.Getpid())
+	collect := func(t *testing.T, options ...CollectorOption) *models.InspectionData {
+		t.Helper()
+		data, err := NewCollector(append([]CollectorOption{WithCPUInterval(0)}, options...)...).Collect(context.Background(), pid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		data := collect(t)
+		if data.Tree != nil || data.Process.Details != nil || data.Top != nil {
+			t.Errorf("optional parts collected without asking: tree %v, details %v, top %v",
+				data.Tree != nil, data.Process.Details != nil, data.Top != nil)
+		}
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		data := collect(t, WithFields(FieldTree))
+		if data.Tree == nil {
+			t.Fatal("WithFields(FieldTree) collected no tree")
+		}
+		if data.Process.Details != nil {
+			t.Error("WithFields(FieldTree) collected details too")
+		}
+	})
+
+	t.Run("verbose", func(t *testing.T) {
+		data := collect(t, WithVerbose(true))
+		if data.Process.Details == nil {
+			t.Error("WithVerbose collected no details")
+		}
+		if data.Tree != nil {
+			t.Error("WithVerbose collected a tree too")
+		}
+	})
+
+	t.Run("cpu interval", func(t *testing.T) {
+		const interval = 300 * time.Millisecond
+		started := time.Now()
+		collect(t, WithCPUInterval(interval))
+		if elapsed := time.Since(started); elapsed < interval {
+			t.Errorf("Collect took %s, shorter than the %s CPU window", elapsed, interval)
+		}
+	})
+}
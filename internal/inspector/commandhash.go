@@ -0,0 +1,69 @@
+package inspector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// commandHashLength is how many hex digits of the SHA-256 a command hash
+// keeps: enough to tell launches apart, short enough to read in a report
+const commandHashLength = 16
+
+// commandHash fingerprints what a process was launched as: its executable
+// path and argv, and the executable's own digest when binary is set. The
+// argv is the redacted one, so a rotated secret alone doesn't count as a
+// change and the hash can't be used to guess one.
+func commandHash(exe string, args []string, binary string) string {
+	hash := sha256.New()
+	// NUL can't occur in a path or argument, so the fields can't run into
+	// each other
+	fmt.Fprintf(hash, "%s\x00", exe)
+	for _, arg := range args {
+		fmt.Fprintf(hash, "%s\x00", arg)
+	}
+	fmt.Fprintf(hash, "%s\x00", binary)
+	return hex.EncodeToString(hash.Sum(nil))[:commandHashLength]
+}
+
+// binaryHash is the SHA-256 of the image the process runs, for
+// --hash-binary. It reads the whole file, which is why it is opt-in.
+func binaryHash(pid int32, exe string) (string, error) {
+	file, err := openExecutable(pid, exe)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// commandTracker notices the command hash of a watched process changing
+// between ticks. Unlike the other trackers it carries over restarts: a
+// restart into the same command is a crash, into another one a redeploy.
+type commandTracker struct {
+	hash   string
+	pid    int32
+	change *models.CommandChange
+}
+
+// observe compares the process's hash with the last tick's and returns the
+// latest change seen, if any
+func (t *commandTracker) observe(proc *models.ProcessInfo, now time.Time) *models.CommandChange {
+	if proc.CommandHash == "" {
+		return t.change
+	}
+	if t.hash != "" && proc.CommandHash != t.hash {
+		t.change = &models.CommandChange{At: now, PreviousHash: t.hash, Hash: proc.CommandHash, PreviousPID: t.pid}
+	}
+	t.hash, t.pid = proc.CommandHash, proc.PID
+	return t.change
+}
@@ -0,0 +1,94 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"inspektor/internal/analyzer"
+	"inspektor/internal/display"
+	"inspektor/internal/models"
+)
+
+// comparisonOutput is the JSON shape of a comparison: both inspections, the
+// difference of each metric (second minus first) and the commentary
+type comparisonOutput struct {
+	First      inspectionOutput     `json:"first"`
+	Second     inspectionOutput     `json:"second"`
+	Difference comparisonDifference `json:"difference"`
+	Commentary string               `json:"commentary"`
+}
+
+type comparisonDifference struct {
+	Metrics     []models.MetricDelta `json:"metrics"`
+	HealthScore int                  `json:"health_score"`
+}
+
+// Compare inspects two processes at the same time, so both CPU samples cover
+// the same window, and renders them side by side with their differences and
+// a short commentary on which looks healthier
+func (i *Inspector) Compare(firstPID, secondPID int32, opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	var done chan bool
+	if opts.decorated() {
+		display.ShowBanner("")
+		done = make(chan bool)
+		go display.ShowProcessingAnimation(fmt.Sprintf("Comparing processes %d and %d...", firstPID, secondPID), done)
+	}
+	stopAnimation := func() {
+		if done != nil {
+			done <- true
+			close(done)
+			done = nil
+			time.Sleep(100 * time.Millisecond) // Give time to clear the animation
+		}
+	}
+	defer stopAnimation()
+
+	pids := []int32{firstPID, secondPID}
+	sides := make([]analyzer.Comparison, len(pids))
+	errs := make([]error, len(pids))
+	var wg sync.WaitGroup
+	for idx, pid := range pids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := opts.inspectionContext(context.Background())
+			defer cancel()
+			sides[idx].Data, errs[idx] = i.sourceFor(opts).Collect(ctx, pid)
+		}()
+	}
+	wg.Wait()
+	for idx, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to inspect process %d: %w", pids[idx], err)
+		}
+	}
+
+	for idx := range sides {
+		sides[idx].Findings = i.analyze(context.Background(), sides[idx].Data, opts)
+	}
+	first, second := sides[0], sides[1]
+	commentary := i.analyzer.CompareProcesses(first, second)
+	stopAnimation()
+
+	if opts.JSON {
+		return writeJSON(comparisonOutput{
+			First:  inspectionDocument(first.Data, first.Findings),
+			Second: inspectionDocument(second.Data, second.Findings),
+			Difference: comparisonDifference{
+				Metrics:     models.DiffProcesses(first.Data.Process, second.Data.Process),
+				HealthScore: second.Data.HealthScore - first.Data.HealthScore,
+			},
+			Commentary: commentary,
+		}, opts)
+	}
+
+	fmt.Print(i.formatter.FormatComparison(
+		display.TableRow{Data: first.Data, Findings: first.Findings},
+		display.TableRow{Data: second.Data, Findings: second.Findings},
+		commentary))
+	return nil
+}
@@ -0,0 +1,118 @@
+package inspector
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"syscall"
+
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+)
+
+// The helpers below back shell completion. Each returns cobra-style
+// candidates, "value\tdescription", and is best effort: anything that can't
+// be read is silently left out.
+
+// CompletePIDs lists running processes as "pid\tname"
+func CompletePIDs() []string {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil
+	}
+
+	self := int32(os.Getpid())
+	var candidates []string
+	for _, proc := range procs {
+		if proc.Pid == self {
+			continue
+		}
+		name, _ := proc.Name()
+		candidates = append(candidates, fmt.Sprintf("%d\t%s", proc.Pid, name))
+	}
+	return candidates
+}
+
+// CompleteProcessNames lists the distinct names of running processes
+func CompleteProcessNames() []string {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, proc := range procs {
+		name, err := proc.Name()
+		if err != nil || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CompleteUsers lists the distinct owners of running processes
+func CompleteUsers() []string {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var users []string
+	for _, proc := range procs {
+		user, err := proc.Username()
+		if err != nil || user == "" || seen[user] {
+			continue
+		}
+		seen[user] = true
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	return users
+}
+
+// CompleteListeningPorts lists ports with a listener as "port\tname (proto)"
+func CompleteListeningPorts() []string {
+	connections, err := net.Connections("inet")
+	if err != nil {
+		return nil
+	}
+
+	listeners := make(map[int]string)
+	for _, conn := range connections {
+		port := int(conn.Laddr.Port)
+		if port == 0 || listeners[port] != "" {
+			continue
+		}
+		if !isListener(conn, PortQuery{Port: port}) {
+			continue
+		}
+		proto := "udp"
+		if conn.Type == syscall.SOCK_STREAM {
+			proto = "tcp"
+		}
+		// The owning PID is unknown for sockets of other users' processes
+		listeners[port] = proto
+		if proc, err := process.NewProcess(conn.Pid); conn.Pid > 0 && err == nil {
+			if name, err := proc.Name(); err == nil {
+				listeners[port] = fmt.Sprintf("%s (%s)", name, proto)
+			}
+		}
+	}
+
+	ports := make([]int, 0, len(listeners))
+	for port := range listeners {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	candidates := make([]string, len(ports))
+	for idx, port := range ports {
+		candidates[idx] = fmt.Sprintf("%d\t%s", port, listeners[port])
+	}
+	return candidates
+}
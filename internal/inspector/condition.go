@@ -0,0 +1,82 @@
+package inspector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// Condition is a single metric comparison such as "cpu<5", used by
+// --watch-until to decide when to stop watching
+type Condition struct {
+	Metric string
+	Op     string
+	Value  float64
+	expr   string
+}
+
+// conditionMetrics maps the metric names accepted in conditions to the
+// process fields they read
+var conditionMetrics = map[string]func(p *models.ProcessInfo) float64{
+	"cpu":         func(p *models.ProcessInfo) float64 { return p.CPUPercent },
+	"mem_percent": func(p *models.ProcessInfo) float64 { return float64(p.MemoryPercent) },
+	"rss":         func(p *models.ProcessInfo) float64 { return float64(p.MemoryRSS) },
+	"connections": func(p *models.ProcessInfo) float64 { return float64(p.Connections) },
+	"threads":     func(p *models.ProcessInfo) float64 { return float64(p.NumThreads) },
+}
+
+// conditionPattern splits "metric op value"; two-character operators are
+// listed first so "<=" isn't read as "<"
+var conditionPattern = regexp.MustCompile(`^\s*([a-z_]+)\s*(<=|>=|==|<|>)\s*([0-9.]+)\s*([kKmMgG]?[bB]?)\s*$`)
+
+// ParseCondition parses an expression like "cpu<5" or "rss>=512M". Sizes for
+// rss accept K, M and G suffixes.
+func ParseCondition(expr string) (*Condition, error) {
+	match := conditionPattern.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, fmt.Errorf("invalid condition %q (expected e.g. cpu<5)", expr)
+	}
+
+	metric, op, number := match[1], match[2], match[3]
+	unit := strings.TrimSuffix(strings.ToUpper(match[4]), "B")
+	if _, ok := conditionMetrics[metric]; !ok {
+		return nil, fmt.Errorf("unknown metric %q in condition (expected cpu, mem_percent, rss, connections or threads)", metric)
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q in condition", number)
+	}
+	if unit != "" {
+		if metric != "rss" {
+			return nil, fmt.Errorf("size suffix only applies to rss")
+		}
+		value *= map[string]float64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30}[unit]
+	}
+
+	return &Condition{Metric: metric, Op: op, Value: value, expr: strings.TrimSpace(expr)}, nil
+}
+
+// Met reports whether the process currently satisfies the condition
+func (c *Condition) Met(p *models.ProcessInfo) bool {
+	actual := conditionMetrics[c.Metric](p)
+	switch c.Op {
+	case "<":
+		return actual < c.Value
+	case ">":
+		return actual > c.Value
+	case "<=":
+		return actual <= c.Value
+	case ">=":
+		return actual >= c.Value
+	default:
+		return actual == c.Value
+	}
+}
+
+func (c *Condition) String() string {
+	return c.expr
+}
@@ -0,0 +1,64 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// dockerSocket is where the Docker daemon serves its API by default
+	dockerSocket = "/var/run/docker.sock"
+
+	// dockerTimeout keeps an unresponsive daemon from stalling inspection
+	dockerTimeout = time.Second
+)
+
+// dockerContainer is the subset of the container inspect response we use
+type dockerContainer struct {
+	Name   string `json:"Name"`
+	Config struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+}
+
+// lookupContainer asks the Docker daemon for a container's name and image
+func lookupContainer(ctx context.Context, id string) (name, image string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerTimeout)
+	defer cancel()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", dockerSocket)
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/"+id+"/json", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("docker daemon unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("docker returned %s for container %s", resp.Status, id)
+	}
+
+	var container dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&container); err != nil {
+		return "", "", fmt.Errorf("failed to decode docker response: %w", err)
+	}
+
+	return strings.TrimPrefix(container.Name, "/"), container.Config.Image, nil
+}
@@ -0,0 +1,41 @@
+package inspector
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// deletedSuffix is what Linux appends to /proc/<pid>/exe once the file the
+// process was started from is unlinked
+const deletedSuffix = " (deleted)"
+
+// modifiedSlack absorbs the coarse start time of a process launched straight
+// after its binary was built, so it isn't reported as modified
+const modifiedSlack = 2 * time.Second
+
+// executableState compares the process's executable on disk with the image it
+// runs, returning the path without any " (deleted)" marker and how the file
+// changed; the state is empty when it is unchanged or can't be told
+func executableState(pid int32, exe string, started time.Time) (string, models.ExecutableState) {
+	path, unlinked := strings.CutSuffix(exe, deletedSuffix)
+	if path == "" {
+		return exe, ""
+	}
+
+	onDisk, running, err := statExecutable(pid, path)
+	switch {
+	case os.IsNotExist(err):
+		return path, models.ExecutableDeleted
+	case err != nil:
+		// Unreadable, typically another user's process without privileges
+		return path, ""
+	case unlinked, running != nil && !os.SameFile(onDisk, running):
+		return path, models.ExecutableReplaced
+	case onDisk.ModTime().After(started.Add(modifiedSlack)):
+		return path, models.ExecutableModified
+	}
+	return path, ""
+}
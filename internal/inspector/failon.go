@@ -0,0 +1,60 @@
+package inspector
+
+import (
+	"fmt"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// recordFailOn remembers the findings matching opts.FailOn. Rule-based checks
+// are always evaluated as well, so the gate holds even when an AI provider
+// wrote the report and worded or categorized things its own way.
+func (i *Inspector) recordFailOn(data *models.InspectionData, findings []models.Finding, opts Options) {
+	if len(opts.FailOn) == 0 {
+		return
+	}
+	if data != nil {
+		findings = append(findings, i.analyzer.RuleFindings(data)...)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, finding := range findings {
+		if finding.Kind == models.KindRecommendation || !finding.Matches(opts.FailOn) {
+			continue
+		}
+		key := finding.Category + ":" + finding.Rule + ":" + finding.Message
+		if i.failedOn[key] {
+			continue
+		}
+		if i.failedOn == nil {
+			i.failedOn = make(map[string]bool)
+		}
+		i.failedOn[key] = true
+		i.failures = append(i.failures, finding)
+	}
+}
+
+// FailOnError returns an error listing the findings that matched --fail-on
+// across every inspection so far, or nil if none did
+func (i *Inspector) FailOnError() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.failures) == 0 {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, finding := range i.failures {
+		name := finding.Rule
+		if name == "" {
+			name = finding.Category
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return fmt.Errorf("--fail-on matched %d finding(s): %s", len(i.failures), strings.Join(names, ", "))
+}
@@ -0,0 +1,76 @@
+package inspector
+
+import (
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// fdGrowthMinFit is how closely the open-file counts must follow a line, as
+// the fit's coefficient of determination, for the climb to count as steady
+// rather than the ups and downs of a busy server
+const fdGrowthMinFit = 0.8
+
+// fdSample is one watch tick's open-file count
+type fdSample struct {
+	at    time.Time
+	count int
+}
+
+// fdTracker fits a line through the recent open-file counts of a watched
+// process, to project when a steady climb reaches its NOFILE limit
+type fdTracker struct {
+	samples []fdSample
+}
+
+// observe records one sample and reports the projected exhaustion, or nil
+// while there are too few samples, the counts aren't climbing steadily or
+// the process has no limit to reach
+func (t *fdTracker) observe(proc *models.ProcessInfo) *models.FDGrowth {
+	t.samples = appendBounded(t.samples, fdSample{at: time.Now(), count: proc.OpenFiles}, watchHistorySize)
+	if len(t.samples) < models.FDGrowthSamples || proc.MaxOpenFiles <= 0 || proc.OpenFiles >= proc.MaxOpenFiles {
+		return nil
+	}
+
+	rate, fit := t.fit()
+	if rate <= 0 || fit < fdGrowthMinFit {
+		return nil
+	}
+	return &models.FDGrowth{
+		Since:       t.samples[0].at,
+		Samples:     len(t.samples),
+		Rate:        rate,
+		OpenFiles:   proc.OpenFiles,
+		Limit:       proc.MaxOpenFiles,
+		ExhaustedIn: float64(proc.MaxOpenFiles-proc.OpenFiles) / rate,
+	}
+}
+
+// fit is the least-squares slope of the counts over time, in descriptors per
+// second, and how much of their variance the line explains (0 to 1). Counts
+// that never change have no slope to speak of.
+func (t *fdTracker) fit() (slope, r2 float64) {
+	n := float64(len(t.samples))
+	var meanX, meanY float64
+	for _, sample := range t.samples {
+		meanX += sample.at.Sub(t.samples[0].at).Seconds() / n
+		meanY += float64(sample.count) / n
+	}
+	var sxx, sxy, syy float64
+	for _, sample := range t.samples {
+		dx := sample.at.Sub(t.samples[0].at).Seconds() - meanX
+		dy := float64(sample.count) - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, 0
+	}
+	return sxy / sxx, sxy * sxy / (sxx * syy)
+}
+
+// reset forgets the previous instance after a restart
+func (t *fdTracker) reset() {
+	t.samples = nil
+}
@@ -0,0 +1,40 @@
+package inspector
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/process"
+
+	"inspektor/internal/models"
+)
+
+// readGoRuntime recognizes a Go program from the build info embedded in its
+// executable, and picks GOMAXPROCS out of its environment. It is best
+// effort: nil for any other program, or when the executable can't be read.
+func readGoRuntime(proc *process.Process, exe string, environ []string) *models.GoRuntime {
+	path := exe
+	if runtime.GOOS == "linux" {
+		// The link still reads the binary once it has been replaced on disk,
+		// or when it lives in a container's filesystem
+		path = fmt.Sprintf("/proc/%d/exe", proc.Pid)
+	}
+	if path == "" {
+		return nil
+	}
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	goRuntime := &models.GoRuntime{Version: info.GoVersion, Module: info.Main.Path}
+	for _, entry := range environ {
+		if value, found := strings.CutPrefix(entry, "GOMAXPROCS="); found {
+			goRuntime.GOMAXPROCS, _ = strconv.Atoi(value)
+		}
+	}
+	return goRuntime
+}
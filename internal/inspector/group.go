@@ -0,0 +1,86 @@
+package inspector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// groupReport is the JSON shape of an inspection of a group of processes, a
+// control group or a user's: the combined usage with the per-process entries
+// alongside. JSON Lines streams the entries first and ends with the totals,
+// without Processes.
+type groupReport struct {
+	Timestamp *time.Time         `json:"timestamp,omitempty"`
+	Cgroup    string             `json:"cgroup,omitempty"`
+	User      string             `json:"user,omitempty"`
+	Totals    *models.TreeTotals `json:"totals"`
+	// Findings are about the group as a whole, such as a user over budget
+	Findings  []models.Finding `json:"findings,omitempty"`
+	Processes []batchEntry     `json:"processes,omitempty"`
+}
+
+// add counts one inspected process towards the totals
+func (r *groupReport) add(proc *models.ProcessInfo) {
+	r.Totals.Processes++
+	r.Totals.CPUPercent += proc.CPUPercent
+	r.Totals.MemoryRSS += proc.MemoryRSS
+	r.Totals.OpenFiles += proc.OpenFiles
+	r.Totals.Connections += proc.Connections
+}
+
+// kind and name label the group in text output
+func (r *groupReport) kind() (string, string) {
+	if r.User != "" {
+		return "User", r.User
+	}
+	return "Cgroup", r.Cgroup
+}
+
+// outputGroup ends a group inspection with the combined usage, after the
+// per-process reports; in JSON the entries are nested under the totals
+func (i *Inspector) outputGroup(report *groupReport, entries []batchEntry, opts Options) error {
+	if report.User != "" {
+		findings := i.analyzer.AnalyzeUserBudget(report.User, report.Totals)
+		i.recordFailOn(nil, findings, opts)
+		report.Findings = i.divertWarnings(nil, models.FilterBySeverity(findings, opts.MinSeverity), opts)
+	}
+
+	kind, name := report.kind()
+	switch {
+	case opts.dir != nil:
+		return opts.dir.save(fmt.Sprintf("%s-%s.json", strings.ToLower(kind), safeFileName(name, "group")), report)
+	case opts.JSONLines:
+		now := opts.now()
+		report.Timestamp = &now
+		return writeJSON(report, opts)
+	case opts.JSON:
+		if opts.Quiet && len(entries) == 0 && len(report.Findings) == 0 {
+			return nil
+		}
+		report.Processes = entries
+		return writeJSON(report, opts)
+	case opts.CSV:
+		// The rows already add up to the totals, and anything else would
+		// break the CSV
+		return nil
+	case opts.GitHub:
+		fmt.Print(i.formatter.FormatGitHubAnnotations(fmt.Sprintf("%s %s", kind, name), report.Findings))
+		return nil
+	case opts.Quiet:
+		if len(report.Findings) > 0 {
+			fmt.Print(i.formatter.FormatFindings(report.Findings))
+		}
+		return nil
+	case opts.Markdown:
+		fmt.Print(i.formatter.FormatGroupMarkdown(kind, name, report.Totals, report.Findings))
+		return nil
+	}
+	fmt.Print(i.formatter.FormatGroupTotals(kind, name, report.Totals))
+	if len(report.Findings) > 0 {
+		fmt.Print(i.formatter.FormatFindings(report.Findings))
+	}
+	return nil
+}
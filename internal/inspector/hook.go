@@ -0,0 +1,71 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// hookTimeout bounds how long an --on-warning command may run
+const hookTimeout = 30 * time.Second
+
+// runWarningHook executes the --on-warning command when the inspection found
+// critical issues, passing the inspection as JSON on stdin. The hook is best
+// effort: failures are reported on stderr and never abort the inspection.
+func (i *Inspector) runWarningHook(command string, data *models.InspectionData, findings []models.Finding) {
+	if command == "" || !hasCritical(findings) {
+		return
+	}
+
+	payload, err := json.Marshal(inspectionDocument(data, findings))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: on-warning hook skipped: %v\n", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr // keep stdout clean for JSON consumers
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		fmt.Fprintf(os.Stderr, "Warning: on-warning hook timed out after %s\n", hookTimeout)
+	case errors.As(err, &exitErr):
+		fmt.Fprintf(os.Stderr, "Warning: on-warning hook exited with status %d\n", exitErr.ExitCode())
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: on-warning hook failed: %v\n", err)
+	}
+}
+
+// shellCommand runs command through the platform shell so hooks can use
+// arguments, pipes and redirection
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+func hasCritical(findings []models.Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == models.SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
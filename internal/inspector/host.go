@@ -0,0 +1,81 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"inspektor/internal/display"
+	"inspektor/internal/models"
+)
+
+// hostReport is the JSON shape of a --system inspection
+type hostReport struct {
+	Timestamp *time.Time         `json:"timestamp,omitempty"`
+	Hostname  string             `json:"hostname"`
+	Host      *models.HostInfo   `json:"host"`
+	System    *models.SystemInfo `json:"system"`
+	Findings  []models.Finding   `json:"findings"`
+}
+
+// InspectSystem collects and reports only host-wide metrics and the
+// system-level rules, without targeting a process
+func (i *Inspector) InspectSystem(opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	var done chan bool
+	if opts.decorated() {
+		display.ShowBanner("")
+		done = make(chan bool)
+		go display.ShowProcessingAnimation("Analyzing system metrics...", done)
+	}
+
+	ctx, cancel := opts.inspectionContext(context.Background())
+	defer cancel()
+
+	sys, err := withDeadline(ctx, func(ctx context.Context) (*models.SystemInfo, error) {
+		return i.sourceFor(opts).CollectSystem(ctx)
+	})
+	if done != nil {
+		done <- true
+		close(done)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to collect system info: %w", err)
+	}
+
+	findings := i.analyzer.AnalyzeSystem(sys)
+	i.recordFailOn(nil, findings, opts)
+	findings = i.divertWarnings(nil, models.FilterBySeverity(findings, opts.MinSeverity), opts)
+	if findings == nil {
+		findings = []models.Finding{}
+	}
+
+	// Quiet mode stays silent unless something needs attention
+	if opts.Quiet && len(findings) == 0 {
+		return nil
+	}
+
+	host := i.collector.host.get(ctx)
+
+	if opts.JSON {
+		report := hostReport{Hostname: host.Hostname, Host: host, System: sys, Findings: findings}
+		if opts.JSONLines {
+			now := opts.now()
+			report.Timestamp = &now
+		}
+		return writeJSON(report, opts)
+	}
+
+	if opts.GitHub {
+		fmt.Print(i.formatter.FormatGitHubAnnotations("host "+host.Hostname, findings))
+		return nil
+	}
+
+	if !opts.Quiet {
+		fmt.Print(i.formatter.FormatSystemReport(host, sys))
+	}
+	fmt.Print(i.formatter.FormatFindings(findings))
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package inspector
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sync"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/host"
+)
+
+// hostIdentity caches the host description, which doesn't change over the
+// life of the command, so watch and batch modes look it up only once
+type hostIdentity struct {
+	once sync.Once
+	info *models.HostInfo
+	// boot is when the host booted, in seconds since the epoch; 0 if unknown
+	boot uint64
+}
+
+// get returns the host description. It never fails: fields the platform
+// doesn't report are left empty, and the hostname and architecture fall back
+// to what the runtime knows.
+func (h *hostIdentity) get(ctx context.Context) *models.HostInfo {
+	h.once.Do(func() {
+		info := &models.HostInfo{Arch: runtime.GOARCH}
+		if stat, err := host.InfoWithContext(ctx); err == nil {
+			info.Hostname = stat.Hostname
+			info.OS = stat.OS
+			info.Platform = stat.Platform
+			info.PlatformVersion = stat.PlatformVersion
+			info.KernelVersion = stat.KernelVersion
+			if stat.KernelArch != "" {
+				info.Arch = stat.KernelArch
+			}
+			h.boot = stat.BootTime
+		}
+		if h.boot == 0 {
+			h.boot, _ = host.BootTimeWithContext(ctx)
+		}
+		if info.Hostname == "" {
+			info.Hostname, _ = os.Hostname()
+		}
+		if info.OS == "" {
+			info.OS = runtime.GOOS
+		}
+		h.info = info
+	})
+	return h.info
+}
+
+// bootTime returns when the host booted, in seconds since the epoch, or 0
+// where the platform doesn't say
+func (h *hostIdentity) bootTime(ctx context.Context) uint64 {
+	h.get(ctx)
+	return h.boot
+}
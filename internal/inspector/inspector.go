@@ -1,43 +1,300 @@
 package inspector
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"inspektor/internal/analyzer"
 	"inspektor/internal/display"
 	"inspektor/internal/models"
+	"inspektor/internal/redact"
 
-	"github.com/charmbracelet/lipgloss"
-	"github.com/shirou/gopsutil/cpu"
-	"github.com/shirou/gopsutil/mem"
 	"github.com/shirou/gopsutil/net"
 	"github.com/shirou/gopsutil/process"
 )
 
+// Options controls what an inspection collects and how it is rendered
+type Options struct {
+	JSON bool
+	// JSONLines writes each inspection or watch sample as one compact,
+	// timestamped JSON object per line; implies JSON
+	JSONLines bool
+	// JSONCompact writes the --json document minified onto a single line,
+	// for log ingestion; unlike JSONLines it adds no timestamp and keeps a
+	// batch as one array
+	JSONCompact bool
+	// Markdown renders reports as plain Markdown for tickets and chat
+	Markdown bool
+	// Table renders one row per process, ordered by SortBy
+	Table  bool
+	SortBy string
+	// CSV writes a header and one row of key metrics per process, in input
+	// order, for spreadsheets; with top-N, one row per ranked process
+	CSV bool
+	// GitHub prints only the findings, as GitHub Actions workflow commands
+	// that show up as annotations on the run
+	GitHub bool
+	// SampleOutput adds the watch readings so far, timestamped, to each
+	// JSON document
+	SampleOutput bool
+	// MetricOnly prints just this field of the inspection, by its JSON
+	// name (see models.MetricFields), and skips the findings
+	MetricOnly string
+
+	Verbose   bool
+	Tree      bool
+	TreeDepth int
+	// Threads lists this many of the process's threads using the most CPU,
+	// like top -H; 0 skips them
+	Threads int
+	// IncludeChildren sums CPU, memory, open files and connections over the
+	// process and all its descendants
+	IncludeChildren bool
+	// FollowChildren reports the descendants a watched process spawns and
+	// loses from tick to tick; it implies IncludeChildren
+	FollowChildren bool
+	Watch          bool
+	Interval       time.Duration
+	// AdaptiveInterval doubles the watch interval, up to MaxInterval, while
+	// the process holds steady, and returns to Interval once it moves
+	AdaptiveInterval bool
+	MaxInterval      time.Duration
+	// Repeat takes exactly this many samples, Interval apart, then exits
+	Repeat  int
+	All     bool
+	Explain bool
+	Quiet   bool
+	// ExplainHealth lists what took points off the health score under it
+	ExplainHealth bool
+	// GroupBy buckets the findings of text reports, see display.GroupByCategory
+	GroupBy string
+	// AllFindings lists every finding in text reports instead of collapsing
+	// those past display.DefaultMaxFindings; JSON always has them all
+	AllFindings bool
+
+	// Until stops watching once the condition holds; WatchTimeout bounds how
+	// long watch mode runs
+	Until        *Condition
+	WatchTimeout time.Duration
+
+	// TimeFormat and UTC control how timestamps are rendered
+	TimeFormat string
+	UTC        bool
+
+	// Width forces the text layout width; Borderless drops separators and
+	// section highlights
+	Width      int
+	Borderless bool
+
+	// Timeout bounds the whole collection; zero means no deadline
+	Timeout time.Duration
+
+	// CPUInterval is the gap between the two CPU readings used to compute
+	// the process's CPU percentage; zero reports the lifetime average instead
+	CPUInterval time.Duration
+
+	// HashBinary adds the SHA-256 of the process's executable to its
+	// command hash, reading the whole file
+	HashBinary bool
+
+	// DetailLimit is the most descriptors a process may hold for them and
+	// its sockets to be listed one by one; above it only the count is read,
+	// since listing 100k descriptors takes seconds of kernel time. Zero
+	// means no limit, as does ForceDetail.
+	DetailLimit int
+	ForceDetail bool
+
+	// Trend takes a second quick reading of CPU and memory to show which
+	// way they are heading; off by default to keep one-shot runs fast
+	Trend bool
+
+	// Power estimates the process's share of the CPU's power draw over the
+	// CPU sampling window, in watts where RAPL energy counters are readable
+	Power bool
+
+	// SystemSamples is how many one-second readings of system CPU and
+	// memory are averaged; the system rules judge the average
+	SystemSamples int
+
+	// CPUMode shows process CPU per core (raw) or as a share of all cores
+	// (normalized); JSON always carries the raw figure
+	CPUMode models.CPUMode
+
+	// DiffThresholds says how far a metric must move to be marked as changed
+	// between watch samples or compared processes; nil uses the defaults
+	DiffThresholds models.DiffThresholds
+
+	// Layout orders and hides the rows of the text report's sections; nil
+	// keeps the built-in layout
+	Layout *display.Layout
+
+	// cpuDelta makes CPU sampling use the previous reading held on the
+	// process handle, set by modes that sample the same handle repeatedly
+	cpuDelta bool
+
+	// details gathers the descriptor, socket and environment listings
+	// whatever the output, for a Collector built WithVerbose
+	details bool
+
+	// watchPort is the listener an inspection by port was resolved from, so
+	// watch mode can find the process again after a restart
+	watchPort *PortQuery
+
+	// children tracks the descendants seen so far with FollowChildren
+	children *childTracker
+
+	// group collects the combined usage of a --cgroup or --user batch
+	group *groupReport
+
+	// WithTop adds the host's heaviest processes by CPU and memory to a
+	// single-process report; off by default as it samples every process
+	WithTop bool
+
+	// Concurrency is how many processes a batch inspects at once; below 2
+	// they are inspected one after another
+	Concurrency int
+
+	// BatchAnalysis analyzes all processes of a batch together, one AI call
+	// per chunk instead of one per process, so the model can also report
+	// issues they share; output then waits for the whole batch
+	BatchAnalysis bool
+
+	// OutputDir saves each inspection of a batch to its own JSON file in
+	// this directory instead of printing it; dir is the open directory
+	OutputDir string
+	dir       *outputDir
+
+	// IncludeSelf counts inspektor's own process in host-wide figures: the
+	// top-N ranking, name, user and cgroup batches, and system CPU usage.
+	// By default it is left out so sampling doesn't skew what it measures.
+	IncludeSelf bool
+
+	// NoKernelThreads leaves kernel threads out of the top-N ranking and of
+	// name and user batches
+	NoKernelThreads bool
+
+	// NameExact makes --name match whole process names rather than any
+	// name containing it; Exclude drops matches whose name or command line
+	// matches one of the patterns
+	NameExact bool
+	Exclude   []*regexp.Regexp
+
+	// Services names the services behind listening ports, extending and
+	// overriding the built-in names (5432 postgres, 6379 redis, ...)
+	Services map[uint32]string
+
+	// RuntimeEnv names environment variables to report on top of the
+	// built-in runtime ones; a trailing * matches a prefix
+	RuntimeEnv []string
+
+	// Port lookup filters
+	Proto       string
+	BindAddress string
+
+	// Docker resolves container IDs to names and images via the Docker socket
+	Docker bool
+
+	// Resolve lists connections in verbose output with their peers'
+	// hostnames and well-known services; reverse DNS is best-effort and can
+	// be slow, so it is off by default
+	Resolve bool
+
+	// MinSeverity drops findings less urgent than this before output
+	MinSeverity models.Severity
+
+	// FailOn lists finding categories or rule IDs that make the run fail,
+	// see FailOnError
+	FailOn []string
+
+	// Warnings receives the findings, one line each, instead of the report,
+	// separating alerts from data; WarningsJSON writes them as JSON Lines
+	// instead of text. Nil keeps the findings in the report.
+	Warnings     io.Writer
+	WarningsJSON bool
+
+	// OnWarning is a shell command run with the inspection JSON on stdin
+	// whenever critical findings are present
+	OnWarning string
+
+	// AuditLog is a file every inspection appends a JSON line to, recording
+	// when, by whom, of what and with how many findings
+	AuditLog string
+
+	// NoBanner suppresses the banner, spinner and progress messages while
+	// leaving the report itself untouched
+	NoBanner bool
+
+	// Strict turns any collection error or timeout into a hard error instead
+	// of reporting partial data; unavailable readers don't count
+	Strict bool
+
+	// Redactor masks secrets in command lines and environments as they are
+	// collected; nil uses the built-in patterns
+	Redactor *redact.Redactor
+}
+
 type Inspector struct {
 	analyzer  *analyzer.AIAnalyzer
 	formatter *display.Formatter
+	collector *Collector
+	source    source
+
+	// failures are the findings that matched Options.FailOn so far, guarded
+	// by mu as batch workers record them concurrently
+	mu       sync.Mutex
+	failures []models.Finding
+	failedOn map[string]bool
+
+	// auditMu serializes appends to Options.AuditLog
+	auditMu sync.Mutex
 }
 
-func New() *Inspector {
-	return &Inspector{
-		analyzer:  analyzer.New(),
+// New creates an Inspector. It owns the AI client for its whole lifetime, so
+// any number of inspections share one connection; call Close when done.
+func New(cfg analyzer.Config) *Inspector {
+	insp := &Inspector{
+		analyzer:  analyzer.New(cfg),
 		formatter: display.NewFormatter(),
+		collector: NewCollector(),
 	}
+	insp.source = insp.collector
+	insp.formatter.Thresholds = insp.analyzer.Thresholds
+	return insp
 }
 
-func (i *Inspector) InspectWithOptions(pid int32, jsonOutput, verbose bool) error {
-	// Ensure AI client is properly closed
-	defer func() {
-		if err := i.analyzer.Close(); err != nil {
-			fmt.Printf("Warning: Failed to close AI client: %v\n", err)
-		}
-	}()
+// collectorFor is the Inspector's collector, set up for one inspection's
+// options; rates are still measured across inspections of the same process
+func (i *Inspector) collectorFor(opts Options) *Collector {
+	return i.collector.With(WithOptions(opts))
+}
+
+// sourceFor is what an inspection with opts collects through
+func (i *Inspector) sourceFor(opts Options) source {
+	return i.source.withOptions(opts)
+}
 
-	// Show banner and start processing animation (skip for JSON output)
-	if !jsonOutput {
+// Close releases the AI client. It is safe to call more than once.
+func (i *Inspector) Close() error {
+	return i.analyzer.Close()
+}
+
+func (i *Inspector) InspectWithOptions(pid int32, opts Options) error {
+	if opts.Watch || opts.Repeat > 0 || opts.Until != nil {
+		return i.Watch(pid, opts)
+	}
+	i.applyDisplayOptions(opts)
+
+	// Show banner and start processing animation (skip for JSON/quiet output)
+	if opts.decorated() {
 		display.ShowBanner("")
 		done := make(chan bool)
 		go display.ShowProcessingAnimation("Analyzing process and system metrics...", done)
@@ -48,200 +305,466 @@ func (i *Inspector) InspectWithOptions(pid int32, jsonOutput, verbose bool) erro
 		}()
 	}
 
-	// Get process information
-	proc, err := process.NewProcess(pid)
-	if err != nil {
-		return fmt.Errorf("failed to get process: %w", err)
+	if opts.MetricOnly != "" {
+		ctx, cancel := opts.inspectionContext(context.Background())
+		defer cancel()
+		data, err := i.sourceFor(opts).Collect(ctx, pid)
+		if err != nil {
+			return err
+		}
+		return outputMetric(data, opts.MetricOnly)
 	}
 
-	// Collect process data
-	processInfo, err := i.collectProcessInfo(proc)
+	// Generate AI analysis and findings
+	result, err := i.Evaluate(pid, opts)
 	if err != nil {
-		return fmt.Errorf("failed to collect process info: %w", err)
+		return err
 	}
 
-	// Collect system data
-	systemInfo, err := i.collectSystemInfo()
-	if err != nil {
-		return fmt.Errorf("failed to collect system info: %w", err)
+	// Quiet mode stays silent unless something needs attention
+	if opts.Quiet && len(result.Findings) == 0 {
+		return nil
 	}
 
-	// Create inspection data
-	data := &models.InspectionData{
-		Process: processInfo,
-		System:  systemInfo,
+	if opts.JSON {
+		return i.outputJSON(result.Data, result.Findings, opts)
 	}
 
-	// Generate AI analysis and warnings
-	warnings := i.analyzer.AnalyzeAndWarn(data)
+	// Display results in rich format
+	i.render(result.Data, result.Findings, opts)
+	if !opts.Quiet && !opts.Markdown && !opts.Table && !opts.CSV && !opts.GitHub {
+		fmt.Print(i.formatter.FormatDuration(result.Duration))
+	}
+
+	return nil
+}
+
+// render prints the text report for one inspection, or only its findings in
+// quiet mode
+func (i *Inspector) render(data *models.InspectionData, findings []models.Finding, opts Options) {
+	if opts.Markdown {
+		fmt.Print(i.formatter.FormatMarkdown(data, findings, opts.now()))
+		return
+	}
+	if opts.Table {
+		fmt.Print(i.formatter.FormatTable([]display.TableRow{{Data: data, Findings: findings}}))
+		return
+	}
+	if opts.CSV {
+		os.Stdout.Write(display.FormatCSV([]*models.InspectionData{data}))
+		return
+	}
+	if opts.GitHub {
+		// A timed-out inspection skipped analysis; say so rather than
+		// passing the step silently
+		if data.TimedOut {
+			findings = append(findings, models.Finding{
+				Kind:     models.KindWarning,
+				Severity: models.SeverityWarning,
+				Message:  "Collection timed out: partial results, analysis skipped",
+			})
+		}
+		fmt.Print(i.formatter.FormatGitHubAnnotations(fmt.Sprintf("PID %d (%s)", data.Process.PID, data.Process.Name), findings))
+		return
+	}
 
-	if jsonOutput {
-		return i.outputJSON(data, warnings)
+	if opts.Quiet {
+		if len(findings) > 0 {
+			fmt.Print(i.formatter.FormatTitle(data))
+			fmt.Print(i.formatter.FormatFindings(findings))
+		}
+		return
 	}
 
-	// Display results in rich format
 	fmt.Print(i.formatter.FormatReport(data))
-	fmt.Print(i.formatter.FormatWarnings(warnings))
+	if data.Tree != nil {
+		fmt.Print(i.formatter.FormatTree(data.Tree))
+	}
+	if data.TimedOut {
+		fmt.Print(i.formatter.FormatTimeout())
+		return
+	}
+	fmt.Print(i.formatter.FormatFindings(findings))
+}
 
-	return nil
+// DefaultCPUInterval is the default gap between CPU readings
+const DefaultCPUInterval = 500 * time.Millisecond
+
+// DefaultDetailLimit is the default Options.DetailLimit
+const DefaultDetailLimit = 20000
+
+// inspectionContext derives the context bounding a single inspection
+func (o Options) inspectionContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if o.Timeout > 0 {
+		return context.WithTimeout(parent, o.Timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// now is the current time, honoring --utc
+func (o Options) now() time.Time {
+	if o.UTC {
+		return time.Now().UTC()
+	}
+	return time.Now()
+}
+
+// redactor is the Redactor to collect with
+func (o Options) redactor() *redact.Redactor {
+	if o.Redactor == nil {
+		return redact.Default
+	}
+	return o.Redactor
+}
+
+// decorated reports whether interactive extras (banner, spinner, progress
+// messages) should be shown
+func (o Options) decorated() bool {
+	return !o.JSON && !o.Markdown && !o.Table && !o.CSV && !o.GitHub && !o.Quiet && !o.NoBanner && o.MetricOnly == "" && o.OutputDir == ""
+}
+
+// applyDisplayOptions carries the rendering-related options over to the formatter
+func (i *Inspector) applyDisplayOptions(opts Options) {
+	i.formatter.Explain = opts.Explain
+	i.formatter.ExplainHealth = opts.ExplainHealth
+	i.formatter.GroupBy = opts.GroupBy
+	i.formatter.Verbose = opts.Verbose
+	i.formatter.MinSeverity = opts.MinSeverity
+	i.formatter.TimeFormat = opts.TimeFormat
+	i.formatter.Width = opts.Width
+	i.formatter.Borderless = opts.Borderless
+	i.formatter.CPUMode = opts.CPUMode
+	i.formatter.DiffThresholds = opts.DiffThresholds
+	i.formatter.Layout = opts.Layout
+	i.formatter.WarningsDiverted = opts.Warnings != nil
+	i.formatter.MaxFindings = display.DefaultMaxFindings
+	if opts.AllFindings {
+		i.formatter.MaxFindings = 0
+	}
+}
+
+// analyze scores the collected data, generates findings for it and fires the
+// --on-warning hook if any are critical and records --fail-on matches, then
+// applies the severity filter. Partial data from a timed-out collection is
+// not analyzed. AI calls end with ctx.
+func (i *Inspector) analyze(ctx context.Context, data *models.InspectionData, opts Options) []models.Finding {
+	findings, _ := i.analyzeWithSource(ctx, data, opts)
+	return findings
+}
+
+// analyzeWithSource is analyze, also saying where the findings came from;
+// the source is empty when nothing was analyzed
+func (i *Inspector) analyzeWithSource(ctx context.Context, data *models.InspectionData, opts Options) ([]models.Finding, string) {
+	if data.TimedOut {
+		return nil, ""
+	}
+	scoreHealth(data)
+	findings, source := i.analyzer.AnalyzeAndWarn(ctx, data)
+	return i.settle(data, findings, opts), source
+}
+
+// scoreHealth rates the inspection, files the score in its band and keeps
+// its breakdown
+func scoreHealth(data *models.InspectionData) {
+	data.HealthScore = analyzer.HealthScore(data)
+	data.HealthComponents = analyzer.HealthComponents(data)
+	data.HealthBand = models.HealthBandOf(data.HealthScore)
+}
+
+// analyzeBatch is analyze for several inspections sharing one AI call per
+// chunk, see analyzer.AnalyzeBatch; the findings are keyed by PID
+func (i *Inspector) analyzeBatch(batch []*models.InspectionData, opts Options) map[int32][]models.Finding {
+	var ready []*models.InspectionData
+	for _, data := range batch {
+		if !data.TimedOut {
+			scoreHealth(data)
+			ready = append(ready, data)
+		}
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+
+	findings := i.analyzer.AnalyzeBatch(ready)
+	for _, data := range ready {
+		findings[data.Process.PID] = i.settle(data, findings[data.Process.PID], opts)
+	}
+	return findings
+}
+
+// settle fires the --on-warning hook, writes the audit log and records
+// --fail-on matches for the findings of one inspection, then applies the
+// severity filter and diverts what is left to the warnings channel, if
+// there is one
+func (i *Inspector) settle(data *models.InspectionData, findings []models.Finding, opts Options) []models.Finding {
+	i.runWarningHook(opts.OnWarning, data, findings)
+	i.audit(data, findings, opts)
+	i.recordFailOn(data, findings, opts)
+	return i.divertWarnings(data, models.FilterBySeverity(findings, opts.MinSeverity), opts)
 }
 
 func (i *Inspector) Inspect(pid int32) error {
-	return i.InspectWithOptions(pid, false, false)
+	return i.InspectWithOptions(pid, Options{})
 }
 
-func (i *Inspector) InspectByPort(port int, jsonOutput, verbose bool) error {
-	// Show banner for port lookup (skip for JSON output)
-	if !jsonOutput {
+func (i *Inspector) InspectByPort(port int, opts Options) error {
+	query := PortQuery{Port: port, Proto: opts.Proto, Address: opts.BindAddress}
+
+	// Show banner for port lookup (skip for JSON/quiet output)
+	var done chan bool
+	if opts.decorated() {
 		display.ShowBanner("")
-		done := make(chan bool)
-		go display.ShowProcessingAnimation(fmt.Sprintf("Finding process on port %d...", port), done)
+		done = make(chan bool)
+		go display.ShowProcessingAnimation(fmt.Sprintf("Finding process on %s...", query), done)
+	}
 
-		// Find the PID listening on the specified port
-		pid, err := i.findProcessByPort(port)
+	// Find the PID listening on the specified port
+	pid, err := i.findProcessByPort(query)
 
+	if done != nil {
 		done <- true
 		close(done)
 		time.Sleep(100 * time.Millisecond)
+	}
+	var multiple *MultipleListenersError
+	if errors.As(err, &multiple) {
+		return i.inspectListeners(multiple, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find process on %s: %w", query, err)
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to find process on port %d: %w", port, err)
-		}
+	if opts.decorated() {
+		fmt.Printf("\n%s\n\n", display.FormatSuccess(fmt.Sprintf("Found process %d listening on %s", pid, query)))
+	}
 
-		fmt.Printf("\n%s\n\n",
-			lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#22C55E")).
-				Bold(true).
-				Render(fmt.Sprintf("✓ Found process %d listening on port %d", pid, port)))
-	} else {
-		// Silent lookup for JSON mode
-		pid, err := i.findProcessByPort(port)
-		if err != nil {
-			return fmt.Errorf("failed to find process on port %d: %w", port, err)
+	// Continue with normal inspection (which will show its own banner)
+	opts.watchPort = &query
+	return i.InspectWithOptions(pid, opts)
+}
+
+// PortQuery selects the listener to inspect by port, protocol and optionally
+// the local address it is bound to
+type PortQuery struct {
+	Port    int
+	Proto   string // "tcp", "udp" or empty for both
+	Address string // empty matches any bind address
+}
+
+func (q PortQuery) String() string {
+	desc := fmt.Sprintf("port %d", q.Port)
+	if q.Proto != "" {
+		desc += "/" + q.Proto
+	}
+	if q.Address != "" {
+		desc += " on " + q.Address
+	}
+	return desc
+}
+
+// findProcessByPort returns the process listening on the query's port. When
+// several do, it returns a *MultipleListenersError listing them.
+func (i *Inspector) findProcessByPort(query PortQuery) (int32, error) {
+	listeners, err := i.findListeners(query)
+	if err != nil {
+		return 0, err
+	}
+	if pids := listenerPIDs(listeners); len(pids) > 1 {
+		return 0, &MultipleListenersError{Query: query, listeners: listeners}
+	}
+	return listeners[0].PID, nil
+}
+
+// isListener reports whether conn is a socket accepting traffic for the query.
+// TCP listeners are in LISTEN state; UDP has no states, so a bound socket
+// without a connected remote peer counts as listening.
+func isListener(conn net.ConnectionStat, query PortQuery) bool {
+	if conn.Laddr.Port != uint32(query.Port) {
+		return false
+	}
+
+	switch conn.Type {
+	case syscall.SOCK_STREAM:
+		if conn.Status != "LISTEN" {
+			return false
 		}
-		return i.InspectWithOptions(pid, jsonOutput, verbose)
+	case syscall.SOCK_DGRAM:
+		if conn.Raddr.Port != 0 {
+			return false
+		}
+	default:
+		return false
 	}
 
-	// Continue with normal inspection (which will show its own banner)
-	pid, _ := i.findProcessByPort(port)
-	return i.InspectWithOptions(pid, jsonOutput, verbose)
+	return matchesAddress(conn.Laddr.IP, query)
+}
+
+// matchesAddress reports whether a socket bound to ip accepts traffic for
+// the query's bind address
+func matchesAddress(ip string, query PortQuery) bool {
+	if query.Address == "" {
+		return true
+	}
+
+	// Wildcard binds accept traffic for every local address
+	return ip == query.Address || ip == "0.0.0.0" || ip == "::"
 }
 
-func (i *Inspector) findProcessByPort(port int) (int32, error) {
-	// Get all network connections
-	connections, err := net.Connections("all")
+// findProcessesByName returns the PIDs of all processes whose name contains
+// the given string, or is exactly it with opts.NameExact. Processes whose
+// name or command line matches an opts.Exclude pattern are skipped, and so,
+// unless opts.IncludeSelf is set, are inspektor itself and the processes
+// that launched it (its shell, sudo, watch, ...), whose command lines name
+// the very process being searched for. opts.NoKernelThreads skips kernel
+// threads too.
+func (i *Inspector) findProcessesByName(name string, opts Options) ([]int32, error) {
+	procs, err := process.Processes()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get network connections: %w", err)
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var skip map[int32]bool
+	if !opts.IncludeSelf {
+		skip = selfAndAncestors()
+	}
+	var pids []int32
+	excluded := 0
+	for _, proc := range procs {
+		if skip[proc.Pid] || skipKernelThread(proc.Pid, opts) {
+			continue
+		}
+		procName, err := proc.Name()
+		if err != nil {
+			continue
+		}
+		if opts.NameExact && procName != name || !opts.NameExact && !strings.Contains(procName, name) {
+			continue
+		}
+		if isExcluded(proc, procName, opts.Exclude) {
+			excluded++
+			continue
+		}
+		pids = append(pids, proc.Pid)
 	}
 
-	// Find connections matching the port
-	var candidatePIDs []int32
-	for _, conn := range connections {
-		if conn.Laddr.Port == uint32(port) && conn.Status == "LISTEN" {
-			candidatePIDs = append(candidatePIDs, conn.Pid)
+	if len(pids) == 0 {
+		match := "matching name"
+		if opts.NameExact {
+			match = "named"
+		}
+		if excluded > 0 {
+			return nil, fmt.Errorf("no process found %s %q (%d excluded)", match, name, excluded)
 		}
+		return nil, fmt.Errorf("no process found %s %q", match, name)
 	}
 
-	if len(candidatePIDs) == 0 {
-		return 0, fmt.Errorf("no process found listening on port %d", port)
+	return pids, nil
+}
+
+// isExcluded reports whether a process's name or command line matches any
+// of the --exclude patterns. The command line is only read when the name
+// alone doesn't settle it.
+func isExcluded(proc *process.Process, name string, patterns []*regexp.Regexp) bool {
+	if len(patterns) == 0 {
+		return false
 	}
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	cmdline, err := proc.Cmdline()
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if pattern.MatchString(cmdline) {
+			return true
+		}
+	}
+	return false
+}
 
-	// Return the first valid PID
-	for _, pid := range candidatePIDs {
-		if pid > 0 {
-			// Verify the process exists
-			if _, err := process.NewProcess(pid); err == nil {
-				return pid, nil
-			}
+// selfAndAncestors returns inspektor's own PID and those of the processes
+// above it, up to but not including init
+func selfAndAncestors() map[int32]bool {
+	pids := make(map[int32]bool)
+	pid := int32(os.Getpid())
+	for pid > 1 && !pids[pid] {
+		pids[pid] = true
+		proc, err := process.NewProcess(pid)
+		if err != nil {
+			break
+		}
+		if pid, err = proc.Ppid(); err != nil {
+			break
 		}
 	}
+	return pids
+}
 
-	return 0, fmt.Errorf("no valid process found listening on port %d", port)
+// inspectionOutput is the JSON shape of a single inspection: the collected
+// data with its findings alongside. Timestamp is only set for JSON Lines,
+// where each line must stand on its own. Saved to a file, it is a snapshot
+// that InspectSnapshot can analyze later.
+type inspectionOutput struct {
+	SchemaVersion int        `json:"schema_version"`
+	Timestamp     *time.Time `json:"timestamp,omitempty"`
+	*models.InspectionData
+	Findings      []models.Finding     `json:"findings"`
+	FindingCounts models.FindingCounts `json:"finding_counts"`
 }
 
-func (i *Inspector) outputJSON(data *models.InspectionData, warnings []string) error {
-	output := struct {
-		*models.InspectionData
-		Warnings []string `json:"warnings"`
-	}{
+func inspectionDocument(data *models.InspectionData, findings []models.Finding) inspectionOutput {
+	if findings == nil {
+		findings = []models.Finding{}
+	}
+	return inspectionOutput{
+		SchemaVersion:  SchemaVersion,
 		InspectionData: data,
-		Warnings:       warnings,
+		Findings:       findings,
+		FindingCounts:  models.CountFindings(findings),
 	}
+}
 
-	jsonData, err := json.MarshalIndent(output, "", "  ")
+// outputMetric prints a single field of data for --metric-only. Of the
+// analysis only the health score is worked out, so no AI provider is called.
+func outputMetric(data *models.InspectionData, field string) error {
+	if !data.TimedOut {
+		scoreHealth(data)
+	}
+	value, err := models.MetricValue(data, field)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return err
 	}
-
-	fmt.Println(string(jsonData))
+	fmt.Println(value)
 	return nil
 }
 
-func (i *Inspector) collectProcessInfo(proc *process.Process) (*models.ProcessInfo, error) {
-	name, _ := proc.Name()
-	exe, _ := proc.Exe()
-	cmdline, _ := proc.Cmdline()
-	cwd, _ := proc.Cwd()
-	status, _ := proc.Status()
-
-	// CPU and Memory usage
-	cpuPercent, _ := proc.CPUPercent()
-	memInfo, _ := proc.MemoryInfo()
-	memPercent, _ := proc.MemoryPercent()
-
-	// Process times
-	createTime, _ := proc.CreateTime()
-
-	// Connections and open files
-	connections, _ := proc.Connections()
-	openFiles, _ := proc.OpenFiles()
-
-	// Child processes
-	children, _ := proc.Children()
-
-	return &models.ProcessInfo{
-		PID:           proc.Pid,
-		Name:          name,
-		Executable:    exe,
-		CommandLine:   cmdline,
-		WorkingDir:    cwd,
-		Status:        status,
-		CPUPercent:    cpuPercent,
-		MemoryRSS:     memInfo.RSS,
-		MemoryVMS:     memInfo.VMS,
-		MemoryPercent: memPercent,
-		CreateTime:    time.Unix(createTime/1000, 0),
-		Connections:   len(connections),
-		OpenFiles:     len(openFiles),
-		Children:      len(children),
-	}, nil
-}
-
-func (i *Inspector) collectSystemInfo() (*models.SystemInfo, error) {
-	// CPU information
-	cpuInfo, err := cpu.Info()
-	if err != nil {
-		return nil, err
+func (i *Inspector) outputJSON(data *models.InspectionData, findings []models.Finding, opts Options) error {
+	doc := inspectionDocument(data, findings)
+	if opts.JSONLines {
+		now := opts.now()
+		doc.Timestamp = &now
 	}
+	return writeJSON(doc, opts)
+}
 
-	cpuPercent, err := cpu.Percent(time.Second, false)
+// writeJSON prints v as an indented document, or as a single compact line in
+// JSON Lines and compact mode. Stdout is unbuffered, so each line reaches
+// downstream consumers as soon as it is written.
+func writeJSON(v any, opts Options) error {
+	var jsonData []byte
+	var err error
+	if opts.JSONLines || opts.JSONCompact {
+		jsonData, err = json.Marshal(v)
+	} else {
+		jsonData, err = json.MarshalIndent(v, "", "  ")
+	}
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	// Memory information
-	memInfo, err := mem.VirtualMemory()
-	if err != nil {
-		return nil, err
-	}
-
-	return &models.SystemInfo{
-		CPUCores:      len(cpuInfo),
-		CPUModel:      cpuInfo[0].ModelName,
-		CPUUsage:      cpuPercent[0],
-		MemoryTotal:   memInfo.Total,
-		MemoryUsed:    memInfo.Used,
-		MemoryPercent: memInfo.UsedPercent,
-		MemoryFree:    memInfo.Free,
-	}, nil
+	fmt.Println(string(jsonData))
+	return nil
 }
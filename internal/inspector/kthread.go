@@ -0,0 +1,65 @@
+package inspector
+
+import (
+	"context"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/process"
+)
+
+// collectKernelThread collects what a kernel thread has: its state, where it
+// waits and the CPU it uses. It runs no program, so the executable, command
+// line, memory, files and sockets a process report is built from are all
+// empty for it and not read at all.
+func (c *Collector) collectKernelThread(ctx context.Context, proc *process.Process) (*models.ProcessInfo, error) {
+	var failures collectionErrors
+	name, err := proc.NameWithContext(ctx)
+	failures.add("name", err)
+	status, err := proc.StatusWithContext(ctx)
+	failures.add("status", err)
+	username, err := proc.UsernameWithContext(ctx)
+	failures.add("username", err)
+
+	cpuPercent, err := sampleCPU(ctx, proc, c.opts)
+	failures.add("cpu_percent", err)
+	cpuTimes, err := proc.TimesWithContext(ctx)
+	failures.add("cpu_times", err)
+	if err != nil {
+		cpuTimes = &cpu.TimesStat{}
+	}
+	scheduler, ok := readScheduler(proc.Pid)
+	failures.unavailable("scheduler", ok)
+	wait, ok := readWaitState(proc.Pid)
+	failures.unavailable("wait_channel", ok)
+
+	createTime, err := proc.CreateTimeWithContext(ctx)
+	failures.add("create_time", err)
+	startedAt := startTime(createTime, c.host.bootTime(ctx))
+	if c.opts.UTC && !startedAt.IsZero() {
+		startedAt = startedAt.UTC()
+	}
+
+	return &models.ProcessInfo{
+		PID:              proc.Pid,
+		Name:             name,
+		Status:           status,
+		Username:         username,
+		KernelThread:     true,
+		CPUPercent:       cpuPercent,
+		CPUTimeUser:      cpuTimes.User,
+		CPUTimeSystem:    cpuTimes.System,
+		Scheduler:        scheduler,
+		Wait:             wait,
+		CreateTime:       startedAt,
+		NumThreads:       1,
+		CollectionErrors: failures,
+	}, nil
+}
+
+// skipKernelThread reports whether --no-kernel-threads leaves pid out of a
+// ranking or batch
+func skipKernelThread(pid int32, opts Options) bool {
+	return opts.NoKernelThreads && isKernelThread(pid)
+}
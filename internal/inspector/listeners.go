@@ -0,0 +1,191 @@
+package inspector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+)
+
+// portListener is one socket listening on a queried port
+type portListener struct {
+	PID     int32
+	Name    string
+	Proto   string // "tcp" or "udp"
+	Family  string // "IPv4" or "IPv6"
+	Address string
+}
+
+func (l portListener) String() string {
+	return fmt.Sprintf("PID %d (%s) %s/%s on %s", l.PID, l.Name, l.Proto, l.Family, l.Address)
+}
+
+// MultipleListenersError is returned when several processes listen on the
+// queried port, as SO_REUSEPORT servers or separate IPv4 and IPv6 daemons
+// do, rather than guessing which one was meant
+type MultipleListenersError struct {
+	Query     PortQuery
+	listeners []portListener
+}
+
+func (e *MultipleListenersError) Error() string {
+	lines := make([]string, len(e.listeners))
+	for idx, listener := range e.listeners {
+		lines[idx] = "  " + listener.String()
+	}
+	return fmt.Sprintf("%d processes listen on %s:\n%s\ninspect one by its PID, or use --all to inspect them all",
+		len(e.PIDs()), e.Query, strings.Join(lines, "\n"))
+}
+
+// PIDs are the distinct listening processes, in the order found
+func (e *MultipleListenersError) PIDs() []int32 {
+	return listenerPIDs(e.listeners)
+}
+
+// findListeners returns every socket listening on the query's port whose
+// process still exists. A process bound on both IPv4 and IPv6 appears once
+// per socket.
+func (i *Inspector) findListeners(query PortQuery) ([]portListener, error) {
+	kind := "inet"
+	switch query.Proto {
+	case "":
+	case "tcp", "udp":
+		kind = query.Proto
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q (expected tcp or udp)", query.Proto)
+	}
+
+	sockets, ok := readListeners(query)
+	if !ok {
+		var err error
+		if sockets, err = connectionListeners(kind, query); err != nil {
+			return nil, err
+		}
+	}
+
+	var listeners []portListener
+	names := make(map[int32]string)
+	for _, listener := range sockets {
+		name, known := names[listener.PID]
+		if !known {
+			proc, err := process.NewProcess(listener.PID)
+			if err != nil {
+				continue
+			}
+			name, _ = proc.Name()
+			names[listener.PID] = name
+		}
+		listener.Name = name
+		listeners = append(listeners, listener)
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no process found listening on %s", query)
+	}
+	return listeners, nil
+}
+
+// connectionListeners finds the listeners in gopsutil's connection list,
+// where platforms without /proc/net have them. The list merges sockets
+// bound to the same address, so SO_REUSEPORT siblings show up as one.
+func connectionListeners(kind string, query PortQuery) ([]portListener, error) {
+	// Get network connections for the requested protocols
+	connections, err := net.Connections(kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network connections: %w", err)
+	}
+
+	var listeners []portListener
+	for _, conn := range connections {
+		if !isListener(conn, query) || conn.Pid <= 0 {
+			continue
+		}
+		listener := portListener{PID: conn.Pid, Proto: "tcp", Family: "IPv4", Address: conn.Laddr.IP}
+		if conn.Type == syscall.SOCK_DGRAM {
+			listener.Proto = "udp"
+		}
+		if conn.Family == syscall.AF_INET6 {
+			listener.Family = "IPv6"
+		}
+		if !slices.Contains(listeners, listener) {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners, nil
+}
+
+// listenerPIDs collapses listeners to their distinct processes
+func listenerPIDs(listeners []portListener) []int32 {
+	var pids []int32
+	for _, listener := range listeners {
+		if !slices.Contains(pids, listener.PID) {
+			pids = append(pids, listener.PID)
+		}
+	}
+	return pids
+}
+
+// chooseListener asks on the terminal which of several listening processes
+// to inspect. The list and prompt go to stderr, as stdout may be paged.
+func chooseListener(multiple *MultipleListenersError) (int32, error) {
+	pids := multiple.PIDs()
+	fmt.Fprintf(os.Stderr, "%d processes listen on %s:\n", len(pids), multiple.Query)
+	for idx, pid := range pids {
+		var sockets []string
+		var name string
+		for _, listener := range multiple.listeners {
+			if listener.PID == pid {
+				name = listener.Name
+				sockets = append(sockets, fmt.Sprintf("%s/%s on %s", listener.Proto, listener.Family, listener.Address))
+			}
+		}
+		fmt.Fprintf(os.Stderr, "  [%d] PID %d (%s): %s\n", idx+1, pid, name, strings.Join(sockets, ", "))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stderr, "Inspect which one? [1-%d]: ", len(pids))
+		line, err := reader.ReadString('\n')
+		if choice, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && choice >= 1 && choice <= len(pids) {
+			return pids[choice-1], nil
+		}
+		if err != nil {
+			return 0, multiple
+		}
+	}
+}
+
+// interactive reports whether the user can be asked to choose: the prompt
+// goes to stderr and the answer comes from stdin, so both must be a terminal
+func interactive() bool {
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stderr.Fd())
+}
+
+// inspectListeners settles which of several processes listening on a port
+// to inspect: all of them with --all, the user's pick on a terminal, and
+// otherwise none, failing with the list so the choice isn't made silently
+func (i *Inspector) inspectListeners(multiple *MultipleListenersError, opts Options) error {
+	switch {
+	case opts.All:
+		pids := multiple.PIDs()
+		targets := make([]string, len(pids))
+		for idx, pid := range pids {
+			targets[idx] = fmt.Sprintf("pid=%d", pid)
+		}
+		return i.InspectBatch(targets, opts)
+	case interactive():
+		pid, err := chooseListener(multiple)
+		if err != nil {
+			return err
+		}
+		return i.InspectWithOptions(pid, opts)
+	}
+	return multiple
+}
@@ -0,0 +1,79 @@
+package inspector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"inspektor/internal/models"
+)
+
+// maxFileNameLength bounds the process-name part of a snapshot file name
+const maxFileNameLength = 64
+
+// outputDir writes each inspection of a batch to its own file, for
+// --output-dir. The files are the JSON documents --json prints, so each can
+// be analyzed again with --from-snapshot.
+type outputDir struct {
+	path    string
+	written int
+}
+
+// newOutputDir creates path, and its parents, if needed
+func newOutputDir(path string) (*outputDir, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return &outputDir{path: path}, nil
+}
+
+// write saves one inspection as <pid>-<name>.json
+func (d *outputDir) write(data *models.InspectionData, findings []models.Finding) error {
+	name := fmt.Sprintf("%d-%s.json", data.Process.PID, safeFileName(data.Process.Name, "process"))
+	return d.save(name, inspectionDocument(data, findings))
+}
+
+// save writes v as an indented JSON document to name within the directory
+func (d *outputDir) save(name string, v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(d.path, name), append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	d.written++
+	return nil
+}
+
+// summary reports how many files were written, and where
+func (d *outputDir) summary() string {
+	files := "files"
+	if d.written == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("Wrote %d %s to %s\n", d.written, files, d.path)
+}
+
+// safeFileName keeps letters, digits, dots, dashes and underscores of name,
+// replacing anything else (path separators included) with an underscore. A
+// name with nothing usable left falls back to fallback.
+func safeFileName(name, fallback string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+	safe = strings.Trim(safe, "._")
+	if len(safe) > maxFileNameLength {
+		safe = safe[:maxFileNameLength]
+	}
+	if safe == "" {
+		return fallback
+	}
+	return safe
+}
@@ -0,0 +1,80 @@
+package inspector
+
+import (
+	"context"
+	"time"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/process"
+)
+
+// energyCounter is one RAPL package's cumulative energy, in microjoules, and
+// the value it wraps around at
+type energyCounter struct {
+	energy, wrap uint64
+}
+
+// powerSample is a reading of the counters a power estimate is worked out
+// from: the process's CPU time, the host's busy CPU time (all cores), and
+// the packages' energy where readable
+type powerSample struct {
+	process, host float64
+	energy        []energyCounter
+	at            time.Time
+}
+
+// readPowerSample reads the CPU time counters, and the energy counters where
+// the platform has them and they are readable (RAPL is root-only on most
+// kernels)
+func readPowerSample(ctx context.Context, proc *process.Process) (powerSample, bool) {
+	times, err := proc.TimesWithContext(ctx)
+	if err != nil {
+		return powerSample{}, false
+	}
+	host, err := cpu.TimesWithContext(ctx, false)
+	if err != nil || len(host) == 0 {
+		return powerSample{}, false
+	}
+	busy := host[0].User + host[0].System + host[0].Nice + host[0].Irq + host[0].Softirq
+	energy, _ := readEnergy()
+	return powerSample{
+		process: times.User + times.System,
+		host:    busy,
+		energy:  energy,
+		at:      time.Now(),
+	}, true
+}
+
+func (s powerSample) takenAt() time.Time { return s.at }
+
+// estimatePower apportions the draw between two readings to the process by
+// its share of the busy CPU time; nil when the window was too short or the
+// host was idle throughout
+func estimatePower(before, after powerSample) *models.PowerEstimate {
+	elapsed := after.at.Sub(before.at)
+	hostBusy := after.host - before.host
+	if elapsed < minRateWindow || hostBusy <= 0 {
+		return nil
+	}
+	share := min(max(after.process-before.process, 0)/hostBusy, 1)
+	estimate := &models.PowerEstimate{CPUShare: share * 100, Source: models.PowerSourceCPUTime}
+
+	if len(before.energy) == 0 || len(before.energy) != len(after.energy) {
+		return estimate
+	}
+	var joules float64
+	for index, counter := range after.energy {
+		spent := counter.energy - before.energy[index].energy
+		if counter.energy < before.energy[index].energy {
+			spent = counter.wrap - before.energy[index].energy + counter.energy
+		}
+		joules += float64(spent) / 1e6
+	}
+	packageWatts := joules / elapsed.Seconds()
+	watts := packageWatts * share
+	estimate.PackageWatts, estimate.Watts = &packageWatts, &watts
+	estimate.Source = models.PowerSourceRAPL
+	return estimate
+}
@@ -0,0 +1,138 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// ProbeLimits are what a probed process must stay within; a zero field is
+// not checked, and then costs nothing to probe
+type ProbeLimits struct {
+	// CPUPercent is per core, as sampled over Options.CPUInterval
+	CPUPercent float64
+	// MemoryPercent is of system memory, MemoryMB the resident set
+	MemoryPercent float32
+	MemoryMB      uint64
+	OpenFiles     int
+	Threads       int
+}
+
+// unhealthyStates are the process states a probe fails on: zombie, stopped,
+// traced and dead. Running and sleeping processes, including those in a
+// short uninterruptible I/O wait, pass.
+var unhealthyStates = map[string]string{
+	"Z": "zombie",
+	"T": "stopped",
+	"t": "stopped by a tracer",
+	"X": "dead",
+}
+
+// Probe checks, for use as a container readiness or liveness probe, that
+// target (a bare PID or a pid=, port= or name= selector) resolves to running
+// processes that all stay within limits. It prints nothing unless
+// opts.Verbose is set, and returns an error naming the first problem.
+// opts.Timeout bounds the whole probe, the process lookup included, so a
+// hung collection fails it rather than hanging.
+func (i *Inspector) Probe(target string, limits ProbeLimits, opts Options) error {
+	ctx, cancel := opts.inspectionContext(context.Background())
+	defer cancel()
+
+	_, err := withDeadline(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, i.probe(ctx, target, limits, opts)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", opts.Timeout)
+	}
+
+	if opts.Verbose {
+		if err != nil {
+			fmt.Printf("unhealthy: %v\n", err)
+		} else {
+			fmt.Println("healthy")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("probe failed: %w", err)
+	}
+	return nil
+}
+
+// probe resolves target and checks every process it names
+func (i *Inspector) probe(ctx context.Context, target string, limits ProbeLimits, opts Options) error {
+	pids, err := i.resolveTarget(target, opts)
+	if err != nil {
+		return err
+	}
+	for _, pid := range pids {
+		if err := probeProcess(ctx, pid, limits, opts); err != nil {
+			return fmt.Errorf("PID %d: %w", pid, err)
+		}
+	}
+	return nil
+}
+
+// probeProcess checks one process's state and only the metrics limits asks
+// about, so an unlimited probe reads nothing but the state
+func probeProcess(ctx context.Context, pid int32, limits ProbeLimits, opts Options) error {
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return err
+	}
+	status, err := proc.StatusWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read state: %w", err)
+	}
+	if state, unhealthy := unhealthyStates[status]; unhealthy {
+		return fmt.Errorf("process is %s", state)
+	}
+
+	if limits.CPUPercent > 0 {
+		percent, err := sampleCPU(ctx, proc, opts)
+		if err != nil {
+			return fmt.Errorf("failed to read CPU usage: %w", err)
+		}
+		if percent > limits.CPUPercent {
+			return fmt.Errorf("CPU usage %.1f%% exceeds %.1f%%", percent, limits.CPUPercent)
+		}
+	}
+	if limits.MemoryPercent > 0 {
+		percent, err := proc.MemoryPercentWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read memory usage: %w", err)
+		}
+		if percent > limits.MemoryPercent {
+			return fmt.Errorf("memory usage %.1f%% exceeds %.1f%%", percent, limits.MemoryPercent)
+		}
+	}
+	if limits.MemoryMB > 0 {
+		memInfo, err := proc.MemoryInfoWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read memory usage: %w", err)
+		}
+		if rss := memInfo.RSS / (1024 * 1024); rss > limits.MemoryMB {
+			return fmt.Errorf("RSS %d MB exceeds %d MB", rss, limits.MemoryMB)
+		}
+	}
+	if limits.OpenFiles > 0 {
+		fds, err := proc.NumFDsWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count open files: %w", err)
+		}
+		if int(fds) > limits.OpenFiles {
+			return fmt.Errorf("%d open files exceed %d", fds, limits.OpenFiles)
+		}
+	}
+	if limits.Threads > 0 {
+		threads, err := proc.NumThreadsWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count threads: %w", err)
+		}
+		if int(threads) > limits.Threads {
+			return fmt.Errorf("%d threads exceed %d", threads, limits.Threads)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,880 @@
+package inspector
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	stdnet "net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+// readProcStatus parses /proc/<pid>/status into a key/value map
+func readProcStatus(pid int32) (map[string]string, error) {
+	return readKeyValues(fmt.Sprintf("/proc/%d/status", pid))
+}
+
+// readKeyValues parses a procfs file made of "Key: value" lines
+func readKeyValues(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if found {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return fields, scanner.Err()
+}
+
+// parseKB converts a "1234 kB" status value to bytes
+func parseKB(value string) (uint64, bool) {
+	kb, err := strconv.ParseUint(strings.TrimSuffix(value, " kB"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return kb * 1024, true
+}
+
+// memoryBreakdown splits the process's memory into what it shares with other
+// processes, what is its own, and what has been swapped out
+type memoryBreakdown struct {
+	shared  uint64
+	private uint64
+	swap    uint64
+}
+
+// readMemoryBreakdown sums the process's mappings from smaps_rollup (Linux
+// 4.14+), which needs the same access as reading its memory maps
+func readMemoryBreakdown(pid int32) (memoryBreakdown, bool) {
+	rollup, err := readKeyValues(fmt.Sprintf("/proc/%d/smaps_rollup", pid))
+	if err != nil {
+		return memoryBreakdown{}, false
+	}
+
+	kb := func(keys ...string) uint64 {
+		var total uint64
+		for _, key := range keys {
+			value, _ := parseKB(rollup[key])
+			total += value
+		}
+		return total
+	}
+
+	if _, ok := rollup["Rss"]; !ok {
+		return memoryBreakdown{}, false
+	}
+	return memoryBreakdown{
+		shared:  kb("Shared_Clean", "Shared_Dirty"),
+		private: kb("Private_Clean", "Private_Dirty"),
+		swap:    kb("Swap"),
+	}, true
+}
+
+// readNetCounters totals received and transmitted bytes across the non-loopback
+// interfaces visible in the process's network namespace. Linux has no
+// per-process byte counters, so this is per-process only when the process
+// has its own namespace (e.g. a container); otherwise it is host-wide.
+func readNetCounters(pid int32) (rx, tx uint64, ok bool) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		iface, counters, found := strings.Cut(scanner.Text(), ":")
+		if !found || strings.TrimSpace(iface) == "lo" {
+			continue
+		}
+		// Receive bytes is the first column, transmit bytes the ninth
+		fields := strings.Fields(counters)
+		if len(fields) < 9 {
+			continue
+		}
+		rxBytes, _ := strconv.ParseUint(fields[0], 10, 64)
+		txBytes, _ := strconv.ParseUint(fields[8], 10, 64)
+		rx += rxBytes
+		tx += txBytes
+	}
+
+	return rx, tx, scanner.Err() == nil
+}
+
+// descriptorSize stats an open descriptor through /proc, which works even when
+// the file it refers to has been deleted
+func descriptorSize(pid int32, fd uint64) (uint64, bool) {
+	info, err := os.Stat(fmt.Sprintf("/proc/%d/fd/%d", pid, fd))
+	if err != nil {
+		return 0, false
+	}
+	return uint64(info.Size()), true
+}
+
+// readPeakRSS returns the process's resident set high-water mark (VmHWM)
+func readPeakRSS(pid int32) (uint64, bool) {
+	status, err := readProcStatus(pid)
+	if err != nil {
+		return 0, false
+	}
+	return parseKB(status["VmHWM"])
+}
+
+// readShmem returns the process's resident shared memory (RssShmem, Linux
+// 4.5+), readable without access to its memory maps
+func readShmem(pid int32) (uint64, bool) {
+	status, err := readProcStatus(pid)
+	if err != nil {
+		return 0, false
+	}
+	return parseKB(status["RssShmem"])
+}
+
+// readContextSwitches sums the voluntary and involuntary context switches of
+// every thread: /proc/<pid>/status only counts the main thread's
+func readContextSwitches(pid int32) (voluntary, involuntary uint64, ok bool) {
+	tasks, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, task := range tasks {
+		status, err := readKeyValues(fmt.Sprintf("/proc/%d/task/%s/status", pid, task.Name()))
+		if err != nil {
+			// The thread exited since the listing
+			continue
+		}
+		v, _ := strconv.ParseUint(status["voluntary_ctxt_switches"], 10, 64)
+		n, _ := strconv.ParseUint(status["nonvoluntary_ctxt_switches"], 10, 64)
+		voluntary += v
+		involuntary += n
+		ok = true
+	}
+	return voluntary, involuntary, ok
+}
+
+// readThreads reads every thread's name, state and CPU time from
+// /proc/<pid>/task/<tid>/stat
+func readThreads(pid int32) (map[int32]threadTimes, bool) {
+	tasks, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return nil, false
+	}
+	threads := make(map[int32]threadTimes, len(tasks))
+	for _, task := range tasks {
+		tid, err := strconv.ParseInt(task.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		stat, ok := readTaskStat(pid, int32(tid))
+		if !ok {
+			// The thread exited since the listing
+			continue
+		}
+		threads[int32(tid)] = stat.threadTimes
+	}
+	return threads, len(threads) > 0
+}
+
+// readTaskStat parses one thread's /proc/<pid>/task/<tid>/stat
+func readTaskStat(pid, tid int32) (taskStat, bool) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/stat", pid, tid))
+	if err != nil {
+		return taskStat{}, false
+	}
+	// The name is in parentheses and may itself contain them
+	start, end := strings.IndexByte(string(content), '('), strings.LastIndexByte(string(content), ')')
+	if start < 0 || end < start {
+		return taskStat{}, false
+	}
+	// state, ppid, pgrp, session, tty_nr, tpgid, flags, minflt, cminflt,
+	// majflt, cmajflt, utime, stime, cutime, cstime, priority, nice,
+	// num_threads, itrealvalue, starttime, ... processor, rt_priority and
+	// policy at 36-38
+	fields := strings.Fields(string(content[end+1:]))
+	if len(fields) < 13 {
+		return taskStat{}, false
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	stat := taskStat{
+		threadTimes: threadTimes{
+			name:   string(content[start+1 : end]),
+			state:  fields[0],
+			user:   float64(utime) / cpu.ClocksPerSec,
+			system: float64(stime) / cpu.ClocksPerSec,
+		},
+		processor: -1,
+	}
+	if len(fields) > 19 {
+		stat.priority, _ = strconv.Atoi(fields[15])
+		stat.nice, _ = strconv.Atoi(fields[16])
+		ticks, _ := strconv.ParseUint(fields[19], 10, 64)
+		stat.sinceBoot = ticksDuration(ticks, cpu.ClocksPerSec)
+	}
+	if len(fields) > 38 {
+		stat.processor, _ = strconv.Atoi(fields[36])
+		stat.rtPriority, _ = strconv.Atoi(fields[37])
+		stat.policy, _ = strconv.Atoi(fields[38])
+	}
+	return stat, true
+}
+
+// schedulingPolicies names the policy numbers of sched_getscheduler(2); 4
+// is unused
+var schedulingPolicies = map[int]string{
+	0: models.SchedOther,
+	1: models.SchedFIFO,
+	2: models.SchedRR,
+	3: models.SchedBatch,
+	5: models.SchedIdle,
+	6: models.SchedDeadline,
+}
+
+// readScheduler reads the scheduling policy of the process's main thread,
+// the policy sched_getscheduler(2) reports for the PID, from its stat
+func readScheduler(pid int32) (*models.Scheduler, bool) {
+	stat, ok := readTaskStat(pid, pid)
+	if !ok {
+		return nil, false
+	}
+	policy, known := schedulingPolicies[stat.policy]
+	if !known {
+		policy = fmt.Sprintf("policy %d", stat.policy)
+	}
+	return &models.Scheduler{Policy: policy, RTPriority: stat.rtPriority, Nice: stat.nice}, true
+}
+
+// readWaitState reads every thread's wait channel and finds the one most of
+// them are blocked in. The kernel shows "0" for a running thread, and for
+// every thread of a process the caller may not trace; nil then, with
+// nothing to report.
+func readWaitState(pid int32) (*models.WaitState, bool) {
+	tasks, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return nil, false
+	}
+	state := &models.WaitState{}
+	channels := make(map[string]int)
+	for _, task := range tasks {
+		content, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%s/wchan", pid, task.Name()))
+		if err != nil {
+			// The thread exited since the listing
+			continue
+		}
+		state.Total++
+		channel := strings.TrimSpace(string(content))
+		if channel == "" || channel == "0" {
+			continue
+		}
+		channels[channel]++
+		if models.LockWait(channel) {
+			state.OnLocks++
+		}
+	}
+	for channel, threads := range channels {
+		if threads > state.Threads || threads == state.Threads && channel < state.Channel {
+			state.Channel, state.Threads = channel, threads
+		}
+	}
+	if state.Threads == 0 {
+		return nil, true
+	}
+	return state, true
+}
+
+// readThread reads one thread of pid: its stat, and its context switches
+// from /proc/<pid>/task/<tid>/status. False when pid has no such thread.
+func readThread(pid, tid int32) (taskStat, bool) {
+	stat, ok := readTaskStat(pid, tid)
+	if !ok {
+		return taskStat{}, false
+	}
+	if status, err := readKeyValues(fmt.Sprintf("/proc/%d/task/%d/status", pid, tid)); err == nil {
+		stat.voluntary, _ = strconv.ParseUint(status["voluntary_ctxt_switches"], 10, 64)
+		stat.involuntary, _ = strconv.ParseUint(status["nonvoluntary_ctxt_switches"], 10, 64)
+	}
+	return stat, true
+}
+
+// readThreadGroup returns the process a thread belongs to. /proc/<tid>
+// resolves for any thread, though only processes are listed in /proc.
+func readThreadGroup(tid int32) (int32, bool) {
+	status, err := readProcStatus(tid)
+	if err != nil {
+		return 0, false
+	}
+	tgid, err := strconv.ParseInt(status["Tgid"], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(tgid), true
+}
+
+// readEnergy reads every CPU package's RAPL energy counter from the
+// powercap sysfs tree. Subzones (cores, uncore, DRAM) are part of their
+// package and skipped.
+func readEnergy() ([]energyCounter, bool) {
+	zones, _ := filepath.Glob("/sys/class/powercap/intel-rapl:*")
+	var counters []energyCounter
+	for _, zone := range zones {
+		if strings.Count(filepath.Base(zone), ":") != 1 {
+			continue
+		}
+		energy, err := readUint(filepath.Join(zone, "energy_uj"))
+		if err != nil {
+			return nil, false
+		}
+		wrap, err := readUint(filepath.Join(zone, "max_energy_range_uj"))
+		if err != nil {
+			return nil, false
+		}
+		counters = append(counters, energyCounter{energy: energy, wrap: wrap})
+	}
+	return counters, len(counters) > 0
+}
+
+// readHandles counts Windows handles; open files cover descriptors here
+func readHandles(pid int32) (uint32, bool) {
+	return 0, false
+}
+
+// openExecutable opens the image the process runs, through
+// /proc/<pid>/exe, which still reaches it after the file was deleted or
+// replaced on disk
+func openExecutable(pid int32, path string) (*os.File, error) {
+	return os.Open(fmt.Sprintf("/proc/%d/exe", pid))
+}
+
+// statExecutable stats the file now at the executable's path, looked up
+// through the process's own root so a container's binary is checked inside
+// the container, and the image the process runs, which /proc/<pid>/exe
+// still reaches after the file was replaced
+func statExecutable(pid int32, path string) (onDisk, running os.FileInfo, err error) {
+	onDisk, err = os.Stat(fmt.Sprintf("/proc/%d/root%s", pid, path))
+	if err != nil {
+		return nil, nil, err
+	}
+	running, _ = os.Stat(fmt.Sprintf("/proc/%d/exe", pid))
+	return onDisk, running, nil
+}
+
+// unsupportedReaders is empty: every platform reader exists on Linux
+var unsupportedReaders = map[string]bool{}
+
+// limitRows maps the /proc/<pid>/limits rows worth reporting, the ones a
+// process can realistically run into, to rlimit names
+var limitRows = []struct {
+	row, name string
+}{
+	{"Max open files", "nofile"},
+	{"Max processes", "nproc"},
+	{"Max locked memory", "memlock"},
+	{"Max stack size", "stack"},
+	{"Max cpu time", "cpu"},
+}
+
+// readLimits parses /proc/<pid>/limits, filling in stack and locked memory
+// usage from its status. gopsutil's rlimits are int32 and fail outright on
+// larger values, so the file is read directly. Usage of nproc counts every task of the user and
+// isn't gathered; nofile and cpu usage are left to the caller.
+func readLimits(pid int32) ([]models.Limit, bool) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		return nil, false
+	}
+	status, _ := readProcStatus(pid)
+
+	parse := func(value string) *uint64 {
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil // "unlimited"
+		}
+		return &n
+	}
+
+	var limits []models.Limit
+	for _, line := range strings.Split(string(content), "\n") {
+		for _, row := range limitRows {
+			rest, found := strings.CutPrefix(line, row.row)
+			fields := strings.Fields(rest)
+			if !found || len(fields) < 2 {
+				continue
+			}
+			limit := models.Limit{Resource: row.name, Soft: parse(fields[0]), Hard: parse(fields[1])}
+			switch row.name {
+			case "stack":
+				if used, ok := parseKB(status["VmStk"]); ok {
+					limit.Used = &used
+				}
+			case "memlock":
+				if used, ok := parseKB(status["VmLck"]); ok {
+					limit.Used = &used
+				}
+			}
+			limits = append(limits, limit)
+		}
+	}
+
+	return limits, len(limits) > 0
+}
+
+// containerIDPattern matches the 64-hex container ID that Docker, containerd
+// and CRI-O embed in cgroup paths (e.g. /docker/<id> or docker-<id>.scope)
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// readContainerID returns the ID of the container the process runs in, or
+// false when its cgroup doesn't belong to one
+func readContainerID(pid int32) (string, bool) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", false
+	}
+	id := containerIDPattern.FindString(string(content))
+	return id, id != ""
+}
+
+// cgroupMemoryFiles are the limit, usage and stat files of a memory cgroup,
+// and the memory.stat key counting its inactive page cache, under cgroup v1
+// and v2
+type cgroupMemoryFiles struct {
+	base, limit, usage, inactive string
+}
+
+var (
+	cgroupV1Memory = cgroupMemoryFiles{"memory", "memory.limit_in_bytes", "memory.usage_in_bytes", "total_inactive_file"}
+	cgroupV2Memory = cgroupMemoryFiles{"", "memory.max", "memory.current", "inactive_file"}
+)
+
+// unlimitedCgroupMemory is where cgroup v1 limits start meaning no limit:
+// it reports an unset one as the largest page-aligned int64
+const unlimitedCgroupMemory = 1 << 62
+
+// readCgroupMemory finds the memory cgroup limit the process is held to,
+// walking up from its group since a pod's or slice's limit binds every
+// group below it. It returns nil and true when no group on the way has a
+// limit, and false when the process's cgroups can't be read.
+func readCgroupMemory(pid int32) (*models.CgroupMemory, bool) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, false
+	}
+
+	// Lines are hierarchy-ID:controllers:path; the v1 memory controller
+	// wins over the v2 unified hierarchy in the hybrid layout, where it is
+	// the one enforcing limits
+	var files cgroupMemoryFiles
+	var path string
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if slices.Contains(strings.Split(parts[1], ","), "memory") {
+			files, path = cgroupV1Memory, parts[2]
+			break
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			files, path = cgroupV2Memory, parts[2]
+		}
+	}
+	if path == "" {
+		return nil, true
+	}
+
+	var binding *models.CgroupMemory
+	for dir := path; ; dir = filepath.Dir(dir) {
+		full := filepath.Join(cgroupRoot, files.base, dir)
+		if limit, ok := readCgroupLimit(filepath.Join(full, files.limit)); ok && (binding == nil || limit < binding.Limit) {
+			if usage, err := readUint(filepath.Join(full, files.usage)); err == nil {
+				binding = &models.CgroupMemory{Path: dir, Usage: usage, WorkingSet: usage, Limit: limit}
+				if inactive, ok := readMemoryStat(filepath.Join(full, "memory.stat"), files.inactive); ok && inactive < usage {
+					binding.WorkingSet = usage - inactive
+				}
+			}
+		}
+		if dir == "/" || dir == "." {
+			break
+		}
+	}
+	return binding, true
+}
+
+// readCgroupLimit reads a memory limit file, false for "max" or an unset
+// v1 limit as well as for a missing file
+func readCgroupLimit(path string) (uint64, bool) {
+	limit, err := readUint(path)
+	if err != nil || limit >= unlimitedCgroupMemory {
+		return 0, false
+	}
+	return limit, true
+}
+
+// readMemoryStat reads one counter of a cgroup's memory.stat
+func readMemoryStat(path, key string) (uint64, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		name, value, found := strings.Cut(line, " ")
+		if found && name == key {
+			n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// readRoot reports the process's root directory when it isn't the host's.
+// The root link names a chroot's directory; a root that is "/" yet another
+// directory is either a container's, set up in its own mount namespace, or
+// a chroot outside inspektor's view. Reading the link needs the same access
+// as ptrace.
+func readRoot(pid int32) (string, error) {
+	link := fmt.Sprintf("/proc/%d/root", pid)
+	path, err := os.Readlink(link)
+	if err != nil {
+		return "", err
+	}
+	if path != "/" {
+		return path, nil
+	}
+
+	theirs, err := os.Stat(link)
+	if err != nil {
+		return "", err
+	}
+	ours, err := os.Stat("/")
+	if err != nil || os.SameFile(theirs, ours) {
+		return "", nil
+	}
+	theirNS, err := namespaceInode(pid, "mnt")
+	if err != nil {
+		return models.RootChrooted, nil
+	}
+	if ourNS, err := namespaceInode(int32(os.Getpid()), "mnt"); err == nil && ourNS != theirNS {
+		return models.RootContainer, nil
+	}
+	return models.RootChrooted, nil
+}
+
+// socketTables are the /proc/net tables listing the host's sockets, which
+// unlike gopsutil's connection list keep one row per socket even when
+// several are bound to the same address with SO_REUSEPORT
+var socketTables = []struct {
+	file, proto, family string
+	listen              string // the st column of a listening socket
+}{
+	{"tcp", "tcp", "IPv4", "0A"},
+	{"tcp6", "tcp", "IPv6", "0A"},
+	{"udp", "udp", "IPv4", "07"},
+	{"udp6", "udp", "IPv6", "07"},
+}
+
+// readListeners returns the sockets listening on the query's port, each
+// attributed to the process holding it. A socket inherited across fork (a
+// pre-forking server's workers) is one listener, attributed to the
+// ancestor that holds it.
+func readListeners(query PortQuery) ([]portListener, bool) {
+	sockets := make(map[string]portListener)
+	for _, table := range socketTables {
+		if query.Proto != "" && query.Proto != table.proto {
+			continue
+		}
+		content, err := os.ReadFile("/proc/net/" + table.file)
+		if err != nil {
+			return nil, false
+		}
+		for _, line := range strings.Split(string(content), "\n")[1:] {
+			// sl local_address rem_address st ... inode
+			fields := strings.Fields(line)
+			if len(fields) < 10 || fields[3] != table.listen {
+				continue
+			}
+			ip, port, ok := parseSocketAddress(fields[1])
+			if !ok || port != uint32(query.Port) || !matchesAddress(ip, query) {
+				continue
+			}
+			sockets[fields[9]] = portListener{Proto: table.proto, Family: table.family, Address: ip}
+		}
+	}
+	if len(sockets) == 0 {
+		return nil, true
+	}
+
+	holders, ok := socketHolders(sockets)
+	if !ok {
+		return nil, false
+	}
+	var listeners []portListener
+	for inode, listener := range sockets {
+		pids := holders[inode]
+		if len(pids) == 0 {
+			continue
+		}
+		listener.PID = pids[0]
+		for _, pid := range pids {
+			if ppid, ok := readPPID(pid); !ok || !slices.Contains(pids, ppid) {
+				listener.PID = pid
+				break
+			}
+		}
+		listeners = append(listeners, listener)
+	}
+	slices.SortFunc(listeners, func(a, b portListener) int {
+		return cmp.Or(cmp.Compare(a.PID, b.PID), cmp.Compare(a.Family, b.Family), cmp.Compare(a.Proto, b.Proto))
+	})
+	return listeners, true
+}
+
+// socketHolders maps each of the socket inodes to the processes holding
+// it, from their /proc/<pid>/fd links
+func socketHolders(sockets map[string]portListener) (map[string][]int32, bool) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, false
+	}
+	holders := make(map[string][]int32)
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		dir := fmt.Sprintf("/proc/%d/fd", pid)
+		fds, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(dir + "/" + fd.Name())
+			if err != nil {
+				continue
+			}
+			inode, found := strings.CutPrefix(target, "socket:[")
+			if !found {
+				continue
+			}
+			inode = strings.TrimSuffix(inode, "]")
+			if _, listening := sockets[inode]; listening && !slices.Contains(holders[inode], int32(pid)) {
+				holders[inode] = append(holders[inode], int32(pid))
+			}
+		}
+	}
+	return holders, true
+}
+
+// parseSocketAddress decodes a /proc/net address such as 0100007F:1F90,
+// whose IP is stored as host-order 32-bit words
+func parseSocketAddress(field string) (string, uint32, bool) {
+	hexIP, hexPort, found := strings.Cut(field, ":")
+	if !found {
+		return "", 0, false
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", 0, false
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+	ip := make(stdnet.IP, len(raw))
+	for word := 0; word < len(raw); word += 4 {
+		binary.BigEndian.PutUint32(ip[word:], binary.LittleEndian.Uint32(raw[word:]))
+	}
+	return ip.String(), uint32(port), true
+}
+
+// kthreaddPID is kthreadd, which starts every other kernel thread
+const kthreaddPID = 2
+
+// isKernelThread reports whether pid is kthreadd or one of the kernel
+// threads it started
+func isKernelThread(pid int32) bool {
+	if pid == kthreaddPID {
+		return true
+	}
+	ppid, ok := readPPID(pid)
+	return ok && ppid == kthreaddPID
+}
+
+// readPPID reads the parent PID from /proc/<pid>/stat
+func readPPID(pid int32) (int32, bool) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, false
+	}
+	end := strings.LastIndexByte(string(content), ')')
+	if end < 0 {
+		return 0, false
+	}
+	// state, ppid, ...
+	fields := strings.Fields(string(content[end+1:]))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	ppid, err := strconv.ParseInt(fields[1], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(ppid), true
+}
+
+// readSession reads the process group and session IDs from /proc/<pid>/stat.
+// The command name in parentheses may itself contain spaces or parentheses,
+// so fields are counted from the last ')'.
+func readSession(pid int32) (pgid, sid int32, ok bool) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, false
+	}
+	end := strings.LastIndexByte(string(content), ')')
+	if end < 0 {
+		return 0, 0, false
+	}
+	// state, ppid, pgrp, session, ...
+	fields := strings.Fields(string(content[end+1:]))
+	if len(fields) < 4 {
+		return 0, 0, false
+	}
+	group, groupErr := strconv.ParseInt(fields[2], 10, 32)
+	session, sessionErr := strconv.ParseInt(fields[3], 10, 32)
+	if groupErr != nil || sessionErr != nil {
+		return 0, 0, false
+	}
+	return int32(group), int32(session), true
+}
+
+// readOOMScore reads the OOM killer's score and adjustment, which any user
+// can read for any process
+func readOOMScore(pid int32) (*models.OOMScore, bool) {
+	score, err := readProcInt(pid, "oom_score")
+	if err != nil {
+		return nil, false
+	}
+	adj, err := readProcInt(pid, "oom_score_adj")
+	if err != nil {
+		return nil, false
+	}
+	return &models.OOMScore{Score: score, Adj: adj}, true
+}
+
+// readProcInt reads a /proc/<pid> file holding a single integer
+func readProcInt(pid int32, name string) (int, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/%s", pid, name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(content)))
+}
+
+// readUint reads a sysfs file holding a single unsigned integer
+func readUint(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// namespaceTypes are read from /proc/<pid>/ns, the ones that say most about
+// a container's isolation first
+var namespaceTypes = []string{"net", "pid", "mnt", "user", "ipc", "uts", "cgroup"}
+
+// readNamespaces lists the process's namespaces, marking those it shares
+// with PID 1. Reading another process's ns links needs the same access as
+// ptrace, so without it (for PID 1 especially) nothing is reported.
+func readNamespaces(pid int32) ([]models.Namespace, bool) {
+	var namespaces []models.Namespace
+	for _, nsType := range namespaceTypes {
+		inode, err := namespaceInode(pid, nsType)
+		if err != nil {
+			continue
+		}
+		host, err := namespaceInode(1, nsType)
+		if err != nil {
+			return nil, false
+		}
+		namespaces = append(namespaces, models.Namespace{Type: nsType, Inode: inode, Shared: inode == host})
+	}
+	return namespaces, len(namespaces) > 0
+}
+
+// namespaceInode parses the "net:[4026531840]" link target of a namespace
+func namespaceInode(pid int32, nsType string) (uint64, error) {
+	target, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/%s", pid, nsType))
+	if err != nil {
+		return 0, err
+	}
+	_, inode, found := strings.Cut(target, ":[")
+	if !found {
+		return 0, fmt.Errorf("unexpected namespace link %q", target)
+	}
+	return strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64)
+}
+
+// capabilityNames maps capability bit numbers to their names, per
+// include/uapi/linux/capability.h
+var capabilityNames = []string{
+	"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER",
+	"CAP_FSETID", "CAP_KILL", "CAP_SETGID", "CAP_SETUID", "CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE", "CAP_NET_BIND_SERVICE", "CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN", "CAP_NET_RAW", "CAP_IPC_LOCK", "CAP_IPC_OWNER",
+	"CAP_SYS_MODULE", "CAP_SYS_RAWIO", "CAP_SYS_CHROOT", "CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT", "CAP_SYS_ADMIN", "CAP_SYS_BOOT", "CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE", "CAP_SYS_TIME", "CAP_SYS_TTY_CONFIG", "CAP_MKNOD",
+	"CAP_LEASE", "CAP_AUDIT_WRITE", "CAP_AUDIT_CONTROL", "CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE", "CAP_MAC_ADMIN", "CAP_SYSLOG", "CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND", "CAP_AUDIT_READ", "CAP_PERFMON", "CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// seccompModes names the values of the Seccomp status field
+var seccompModes = map[string]string{"0": "disabled", "1": "strict", "2": "filtered"}
+
+// readSecurity decodes the effective capability set (CapEff) and seccomp mode
+// from /proc/<pid>/status. Capabilities are non-nil, possibly empty, whenever
+// ok is true.
+func readSecurity(pid int32) (capabilities []string, seccomp string, ok bool) {
+	status, err := readProcStatus(pid)
+	if err != nil {
+		return nil, "", false
+	}
+
+	mask, err := strconv.ParseUint(status["CapEff"], 16, 64)
+	if err != nil {
+		return nil, "", false
+	}
+	capabilities = []string{}
+	for bit := 0; bit < 64; bit++ {
+		if mask&(1<<bit) == 0 {
+			continue
+		}
+		if bit < len(capabilityNames) {
+			capabilities = append(capabilities, capabilityNames[bit])
+		} else {
+			capabilities = append(capabilities, fmt.Sprintf("CAP_%d", bit))
+		}
+	}
+
+	seccomp = seccompModes[status["Seccomp"]]
+	return capabilities, seccomp, true
+}
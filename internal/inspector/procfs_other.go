@@ -0,0 +1,162 @@
+//go:build !linux && !windows
+
+package inspector
+
+import (
+	"os"
+
+	"inspektor/internal/models"
+)
+
+// readPeakRSS is only available from procfs on Linux
+func readPeakRSS(pid int32) (uint64, bool) {
+	return 0, false
+}
+
+// readHandles counts Windows handles
+func readHandles(pid int32) (uint32, bool) {
+	return 0, false
+}
+
+// unsupportedReaders are the collectors that rely on procfs
+var unsupportedReaders = map[string]bool{
+	"session":          true,
+	"memory_peak_rss":  true,
+	"memory_breakdown": true,
+	"memory_shmem":     true,
+	"oom_score":        true,
+	"capabilities":     true,
+	"namespaces":       true,
+	"limits":           true,
+	"context_switches": true,
+	"thread_cpu":       true,
+	"scheduler":        true,
+	"cgroup_memory":    true,
+	"wait_channel":     true,
+}
+
+// memoryBreakdown splits the process's memory into shared, private and swap
+type memoryBreakdown struct {
+	shared  uint64
+	private uint64
+	swap    uint64
+}
+
+// readShmem relies on Linux /proc/<pid>/status
+func readShmem(pid int32) (uint64, bool) {
+	return 0, false
+}
+
+// readMemoryBreakdown relies on Linux smaps_rollup
+func readMemoryBreakdown(pid int32) (memoryBreakdown, bool) {
+	return memoryBreakdown{}, false
+}
+
+// readNetCounters relies on Linux /proc/<pid>/net/dev
+func readNetCounters(pid int32) (rx, tx uint64, ok bool) {
+	return 0, 0, false
+}
+
+// descriptorSize needs /proc/<pid>/fd, which only exists on Linux
+func descriptorSize(pid int32, fd uint64) (uint64, bool) {
+	return 0, false
+}
+
+// readListeners relies on Linux /proc/net; gopsutil's connection list is
+// used instead
+func readListeners(query PortQuery) ([]portListener, bool) {
+	return nil, false
+}
+
+// readContextSwitches relies on Linux /proc/<pid>/task
+func readContextSwitches(pid int32) (voluntary, involuntary uint64, ok bool) {
+	return 0, 0, false
+}
+
+// readThreads relies on Linux /proc/<pid>/task
+func readThreads(pid int32) (map[int32]threadTimes, bool) {
+	return nil, false
+}
+
+// readEnergy relies on the Linux powercap RAPL interface
+func readEnergy() ([]energyCounter, bool) {
+	return nil, false
+}
+
+// readThread relies on Linux /proc/<pid>/task
+func readThread(pid, tid int32) (taskStat, bool) {
+	return taskStat{}, false
+}
+
+// readThreadGroup relies on Linux /proc/<tid>/status
+func readThreadGroup(tid int32) (int32, bool) {
+	return 0, false
+}
+
+// readRoot relies on Linux /proc/<pid>/root; there is nothing to report
+func readRoot(pid int32) (string, error) {
+	return "", nil
+}
+
+// readContainerID relies on Linux cgroups
+func readContainerID(pid int32) (string, bool) {
+	return "", false
+}
+
+// readSecurity relies on Linux capabilities and seccomp
+func readSecurity(pid int32) (capabilities []string, seccomp string, ok bool) {
+	return nil, "", false
+}
+
+// readSession relies on Linux /proc/<pid>/stat
+func readSession(pid int32) (pgid, sid int32, ok bool) {
+	return 0, 0, false
+}
+
+// readScheduler relies on Linux /proc/<pid>/stat
+func readScheduler(pid int32) (*models.Scheduler, bool) {
+	return nil, false
+}
+
+// isKernelThread relies on Linux's kthreadd
+func isKernelThread(pid int32) bool {
+	return false
+}
+
+// readWaitState relies on Linux /proc/<pid>/task/<tid>/wchan
+func readWaitState(pid int32) (*models.WaitState, bool) {
+	return nil, false
+}
+
+// readCgroupMemory relies on Linux cgroups
+func readCgroupMemory(pid int32) (*models.CgroupMemory, bool) {
+	return nil, false
+}
+
+// readOOMScore relies on the Linux OOM killer's /proc/<pid>/oom_score
+func readOOMScore(pid int32) (*models.OOMScore, bool) {
+	return nil, false
+}
+
+// readNamespaces relies on Linux /proc/<pid>/ns
+func readNamespaces(pid int32) ([]models.Namespace, bool) {
+	return nil, false
+}
+
+// readLimits relies on Linux /proc/<pid>/limits
+func readLimits(pid int32) ([]models.Limit, bool) {
+	return nil, false
+}
+
+// openExecutable opens the file at the executable's path; the running
+// image can't be reached on its own without procfs
+func openExecutable(pid int32, path string) (*os.File, error) {
+	return os.Open(path)
+}
+
+// statExecutable stats the file at the executable's path; the running image
+// can't be told apart from it without procfs
+func statExecutable(pid int32, path string) (onDisk, running os.FileInfo, err error) {
+	onDisk, err = os.Stat(path)
+	return onDisk, nil, err
+}
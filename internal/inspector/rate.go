@@ -0,0 +1,109 @@
+package inspector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// minRateWindow is the shortest gap between two counter readings that still
+// gives a meaningful rate
+const minRateWindow = 100 * time.Millisecond
+
+// sampledMetric names what a reading is of, so a process's readings of
+// different metrics are kept apart
+type sampledMetric string
+
+const (
+	sampledNetwork  sampledMetric = "network"
+	sampledDisk     sampledMetric = "disk"
+	sampledSwitches sampledMetric = "context_switches"
+	sampledThreads  sampledMetric = "threads"
+	sampledPower    sampledMetric = "power"
+)
+
+// timedSample is a reading of some counters, taken at a known time
+type timedSample interface {
+	takenAt() time.Time
+}
+
+// sampleKey identifies one process's readings of one metric
+type sampleKey struct {
+	metric sampledMetric
+	pid    int32
+}
+
+// sampleState keeps the latest reading of each metric per PID, carried
+// across watch and repeat ticks so every rate of a process sampled again is
+// measured from the previous tick's reading instead of the short window
+// within one collection
+type sampleState struct {
+	mu      sync.Mutex
+	samples map[sampleKey]timedSample
+}
+
+// baseline records after as pid's latest reading of metric and returns the
+// earliest reading to measure against: the previous tick's if there is one,
+// otherwise before
+func baseline[S timedSample](s *sampleState, metric sampledMetric, pid int32, before, after S) S {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.samples == nil {
+		s.samples = make(map[sampleKey]timedSample)
+	}
+	key := sampleKey{metric, pid}
+	if previous, found := s.samples[key].(S); found && previous.takenAt().Before(before.takenAt()) {
+		before = previous
+	}
+	s.samples[key] = after
+	return before
+}
+
+// counterSample is a reading of a pair of cumulative counters: bytes
+// received and transmitted for the network, read and written for the disk,
+// voluntary and involuntary context switches for the scheduler
+type counterSample struct {
+	in, out uint64
+	at      time.Time
+}
+
+func (s counterSample) takenAt() time.Time { return s.at }
+
+// readNetSample reads the network counters, reporting false where
+// unsupported
+func readNetSample(pid int32) (counterSample, bool) {
+	rx, tx, ok := readNetCounters(pid)
+	return counterSample{in: rx, out: tx, at: time.Now()}, ok
+}
+
+// readDiskSample reads the bytes the process had read from and written to
+// storage, which on Linux needs the same access as ptrace
+func readDiskSample(ctx context.Context, proc *process.Process) (counterSample, error) {
+	counters, err := proc.IOCountersWithContext(ctx)
+	if err != nil {
+		return counterSample{}, err
+	}
+	return counterSample{in: counters.ReadBytes, out: counters.WriteBytes, at: time.Now()}, nil
+}
+
+// readSwitchSample reads the process's voluntary and involuntary context
+// switches, reporting false where unsupported
+func readSwitchSample(pid int32) (counterSample, bool) {
+	voluntary, involuntary, ok := readContextSwitches(pid)
+	return counterSample{in: voluntary, out: involuntary, at: time.Now()}, ok
+}
+
+// counterRate returns both rates, per second, between two readings of the
+// same counters
+func counterRate(before, after counterSample) (in, out float64, ok bool) {
+	elapsed := after.at.Sub(before.at)
+	// Counters going backwards means the namespace or process changed under us
+	if elapsed < minRateWindow || after.in < before.in || after.out < before.out {
+		return 0, 0, false
+	}
+
+	seconds := elapsed.Seconds()
+	return float64(after.in-before.in) / seconds, float64(after.out-before.out) / seconds, true
+}
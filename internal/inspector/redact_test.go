@@ -0,0 +1,78 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"inspektor/internal/analyzer"
+	"inspektor/internal/redact"
+)
+
+// TestRedactionReachesEveryOutput collects a process launched with secrets
+// in its arguments and environment and checks that none of them survive
+// into the report, the JSON output or the AI prompt
+func TestRedactionReachesEveryOutput(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads another process's command line and environment from /proc")
+	}
+	secrets := []string{"hunter2", "s3cr3tvalue", "pw123", "javapw", "ACME-deadbeef42"}
+
+	cmd := exec.Command("sh", "-c", "sleep 30", "inspektor-redact-test",
+		"--password", "hunter2", "--api-key=s3cr3tvalue", "postgres://app:pw123@db/app", "ACME-deadbeef42")
+	cmd.Env = append(os.Environ(), "JAVA_TOOL_OPTIONS=-Dpassword=javapw")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	redactor, err := redact.New([]string{`ACME-[a-z0-9]+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Redactor: redactor, Verbose: true, JSON: true}
+	data, err := NewCollector(WithOptions(opts)).Collect(context.Background(), int32(cmd.Process.Pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(data.Process.CommandLine, redact.Mask) {
+		t.Fatalf("command line wasn't redacted: %q", data.Process.CommandLine)
+	}
+
+	jsonOutput, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insp := New(analyzer.Config{NoAI: true})
+	defer insp.Close()
+	insp.formatter.Verbose = true
+	report := insp.formatter.FormatReport(data)
+
+	promptFile := filepath.Join(t.TempDir(), "prompt.txt")
+	analyzer.New(analyzer.Config{DumpPrompt: promptFile}).AnalyzeAndWarn(context.Background(), data)
+	prompt, err := os.ReadFile(promptFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(prompt), "inspektor-redact-test") {
+		t.Fatalf("prompt doesn't describe the process:\n%s", prompt)
+	}
+
+	outputs := map[string]string{"report": report, "JSON": string(jsonOutput), "prompt": string(prompt)}
+	for name, output := range outputs {
+		for _, secret := range secrets {
+			if strings.Contains(output, secret) {
+				t.Errorf("%s leaks %q", name, secret)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultRemoteTimeout bounds a --ssh collection, connection included
+const DefaultRemoteTimeout = 30 * time.Second
+
+// sshConnectTimeout is how long ssh waits for the host to answer, in seconds
+const sshConnectTimeout = "10"
+
+// Remote says where and how to collect an inspection over SSH, for --ssh
+type Remote struct {
+	// Destination is what ssh connects to: host, user@host or a Host alias
+	// from ~/.ssh/config
+	Destination string
+	// Inspektor is the inspektor command on the remote host
+	Inspektor string
+	// Args are its arguments, which must make it print one JSON inspection
+	Args    []string
+	Timeout time.Duration
+}
+
+// InspectRemote runs inspektor on another host through the system's ssh
+// client, so authentication comes from the SSH agent and ~/.ssh/config as
+// for any ssh login, then analyzes and renders the inspection it returns
+// here: findings, AI included, are produced locally from the remote
+// metrics. ssh runs in batch mode, so a host that would prompt for a
+// password or an unknown host key fails instead of hanging.
+func (i *Inspector) InspectRemote(remote Remote, opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	timeout := remote.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRemoteTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	command := make([]string, 0, len(remote.Args)+1)
+	command = append(command, shellQuote(remote.Inspektor))
+	for _, arg := range remote.Args {
+		command = append(command, shellQuote(arg))
+	}
+	// The remote shell joins ssh's arguments into one command line, hence
+	// the quoting
+	ssh := exec.CommandContext(ctx, "ssh",
+		"-o", "BatchMode=yes", "-o", "ConnectTimeout="+sshConnectTimeout,
+		"--", remote.Destination, strings.Join(command, " "))
+	var stdout, stderr bytes.Buffer
+	ssh.Stdout, ssh.Stderr = &stdout, &stderr
+
+	err := ssh.Run()
+	if ctx.Err() != nil {
+		return fmt.Errorf("no answer from %s within %s", remote.Destination, timeout)
+	}
+	if err != nil {
+		return remoteError(remote, err, strings.TrimSpace(stderr.String()))
+	}
+
+	data, err := decodeSnapshot(&stdout, "the inspection from "+remote.Destination)
+	if err != nil {
+		return err
+	}
+	return i.inspectSaved(data, opts)
+}
+
+// remoteError explains why a remote collection failed, from ssh's exit
+// status: 255 is ssh's own failure (connection or authentication), 127 the
+// remote shell not finding inspektor, anything else inspektor's own error
+func remoteError(remote Remote, err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if errors.Is(err, exec.ErrNotFound) {
+		return errors.New("--ssh needs an ssh client on the PATH")
+	}
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to run ssh: %w", err)
+	}
+
+	detail := ""
+	if stderr != "" {
+		detail = ": " + stderr
+	}
+	switch exitErr.ExitCode() {
+	case 255:
+		return fmt.Errorf("failed to connect to %s%s", remote.Destination, detail)
+	case 127:
+		return fmt.Errorf("%s was not found on %s; install inspektor there or point --ssh-inspektor at it%s",
+			remote.Inspektor, remote.Destination, detail)
+	}
+	return fmt.Errorf("inspektor on %s failed%s", remote.Destination, strings.Replace(detail, "Error: ", "", 1))
+}
+
+// shellQuote makes arg a single word for a POSIX shell, leaving plain words
+// as they are
+func shellQuote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%", r))
+	}) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
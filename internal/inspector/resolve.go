@@ -0,0 +1,102 @@
+package inspector
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// resolveTimeout bounds each reverse lookup; DNS that slow won't answer
+// usefully anyway
+const resolveTimeout = 500 * time.Millisecond
+
+// wellKnownPorts labels the ports common services listen on
+var wellKnownPorts = map[string]string{
+	"21": "ftp", "22": "ssh", "25": "smtp", "53": "dns", "80": "http",
+	"110": "pop3", "123": "ntp", "143": "imap", "389": "ldap", "443": "https",
+	"465": "smtps", "587": "submission", "636": "ldaps", "993": "imaps",
+	"995": "pop3s", "1883": "mqtt", "2181": "zookeeper", "2379": "etcd",
+	"3306": "mysql", "4222": "nats", "5432": "postgres", "5672": "amqp",
+	"6379": "redis", "8080": "http-alt", "8443": "https-alt", "9092": "kafka",
+	"9200": "elasticsearch", "11211": "memcached", "27017": "mongodb",
+}
+
+// hostNames reverse-resolves peer addresses. Results, failures included,
+// are cached for the Inspector's lifetime so watch mode and batches don't
+// repeat lookups.
+type hostNames struct {
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// annotate fills in the peer hostname and service of each connection,
+// looking up every distinct address concurrently. Resolution is best-effort:
+// a peer without a PTR record, or whose lookup times out, keeps only its IP.
+func (h *hostNames) annotate(ctx context.Context, connections []models.Connection) {
+	var wg sync.WaitGroup
+	hosts := make([]string, len(connections))
+	for idx, conn := range connections {
+		if conn.Proto == "unix" {
+			continue
+		}
+		conn := &connections[idx]
+		conn.Service = serviceName(conn.Remote, conn.Local)
+
+		ip, _, err := net.SplitHostPort(conn.Remote)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hosts[idx] = h.lookup(ctx, ip)
+		}()
+	}
+	wg.Wait()
+
+	for idx, host := range hosts {
+		connections[idx].RemoteHost = host
+	}
+}
+
+// lookup returns the hostname for ip, or "" if it has none
+func (h *hostNames) lookup(ctx context.Context, ip string) string {
+	h.mu.Lock()
+	host, found := h.cache[ip]
+	h.mu.Unlock()
+	if found {
+		return host
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	if names, err := net.DefaultResolver.LookupAddr(ctx, ip); err == nil && len(names) > 0 {
+		host = strings.TrimSuffix(names[0], ".")
+	}
+
+	h.mu.Lock()
+	if h.cache == nil {
+		h.cache = make(map[string]string)
+	}
+	h.cache[ip] = host
+	h.mu.Unlock()
+	return host
+}
+
+// serviceName labels a connection by the well-known port at either end: the
+// remote one for outgoing connections, the local one for clients connected
+// to a service the process runs
+func serviceName(remote, local string) string {
+	for _, addr := range []string{remote, local} {
+		if _, port, err := net.SplitHostPort(addr); err == nil {
+			if name, ok := wellKnownPorts[port]; ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
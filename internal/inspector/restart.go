@@ -0,0 +1,113 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// restartTracker follows a watched process across restarts. A restart is
+// either the PID disappearing and a process with the same name (or on the
+// same port, when watching by port) taking its place, or the PID being
+// reused by a newer process with the same name.
+type restartTracker struct {
+	name       string
+	command    string
+	createTime int64
+	port       *PortQuery
+	info       models.RestartInfo
+}
+
+func newRestartTracker(ctx context.Context, proc *process.Process, port *PortQuery) *restartTracker {
+	tracker := &restartTracker{port: port, info: models.RestartInfo{Since: time.Now()}}
+	tracker.remember(ctx, proc)
+	return tracker
+}
+
+// remember records the identity of the current instance
+func (t *restartTracker) remember(ctx context.Context, proc *process.Process) {
+	t.name, _ = proc.NameWithContext(ctx)
+	t.command, _ = proc.CmdlineWithContext(ctx)
+	t.createTime, _ = proc.CreateTimeWithContext(ctx)
+}
+
+// follow returns the process to sample next: proc itself while it is still
+// the instance being watched, otherwise its replacement, recording the
+// restart. It fails when the process is gone and nothing replaced it.
+func (t *restartTracker) follow(ctx context.Context, i *Inspector, proc *process.Process) (*process.Process, bool, error) {
+	// IsRunning compares start times, so it is false for a reused PID too
+	if running, err := proc.IsRunningWithContext(ctx); err == nil && running {
+		return proc, false, nil
+	}
+
+	replacement, err := t.findReplacement(ctx, i)
+	if err != nil {
+		return nil, false, fmt.Errorf("process %d is no longer available: %w", proc.Pid, err)
+	}
+
+	t.info.Count++
+	t.info.Last = time.Now()
+	t.info.PreviousPID = proc.Pid
+	t.remember(ctx, replacement)
+	return replacement, true, nil
+}
+
+// findReplacement looks for the new instance: the listener on the watched
+// port, or else the newest process with the same name started after the old
+// one, preferring an identical command line
+func (t *restartTracker) findReplacement(ctx context.Context, i *Inspector) (*process.Process, error) {
+	if t.port != nil {
+		pid, err := i.findProcessByPort(*t.port)
+		if err != nil {
+			return nil, err
+		}
+		return process.NewProcessWithContext(ctx, pid)
+	}
+
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	self := int32(os.Getpid())
+	var best *process.Process
+	var bestCreated int64
+	bestSameCommand := false
+	for _, proc := range procs {
+		if proc.Pid == self {
+			continue
+		}
+		if name, err := proc.NameWithContext(ctx); err != nil || name != t.name {
+			continue
+		}
+		created, err := proc.CreateTimeWithContext(ctx)
+		if err != nil || created <= t.createTime {
+			continue
+		}
+		command, _ := proc.CmdlineWithContext(ctx)
+		sameCommand := command == t.command
+		if best == nil || (sameCommand && !bestSameCommand) ||
+			(sameCommand == bestSameCommand && created > bestCreated) {
+			best, bestCreated, bestSameCommand = proc, created, sameCommand
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no new process named %q was found", t.name)
+	}
+	return best, nil
+}
+
+// restarts reports the restarts seen so far, or nil if there were none
+func (t *restartTracker) restarts() *models.RestartInfo {
+	if t.info.Count == 0 {
+		return nil
+	}
+	info := t.info
+	return &info
+}
@@ -0,0 +1,57 @@
+package inspector
+
+import (
+	"context"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// InspectionResult is one inspection, collected and analyzed but not
+// rendered, for programs that embed inspektor rather than run it
+type InspectionResult struct {
+	// Data is everything collected, with the health score filled in
+	Data *models.InspectionData
+	// Findings are the warnings and recommendations, filtered by
+	// Options.MinSeverity
+	Findings []models.Finding
+	// HealthScore is Data.HealthScore, 0 to 100; like Findings it is left
+	// at zero when the collection timed out and nothing was analyzed
+	HealthScore int
+	HealthBand  models.HealthBand
+	// Source is where the findings came from: models.SourceAI,
+	// models.SourceMerged (--mode both) or models.SourceRules, also when
+	// no provider answered; empty when nothing was analyzed
+	Source string
+	// Duration is how long collection and analysis took together
+	Duration time.Duration
+}
+
+// Evaluate inspects pid and returns the result without printing anything.
+// Only the collection and analysis options in opts apply; the display ones
+// are for the commands that render a result. The --on-warning hook, audit
+// log, --fail-on and warnings channel still see the findings, when set.
+// Options.Timeout bounds collection and analysis together: an AI provider
+// gets only what the collection left of it before the rules take over.
+func (i *Inspector) Evaluate(pid int32, opts Options) (*InspectionResult, error) {
+	ctx, cancel := opts.inspectionContext(context.Background())
+	defer cancel()
+
+	started := time.Now()
+	data, err := i.sourceFor(opts).Collect(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	findings, source := i.analyzeWithSource(ctx, data, opts)
+	elapsed := time.Since(started)
+	data.DurationMS = elapsed.Milliseconds()
+
+	return &InspectionResult{
+		Data:        data,
+		Findings:    findings,
+		HealthScore: data.HealthScore,
+		HealthBand:  data.HealthBand,
+		Source:      source,
+		Duration:    elapsed,
+	}, nil
+}
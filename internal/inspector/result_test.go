@@ -0,0 +1,173 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"inspektor/internal/analyzer"
+	"inspektor/internal/models"
+)
+
+// fakeSource serves canned inspections in place of a Collector
+type fakeSource struct {
+	processes map[int32]models.ProcessInfo
+	// delay holds back a PID's collection, to make jobs finish out of order
+	delay    map[int32]time.Duration
+	timedOut bool
+}
+
+func (s *fakeSource) Collect(ctx context.Context, pid int32) (*models.InspectionData, error) {
+	proc, ok := s.processes[pid]
+	if !ok {
+		return nil, errors.New("no such process")
+	}
+	time.Sleep(s.delay[pid])
+	proc.PID = pid
+	return &models.InspectionData{
+		Process:  &proc,
+		System:   &models.SystemInfo{CPUCores: 4, MemoryTotal: 8 << 30, MemoryPercent: 40},
+		TimedOut: s.timedOut,
+	}, nil
+}
+
+func (s *fakeSource) CollectSystem(ctx context.Context) (*models.SystemInfo, error) {
+	return &models.SystemInfo{CPUCores: 4}, nil
+}
+
+func (s *fakeSource) withOptions(opts Options) source {
+	return s
+}
+
+// newFakeInspector is an offline Inspector collecting from source
+func newFakeInspector(source *fakeSource) *Inspector {
+	insp := New(analyzer.Config{NoAI: true})
+	insp.source = source
+	return insp
+}
+
+// hasRule reports whether findings include one raised by rule
+func hasRule(findings []models.Finding, rule string) bool {
+	for _, finding := range findings {
+		if finding.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEvaluate(t *testing.T) {
+	source := &fakeSource{processes: map[int32]models.ProcessInfo{
+		100: {Name: "idle", Status: "S", MemoryRSS: 10 << 20, MemoryVMS: 20 << 20},
+		200: {Name: "busy", Status: "R", CPUPercent: 99, MemoryRSS: 10 << 20, MemoryVMS: 20 << 20},
+	}}
+	insp := newFakeInspector(source)
+	defer insp.Close()
+
+	t.Run("healthy", func(t *testing.T) {
+		result, err := insp.Evaluate(100, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Data.Process.Name != "idle" {
+			t.Errorf("Data.Process.Name = %q, want idle", result.Data.Process.Name)
+		}
+		if hasRule(result.Findings, analyzer.RuleHighCPU) {
+			t.Errorf("idle process raised %s: %v", analyzer.RuleHighCPU, result.Findings)
+		}
+		if result.Source != models.SourceRules {
+			t.Errorf("Source = %q, want %q", result.Source, models.SourceRules)
+		}
+		if result.HealthScore != result.Data.HealthScore || result.HealthBand != result.Data.HealthBand {
+			t.Errorf("score %d (%s) doesn't match Data's %d (%s)",
+				result.HealthScore, result.HealthBand, result.Data.HealthScore, result.Data.HealthBand)
+		}
+		if result.Duration <= 0 || result.Data.DurationMS != result.Duration.Milliseconds() {
+			t.Errorf("Duration = %s with DurationMS %d", result.Duration, result.Data.DurationMS)
+		}
+	})
+
+	t.Run("busy", func(t *testing.T) {
+		result, err := insp.Evaluate(200, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hasRule(result.Findings, analyzer.RuleHighCPU) {
+			t.Errorf("busy process didn't raise %s: %v", analyzer.RuleHighCPU, result.Findings)
+		}
+		idle, _ := insp.Evaluate(100, Options{})
+		if result.HealthScore >= idle.HealthScore {
+			t.Errorf("busy process scored %d, not below the idle one's %d", result.HealthScore, idle.HealthScore)
+		}
+	})
+
+	t.Run("min severity", func(t *testing.T) {
+		result, err := insp.Evaluate(200, Options{MinSeverity: models.SeverityCritical})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, finding := range result.Findings {
+			if finding.Severity != models.SeverityCritical {
+				t.Errorf("finding below --min-severity kept: %+v", finding)
+			}
+		}
+	})
+
+	t.Run("collection error", func(t *testing.T) {
+		if _, err := insp.Evaluate(300, Options{}); err == nil {
+			t.Error("Evaluate of a missing process succeeded")
+		}
+	})
+}
+
+func TestEvaluateTimedOut(t *testing.T) {
+	source := &fakeSource{
+		processes: map[int32]models.ProcessInfo{200: {Name: "busy", CPUPercent: 99}},
+		timedOut:  true,
+	}
+	insp := newFakeInspector(source)
+	defer insp.Close()
+
+	result, err := insp.Evaluate(200, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Findings) != 0 || result.Source != "" || result.HealthScore != 0 {
+		t.Errorf("timed-out inspection was analyzed: %d findings, source %q, score %d",
+			len(result.Findings), result.Source, result.HealthScore)
+	}
+}
+
+func TestEvaluateTimeoutCoversAnalysis(t *testing.T) {
+	// An OpenAI-compatible endpoint that never replies
+	release := make(chan struct{})
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer stalled.Close()
+	defer close(release)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_BASE_URL", stalled.URL)
+
+	insp := New(analyzer.Config{Provider: analyzer.ProviderOpenAI, AITimeout: time.Minute})
+	defer insp.Close()
+	insp.source = &fakeSource{processes: map[int32]models.ProcessInfo{100: {Name: "idle", Status: "S"}}}
+
+	started := time.Now()
+	result, err := insp.Evaluate(100, Options{Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("Evaluate took %s with a 200ms --timeout", elapsed)
+	}
+	if result.Source != models.SourceRules {
+		t.Errorf("Source = %q, want %q", result.Source, models.SourceRules)
+	}
+}
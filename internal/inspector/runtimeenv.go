@@ -0,0 +1,110 @@
+package inspector
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"inspektor/internal/redact"
+)
+
+// runtimeVariables are the environment variables that tune a language
+// runtime's threads, heap or allocator, which explain much of how a process
+// behaves without the rest of its environment. A trailing * matches any
+// variable with that prefix.
+var runtimeVariables = []string{
+	// Go
+	"GOMAXPROCS",
+	"GOGC",
+	"GOMEMLIMIT",
+	"GODEBUG",
+	// JVM
+	"JAVA_OPTS",
+	"JAVA_TOOL_OPTIONS",
+	"JDK_JAVA_OPTIONS",
+	"_JAVA_OPTIONS",
+	"CATALINA_OPTS",
+	// .NET
+	"DOTNET_gcServer",
+	"DOTNET_GCHeapHardLimit",
+	"DOTNET_GCHeapHardLimitPercent",
+	// Node.js
+	"NODE_OPTIONS",
+	"UV_THREADPOOL_SIZE",
+	// Python and Ruby
+	"PYTHONMALLOC",
+	"WEB_CONCURRENCY",
+	"GUNICORN_CMD_ARGS",
+	"RUBY_GC_*",
+	"RUBY_YJIT_ENABLE",
+	// Numeric libraries' thread pools
+	"OMP_NUM_THREADS",
+	"MKL_NUM_THREADS",
+	"OPENBLAS_NUM_THREADS",
+	// glibc malloc, jemalloc and preloaded allocators
+	"MALLOC_ARENA_MAX",
+	"MALLOC_CONF",
+	"LD_PRELOAD",
+}
+
+// LoadRuntimeEnv reads a YAML list of extra environment variable names, such
+// as "- APP_WORKERS" or "- MYAPP_POOL_*", to report alongside the built-in
+// runtime variables
+func LoadRuntimeEnv(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runtime variables: %w", err)
+	}
+
+	var names []string
+	if err := yaml.Unmarshal(content, &names); err != nil {
+		return nil, fmt.Errorf("failed to parse runtime variables %s: %w", path, err)
+	}
+	for idx, name := range names {
+		if strings.TrimSuffix(name, "*") == "" || strings.ContainsAny(name, "= ") {
+			return nil, fmt.Errorf("runtime variables %s: entry %d (%q) is not a variable name", path, idx+1, name)
+		}
+	}
+
+	return names, nil
+}
+
+// runtimeEnv picks the runtime variables, built-in and extra, out of the
+// process's environment. Their values are not secret by nature, but still
+// go through redactor in case one carries e.g. a -Dpassword= flag.
+func runtimeEnv(environ, extra []string, redactor *redact.Redactor) map[string]string {
+	var picked []string
+	for _, entry := range environ {
+		name, _, found := strings.Cut(entry, "=")
+		if found && (matchesVariable(name, runtimeVariables) || matchesVariable(name, extra)) {
+			picked = append(picked, entry)
+		}
+	}
+	if len(picked) == 0 {
+		return nil
+	}
+
+	env := make(map[string]string, len(picked))
+	for _, entry := range redactor.Environment(picked) {
+		name, value, _ := strings.Cut(entry, "=")
+		env[name] = value
+	}
+	return env
+}
+
+// matchesVariable reports whether name is one of names, or starts with the
+// prefix of one ending in *
+func matchesVariable(name string, names []string) bool {
+	for _, candidate := range names {
+		if prefix, ok := strings.CutSuffix(candidate, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"inspektor/internal/display"
+	"inspektor/internal/models"
+)
+
+// selfTestLoad is how long the self-test keeps a CPU busy before inspecting
+// itself, so the CPU time counters have something to show
+const selfTestLoad = 50 * time.Millisecond
+
+// SelfTest inspects inspektor's own process, checks that every collector
+// returns plausible values and that the configured AI providers answer, and
+// prints a pass/fail summary. It returns an error if any check failed.
+func (i *Inspector) SelfTest(opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	// Burn a little CPU so a healthy collector can't report zero CPU time
+	for deadline := time.Now().Add(selfTestLoad); time.Now().Before(deadline); {
+	}
+
+	ctx, cancel := opts.inspectionContext(context.Background())
+	defer cancel()
+
+	pid := int32(os.Getpid())
+	var results []display.CheckResult
+	data, err := i.collectorFor(opts).Collect(ctx, pid)
+	if err != nil {
+		results = append(results, display.CheckResult{Name: "collect", Status: display.CheckFail, Detail: err.Error()})
+	} else {
+		results = append(results, collectorChecks(pid, data)...)
+	}
+	results = append(results, i.providerChecks()...)
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == display.CheckFail {
+			failed++
+		}
+	}
+
+	if opts.JSON {
+		if err := writeJSON(results, opts); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(i.formatter.FormatSelfTest(results))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d self-test check(s) failed", failed)
+	}
+	return nil
+}
+
+// collectorChecks judges the data collected about our own process, where the
+// right answers are known: it is running, uses CPU and memory, and has
+// descriptors open
+func collectorChecks(pid int32, data *models.InspectionData) []display.CheckResult {
+	proc := data.Process
+	check := func(name string, ok bool, detail string, args ...any) display.CheckResult {
+		status := display.CheckPass
+		if !ok {
+			status = display.CheckFail
+		}
+		return display.CheckResult{Name: name, Status: status, Detail: fmt.Sprintf(detail, args...)}
+	}
+
+	results := []display.CheckResult{
+		check("process", proc.PID == pid && proc.Name != "" && proc.CommandLine != "",
+			"%s (PID %d)", proc.Name, proc.PID),
+		check("cpu", proc.CPUTimeUser+proc.CPUTimeSystem > 0 && proc.CPUPercent >= 0,
+			"%.2fs CPU time, %.1f%% usage", proc.CPUTimeUser+proc.CPUTimeSystem, proc.CPUPercent),
+		check("memory", proc.MemoryRSS > 0 && proc.MemoryPercent > 0,
+			"%d bytes RSS (%.2f%%)", proc.MemoryRSS, proc.MemoryPercent),
+		check("threads", proc.NumThreads > 0, "%d threads", proc.NumThreads),
+	}
+
+	// Descriptor listing and limits aren't implemented everywhere
+	if runtime.GOOS == "linux" {
+		results = append(results, check("descriptors", proc.OpenFiles > 0 && proc.MaxOpenFiles > 0,
+			"%d open files, limit %d", proc.OpenFiles, proc.MaxOpenFiles))
+	} else {
+		results = append(results, display.CheckResult{Name: "descriptors", Status: display.CheckSkip,
+			Detail: fmt.Sprintf("not checked on %s (%d open files)", runtime.GOOS, proc.OpenFiles)})
+	}
+
+	if data.System == nil {
+		results = append(results, check("system", false, "no system data (collection timed out)"))
+	} else {
+		sys := data.System
+		results = append(results, check("system", sys.CPUCores > 0 && sys.MemoryTotal > 0 && sys.MemoryUsed > 0,
+			"%d cores, %d bytes memory", sys.CPUCores, sys.MemoryTotal))
+		results = append(results, check("disk", sys.DiskTotal > 0, "%d bytes on %s", sys.DiskTotal, rootPath()))
+	}
+
+	if data.Host != nil {
+		results = append(results, check("host", data.Host.Hostname != "", "%s", data.Host))
+	}
+
+	return results
+}
+
+// providerChecks reports whether each configured AI provider answers a
+// trivial prompt; without providers the check is skipped
+func (i *Inspector) providerChecks() []display.CheckResult {
+	if i.analyzer.AIDisabled() {
+		return []display.CheckResult{{Name: "ai", Status: display.CheckSkip, Detail: "AI disabled by --no-ai, rule-based analysis only"}}
+	}
+	statuses := i.analyzer.CheckProviders()
+	if len(statuses) == 0 {
+		return []display.CheckResult{{Name: "ai", Status: display.CheckSkip, Detail: "no AI provider configured, rule-based analysis only"}}
+	}
+
+	results := make([]display.CheckResult, len(statuses))
+	for idx, status := range statuses {
+		results[idx] = display.CheckResult{Name: "ai:" + status.Name, Status: display.CheckPass,
+			Detail: fmt.Sprintf("answered in %s", status.Latency.Round(time.Millisecond))}
+		if status.Err != nil {
+			results[idx].Status = display.CheckFail
+			results[idx].Detail = status.Err.Error()
+		}
+	}
+	return results
+}
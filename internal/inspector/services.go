@@ -0,0 +1,89 @@
+package inspector
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// wellKnownServices names the service usually behind a listening port, so
+// reports can say "5432 (postgres)" instead of leaving the reader to know
+var wellKnownServices = map[uint32]string{
+	22:    "ssh",
+	25:    "smtp",
+	53:    "dns",
+	80:    "http",
+	123:   "ntp",
+	389:   "ldap",
+	443:   "https",
+	636:   "ldaps",
+	1433:  "mssql",
+	1521:  "oracle",
+	1883:  "mqtt",
+	2181:  "zookeeper",
+	2379:  "etcd",
+	3306:  "mysql",
+	4222:  "nats",
+	5432:  "postgres",
+	5601:  "kibana",
+	5672:  "amqp",
+	6379:  "redis",
+	6443:  "kubernetes-api",
+	8080:  "http-alt",
+	8086:  "influxdb",
+	8443:  "https-alt",
+	9090:  "prometheus",
+	9092:  "kafka",
+	9100:  "node-exporter",
+	9200:  "elasticsearch",
+	10250: "kubelet",
+	11211: "memcached",
+	15672: "rabbitmq-management",
+	26257: "cockroachdb",
+	27017: "mongodb",
+}
+
+// LoadServices reads a YAML map of port numbers to service names, such as
+// "8081: billing-api", that extend and override the built-in names
+func LoadServices(path string) (map[uint32]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read services: %w", err)
+	}
+
+	var services map[uint32]string
+	if err := yaml.Unmarshal(content, &services); err != nil {
+		return nil, fmt.Errorf("failed to parse services %s: %w", path, err)
+	}
+	for port, name := range services {
+		if port == 0 || port > 65535 {
+			return nil, fmt.Errorf("services %s: %d is not a port", path, port)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("services %s: port %d has no name", path, port)
+		}
+	}
+
+	return services, nil
+}
+
+// portServices names the services behind the listening ports, from extra
+// first and then the built-in table; ports neither knows are left out
+func portServices(ports []uint32, extra map[uint32]string) map[uint32]string {
+	var services map[uint32]string
+	for _, port := range ports {
+		name, ok := extra[port]
+		if !ok {
+			name, ok = wellKnownServices[port]
+		}
+		if !ok {
+			continue
+		}
+		if services == nil {
+			services = make(map[uint32]string)
+		}
+		services[port] = name
+	}
+	return services
+}
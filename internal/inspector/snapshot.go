@@ -0,0 +1,90 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"inspektor/internal/models"
+)
+
+// SchemaVersion versions the JSON inspection document. It is bumped when a
+// change would make older documents read wrongly, so a saved snapshot is
+// either understood or rejected, never misread.
+const SchemaVersion = 1
+
+// InspectSnapshot analyzes and renders an inspection saved earlier with
+// --json, e.g. of a process that has since crashed, without touching any
+// live process. Its findings are produced afresh; the saved ones are
+// ignored. Checks relative to the current time, such as process age, judge
+// the snapshot as of now.
+func (i *Inspector) InspectSnapshot(path string, opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	data, err := loadSnapshot(path)
+	if err != nil {
+		return err
+	}
+	return i.inspectSaved(data, opts)
+}
+
+// inspectSaved analyzes and renders an inspection collected elsewhere, from
+// a snapshot file or a remote host
+func (i *Inspector) inspectSaved(data *models.InspectionData, opts Options) error {
+	if opts.MetricOnly != "" {
+		return outputMetric(data, opts.MetricOnly)
+	}
+
+	findings := i.analyze(context.Background(), data, opts)
+	if opts.Quiet && len(findings) == 0 {
+		return nil
+	}
+	if opts.JSON {
+		return i.outputJSON(data, findings, opts)
+	}
+	i.render(data, findings, opts)
+	return nil
+}
+
+// loadSnapshot reads the first JSON document in path and checks it is an
+// inspection this version can analyze. Documents from before schema
+// versioning carry no version and are read as version 1, which they match.
+func loadSnapshot(path string) (*models.InspectionData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer file.Close()
+
+	return decodeSnapshot(file, "snapshot "+path)
+}
+
+// decodeSnapshot reads one JSON inspection document from r, naming it by
+// source in errors
+func decodeSnapshot(r io.Reader, source string) (*models.InspectionData, error) {
+	snapshot := inspectionOutput{InspectionData: &models.InspectionData{}}
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+
+	switch {
+	case snapshot.SchemaVersion > SchemaVersion:
+		return nil, fmt.Errorf("%s has schema version %d, newer than the %d this inspektor reads; upgrade inspektor",
+			source, snapshot.SchemaVersion, SchemaVersion)
+	case snapshot.SchemaVersion < 0:
+		return nil, fmt.Errorf("%s has invalid schema version %d", source, snapshot.SchemaVersion)
+	}
+
+	data := snapshot.InspectionData
+	if data.Process == nil || data.Process.PID == 0 {
+		return nil, fmt.Errorf("%s holds no process inspection (was it saved with --json?)", source)
+	}
+	// A missing system side must be accounted for: collection never got
+	// there, or it failed and said so
+	if data.System == nil && !data.TimedOut && len(data.CollectionErrors) == 0 {
+		return nil, fmt.Errorf("%s has no system information", source)
+	}
+	return data, nil
+}
@@ -0,0 +1,52 @@
+package inspector
+
+import (
+	"strings"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// stallTracker watches for a process that stays in D state while its CPU
+// time stands still
+type stallTracker struct {
+	since   time.Time
+	samples int
+	cpuTime float64
+}
+
+// observe records one sample and reports the stall, or nil while the
+// process is running normally or hasn't been stuck for long enough
+func (t *stallTracker) observe(proc *models.ProcessInfo) *models.StallInfo {
+	cpuTime := proc.CPUTimeUser + proc.CPUTimeSystem
+	switch {
+	case !uninterruptible(proc.Status):
+		t.samples = 0
+		return nil
+	case t.samples == 0 || cpuTime != t.cpuTime:
+		// Entering D state, or it made progress since the last sample
+		t.since, t.samples, t.cpuTime = time.Now(), 1, cpuTime
+	default:
+		t.samples++
+	}
+
+	if t.samples < models.StallSamples {
+		return nil
+	}
+	return &models.StallInfo{Since: t.since, Samples: t.samples}
+}
+
+// reset forgets the previous instance after a restart
+func (t *stallTracker) reset() {
+	t.samples = 0
+}
+
+// uninterruptible reports whether a status is D state, which procfs reports
+// as a single letter
+func uninterruptible(status string) bool {
+	switch strings.ToLower(status) {
+	case "d", "disk-sleep":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package inspector
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"inspektor/internal/display"
+)
+
+// InspectByUnit inspects the main process of a systemd unit, or every
+// process in the unit's control group when opts.All is set
+func (i *Inspector) InspectByUnit(unit string, opts Options) error {
+	unit = normalizeUnitName(unit)
+
+	if opts.All {
+		pids, err := i.findUnitProcesses(unit)
+		if err != nil {
+			return fmt.Errorf("failed to list processes of unit %s: %w", unit, err)
+		}
+		targets := make([]string, len(pids))
+		for idx, pid := range pids {
+			targets[idx] = fmt.Sprintf("pid=%d", pid)
+		}
+		return i.InspectBatch(targets, opts)
+	}
+
+	pid, err := i.findProcessByUnit(unit)
+	if err != nil {
+		return fmt.Errorf("failed to resolve unit %s: %w", unit, err)
+	}
+
+	if opts.decorated() {
+		display.ShowBanner("")
+		fmt.Printf("\n%s\n\n", display.FormatSuccess(fmt.Sprintf("Found main process %d of unit %s", pid, unit)))
+	}
+
+	return i.InspectWithOptions(pid, opts)
+}
+
+// findProcessByUnit returns the MainPID systemd tracks for the unit
+func (i *Inspector) findProcessByUnit(unit string) (int32, error) {
+	value, err := systemctlShow(unit, "MainPID")
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected MainPID %q", value)
+	}
+	if pid == 0 {
+		return 0, fmt.Errorf("unit is not running or has no main process (try --all)")
+	}
+
+	return int32(pid), nil
+}
+
+// findUnitProcesses lists every PID in the unit's control group
+func (i *Inspector) findUnitProcesses(unit string) ([]int32, error) {
+	cgroup, err := systemctlShow(unit, "ControlGroup")
+	if err != nil {
+		return nil, err
+	}
+	if cgroup == "" {
+		return nil, fmt.Errorf("unit is not running")
+	}
+
+	// cgroup v2 unified hierarchy first, then the v1 systemd hierarchy
+	candidates := []string{
+		filepath.Join("/sys/fs/cgroup", cgroup, "cgroup.procs"),
+		filepath.Join("/sys/fs/cgroup/systemd", cgroup, "cgroup.procs"),
+	}
+
+	for _, path := range candidates {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		pids := parsePIDList(string(content))
+		if len(pids) == 0 {
+			return nil, fmt.Errorf("control group %s has no processes", cgroup)
+		}
+		return pids, nil
+	}
+
+	return nil, fmt.Errorf("could not read processes of control group %s", cgroup)
+}
+
+// systemctlShow reads a single property of a unit
+func systemctlShow(unit, property string) (string, error) {
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return "", fmt.Errorf("systemd is not running on this host")
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return "", fmt.Errorf("systemctl not found in PATH")
+	}
+
+	out, err := exec.Command("systemctl", "show", "-p", property, "--value", unit).Output()
+	if err != nil {
+		return "", fmt.Errorf("systemctl show failed: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// normalizeUnitName defaults bare names to service units, so "nginx" means
+// "nginx.service"
+func normalizeUnitName(unit string) string {
+	if !strings.Contains(unit, ".") {
+		return unit + ".service"
+	}
+	return unit
+}
+
+func parsePIDList(content string) []int32 {
+	var pids []int32
+	for _, field := range strings.Fields(content) {
+		pid, err := strconv.ParseInt(field, 10, 32)
+		if err == nil && pid > 0 {
+			pids = append(pids, int32(pid))
+		}
+	}
+	return pids
+}
@@ -0,0 +1,89 @@
+package inspector
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// DefaultHotThreads is how many threads --threads lists by default
+const DefaultHotThreads = 10
+
+// threadTimes is one thread's cumulative CPU time, in seconds
+type threadTimes struct {
+	name, state  string
+	user, system float64
+}
+
+// taskStat is one thread as --tid reports it: its times, scheduling and
+// context switches. processor is -1 when the kernel doesn't say, and
+// sinceBoot is when the thread started, zero when unknown.
+type taskStat struct {
+	threadTimes
+	priority, nice         int
+	policy, rtPriority     int
+	processor              int
+	sinceBoot              time.Duration
+	voluntary, involuntary uint64
+}
+
+// threadSample is a reading of every thread of a process
+type threadSample struct {
+	threads map[int32]threadTimes
+	at      time.Time
+}
+
+// readThreadSample reads the process's threads, reporting false where
+// unsupported
+func readThreadSample(pid int32) (threadSample, bool) {
+	threads, ok := readThreads(pid)
+	return threadSample{threads: threads, at: time.Now()}, ok
+}
+
+func (s threadSample) takenAt() time.Time { return s.at }
+
+// hottestThreads returns the top threads by CPU usage between two readings.
+// Threads without a usage figure, new since before, are ranked by their
+// total CPU time, after the rest.
+func hottestThreads(before, after threadSample, top int) []models.ThreadUsage {
+	elapsed := after.at.Sub(before.at)
+	usage := make([]models.ThreadUsage, 0, len(after.threads))
+	for tid, times := range after.threads {
+		thread := models.ThreadUsage{
+			TID:           tid,
+			Name:          times.name,
+			State:         times.state,
+			CPUTimeUser:   times.user,
+			CPUTimeSystem: times.system,
+		}
+		if earlier, found := before.threads[tid]; found && elapsed >= minRateWindow {
+			if spent := times.user + times.system - earlier.user - earlier.system; spent >= 0 {
+				percent := spent / elapsed.Seconds() * 100
+				thread.CPUPercent = &percent
+			}
+		}
+		usage = append(usage, thread)
+	}
+
+	slices.SortFunc(usage, func(a, b models.ThreadUsage) int {
+		switch {
+		case a.CPUPercent != nil && b.CPUPercent != nil:
+			if c := cmp.Compare(*b.CPUPercent, *a.CPUPercent); c != 0 {
+				return c
+			}
+		case a.CPUPercent != nil:
+			return -1
+		case b.CPUPercent != nil:
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(b.CPUTimeUser+b.CPUTimeSystem, a.CPUTimeUser+a.CPUTimeSystem),
+			cmp.Compare(a.TID, b.TID))
+	})
+	if len(usage) > top {
+		usage = usage[:top]
+	}
+	return usage
+}
@@ -0,0 +1,117 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"inspektor/internal/display"
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// InspectThread reports on a single thread of pid, such as one --threads
+// showed busy: its name, state, CPU usage over the sampling window and CPU
+// time, context switches and scheduling. Threads can only be read on their
+// own through Linux /proc.
+func (i *Inspector) InspectThread(pid, tid int32, opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	var done chan bool
+	if opts.decorated() {
+		display.ShowBanner("")
+		done = make(chan bool)
+		go display.ShowProcessingAnimation("Sampling thread...", done)
+	}
+
+	ctx, cancel := opts.inspectionContext(context.Background())
+	defer cancel()
+
+	info, err := withDeadline(ctx, func(ctx context.Context) (*models.ThreadInfo, error) {
+		return i.collectThread(ctx, pid, tid, opts)
+	})
+	if done != nil {
+		done <- true
+		close(done)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		return writeJSON(info, opts)
+	}
+	fmt.Print(i.formatter.FormatThread(info))
+	return nil
+}
+
+// collectThread reads tid twice, CPUInterval apart, measuring its CPU usage
+// and context switch rates as the process collection does for its threads
+func (i *Inspector) collectThread(ctx context.Context, pid, tid int32, opts Options) (*models.ThreadInfo, error) {
+	if unsupportedReaders["thread_cpu"] {
+		return nil, errors.New("--tid needs Linux /proc, where each thread can be read on its own")
+	}
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, fmt.Errorf("process %d not found", pid)
+	}
+	name, _ := proc.NameWithContext(ctx)
+
+	before, ok := readThread(pid, tid)
+	if !ok {
+		return nil, threadNotFound(pid, tid)
+	}
+	beforeAt := time.Now()
+	if opts.CPUInterval > 0 {
+		select {
+		case <-time.After(opts.CPUInterval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("sampling thread: %w", ctx.Err())
+		}
+	}
+	after, ok := readThread(pid, tid)
+	if !ok {
+		return nil, fmt.Errorf("thread %d exited while it was sampled", tid)
+	}
+	afterAt := time.Now()
+
+	usage := hottestThreads(
+		threadSample{threads: map[int32]threadTimes{tid: before.threadTimes}, at: beforeAt},
+		threadSample{threads: map[int32]threadTimes{tid: after.threadTimes}, at: afterAt}, 1)
+
+	info := &models.ThreadInfo{
+		ThreadUsage: usage[0],
+		PID:         pid,
+		ProcessName: name,
+		Priority:    after.priority,
+		Nice:        after.nice,
+		ContextSwitches: &models.ContextSwitches{
+			Voluntary:   after.voluntary,
+			Involuntary: after.involuntary,
+		},
+	}
+	if after.processor >= 0 {
+		info.Processor = &after.processor
+	}
+	if voluntary, involuntary, ok := counterRate(
+		counterSample{in: before.voluntary, out: before.involuntary, at: beforeAt},
+		counterSample{in: after.voluntary, out: after.involuntary, at: afterAt}); ok {
+		info.ContextSwitches.VoluntaryRate, info.ContextSwitches.InvoluntaryRate = &voluntary, &involuntary
+	}
+	info.CreateTime = afterBoot(i.collector.host.bootTime(ctx), after.sinceBoot)
+	if opts.UTC && !info.CreateTime.IsZero() {
+		info.CreateTime = info.CreateTime.UTC()
+	}
+	return info, nil
+}
+
+// threadNotFound explains why pid has no thread tid: it belongs to another
+// process, or doesn't exist at all
+func threadNotFound(pid, tid int32) error {
+	if owner, ok := readThreadGroup(tid); ok {
+		return fmt.Errorf("thread %d belongs to process %d, not %d", tid, owner, pid)
+	}
+	return fmt.Errorf("process %d has no thread %d", pid, tid)
+}
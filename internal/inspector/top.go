@@ -0,0 +1,219 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"inspektor/internal/display"
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+)
+
+// topReport is the JSON shape of a --top-n inspection
+type topReport struct {
+	Timestamp *time.Time           `json:"timestamp,omitempty"`
+	Hostname  string               `json:"hostname"`
+	Host      *models.HostInfo     `json:"host"`
+	System    *models.SystemInfo   `json:"system"`
+	Top       *models.TopConsumers `json:"top"`
+	Summary   string               `json:"summary"`
+	Findings  []models.Finding     `json:"findings"`
+}
+
+// InspectTop ranks every process on the host by CPU, memory, open files and
+// connections, and reports the n heaviest of each along with one summary of
+// the system's health
+func (i *Inspector) InspectTop(n int, opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	var done chan bool
+	if opts.decorated() {
+		display.ShowBanner("")
+		done = make(chan bool)
+		go display.ShowProcessingAnimation("Ranking processes...", done)
+	}
+	stopAnimation := func() {
+		if done != nil {
+			done <- true
+			close(done)
+			done = nil
+		}
+	}
+	defer stopAnimation()
+
+	ctx, cancel := opts.inspectionContext(context.Background())
+	defer cancel()
+
+	top, err := collectTop(ctx, n, opts)
+	if err != nil {
+		return err
+	}
+	sys, err := withDeadline(ctx, func(ctx context.Context) (*models.SystemInfo, error) {
+		return i.sourceFor(opts).CollectSystem(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to collect system info: %w", err)
+	}
+
+	findings := i.analyzer.AnalyzeSystem(sys)
+	i.recordFailOn(nil, findings, opts)
+	// CSV carries only the rankings and GitHub annotations only the
+	// findings, so neither needs a summary
+	var summary string
+	if !opts.CSV && !opts.GitHub {
+		summary = i.analyzer.SummarizeTop(sys, top, findings)
+	}
+	stopAnimation()
+
+	findings = i.divertWarnings(nil, models.FilterBySeverity(findings, opts.MinSeverity), opts)
+	if findings == nil {
+		findings = []models.Finding{}
+	}
+
+	// Quiet mode stays silent unless something needs attention
+	if opts.Quiet && len(findings) == 0 {
+		return nil
+	}
+
+	host := i.collector.host.get(ctx)
+
+	if opts.JSON {
+		report := topReport{Hostname: host.Hostname, Host: host, System: sys, Top: top, Summary: summary, Findings: findings}
+		if opts.JSONLines {
+			now := opts.now()
+			report.Timestamp = &now
+		}
+		return writeJSON(report, opts)
+	}
+	if opts.CSV {
+		_, err := os.Stdout.Write(display.FormatTopCSV(top))
+		return err
+	}
+	if opts.GitHub {
+		fmt.Print(i.formatter.FormatGitHubAnnotations("host "+host.Hostname, findings))
+		return nil
+	}
+
+	if !opts.Quiet {
+		fmt.Print(i.formatter.FormatTop(host, sys, top, summary))
+	}
+	fmt.Print(i.formatter.FormatFindings(findings))
+
+	return nil
+}
+
+// withTopSize is how many of the heaviest processes --with-top lists
+const withTopSize = 5
+
+// collectTop enumerates processes once and samples every CPU counter in a
+// single pass: all handles are primed, then one CPUInterval wait covers them
+// all. Connections come from one system-wide socket scan rather than a scan
+// per process.
+func collectTop(ctx context.Context, n int, opts Options) (*models.TopConsumers, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	self := int32(os.Getpid())
+	sampled := procs[:0]
+	for _, proc := range procs {
+		if proc.Pid == self && !opts.IncludeSelf || skipKernelThread(proc.Pid, opts) {
+			continue
+		}
+		if opts.CPUInterval > 0 {
+			// The first call only records the counters on the handle
+			if _, err := proc.PercentWithContext(ctx, 0); err != nil {
+				continue
+			}
+		}
+		sampled = append(sampled, proc)
+	}
+
+	if opts.CPUInterval > 0 {
+		select {
+		case <-time.After(opts.CPUInterval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("sampling CPU: %w", ctx.Err())
+		}
+	}
+
+	connections := make(map[int32]int)
+	if conns, err := net.ConnectionsWithContext(ctx, "inet"); err == nil {
+		for _, conn := range conns {
+			connections[conn.Pid]++
+		}
+	}
+
+	usage := make([]models.ProcessUsage, 0, len(sampled))
+	for _, proc := range sampled {
+		// Processes that exited since the listing are dropped
+		name, err := proc.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		entry := models.ProcessUsage{PID: proc.Pid, Name: name, Connections: connections[proc.Pid], Self: proc.Pid == self,
+			KernelThread: isKernelThread(proc.Pid)}
+		if opts.CPUInterval > 0 {
+			entry.CPUPercent, _ = proc.PercentWithContext(ctx, 0)
+		} else {
+			entry.CPUPercent, _ = proc.CPUPercentWithContext(ctx)
+		}
+		if memInfo, err := proc.MemoryInfoWithContext(ctx); err == nil {
+			entry.MemoryRSS = memInfo.RSS
+		}
+		if fds, err := proc.NumFDsWithContext(ctx); err == nil {
+			entry.OpenFiles = int(fds)
+		}
+		usage = append(usage, entry)
+	}
+
+	top := &models.TopConsumers{
+		Processes:     len(usage),
+		ByCPU:         topBy(usage, n, func(u models.ProcessUsage) float64 { return u.CPUPercent }),
+		ByMemory:      topBy(usage, n, func(u models.ProcessUsage) float64 { return float64(u.MemoryRSS) }),
+		ByOpenFiles:   topBy(usage, n, func(u models.ProcessUsage) float64 { return float64(u.OpenFiles) }),
+		ByConnections: topBy(usage, n, func(u models.ProcessUsage) float64 { return float64(u.Connections) }),
+	}
+
+	// A --sort-by that combines several metrics adds a ranking of its own;
+	// a single metric would only repeat one of the above
+	if expr, err := models.ParseScoreExpr(opts.SortBy); err == nil && len(expr) > 1 {
+		procs := make([]*models.ProcessInfo, len(usage))
+		for idx, entry := range usage {
+			procs[idx] = &models.ProcessInfo{CPUPercent: entry.CPUPercent, MemoryRSS: entry.MemoryRSS, OpenFiles: entry.OpenFiles, Connections: entry.Connections}
+		}
+		for idx, score := range expr.Scores(procs) {
+			usage[idx].Score = score
+		}
+		top.Score = opts.SortBy
+		top.ByScore = topBy(usage, n, func(u models.ProcessUsage) float64 { return u.Score })
+	}
+
+	return top, nil
+}
+
+// topBy returns the n entries with the highest nonzero value, heaviest first
+func topBy(usage []models.ProcessUsage, n int, value func(models.ProcessUsage) float64) []models.ProcessUsage {
+	ranked := make([]models.ProcessUsage, 0, len(usage))
+	for _, entry := range usage {
+		if value(entry) > 0 {
+			ranked = append(ranked, entry)
+		}
+	}
+	sort.SliceStable(ranked, func(a, b int) bool {
+		if value(ranked[a]) != value(ranked[b]) {
+			return value(ranked[a]) > value(ranked[b])
+		}
+		return ranked[a].PID < ranked[b].PID
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
@@ -0,0 +1,162 @@
+package inspector
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// maxTreeNodes bounds how many processes a single tree walk will visit so a
+// fork bomb can't make the inspection itself run away
+const maxTreeNodes = 1000
+
+// unlimitedTreeDepth lets --include-children walk every descendant on its
+// own; maxTreeNodes still bounds the walk
+const unlimitedTreeDepth = math.MaxInt32
+
+// collectTree walks the descendants of proc up to maxDepth levels and
+// aggregates CPU and memory across the whole subtree. With descriptors set it
+// also counts each node's open files and connections. CPU is sampled once
+// the walk is done, every node over the same CPUInterval window.
+func (c *Collector) collectTree(ctx context.Context, proc *process.Process, maxDepth int, descriptors bool) *models.ProcessTree {
+	tree := &models.ProcessTree{}
+	visited := make(map[int32]bool)
+	var samples []treeSample
+	tree.Root = c.walkTree(ctx, proc, 0, maxDepth, descriptors, visited, tree, &samples)
+	tree.Descendants = len(visited) - 1
+	tree.TotalCPU = sampleTreeCPU(ctx, samples, c.opts.CPUInterval)
+	return tree
+}
+
+// cpuSampler reads a process's CPU counters; *process.Process is one
+type cpuSampler interface {
+	PercentWithContext(ctx context.Context, interval time.Duration) (float64, error)
+	CPUPercentWithContext(ctx context.Context) (float64, error)
+}
+
+// treeSample is a tree node waiting for its CPU reading
+type treeSample struct {
+	node *models.ProcessNode
+	proc cpuSampler
+}
+
+// sampleTreeCPU fills in each node's CPU usage and returns their sum. Like
+// collectTop, it primes every handle, waits out a single interval and reads
+// them all, so the nodes are measured over the same window as the process
+// itself rather than over their lifetimes. A zero interval gives the
+// lifetime averages.
+func sampleTreeCPU(ctx context.Context, samples []treeSample, interval time.Duration) float64 {
+	if interval > 0 {
+		// The first call only records the counters on the handle
+		for _, sample := range samples {
+			_, _ = sample.proc.PercentWithContext(ctx, 0)
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return 0
+		}
+	}
+
+	var total float64
+	for _, sample := range samples {
+		if interval > 0 {
+			sample.node.CPUPercent, _ = sample.proc.PercentWithContext(ctx, 0)
+		} else {
+			sample.node.CPUPercent, _ = sample.proc.CPUPercentWithContext(ctx)
+		}
+		total += sample.node.CPUPercent
+	}
+	return total
+}
+
+// treeTotals adds the descendants' usage from tree to the process's own
+// figures. Self comes from the full collection rather than the root node so
+// the totals agree with the rest of the report; its CPU is sampled over a
+// window as long as the descendants' one.
+func treeTotals(self *models.ProcessInfo, tree *models.ProcessTree) *models.TreeTotals {
+	totals := &models.TreeTotals{
+		Processes:   1,
+		CPUPercent:  self.CPUPercent,
+		MemoryRSS:   self.MemoryRSS,
+		OpenFiles:   self.OpenFiles,
+		Connections: self.Connections,
+		Truncated:   tree.Truncated,
+	}
+
+	var add func(nodes []*models.ProcessNode)
+	add = func(nodes []*models.ProcessNode) {
+		for _, node := range nodes {
+			totals.Processes++
+			totals.CPUPercent += node.CPUPercent
+			totals.MemoryRSS += node.MemoryRSS
+			totals.OpenFiles += node.OpenFiles
+			totals.Connections += node.Connections
+			add(node.Children)
+		}
+	}
+	add(tree.Root.Children)
+
+	return totals
+}
+
+// walkTree builds the node for proc and its descendants, queuing each one
+// on samples for its CPU reading
+func (c *Collector) walkTree(ctx context.Context, proc *process.Process, depth, maxDepth int, descriptors bool, visited map[int32]bool, tree *models.ProcessTree, samples *[]treeSample) *models.ProcessNode {
+	visited[proc.Pid] = true
+
+	name, _ := proc.NameWithContext(ctx)
+	var rss uint64
+	if memInfo, err := proc.MemoryInfoWithContext(ctx); err == nil {
+		rss = memInfo.RSS
+	}
+
+	tree.TotalRSS += rss
+
+	node := &models.ProcessNode{
+		PID:       proc.Pid,
+		Name:      name,
+		MemoryRSS: rss,
+	}
+	*samples = append(*samples, treeSample{node: node, proc: proc})
+	if _, sid, ok := readSession(proc.Pid); ok {
+		node.SID = sid
+	}
+
+	if descriptors {
+		if fds, err := proc.NumFDsWithContext(ctx); err == nil {
+			node.OpenFiles = int(fds)
+		}
+		if connections, err := proc.ConnectionsWithContext(ctx); err == nil {
+			node.Connections = len(connections)
+		}
+	}
+
+	children, err := proc.ChildrenWithContext(ctx)
+	if err != nil || len(children) == 0 {
+		return node
+	}
+
+	if depth >= maxDepth {
+		tree.Truncated = true
+		return node
+	}
+
+	for _, child := range children {
+		// Guard against PID reuse producing a cycle
+		if visited[child.Pid] {
+			continue
+		}
+		if len(visited) >= maxTreeNodes || ctx.Err() != nil {
+			tree.Truncated = true
+			break
+		}
+		node.Children = append(node.Children, c.walkTree(ctx, child, depth+1, maxDepth, descriptors, visited, tree, samples))
+	}
+
+	return node
+}
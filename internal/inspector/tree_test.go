@@ -0,0 +1,79 @@
+package inspector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// fakeSampler reports a process busy now but idle over its lifetime
+type fakeSampler struct {
+	lifetime float64
+	window   float64
+	primed   bool
+}
+
+func (s *fakeSampler) PercentWithContext(ctx context.Context, interval time.Duration) (float64, error) {
+	if !s.primed {
+		s.primed = true
+		return 0, nil
+	}
+	return s.window, nil
+}
+
+func (s *fakeSampler) CPUPercentWithContext(ctx context.Context) (float64, error) {
+	return s.lifetime, nil
+}
+
+// fakeTree is a parent with two workers, busy now after hours idle
+func fakeTree() (*models.ProcessTree, []treeSample) {
+	workers := []*models.ProcessNode{{PID: 11, Name: "worker"}, {PID: 12, Name: "worker"}}
+	root := &models.ProcessNode{PID: 10, Name: "pool", Children: workers}
+	samples := []treeSample{
+		{node: root, proc: &fakeSampler{lifetime: 0.5, window: 5}},
+		{node: workers[0], proc: &fakeSampler{lifetime: 0.1, window: 90}},
+		{node: workers[1], proc: &fakeSampler{lifetime: 0.2, window: 60}},
+	}
+	return &models.ProcessTree{Root: root, Descendants: 2}, samples
+}
+
+func TestTreeCPUSampledOverOneWindow(t *testing.T) {
+	tree, samples := fakeTree()
+	tree.TotalCPU = sampleTreeCPU(context.Background(), samples, 10*time.Millisecond)
+
+	if tree.TotalCPU != 155 {
+		t.Errorf("TotalCPU = %g, want the windowed 155", tree.TotalCPU)
+	}
+	for _, sample := range samples {
+		if !sample.proc.(*fakeSampler).primed {
+			t.Errorf("PID %d wasn't primed before the window", sample.node.PID)
+		}
+	}
+
+	// The process itself, sampled over the same length of window
+	self := &models.ProcessInfo{PID: 10, CPUPercent: 5}
+	if totals := treeTotals(self, tree); totals.CPUPercent != 155 || totals.Processes != 3 {
+		t.Errorf("totals = %g%% over %d processes, want 155%% over 3", totals.CPUPercent, totals.Processes)
+	}
+}
+
+func TestTreeCPULifetime(t *testing.T) {
+	tree, samples := fakeTree()
+	if total := sampleTreeCPU(context.Background(), samples, 0); total != 0.8 {
+		t.Errorf("lifetime TotalCPU = %g, want 0.8", total)
+	}
+	if cpu := tree.Root.Children[0].CPUPercent; cpu != 0.1 {
+		t.Errorf("worker CPU = %g, want its lifetime 0.1", cpu)
+	}
+}
+
+func TestTreeCPUDeadline(t *testing.T) {
+	_, samples := fakeTree()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if total := sampleTreeCPU(ctx, samples, time.Hour); total != 0 {
+		t.Errorf("TotalCPU after the deadline = %g, want 0", total)
+	}
+}
@@ -0,0 +1,34 @@
+package inspector
+
+import (
+	"context"
+	"time"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+const (
+	// trendInterval is the gap between the two readings --trend compares
+	trendInterval = 250 * time.Millisecond
+	// The smallest moves that count as a direction rather than noise: CPU
+	// usage must shift by cpuTrendPoints percentage points and by
+	// cpuTrendRatio of itself, RSS by memoryTrendRatio of itself
+	cpuTrendPoints   = 2.0
+	cpuTrendRatio    = 0.1
+	memoryTrendRatio = 0.01
+)
+
+// sampleTrend takes a second, quick reading of CPU and memory and compares
+// it with the inspection's own, cpuPercent and rss. A reading that fails
+// leaves its trend unknown.
+func sampleTrend(ctx context.Context, proc *process.Process, cpuPercent float64, rss uint64) (cpuTrend, memoryTrend models.Trend) {
+	if percent, err := proc.PercentWithContext(ctx, trendInterval); err == nil {
+		cpuTrend = models.TrendOf(cpuPercent, percent, max(cpuTrendPoints, cpuPercent*cpuTrendRatio))
+	}
+	if memInfo, err := proc.MemoryInfoWithContext(ctx); err == nil && rss > 0 {
+		memoryTrend = models.TrendOf(float64(rss), float64(memInfo.RSS), float64(rss)*memoryTrendRatio)
+	}
+	return cpuTrend, memoryTrend
+}
@@ -0,0 +1,57 @@
+package inspector
+
+import (
+	"fmt"
+	"os"
+
+	"inspektor/internal/models"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// InspectByUser inspects every process owned by a user, then reports their
+// combined usage, flagging any part of the analyzer's user budget it exceeds
+func (i *Inspector) InspectByUser(user string, opts Options) error {
+	pids, err := i.findProcessesByUser(user, opts)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]string, len(pids))
+	for idx, pid := range pids {
+		targets[idx] = fmt.Sprintf("pid=%d", pid)
+	}
+	opts.group = &groupReport{User: user, Totals: &models.TreeTotals{}}
+	return i.InspectBatch(targets, opts)
+}
+
+// findProcessesByUser lists the processes whose owner is the named user,
+// leaving out inspektor itself unless opts.IncludeSelf is set, and kernel
+// threads with opts.NoKernelThreads
+func (i *Inspector) findProcessesByUser(user string, opts Options) ([]int32, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	self := int32(os.Getpid())
+	var pids []int32
+	for _, proc := range procs {
+		if proc.Pid == self && !opts.IncludeSelf || skipKernelThread(proc.Pid, opts) {
+			continue
+		}
+		owner, err := proc.Username()
+		if err != nil {
+			continue
+		}
+		if owner == user {
+			pids = append(pids, proc.Pid)
+		}
+	}
+
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no process found owned by user %q", user)
+	}
+
+	return pids, nil
+}
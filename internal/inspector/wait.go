@@ -0,0 +1,84 @@
+package inspector
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"inspektor/internal/display"
+)
+
+// DefaultWaitTimeout bounds --wait-for-port when no timeout is given
+const DefaultWaitTimeout = time.Minute
+
+// waitPollInterval is how often --wait-for-port checks for a listener
+const waitPollInterval = 250 * time.Millisecond
+
+// WaitForPort blocks until a process is listening on the port, then inspects
+// it like InspectByPort. It fails if nothing listens within timeout; zero
+// waits indefinitely. This folds a readiness probe into the inspection for
+// startup scripts.
+func (i *Inspector) WaitForPort(port int, timeout time.Duration, opts Options) error {
+	query := PortQuery{Port: port, Proto: opts.Proto, Address: opts.BindAddress}
+
+	var done chan bool
+	if opts.decorated() {
+		display.ShowBanner("")
+		done = make(chan bool)
+		go display.ShowProcessingAnimation(fmt.Sprintf("Waiting for a listener on %s...", query), done)
+	}
+
+	started := time.Now()
+	pid, err := i.pollPort(query, timeout)
+
+	if done != nil {
+		done <- true
+		close(done)
+		time.Sleep(100 * time.Millisecond)
+	}
+	var multiple *MultipleListenersError
+	if errors.As(err, &multiple) {
+		return i.inspectListeners(multiple, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.decorated() {
+		fmt.Printf("\n%s\n\n", display.FormatSuccess(fmt.Sprintf("Process %d listening on %s after %s", pid, query, time.Since(started).Round(time.Millisecond))))
+	}
+
+	opts.watchPort = &query
+	return i.InspectWithOptions(pid, opts)
+}
+
+// pollPort retries the listener lookup until it succeeds or timeout passes
+func (i *Inspector) pollPort(query PortQuery, timeout time.Duration) (int32, error) {
+	switch query.Proto {
+	case "", "tcp", "udp":
+	default:
+		return 0, fmt.Errorf("unsupported protocol %q (expected tcp or udp)", query.Proto)
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Several listeners won't settle by waiting
+		pid, err := i.findProcessByPort(query)
+		var multiple *MultipleListenersError
+		if err == nil || errors.As(err, &multiple) {
+			return pid, err
+		}
+
+		select {
+		case <-deadline:
+			return 0, fmt.Errorf("nothing listening on %s after %s: %w", query, timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package inspector
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// warningLine is the JSON Lines shape of one finding written to the
+// --warnings-to channel; PID and Name are unset for host-wide findings
+type warningLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	PID       int32     `json:"pid,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	models.Finding
+}
+
+// divertWarnings writes findings to opts.Warnings, one line each, and returns
+// none for the report, which then carries only the data. Without a warnings
+// writer the findings stay in the report. data is nil for host-wide findings.
+func (i *Inspector) divertWarnings(data *models.InspectionData, findings []models.Finding, opts Options) []models.Finding {
+	if opts.Warnings == nil {
+		return findings
+	}
+
+	subject := "host"
+	var pid int32
+	var name string
+	if data != nil {
+		pid, name = data.Process.PID, data.Process.Name
+		subject = fmt.Sprintf("PID %d (%s)", pid, name)
+	}
+	now := opts.now()
+	for _, finding := range findings {
+		if !opts.WarningsJSON {
+			fmt.Fprintf(opts.Warnings, "%s: %s\n", subject, i.formatter.FormatFindingLine(finding))
+			continue
+		}
+		line, err := json.Marshal(warningLine{Timestamp: now, PID: pid, Name: name, Finding: finding})
+		if err != nil {
+			continue
+		}
+		fmt.Fprintln(opts.Warnings, string(line))
+	}
+	return nil
+}
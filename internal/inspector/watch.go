@@ -0,0 +1,230 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+
+	"inspektor/internal/models"
+)
+
+// DefaultWatchInterval is used when watch mode is enabled without an interval
+const DefaultWatchInterval = 2 * time.Second
+
+// watchHistorySize is how many recent samples feed the history sparklines
+const watchHistorySize = 30
+
+// sampleOutputLimit is how many recent samples --sample-output keeps, so a
+// long watch doesn't grow without bound
+const sampleOutputLimit = 1000
+
+// Watch re-inspects the process every interval until interrupted, rendering
+// the latest report along with a short history of CPU and memory. With
+// opts.Repeat set it instead prints that many reports one after another and
+// returns. With opts.Until set it returns once the condition holds, or with
+// an error if opts.WatchTimeout expires first. If the process restarts, watching
+// follows the new instance and the report counts the restarts. Each report
+// marks the resource metrics that changed since the previous tick, a
+// process stuck in uninterruptible sleep across ticks is reported as stalled,
+// one writing to disk heavily across ticks as such and one whose open files
+// climb steadily with when they reach its limit. With
+// opts.AdaptiveInterval set the interval stretches while the process holds
+// steady.
+func (i *Inspector) Watch(pid int32, opts Options) error {
+	i.applyDisplayOptions(opts)
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	// An interrupt exits through display.HandleInterrupts; only a timeout
+	// ends the watch from here
+	ctx := context.Background()
+	if opts.WatchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.WatchTimeout)
+		defer cancel()
+	}
+	started := time.Now()
+
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return fmt.Errorf("failed to get process: %w", err)
+	}
+
+	// Prime the CPU delta so the first tick reports a meaningful value, then
+	// let every tick measure against the previous one without extra waiting
+	_, _ = proc.Percent(0)
+	opts.cpuDelta = true
+
+	if opts.FollowChildren {
+		opts.IncludeChildren = true
+		opts.children = &childTracker{}
+	}
+
+	collector := i.collectorFor(opts)
+	tracker := newRestartTracker(ctx, proc, opts.watchPort)
+	var stall stallTracker
+	var writes writeTracker
+	var descriptors fdTracker
+	var command commandTracker
+	defer func() { i.formatter.Previous = nil }()
+
+	var cpuHistory []float64
+	var memHistory []uint64
+	var samples []models.ProcessSample
+
+	var adaptive *adaptiveInterval
+	if opts.AdaptiveInterval {
+		maxInterval := opts.MaxInterval
+		if maxInterval <= 0 {
+			maxInterval = DefaultMaxInterval
+		}
+		adaptive = newAdaptiveInterval(interval, maxInterval, opts.DiffThresholds)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for sample := 1; ; sample++ {
+		next, restarted, err := tracker.follow(ctx, i, proc)
+		if ctx.Err() != nil {
+			return watchEnded(ctx, opts)
+		}
+		if err != nil {
+			return err
+		}
+		if restarted {
+			proc, pid = next, next.Pid
+			_, _ = proc.Percent(0)
+			// A new instance has nothing to compare against
+			i.formatter.Previous = nil
+			stall.reset()
+			writes.reset()
+			descriptors.reset()
+			if opts.children != nil {
+				opts.children.reset()
+			}
+		}
+
+		tickCtx, cancel := opts.inspectionContext(ctx)
+		data, err := collector.collectFrom(tickCtx, proc)
+		cancel()
+		if ctx.Err() != nil {
+			return watchEnded(ctx, opts)
+		}
+		if err != nil {
+			return fmt.Errorf("process %d is no longer available: %w", pid, err)
+		}
+		data.Restarts = tracker.restarts()
+		if !data.TimedOut {
+			data.Stalled = stall.observe(data.Process)
+			data.HeavyWrites = writes.observe(data.Process)
+			data.FDGrowth = descriptors.observe(data.Process)
+			data.CommandChanged = command.observe(data.Process, opts.now())
+		}
+
+		cpuHistory = appendBounded(cpuHistory, data.Process.CPUPercent, watchHistorySize)
+		memHistory = appendBounded(memHistory, data.Process.MemoryRSS, watchHistorySize)
+		if opts.SampleOutput && !data.TimedOut {
+			samples = appendBounded(samples, models.ProcessSample{
+				Timestamp:  opts.now(),
+				CPUPercent: data.Process.CPUPercent,
+				MemoryRSS:  data.Process.MemoryRSS,
+			}, sampleOutputLimit)
+			data.Samples = samples
+		}
+
+		findings := i.analyze(ctx, data, opts)
+
+		if adaptive != nil {
+			if next := adaptive.next(i.formatter.Previous, data.Process, data.TimedOut); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
+		}
+
+		if opts.Quiet {
+			// Only report ticks that produced findings, without redrawing
+			if len(findings) > 0 {
+				if opts.JSON {
+					if err := i.outputJSON(data, findings, opts); err != nil {
+						return err
+					}
+				} else {
+					i.render(data, findings, opts)
+				}
+			}
+		} else if opts.JSON {
+			if err := i.outputJSON(data, findings, opts); err != nil {
+				return err
+			}
+		} else if opts.Repeat > 0 || opts.Markdown || opts.Table || opts.GitHub {
+			// Fixed-count runs are for scripts, Markdown is for pasting and
+			// table rows and annotations read as a log, so print sequential
+			// reports
+			i.render(data, findings, opts)
+		} else {
+			// Redraw in place rather than scrolling
+			fmt.Print("\033[H\033[2J")
+			fmt.Print(i.formatter.FormatReport(data))
+			fmt.Print(i.formatter.FormatHistory(cpuHistory, memHistory))
+			if data.TimedOut {
+				fmt.Print(i.formatter.FormatTimeout())
+			} else {
+				fmt.Print(i.formatter.FormatFindings(findings))
+			}
+			every := interval.String()
+			if adaptive != nil {
+				every = fmt.Sprintf("%s (adaptive, %s to %s)", interval, adaptive.min, adaptive.max)
+			}
+			fmt.Printf("Watching PID %d every %s, press Ctrl+C to stop\n", pid, every)
+		}
+
+		// Later ticks mark what changed since this one
+		if !data.TimedOut {
+			i.formatter.Previous = data.Process
+		}
+
+		if opts.Repeat > 0 && sample >= opts.Repeat {
+			return nil
+		}
+
+		if opts.Until != nil && !data.TimedOut && opts.Until.Met(data.Process) {
+			if !opts.JSON {
+				fmt.Printf("Condition %s met after %s\n", opts.Until, time.Since(started).Round(time.Second))
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return watchEnded(ctx, opts)
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchEnded decides how a watch stopped by ctx finishes: a plain
+// --watch-timeout ends cleanly, but a --watch-until condition that never
+// held is an error
+func watchEnded(ctx context.Context, opts Options) error {
+	if opts.Until != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("condition %s not met within %s", opts.Until, opts.WatchTimeout)
+	}
+	return nil
+}
+
+// appendBounded adds value to history, dropping the oldest entries beyond
+// limit
+func appendBounded[T any](history []T, value T, limit int) []T {
+	history = append(history, value)
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
+}
@@ -0,0 +1,40 @@
+package inspector
+
+import (
+	"time"
+
+	"inspektor/internal/models"
+)
+
+// writeTracker watches for a process that keeps writing to disk above
+// models.HeavyWriteRate
+type writeTracker struct {
+	since   time.Time
+	samples int
+	total   float64
+}
+
+// observe records one sample and reports the heavy writing, or nil while the
+// rate is normal, unknown or hasn't stayed high for long enough
+func (t *writeTracker) observe(proc *models.ProcessInfo) *models.HeavyWrites {
+	rate := proc.DiskWriteRate
+	if rate == nil || *rate < models.HeavyWriteRate {
+		t.samples = 0
+		return nil
+	}
+	if t.samples == 0 {
+		t.since, t.total = time.Now(), 0
+	}
+	t.samples++
+	t.total += *rate
+
+	if t.samples < models.HeavyWriteSamples {
+		return nil
+	}
+	return &models.HeavyWrites{Since: t.since, Samples: t.samples, Rate: t.total / float64(t.samples)}
+}
+
+// reset forgets the previous instance after a restart
+func (t *writeTracker) reset() {
+	t.samples = 0
+}
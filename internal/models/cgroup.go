@@ -0,0 +1,20 @@
+package models
+
+// CgroupMemory is the memory cgroup whose limit binds the process: its own
+// group or the ancestor with the lowest limit. Usage counts page cache the
+// kernel can reclaim before an OOM kill; WorkingSet leaves the inactive part
+// of it out, the figure the kernel's OOM decision and `docker stats` go by.
+type CgroupMemory struct {
+	Path       string `json:"path"`
+	Usage      uint64 `json:"usage"`
+	WorkingSet uint64 `json:"working_set"`
+	Limit      uint64 `json:"limit"`
+}
+
+// Percent is the working set as a share of the limit
+func (c *CgroupMemory) Percent() float64 {
+	if c.Limit == 0 {
+		return 0
+	}
+	return float64(c.WorkingSet) / float64(c.Limit) * 100
+}
@@ -0,0 +1,43 @@
+package models
+
+import "fmt"
+
+// CPUMode selects how per-process CPU usage is expressed. Raw follows top,
+// where 100% is one fully busy core, so a multi-threaded process can exceed
+// 100% (350% is three and a half cores). Normalized divides by the core
+// count, so 100% is the whole machine.
+type CPUMode string
+
+const (
+	CPUModeRaw        CPUMode = "raw"
+	CPUModeNormalized CPUMode = "normalized"
+)
+
+// Valid reports whether m is one of the known modes
+func (m CPUMode) Valid() bool {
+	return m == CPUModeRaw || m == CPUModeNormalized
+}
+
+// Scale expresses a raw per-core percentage in this mode. The empty mode is
+// raw.
+func (m CPUMode) Scale(percent float64, cores int) float64 {
+	if m == CPUModeNormalized && cores > 0 {
+		return percent / float64(cores)
+	}
+	return percent
+}
+
+// Describe renders a raw per-core percentage in this mode, spelling out how
+// many cores it amounts to where the number alone would mislead: "350.0%
+// (3.5 cores)" when raw, "87.5% (3.5/4 cores)" when normalized
+func (m CPUMode) Describe(percent float64, cores int) string {
+	busy := percent / 100
+	switch {
+	case m == CPUModeNormalized && cores > 0:
+		return fmt.Sprintf("%.1f%% (%.1f/%d cores)", m.Scale(percent, cores), busy, cores)
+	case percent >= 100:
+		return fmt.Sprintf("%.1f%% (%.1f cores)", percent, busy)
+	default:
+		return fmt.Sprintf("%.1f%%", percent)
+	}
+}
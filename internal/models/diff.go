@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MetricDelta is the change in one numeric process metric between two
+// inspections, either of the same process over time or of two processes
+type MetricDelta struct {
+	Metric string  `json:"metric"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+	Delta  float64 `json:"delta"`
+}
+
+// Changed reports whether the metric moved by more than rounding noise
+func (d MetricDelta) Changed() bool {
+	return math.Abs(d.Delta) >= 0.05
+}
+
+// Significant reports whether the metric moved by at least its threshold
+// in thresholds; metrics without one only need to beat rounding noise
+func (d MetricDelta) Significant(thresholds DiffThresholds) bool {
+	threshold, ok := thresholds[d.Metric]
+	if !ok {
+		return d.Changed()
+	}
+	return d.Changed() && math.Abs(d.Delta) >= threshold.of(d.Before)
+}
+
+// DiffThreshold is the smallest move of a metric that counts as a change:
+// Value in the metric's own unit (percentage points, bytes, seconds or a
+// count), or a percentage of the earlier reading when Relative is set
+type DiffThreshold struct {
+	Value    float64
+	Relative bool
+}
+
+// of is the threshold in absolute terms, for a metric that was before
+func (t DiffThreshold) of(before float64) float64 {
+	if t.Relative {
+		return math.Abs(before) * t.Value / 100
+	}
+	return t.Value
+}
+
+// DiffThresholds holds the threshold of each metric DiffProcesses reports
+type DiffThresholds map[string]DiffThreshold
+
+// DefaultDiffThresholds ignore the jitter every live process shows: CPU
+// wandering by a fraction of a point and memory by a few pages
+var DefaultDiffThresholds = DiffThresholds{
+	"cpu_percent":    {Value: 1},
+	"cpu_time":       {Value: 1},
+	"memory_rss":     {Value: 1, Relative: true},
+	"memory_percent": {Value: 0.1},
+	"memory_vms":     {Value: 1, Relative: true},
+	"open_files":     {Value: 1},
+	"connections":    {Value: 1},
+	"children":       {Value: 1},
+	"threads":        {Value: 1},
+}
+
+// ParseDiffThresholds reads thresholds such as "5%" or
+// "cpu_percent=2,memory_rss=10%". An entry without a metric applies to every
+// metric, and named ones override it; metrics left out keep their default.
+// A trailing % makes a threshold relative to the earlier reading.
+func ParseDiffThresholds(spec string) (DiffThresholds, error) {
+	thresholds := maps.Clone(DefaultDiffThresholds)
+	overrides := make(DiffThresholds)
+	for _, field := range strings.Split(spec, ",") {
+		metric, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			metric, value = "", metric
+		}
+		metric, value = strings.TrimSpace(metric), strings.TrimSpace(value)
+
+		number, relative := strings.CutSuffix(value, "%")
+		amount, err := strconv.ParseFloat(number, 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("invalid diff threshold %q (expected e.g. 5%% or cpu_percent=2,memory_rss=10%%)", field)
+		}
+		threshold := DiffThreshold{Value: amount, Relative: relative}
+
+		if metric == "" {
+			for name := range thresholds {
+				thresholds[name] = threshold
+			}
+			continue
+		}
+		if _, known := DefaultDiffThresholds[metric]; !known {
+			return nil, fmt.Errorf("unknown diff threshold metric %q (expected one of %s)", metric, strings.Join(DiffMetrics(), ", "))
+		}
+		overrides[metric] = threshold
+	}
+
+	for metric, threshold := range overrides {
+		thresholds[metric] = threshold
+	}
+	return thresholds, nil
+}
+
+// DiffMetrics lists the metrics DiffProcesses reports, sorted
+func DiffMetrics() []string {
+	return slices.Sorted(maps.Keys(DefaultDiffThresholds))
+}
+
+// DiffProcesses compares the numeric metrics of before and after, in a fixed
+// order
+func DiffProcesses(before, after *ProcessInfo) []MetricDelta {
+	metrics := []struct {
+		name  string
+		value func(p *ProcessInfo) float64
+	}{
+		{"cpu_percent", func(p *ProcessInfo) float64 { return p.CPUPercent }},
+		{"cpu_time", func(p *ProcessInfo) float64 { return p.CPUTimeUser + p.CPUTimeSystem }},
+		{"memory_rss", func(p *ProcessInfo) float64 { return float64(p.MemoryRSS) }},
+		{"memory_percent", func(p *ProcessInfo) float64 { return float64(p.MemoryPercent) }},
+		{"memory_vms", func(p *ProcessInfo) float64 { return float64(p.MemoryVMS) }},
+		{"open_files", func(p *ProcessInfo) float64 { return float64(p.OpenFiles) }},
+		{"connections", func(p *ProcessInfo) float64 { return float64(p.Connections) }},
+		{"children", func(p *ProcessInfo) float64 { return float64(p.Children) }},
+		{"threads", func(p *ProcessInfo) float64 { return float64(p.NumThreads) }},
+	}
+
+	deltas := make([]MetricDelta, len(metrics))
+	for idx, metric := range metrics {
+		b, a := metric.value(before), metric.value(after)
+		deltas[idx] = MetricDelta{Metric: metric.name, Before: b, After: a, Delta: a - b}
+	}
+	return deltas
+}
@@ -0,0 +1,33 @@
+package models
+
+import "fmt"
+
+// GoRuntime describes a process found to be a Go program, from the build
+// info the toolchain embeds in every binary
+type GoRuntime struct {
+	// Version is the toolchain that built the binary, such as go1.22.3
+	Version string `json:"version"`
+	// Module is the path of the main module, empty for binaries built
+	// outside one
+	Module string `json:"module,omitempty"`
+	// GOMAXPROCS is the value set in the process's environment; 0 when unset
+	// or unreadable, the runtime then picking its own
+	GOMAXPROCS int `json:"gomaxprocs,omitempty"`
+}
+
+// Procs is how many threads may run Go code at once: GOMAXPROCS when set,
+// otherwise the runtime's default of one per core
+func (g *GoRuntime) Procs(cores int) int {
+	if g.GOMAXPROCS > 0 {
+		return g.GOMAXPROCS
+	}
+	return max(cores, 1)
+}
+
+// Describe summarizes the runtime on one line, e.g. "go1.22.3, GOMAXPROCS 4"
+func (g *GoRuntime) Describe() string {
+	if g.GOMAXPROCS == 0 {
+		return g.Version + ", GOMAXPROCS unset"
+	}
+	return fmt.Sprintf("%s, GOMAXPROCS %d", g.Version, g.GOMAXPROCS)
+}
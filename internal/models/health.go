@@ -0,0 +1,45 @@
+package models
+
+// HealthBand is the coarse verdict a health score falls in, so automation
+// can branch on it without reimplementing the thresholds
+type HealthBand string
+
+const (
+	HealthBandHealthy  HealthBand = "healthy"
+	HealthBandDegraded HealthBand = "degraded"
+	HealthBandCritical HealthBand = "critical"
+)
+
+// HealthBandOf places a 0-100 health score: 80 and up is healthy, 50 to 79
+// degraded and below 50 critical
+func HealthBandOf(score int) HealthBand {
+	switch {
+	case score >= 80:
+		return HealthBandHealthy
+	case score >= 50:
+		return HealthBandDegraded
+	default:
+		return HealthBandCritical
+	}
+}
+
+// Label is the band as reports show it: "Healthy", "Degraded" or "Critical"
+func (b HealthBand) Label() string {
+	switch b {
+	case HealthBandHealthy:
+		return "Healthy"
+	case HealthBandDegraded:
+		return "Degraded"
+	case HealthBandCritical:
+		return "Critical"
+	}
+	return ""
+}
+
+// HealthComponent is one input's share of the health score: Deduction is
+// how many of its Weight points it took off a perfect 100
+type HealthComponent struct {
+	Name      string  `json:"name"`
+	Weight    float64 `json:"weight"`
+	Deduction float64 `json:"deduction"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricFields lists the field names MetricValue accepts: dotted JSON paths
+// into the inspection such as system.cpu_usage or health_score, where the
+// process's own fields need no "process." prefix
+func MetricFields() []string {
+	var fields []string
+	for field, path := range metricPaths() {
+		if field != path || !strings.HasPrefix(path, "process.") {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ValidMetric reports whether MetricValue accepts field
+func ValidMetric(field string) bool {
+	_, ok := metricPaths()[field]
+	return ok
+}
+
+// metricPaths maps each accepted field name to its full path, both with and
+// without the prefix for the process's fields
+func metricPaths() map[string]string {
+	paths := make(map[string]string)
+	walkMetrics(reflect.TypeOf(InspectionData{}), "", func(path string) {
+		paths[path] = path
+	})
+	for path := range paths {
+		if field, found := strings.CutPrefix(path, "process."); found {
+			paths[field] = path
+		}
+	}
+	return paths
+}
+
+// MetricValue renders one field of data as plain text for scripts: numbers
+// without units (floats to one decimal), times as RFC 3339. It fails for an
+// unknown field and for one the inspection didn't collect.
+func MetricValue(data *InspectionData, field string) (string, error) {
+	path, ok := metricPaths()[field]
+	if !ok {
+		return "", fmt.Errorf("unknown field %q", field)
+	}
+
+	value := reflect.ValueOf(data)
+	for _, name := range strings.Split(path, ".") {
+		for value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return "", fmt.Errorf("%s is not available", field)
+			}
+			value = value.Elem()
+		}
+		idx, _ := fieldByJSONName(value.Type(), name)
+		value = value.Field(idx)
+	}
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", fmt.Errorf("%s is not available", field)
+		}
+		value = value.Elem()
+	}
+
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339), nil
+	}
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', 1, 64), nil
+	default:
+		return fmt.Sprint(value.Interface()), nil
+	}
+}
+
+// walkMetrics calls visit with the JSON path of every scalar field reachable
+// from t; lists and maps are left out, having no single value to print
+func walkMetrics(t reflect.Type, prefix string, visit func(path string)) {
+	for idx := 0; idx < t.NumField(); idx++ {
+		name := jsonName(t.Field(idx))
+		if name == "" {
+			continue
+		}
+		field := t.Field(idx).Type
+		if field.Kind() == reflect.Pointer {
+			field = field.Elem()
+		}
+		switch {
+		case field == reflect.TypeOf(time.Time{}):
+			visit(prefix + name)
+		case field.Kind() == reflect.Struct:
+			walkMetrics(field, prefix+name+".", visit)
+		case field.Kind() <= reflect.Complex128 || field.Kind() == reflect.String:
+			visit(prefix + name)
+		}
+	}
+}
+
+// fieldByJSONName finds the index of t's field serialized as name
+func fieldByJSONName(t reflect.Type, name string) (int, bool) {
+	for idx := 0; idx < t.NumField(); idx++ {
+		if jsonName(t.Field(idx)) == name {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// jsonName is the name a field is serialized under, empty when it isn't
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" || !field.IsExported() {
+		return ""
+	}
+	return name
+}
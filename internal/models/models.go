@@ -1,23 +1,418 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // ProcessInfo contains detailed information about a specific process
 type ProcessInfo struct {
-	PID           int32     `json:"pid"`
-	Name          string    `json:"name"`
-	Executable    string    `json:"executable"`
-	CommandLine   string    `json:"command_line"`
-	WorkingDir    string    `json:"working_dir"`
-	Status        string    `json:"status"`
-	CPUPercent    float64   `json:"cpu_percent"`
-	MemoryRSS     uint64    `json:"memory_rss"`
-	MemoryVMS     uint64    `json:"memory_vms"`
-	MemoryPercent float32   `json:"memory_percent"`
-	CreateTime    time.Time `json:"create_time"`
-	Connections   int       `json:"connections"`
-	OpenFiles     int       `json:"open_files"`
-	Children      int       `json:"children"`
+	PID         int32  `json:"pid"`
+	Name        string `json:"name"`
+	Executable  string `json:"executable"`
+	CommandLine string `json:"command_line"`
+	// CommandLineArgs is the unambiguous argv behind CommandLine
+	CommandLineArgs []string `json:"command_line_args,omitempty"`
+	WorkingDir      string   `json:"working_dir"`
+	Status          string   `json:"status"`
+	Terminal        string   `json:"terminal"`
+	// KernelThread marks a thread the kernel runs, such as kworker, which
+	// has no executable, command line or memory of its own; only its state
+	// and CPU use are collected
+	KernelThread bool `json:"kernel_thread,omitempty"`
+	// PGID and SID are the process group and session, which tell a shell
+	// session's children apart from a daemon's workers; omitted off Linux
+	PGID int32 `json:"pgid,omitempty"`
+	SID  int32 `json:"sid,omitempty"`
+	// Username owns the process
+	Username string `json:"username,omitempty"`
+	// Restricted lists details that couldn't be read for lack of privileges
+	// (e.g. another user's process without sudo); they show up as empty
+	Restricted []string `json:"restricted,omitempty"`
+	// CollectionErrors lists every collector that failed, so partial data
+	// can be told apart from genuine zeros
+	CollectionErrors []CollectionError `json:"collection_errors,omitempty"`
+
+	// Capabilities is the effective Linux capability set and Seccomp the
+	// seccomp mode (disabled, strict or filtered); both omitted off Linux
+	Capabilities []string `json:"capabilities,omitempty"`
+	Seccomp      string   `json:"seccomp,omitempty"`
+
+	// Namespaces the process is in, each compared with PID 1's; omitted off
+	// Linux or without access to PID 1
+	Namespaces []Namespace `json:"namespaces,omitempty"`
+
+	// Container the process runs in, detected from its cgroup; name and
+	// image are only resolved with --docker
+	ContainerID    string `json:"container_id,omitempty"`
+	ContainerName  string `json:"container_name,omitempty"`
+	ContainerImage string `json:"container_image,omitempty"`
+	// Root is the process's root directory when it isn't the host's: the
+	// path it was chrooted to, RootChrooted when that path can't be named
+	// from here, or RootContainer for a container's own root filesystem.
+	// Empty for the host's root, or where it couldn't be read.
+	Root string `json:"root,omitempty"`
+
+	// ExecutableState is set when the executable on disk is no longer the
+	// one the process is running, e.g. after an upgrade without a restart
+	ExecutableState ExecutableState `json:"executable_state,omitempty"`
+	// CommandHash fingerprints the executable path and arguments, and the
+	// executable's contents with --hash-binary, so a redeploy or a reused
+	// PID shows as a different hash; BinarySHA256 is that contents digest
+	CommandHash  string `json:"command_hash,omitempty"`
+	BinarySHA256 string `json:"binary_sha256,omitempty"`
+
+	// Go is set when the process is a Go program, for runtime-specific
+	// advice; nil otherwise or when its executable can't be read
+	Go *GoRuntime `json:"go,omitempty"`
+
+	// RuntimeEnv holds the environment variables known to tune the
+	// process's runtime (GOMAXPROCS, JAVA_OPTS, OMP_NUM_THREADS, ...), by
+	// name; the rest of the environment is left out
+	RuntimeEnv map[string]string `json:"runtime_env,omitempty"`
+
+	CPUPercent    float64 `json:"cpu_percent"`
+	CPUTimeUser   float64 `json:"cpu_time_user"`
+	CPUTimeSystem float64 `json:"cpu_time_system"`
+	MemoryRSS     uint64  `json:"memory_rss"`
+	MemoryVMS     uint64  `json:"memory_vms"`
+	MemoryPeakRSS uint64  `json:"memory_peak_rss,omitempty"`
+	// Shared/private/swap breakdown, where the platform exposes it
+	MemoryShared  uint64 `json:"memory_shared,omitempty"`
+	MemoryPrivate uint64 `json:"memory_private,omitempty"`
+	MemorySwap    uint64 `json:"memory_swap,omitempty"`
+	// MemoryShmem is the part of RSS in shared memory segments (SysV and
+	// POSIX shm, shared anonymous mappings), as databases and caches use
+	// for their buffers; Linux only
+	MemoryShmem   uint64  `json:"memory_shmem,omitempty"`
+	MemoryPercent float32 `json:"memory_percent"`
+	// CPUTrend and MemoryTrend are the immediate direction of CPU and
+	// memory usage, from a second reading moments after the first; only
+	// taken with --trend
+	CPUTrend    Trend `json:"cpu_trend,omitempty"`
+	MemoryTrend Trend `json:"memory_trend,omitempty"`
+	// Scheduler is the CPU scheduling policy and priority, omitted off Linux
+	Scheduler *Scheduler `json:"scheduler,omitempty"`
+	// Wait is where the process's threads are blocked in the kernel, omitted
+	// off Linux and while none is
+	Wait *WaitState `json:"wait,omitempty"`
+	// CgroupMemory is the memory cgroup limit the process is held to, the
+	// ceiling that matters in a container; omitted without a limit
+	CgroupMemory *CgroupMemory `json:"cgroup_memory,omitempty"`
+	// OOM is the kernel's OOM killer ranking, omitted off Linux
+	OOM         *OOMScore `json:"oom,omitempty"`
+	CreateTime  time.Time `json:"create_time"`
+	Connections int       `json:"connections"`
+	// NetRxRate and NetTxRate are bytes/sec across the process's network
+	// namespace (host-wide unless it has its own); nil where unavailable
+	NetRxRate *float64 `json:"net_rx_rate,omitempty"`
+	NetTxRate *float64 `json:"net_tx_rate,omitempty"`
+	// DiskReadRate and DiskWriteRate are bytes/sec the process read from and
+	// wrote to storage; nil where the IO counters can't be read
+	DiskReadRate  *float64 `json:"disk_read_rate,omitempty"`
+	DiskWriteRate *float64 `json:"disk_write_rate,omitempty"`
+	// ConnectionStates counts connections by socket state (ESTABLISHED,
+	// CLOSE_WAIT, ...); connectionless sockets are counted as NONE
+	ConnectionStates map[string]int `json:"connection_states,omitempty"`
+	// ListenPorts are the TCP ports the process listens on and the UDP ports
+	// it has bound, in ascending order
+	ListenPorts []uint32 `json:"listen_ports,omitempty"`
+	// PortServices names the service usually behind each listening port
+	// that has a known one, e.g. 5432 -> postgres
+	PortServices map[uint32]string `json:"port_services,omitempty"`
+	OpenFiles    int               `json:"open_files"`
+	// DescriptorsSkipped is set when the process held too many descriptors
+	// to list: OpenFiles is then only their count, and its connections,
+	// file types and deleted files weren't read
+	DescriptorsSkipped bool `json:"descriptors_skipped,omitempty"`
+	// OpenFileTypes splits OpenFiles by what each descriptor refers to
+	OpenFileTypes *OpenFileTypes `json:"open_file_types,omitempty"`
+	MaxOpenFiles  int            `json:"max_open_files"`
+	// Handles counts the kernel objects (files, registry keys, events, ...)
+	// a Windows process holds open. Windows has no descriptor table, so it
+	// stands in for OpenFiles there; omitted elsewhere.
+	Handles uint32 `json:"handles,omitempty"`
+	// Limits are the process's resource limits with current usage, where the
+	// platform exposes them
+	Limits     []Limit `json:"limits,omitempty"`
+	Children   int     `json:"children"`
+	NumThreads int32   `json:"num_threads"`
+	// ContextSwitches are the process's scheduler switches; omitted where
+	// the platform doesn't count them
+	ContextSwitches *ContextSwitches `json:"context_switches,omitempty"`
+	// PowerEstimate is the process's estimated share of the CPU's power
+	// draw; only collected with --power
+	PowerEstimate *PowerEstimate `json:"power_estimate,omitempty"`
+	// HotThreads are the threads using the most CPU, busiest first; only
+	// collected with --threads, and only on Linux
+	HotThreads []ThreadUsage `json:"hot_threads,omitempty"`
+
+	// DeletedFiles are open files that have been unlinked from disk; their
+	// space isn't reclaimed until the process closes them
+	DeletedFiles []DeletedFile `json:"deleted_files,omitempty"`
+
+	// Details are only collected for verbose JSON output, or verbose output
+	// with --resolve
+	Details *ProcessDetails `json:"details,omitempty"`
+}
+
+// ContextSwitches counts how often the scheduler took the CPU off the
+// process's threads: voluntarily when they blocked or slept, involuntarily
+// when they were preempted with work still to do. The rates are per second
+// over the CPU sampling window, nil when it was too short to measure.
+type ContextSwitches struct {
+	Voluntary       uint64   `json:"voluntary"`
+	Involuntary     uint64   `json:"involuntary"`
+	VoluntaryRate   *float64 `json:"voluntary_rate,omitempty"`
+	InvoluntaryRate *float64 `json:"involuntary_rate,omitempty"`
+}
+
+// ThreadUsage is one thread's share of the process's CPU, like a row of
+// top -H. CPUPercent is over the CPU sampling window, per core, and nil
+// when the thread started during it or the window was too short.
+type ThreadUsage struct {
+	TID           int32    `json:"tid"`
+	Name          string   `json:"name"`
+	State         string   `json:"state"`
+	CPUPercent    *float64 `json:"cpu_percent,omitempty"`
+	CPUTimeUser   float64  `json:"cpu_time_user"`
+	CPUTimeSystem float64  `json:"cpu_time_system"`
+}
+
+// ThreadInfo is a single thread inspected on its own with --tid. Processor
+// is the CPU it last ran on, nil where unknown.
+type ThreadInfo struct {
+	ThreadUsage
+	PID             int32            `json:"pid"`
+	ProcessName     string           `json:"process_name"`
+	Priority        int              `json:"priority"`
+	Nice            int              `json:"nice"`
+	Processor       *int             `json:"processor,omitempty"`
+	ContextSwitches *ContextSwitches `json:"context_switches,omitempty"`
+	CreateTime      time.Time        `json:"create_time"`
+}
+
+// ProcessDetails is the fuller picture behind the summary counts: every
+// open descriptor and socket, and the environment with secrets redacted.
+// Resource limits are part of ProcessInfo itself.
+type ProcessDetails struct {
+	OpenFiles   []OpenFile   `json:"open_files,omitempty"`
+	Connections []Connection `json:"connections,omitempty"`
+	// Environment holds KEY=value entries with secrets masked
+	Environment []string `json:"environment,omitempty"`
+}
+
+// OpenFile is one open descriptor and what it refers to: a path, or a
+// "socket:[inode]", "pipe:[inode]" or "anon_inode:..." link target
+type OpenFile struct {
+	FD   uint64 `json:"fd"`
+	Path string `json:"path"`
+}
+
+// Connection is one socket; Remote is empty while listening or unconnected.
+// RemoteHost and Service are only filled in with --resolve, and RemoteHost
+// only when the peer's address has a reverse DNS name.
+type Connection struct {
+	FD         uint32 `json:"fd"`
+	Proto      string `json:"proto"`
+	Local      string `json:"local"`
+	Remote     string `json:"remote,omitempty"`
+	RemoteHost string `json:"remote_host,omitempty"`
+	Service    string `json:"service,omitempty"`
+	State      string `json:"state,omitempty"`
+}
+
+// ConnectionState is one entry of a connection-state breakdown
+type ConnectionState struct {
+	State string
+	Count int
+}
+
+// SortedConnectionStates returns the breakdown ordered by count, most common
+// first, so rendering is stable
+func (p *ProcessInfo) SortedConnectionStates() []ConnectionState {
+	states := make([]ConnectionState, 0, len(p.ConnectionStates))
+	for state, count := range p.ConnectionStates {
+		states = append(states, ConnectionState{State: state, Count: count})
+	}
+	sort.Slice(states, func(a, b int) bool {
+		if states[a].Count != states[b].Count {
+			return states[a].Count > states[b].Count
+		}
+		return states[a].State < states[b].State
+	})
+	return states
+}
+
+// Namespace is one Linux namespace of a process, identified by its inode.
+// Shared means PID 1 is in the same one, so the process sees that part of
+// the host rather than a container's own view.
+type Namespace struct {
+	Type   string `json:"type"`
+	Inode  uint64 `json:"inode"`
+	Shared bool   `json:"shared_with_host"`
+}
+
+// CollectionError is one piece of process data that couldn't be collected,
+// named after the field it would have filled
+type CollectionError struct {
+	Collector string `json:"collector"`
+	Error     string `json:"error"`
+}
+
+// OpenFileTypes counts open descriptors by kind, which tells a file
+// descriptor leak apart from a process that simply holds many sockets
+type OpenFileTypes struct {
+	Files      int `json:"files"`
+	Sockets    int `json:"sockets"`
+	Pipes      int `json:"pipes"`
+	AnonInodes int `json:"anon_inodes"`
+	Other      int `json:"other"`
+}
+
+// Limit is one resource limit (rlimit) of a process. A nil Soft or Hard is
+// unlimited and a nil Used is unknown. Stack and memlock are in bytes, cpu in
+// seconds, and nofile and nproc are counts.
+type Limit struct {
+	Resource string  `json:"resource"`
+	Soft     *uint64 `json:"soft"`
+	Hard     *uint64 `json:"hard"`
+	Used     *uint64 `json:"used,omitempty"`
+}
+
+// UsagePercent is how much of the soft limit is in use; ok is false when the
+// limit is unlimited or usage is unknown
+func (l Limit) UsagePercent() (percent float64, ok bool) {
+	if l.Soft == nil || l.Used == nil || *l.Soft == 0 {
+		return 0, false
+	}
+	return float64(*l.Used) / float64(*l.Soft) * 100, true
+}
+
+// ExecutableState is how a process's executable on disk differs from the
+// image it is running
+type ExecutableState string
+
+const (
+	// ExecutableDeleted means nothing is left at the executable's path
+	ExecutableDeleted ExecutableState = "deleted"
+	// ExecutableReplaced means a different file now sits at the path, as
+	// package upgrades leave it
+	ExecutableReplaced ExecutableState = "replaced"
+	// ExecutableModified means the file was written to after the process
+	// started
+	ExecutableModified ExecutableState = "modified"
+)
+
+// Describe words the state for reports, e.g. "deleted on disk"
+func (s ExecutableState) Describe() string {
+	if s == "" {
+		return ""
+	}
+	return string(s) + " on disk"
+}
+
+// DeletedFile is an open descriptor whose file has been deleted
+type DeletedFile struct {
+	FD   uint64 `json:"fd"`
+	Path string `json:"path"`
+	Size uint64 `json:"size"`
+}
+
+// OwnMemoryPercent is MemoryPercent without the shared memory segments,
+// which every attached process maps and none is solely responsible for
+func (p *ProcessInfo) OwnMemoryPercent() float32 {
+	if p.MemoryShmem == 0 || p.MemoryRSS == 0 {
+		return p.MemoryPercent
+	}
+	if p.MemoryShmem >= p.MemoryRSS {
+		return 0
+	}
+	return p.MemoryPercent * float32(p.MemoryRSS-p.MemoryShmem) / float32(p.MemoryRSS)
+}
+
+// ShmemDominated reports whether shared memory segments make up at least
+// half of RSS, as for a database with a large buffer pool
+func (p *ProcessInfo) ShmemDominated() bool {
+	return p.MemoryShmem > 0 && p.MemoryShmem*2 >= p.MemoryRSS
+}
+
+// HasCapability reports whether the process holds the named capability
+func (p *ProcessInfo) HasCapability(name string) bool {
+	return slices.Contains(p.Capabilities, name)
+}
+
+// fullCapabilityCount is the size of the capability set on kernels since 3.16
+const fullCapabilityCount = 38
+
+// HasAllCapabilities reports whether the process effectively holds every
+// capability, as root or a privileged container does
+func (p *ProcessInfo) HasAllCapabilities() bool {
+	return len(p.Capabilities) >= fullCapabilityCount
+}
+
+// NamespaceSummary lists the namespaces as "net(host), pid(isolated), ..."
+func (p *ProcessInfo) NamespaceSummary() string {
+	parts := make([]string, len(p.Namespaces))
+	for idx, ns := range p.Namespaces {
+		where := "isolated"
+		if ns.Shared {
+			where = "host"
+		}
+		parts[idx] = fmt.Sprintf("%s(%s)", ns.Type, where)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SessionLeader reports whether the process started its session, as shells
+// and daemons that detach with setsid do
+func (p *ProcessInfo) SessionLeader() bool {
+	return p.SID != 0 && p.SID == p.PID
+}
+
+// DisplayName is the process name, bracketed for a kernel thread the way ps
+// shows it, e.g. "[kworker/0:1]"
+func (p *ProcessInfo) DisplayName() string {
+	if p.KernelThread {
+		return "[" + p.Name + "]"
+	}
+	return p.Name
+}
+
+// ListenPortLabels lists the listening ports in order, each followed by
+// its service name where known, e.g. "5432 (postgres)"
+func (p *ProcessInfo) ListenPortLabels() []string {
+	labels := make([]string, len(p.ListenPorts))
+	for idx, port := range p.ListenPorts {
+		labels[idx] = fmt.Sprint(port)
+		if service, ok := p.PortServices[port]; ok {
+			labels[idx] += " (" + service + ")"
+		}
+	}
+	return labels
+}
+
+// DeletedFilesSize is the disk space held by the process's deleted files
+func (p *ProcessInfo) DeletedFilesSize() uint64 {
+	var total uint64
+	for _, file := range p.DeletedFiles {
+		total += file.Size
+	}
+	return total
+}
+
+// Age is how long the process has been running, false when its start time
+// is unknown (a zero CreateTime). Clock adjustments can't make it negative.
+func (p *ProcessInfo) Age() (time.Duration, bool) {
+	if p.CreateTime.IsZero() {
+		return 0, false
+	}
+	return max(time.Since(p.CreateTime), 0), true
 }
 
 // SystemInfo contains system-wide resource information
@@ -29,10 +424,462 @@ type SystemInfo struct {
 	MemoryUsed    uint64  `json:"memory_used"`
 	MemoryPercent float64 `json:"memory_percent"`
 	MemoryFree    uint64  `json:"memory_free"`
+	SwapTotal     uint64  `json:"swap_total"`
+	SwapUsed      uint64  `json:"swap_used"`
+	SwapPercent   float64 `json:"swap_percent"`
+	Load1         float64 `json:"load_1"`
+	Load5         float64 `json:"load_5"`
+	Load15        float64 `json:"load_15"`
+	DiskTotal     uint64  `json:"disk_total"`
+	DiskUsed      uint64  `json:"disk_used"`
+	DiskPercent   float64 `json:"disk_percent"`
+	// Samples is the spread of CPU and memory readings when several were
+	// taken; CPUUsage, MemoryUsed and MemoryPercent are then their averages
+	Samples *SystemSamples `json:"samples,omitempty"`
+	// SelfCPUUsage is inspektor's own CPU use while sampling, in percent of
+	// all cores, which was taken out of CPUUsage; zero with --include-self
+	SelfCPUUsage float64 `json:"self_cpu_usage,omitempty"`
+}
+
+// SystemSamples summarizes repeated system readings
+type SystemSamples struct {
+	Count int `json:"count"`
+	// CPU is system CPU usage and Memory the used memory, both in percent
+	CPU    SampleStats `json:"cpu"`
+	Memory SampleStats `json:"memory"`
+}
+
+// SampleStats is the minimum, average and maximum of a series of readings
+type SampleStats struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+}
+
+// Summarize computes the stats of a non-empty series
+func Summarize(values []float64) SampleStats {
+	stats := SampleStats{Min: values[0], Max: values[0]}
+	var sum float64
+	for _, v := range values {
+		stats.Min = min(stats.Min, v)
+		stats.Max = max(stats.Max, v)
+		sum += v
+	}
+	stats.Avg = sum / float64(len(values))
+	return stats
+}
+
+// ProcessSample is one watch-mode reading of the process, kept for
+// --sample-output so the series can be plotted
+type ProcessSample struct {
+	Timestamp  time.Time `json:"timestamp"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MemoryRSS  uint64    `json:"memory_rss"`
+}
+
+// HostInfo identifies the machine an inspection ran on
+type HostInfo struct {
+	Hostname        string `json:"hostname"`
+	OS              string `json:"os"`
+	Platform        string `json:"platform,omitempty"`
+	PlatformVersion string `json:"platform_version,omitempty"`
+	KernelVersion   string `json:"kernel_version,omitempty"`
+	Arch            string `json:"arch"`
+}
+
+// String is a compact one-line description such as
+// "web-1 (ubuntu 22.04, linux 5.15.0, x86_64)"
+func (h *HostInfo) String() string {
+	platform := h.OS
+	if h.Platform != "" {
+		platform = strings.TrimSpace(h.Platform + " " + h.PlatformVersion)
+	}
+	details := []string{platform}
+	if h.KernelVersion != "" {
+		details = append(details, h.OS+" "+h.KernelVersion)
+	}
+	details = append(details, h.Arch)
+	return fmt.Sprintf("%s (%s)", h.Hostname, strings.Join(details, ", "))
+}
+
+// ProcessNode is a single entry in a process descendant tree
+type ProcessNode struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"`
+	MemoryRSS  uint64  `json:"memory_rss"`
+	// SID is the node's session, omitted off Linux
+	SID int32 `json:"sid,omitempty"`
+	// Descriptor counts are only collected with --include-children
+	OpenFiles   int            `json:"open_files,omitempty"`
+	Connections int            `json:"connections,omitempty"`
+	Children    []*ProcessNode `json:"children,omitempty"`
+}
+
+// ProcessTree holds the descendant tree of the inspected process along with
+// resource usage aggregated across the whole subtree
+type ProcessTree struct {
+	Root        *ProcessNode `json:"root"`
+	Descendants int          `json:"descendants"`
+	TotalCPU    float64      `json:"total_cpu_percent"`
+	TotalRSS    uint64       `json:"total_memory_rss"`
+	Truncated   bool         `json:"truncated"`
+}
+
+// TreeTotals sums resource usage over a process and all its descendants, for
+// processes such as worker pools whose own figures understate the footprint
+type TreeTotals struct {
+	Processes   int     `json:"processes"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemoryRSS   uint64  `json:"memory_rss"`
+	OpenFiles   int     `json:"open_files"`
+	Connections int     `json:"connections"`
+	// Truncated means the walk hit its size limit, so the totals are a floor
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ProcessUsage is one process's resource usage in a system-wide ranking
+type ProcessUsage struct {
+	PID         int32   `json:"pid"`
+	Name        string  `json:"name"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemoryRSS   uint64  `json:"memory_rss"`
+	OpenFiles   int     `json:"open_files"`
+	Connections int     `json:"connections"`
+	// Score is the process's rating under TopConsumers.Score
+	Score float64 `json:"score,omitempty"`
+	// Self marks inspektor's own process, only ranked with --include-self
+	Self bool `json:"self,omitempty"`
+	// KernelThread marks a kernel thread, ranked unless --no-kernel-threads
+	KernelThread bool `json:"kernel_thread,omitempty"`
+}
+
+// DisplayName is the process name, bracketed for a kernel thread
+func (u ProcessUsage) DisplayName() string {
+	if u.KernelThread {
+		return "[" + u.Name + "]"
+	}
+	return u.Name
+}
+
+// TopConsumers ranks the heaviest processes on the host by each resource
+type TopConsumers struct {
+	// Processes is how many processes were enumerated
+	Processes     int            `json:"processes"`
+	ByCPU         []ProcessUsage `json:"by_cpu"`
+	ByMemory      []ProcessUsage `json:"by_memory"`
+	ByOpenFiles   []ProcessUsage `json:"by_open_files"`
+	ByConnections []ProcessUsage `json:"by_connections"`
+	// Score is the --sort-by expression ByScore ranks by, if one was given
+	Score   string         `json:"score,omitempty"`
+	ByScore []ProcessUsage `json:"by_score,omitempty"`
+}
+
+// RestartInfo counts how often a watched process was replaced by a new
+// instance, either under a new PID or with its PID reused
+type RestartInfo struct {
+	Count int `json:"count"`
+	// Since is when watching began, the window Count covers
+	Since       time.Time `json:"since"`
+	Last        time.Time `json:"last"`
+	PreviousPID int32     `json:"previous_pid"`
+}
+
+// StallSamples is how many consecutive samples in uninterruptible sleep
+// without CPU progress make a watched process count as stalled. A single D
+// state sample is routine; a process that stays there is waiting on I/O that
+// isn't completing.
+const StallSamples = 3
+
+// StallInfo describes a watched process stuck in uninterruptible sleep with
+// no CPU progress over consecutive samples
+type StallInfo struct {
+	// Since is the first of the samples, and Samples how many in a row found
+	// the process in D state with unchanged CPU time
+	Since   time.Time `json:"since"`
+	Samples int       `json:"samples"`
+}
+
+// ChildChanges are the descendants a watched process gained and lost, with
+// --follow-children. Spawned and Exited are since the previous tick, the
+// totals since Since, when watching began.
+type ChildChanges struct {
+	Spawned      []ChildProcess `json:"spawned,omitempty"`
+	Exited       []ChildProcess `json:"exited,omitempty"`
+	TotalSpawned int            `json:"total_spawned"`
+	TotalExited  int            `json:"total_exited"`
+	Since        time.Time      `json:"since"`
+}
+
+// ChildProcess names one descendant that appeared or went away
+type ChildProcess struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+}
+
+// HeavyWriteRate is the disk write rate, in bytes/sec, above which a watched
+// process counts as writing heavily
+const HeavyWriteRate = 50 * 1024 * 1024
+
+// HeavyWriteSamples is how many consecutive samples above HeavyWriteRate
+// make the writing sustained. A burst (a flush, a checkpoint) is routine; a
+// process that keeps it up fills disks and starves other IO.
+const HeavyWriteSamples = 3
+
+// HeavyWrites describes a watched process writing to disk above
+// HeavyWriteRate over consecutive samples
+type HeavyWrites struct {
+	// Since is the first of the samples, Samples how many in a row were
+	// above the rate and Rate their average in bytes/sec
+	Since   time.Time `json:"since"`
+	Samples int       `json:"samples"`
+	Rate    float64   `json:"rate"`
+}
+
+// FDGrowthSamples is how many watch samples the open-file count is fitted
+// over before a climb toward the limit counts as steady
+const FDGrowthSamples = 5
+
+// FDGrowth describes a watched process whose open-file count climbs steadily
+// toward its NOFILE limit, projected forward at the fitted rate
+type FDGrowth struct {
+	// Since is the first of the fitted samples and Samples how many there
+	// are; Rate is the fitted growth in descriptors per second
+	Since   time.Time `json:"since"`
+	Samples int       `json:"samples"`
+	Rate    float64   `json:"rate"`
+	// OpenFiles is the latest count and Limit the soft NOFILE limit
+	OpenFiles int `json:"open_files"`
+	Limit     int `json:"limit"`
+	// ExhaustedIn is how many seconds until the limit is reached if the
+	// rate holds
+	ExhaustedIn float64 `json:"exhausted_in_seconds"`
+}
+
+// TimeLeft is ExhaustedIn as a duration
+func (g *FDGrowth) TimeLeft() time.Duration {
+	return time.Duration(g.ExhaustedIn * float64(time.Second))
+}
+
+// CommandChange records that a watched process's command hash changed: it
+// was relaunched from another build or with other arguments, or its PID
+// now belongs to something else. Only the latest change is kept.
+type CommandChange struct {
+	At           time.Time `json:"at"`
+	PreviousHash string    `json:"previous_hash"`
+	Hash         string    `json:"hash"`
+	PreviousPID  int32     `json:"previous_pid"`
 }
 
 // InspectionData combines process and system information
 type InspectionData struct {
-	Process *ProcessInfo `json:"process"`
-	System  *SystemInfo  `json:"system"`
-}
\ No newline at end of file
+	Host        *HostInfo    `json:"host,omitempty"`
+	Process     *ProcessInfo `json:"process"`
+	System      *SystemInfo  `json:"system"`
+	Tree        *ProcessTree `json:"tree,omitempty"`
+	TreeTotals  *TreeTotals  `json:"tree_totals,omitempty"`
+	Restarts    *RestartInfo `json:"restarts,omitempty"`
+	Stalled     *StallInfo   `json:"stalled,omitempty"`
+	HeavyWrites *HeavyWrites `json:"heavy_writes,omitempty"`
+	FDGrowth    *FDGrowth    `json:"fd_growth,omitempty"`
+	// CommandChanged is set in watch mode once the command hash changed
+	CommandChanged *CommandChange `json:"command_changed,omitempty"`
+	HealthScore    int            `json:"health_score"`
+	// HealthComponents break the score down by input, in scoring order
+	HealthComponents []HealthComponent `json:"health_components,omitempty"`
+	// HealthBand is the score's coarse verdict; omitted when the collection
+	// timed out and nothing was scored
+	HealthBand HealthBand `json:"health_band,omitempty"`
+	TimedOut   bool       `json:"timed_out,omitempty"`
+	// ChildChanges tracks spawned and exited descendants while watching with
+	// --follow-children
+	ChildChanges *ChildChanges `json:"child_changes,omitempty"`
+	// Top ranks the host's heaviest processes alongside this one, with
+	// --with-top
+	Top *TopConsumers `json:"top,omitempty"`
+	// Samples are the watch readings so far, oldest first, with
+	// --sample-output
+	Samples []ProcessSample `json:"samples,omitempty"`
+	// CollectionErrors lists whole sections that couldn't be collected, such
+	// as "system" when host-wide calls are blocked; the section is then null
+	// and the rest of the inspection stands
+	CollectionErrors []CollectionError `json:"collection_errors,omitempty"`
+	// DurationMS is how long collection and analysis took, in milliseconds
+	DurationMS int64 `json:"duration_ms,omitempty"`
+}
+
+// Severity ranks how urgent a finding is
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRank orders severities from least to most urgent
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityCritical: 2,
+}
+
+// Valid reports whether s is one of the known severities
+func (s Severity) Valid() bool {
+	_, ok := severityRank[s]
+	return ok
+}
+
+// AtLeast reports whether s is as urgent as min or more
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRank[s] >= severityRank[min]
+}
+
+// FindingKind separates problems from preventive advice
+type FindingKind string
+
+const (
+	KindWarning        FindingKind = "warning"
+	KindRecommendation FindingKind = "recommendation"
+)
+
+// Finding sources
+const (
+	SourceAI    = "ai"
+	SourceRules = "rules"
+	// SourceBaseline marks a deviation from the --baseline profile
+	SourceBaseline = "baseline"
+	// SourceMerged marks an AI finding that absorbed the rule finding
+	// reporting the same issue
+	SourceMerged = "ai+rules"
+)
+
+// Evidence records the metric value and threshold that made a rule fire
+type Evidence struct {
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+}
+
+// String renders evidence as "metric=value op threshold=limit"
+func (e Evidence) String() string {
+	return fmt.Sprintf("%s=%s %s threshold=%s",
+		e.Metric, formatNumber(e.Value), e.Operator, formatNumber(e.Threshold))
+}
+
+// Finding is a single warning or recommendation produced by analysis
+type Finding struct {
+	Kind     FindingKind `json:"kind"`
+	Severity Severity    `json:"severity"`
+	Category string      `json:"category,omitempty"`
+	// Rule is the stable ID of the rule-based check that fired, e.g. "zombie",
+	// and DocURL links to its cause and remediation
+	Rule     string     `json:"rule,omitempty"`
+	DocURL   string     `json:"doc_url,omitempty"`
+	Message  string     `json:"message"`
+	Source   string     `json:"source"`
+	Evidence []Evidence `json:"evidence,omitempty"`
+	// Processes lists every PID a finding from a batched analysis covers,
+	// when the model saw the same issue in several of them
+	Processes []int32 `json:"processes,omitempty"`
+}
+
+func formatNumber(v float64) string {
+	if v == math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+// Matches reports whether the finding's category or rule is one of names
+func (f Finding) Matches(names []string) bool {
+	return slices.Contains(names, f.Category) || (f.Rule != "" && slices.Contains(names, f.Rule))
+}
+
+// MostSevere keeps the n most urgent findings in their original order,
+// preferring earlier ones among equally urgent findings. n <= 0 keeps all.
+func MostSevere(findings []Finding, n int) []Finding {
+	if n <= 0 || len(findings) <= n {
+		return findings
+	}
+	order := make([]int, len(findings))
+	for index := range order {
+		order[index] = index
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return severityRank[findings[b].Severity] - severityRank[findings[a].Severity]
+	})
+	order = order[:n]
+	slices.Sort(order)
+
+	kept := make([]Finding, 0, n)
+	for _, index := range order {
+		kept = append(kept, findings[index])
+	}
+	return kept
+}
+
+// FilterBySeverity keeps the findings at or above min
+func FilterBySeverity(findings []Finding, min Severity) []Finding {
+	if min == "" || min == SeverityInfo {
+		return findings
+	}
+	kept := []Finding{}
+	for _, finding := range findings {
+		if finding.Severity.AtLeast(min) {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+// FindingCounts tallies findings: warnings by severity, recommendations
+// on their own
+type FindingCounts struct {
+	Critical       int `json:"critical"`
+	Warning        int `json:"warning"`
+	Info           int `json:"info"`
+	Recommendation int `json:"recommendation"`
+}
+
+// CountFindings tallies findings, after any severity filter has been applied
+func CountFindings(findings []Finding) FindingCounts {
+	var counts FindingCounts
+	for _, finding := range findings {
+		switch {
+		case finding.Kind == KindRecommendation:
+			counts.Recommendation++
+		case finding.Severity == SeverityCritical:
+			counts.Critical++
+		case finding.Severity == SeverityWarning:
+			counts.Warning++
+		default:
+			counts.Info++
+		}
+	}
+	return counts
+}
+
+// String summarizes the counts as e.g. "3 critical, 2 warnings, 4
+// recommendations", leaving out the zeros
+func (c FindingCounts) String() string {
+	var parts []string
+	for _, count := range []struct {
+		n              int
+		singular, many string
+	}{
+		{c.Critical, "critical", "critical"},
+		{c.Warning, "warning", "warnings"},
+		{c.Info, "info", "info"},
+		{c.Recommendation, "recommendation", "recommendations"},
+	} {
+		switch {
+		case count.n == 1:
+			parts = append(parts, "1 "+count.singular)
+		case count.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.many))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,63 @@
+package models
+
+// OOM risk levels, from the process being exempt to a likely kill
+const (
+	OOMRiskNone     = "none"
+	OOMRiskLow      = "low"
+	OOMRiskModerate = "moderate"
+	OOMRiskHigh     = "high"
+)
+
+// OOMScore is how the kernel's OOM killer ranks the process. Score runs
+// from 0 to 1000, the highest being killed first, and already includes Adj;
+// an Adj of -1000 exempts the process. Risk weighs the score against the
+// system's memory pressure, see OOMRisk.
+type OOMScore struct {
+	Score int    `json:"score"`
+	Adj   int    `json:"adj"`
+	Risk  string `json:"risk,omitempty"`
+}
+
+// OOMRisk judges whether the OOM killer is likely to take the process. The
+// OOM killer only runs when memory and swap are exhausted, so a high score
+// alone is at most a moderate risk; it takes system pressure as well for a
+// high one.
+func OOMRisk(proc *ProcessInfo, sys *SystemInfo) string {
+	if proc.OOM == nil || sys == nil {
+		return ""
+	}
+	if proc.OOM.Adj == -1000 {
+		return OOMRiskNone
+	}
+
+	points := 0
+	// Pressure: how close the system is to invoking the OOM killer
+	switch {
+	case sys.MemoryPercent >= 90:
+		points += 2
+	case sys.MemoryPercent >= 80:
+		points++
+	}
+	if sys.MemoryPercent >= 80 && (sys.SwapTotal == 0 || sys.SwapPercent >= 80) {
+		points++
+	}
+	// Victim: how likely this process is to be the one picked
+	switch {
+	case proc.OOM.Score >= 500:
+		points += 2
+	case proc.OOM.Score >= 200:
+		points++
+	}
+	if proc.MemoryPercent >= 25 {
+		points++
+	}
+
+	switch {
+	case points >= 4:
+		return OOMRiskHigh
+	case points >= 2:
+		return OOMRiskModerate
+	default:
+		return OOMRiskLow
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+// Power estimate sources
+const (
+	// PowerSourceRAPL apportions the CPU packages' measured draw, from
+	// Intel/AMD RAPL energy counters
+	PowerSourceRAPL = "rapl"
+	// PowerSourceCPUTime is the CPU time share alone, where no energy
+	// counters can be read
+	PowerSourceCPUTime = "cpu_time"
+)
+
+// PowerEstimate attributes part of the CPU's power draw to a process by its
+// share of the CPU time the host spent busy over the sampling window. It is
+// only an estimate: the packages' draw includes their idle floor and
+// uncore, and a CPU second costs more on a boosted core than a throttled
+// one. Watts and PackageWatts are nil without energy counters.
+type PowerEstimate struct {
+	// CPUShare is the process's percentage of the host's busy CPU time
+	CPUShare     float64  `json:"cpu_share"`
+	Watts        *float64 `json:"watts,omitempty"`
+	PackageWatts *float64 `json:"package_watts,omitempty"`
+	Source       string   `json:"source"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Roots other than a path, for ProcessInfo.Root
+const (
+	// RootChrooted is a chroot whose directory isn't reachable from
+	// inspektor's own root
+	RootChrooted = "chrooted"
+	// RootContainer is the root filesystem of a separate mount namespace
+	RootContainer = "container"
+)
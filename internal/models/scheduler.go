@@ -0,0 +1,39 @@
+package models
+
+import "fmt"
+
+// Scheduling policies, as sched_setscheduler(2) names them
+const (
+	SchedOther    = "SCHED_OTHER"
+	SchedFIFO     = "SCHED_FIFO"
+	SchedRR       = "SCHED_RR"
+	SchedBatch    = "SCHED_BATCH"
+	SchedIdle     = "SCHED_IDLE"
+	SchedDeadline = "SCHED_DEADLINE"
+)
+
+// Scheduler is how the kernel schedules the process's main thread. The
+// real-time policies, FIFO and RR, run ahead of every normal process at
+// RTPriority (1-99); the others share the CPUs by Nice.
+type Scheduler struct {
+	Policy     string `json:"policy"`
+	RTPriority int    `json:"rt_priority,omitempty"`
+	Nice       int    `json:"nice"`
+}
+
+// RealTime reports whether the policy preempts normal processes
+func (s *Scheduler) RealTime() bool {
+	return s.Policy == SchedFIFO || s.Policy == SchedRR
+}
+
+// String renders the policy with the priority that matters for it, e.g.
+// "SCHED_FIFO (rt prio 50)" or "SCHED_OTHER (nice 0)"
+func (s *Scheduler) String() string {
+	switch {
+	case s.RealTime():
+		return fmt.Sprintf("%s (rt prio %d)", s.Policy, s.RTPriority)
+	case s.Policy == SchedDeadline:
+		return s.Policy
+	}
+	return fmt.Sprintf("%s (nice %d)", s.Policy, s.Nice)
+}
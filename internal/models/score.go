@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scoreMetrics are the metrics a score expression can weigh. io counts open
+// files and connections together, the descriptor side of a process's IO.
+var scoreMetrics = map[string]func(p *ProcessInfo) float64{
+	"cpu":     func(p *ProcessInfo) float64 { return p.CPUPercent },
+	"mem":     func(p *ProcessInfo) float64 { return float64(p.MemoryRSS) },
+	"rss":     func(p *ProcessInfo) float64 { return float64(p.MemoryRSS) },
+	"threads": func(p *ProcessInfo) float64 { return float64(p.NumThreads) },
+	"conn":    func(p *ProcessInfo) float64 { return float64(p.Connections) },
+	"files":   func(p *ProcessInfo) float64 { return float64(p.OpenFiles) },
+	"io":      func(p *ProcessInfo) float64 { return float64(p.OpenFiles + p.Connections) },
+}
+
+// ScoreMetrics lists the metric names a score expression accepts
+var ScoreMetrics = []string{"cpu", "mem", "rss", "threads", "conn", "files", "io"}
+
+// composites are named score expressions
+var composites = map[string]string{
+	"pressure": "cpu+mem+io",
+}
+
+// ScoreTerm is one weighted metric of a score expression
+type ScoreTerm struct {
+	Metric string
+	Weight float64
+}
+
+// ScoreExpr ranks processes by several metrics at once, e.g. "cpu*2+mem",
+// so a process that is bad across the board ranks above one that is only
+// the worst at a single metric
+type ScoreExpr []ScoreTerm
+
+// ParseScoreExpr reads a sum of metrics, each optionally weighted
+// ("cpu*2+mem", "0.5*conn+files"), or a named composite such as "pressure"
+func ParseScoreExpr(spec string) (ScoreExpr, error) {
+	spec = strings.ToLower(strings.ReplaceAll(spec, " ", ""))
+	if composite, ok := composites[spec]; ok {
+		spec = composite
+	}
+
+	var expr ScoreExpr
+	for _, term := range strings.Split(spec, "+") {
+		metric, weight := term, 1.0
+		if left, right, found := strings.Cut(term, "*"); found {
+			metric, weight = left, 0
+			number := right
+			if _, known := scoreMetrics[right]; known {
+				metric, number = right, left
+			}
+			parsed, err := strconv.ParseFloat(number, 64)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid weight %q in %q", number, term)
+			}
+			weight = parsed
+		}
+		if _, known := scoreMetrics[metric]; !known {
+			return nil, fmt.Errorf("unknown metric %q (expected one of %s)", metric, strings.Join(ScoreMetrics, ", "))
+		}
+		expr = append(expr, ScoreTerm{Metric: metric, Weight: weight})
+	}
+	return expr, nil
+}
+
+// Scores rates each process. Every metric is first scaled by its largest
+// value among procs, so metrics with different units weigh in evenly before
+// the weights apply; a process that is the worst at everything scores the
+// sum of the weights.
+func (e ScoreExpr) Scores(procs []*ProcessInfo) []float64 {
+	scores := make([]float64, len(procs))
+	for _, term := range e {
+		value := scoreMetrics[term.Metric]
+		peak := 0.0
+		for _, proc := range procs {
+			peak = max(peak, value(proc))
+		}
+		if peak == 0 {
+			continue
+		}
+		for idx, proc := range procs {
+			scores[idx] += term.Weight * value(proc) / peak
+		}
+	}
+	return scores
+}
@@ -0,0 +1,37 @@
+package models
+
+// Thresholds are the limits the rule analyzer warns at. The report colors
+// the same values by them, so a highlighted value and a finding always go
+// together: for the two-level CPU and system memory limits, yellow is a
+// warning and red critical; for the rest, red is a warning and yellow means
+// past half-way to one.
+type Thresholds struct {
+	// CPUWarning and CPUCritical judge process CPU on the CPUMode scale
+	CPUWarning  float64
+	CPUCritical float64
+	// MemoryPercent is the share of system memory a process may use
+	MemoryPercent float32
+	OpenFiles     int
+	Connections   int
+	Children      int
+
+	SystemCPUWarning     float64
+	SystemCPUCritical    float64
+	SystemMemoryWarning  float64
+	SystemMemoryCritical float64
+}
+
+// DefaultThresholds apply to processes of no known type
+var DefaultThresholds = Thresholds{
+	CPUWarning:    50,
+	CPUCritical:   80,
+	MemoryPercent: 10,
+	OpenFiles:     1000,
+	Connections:   100,
+	Children:      50,
+
+	SystemCPUWarning:     75,
+	SystemCPUCritical:    90,
+	SystemMemoryWarning:  80,
+	SystemMemoryCritical: 90,
+}
@@ -0,0 +1,24 @@
+package models
+
+// Trend is the immediate direction of a metric, from two readings taken
+// moments apart. The empty trend is unknown.
+type Trend string
+
+const (
+	TrendUp     Trend = "up"
+	TrendDown   Trend = "down"
+	TrendSteady Trend = "steady"
+)
+
+// TrendOf compares two readings, calling a move smaller than minChange
+// steady
+func TrendOf(before, after, minChange float64) Trend {
+	switch {
+	case after-before > minChange:
+		return TrendUp
+	case before-after > minChange:
+		return TrendDown
+	default:
+		return TrendSteady
+	}
+}
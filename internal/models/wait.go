@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lockWaits are the kernel functions a thread sleeps in while waiting for a
+// lock: futexes, which back pthread mutexes and condition variables and most
+// language runtimes' locks, kernel mutexes and semaphores, and file locks.
+// Matched as prefixes, since their names vary between kernel versions.
+var lockWaits = []string{"futex", "__mutex_lock", "mutex_lock", "rt_mutex", "rwsem_down", "down_read", "down_write", "locks_lock", "flock_lock"}
+
+// LockWait reports whether channel is a wait for a lock
+func LockWait(channel string) bool {
+	for _, prefix := range lockWaits {
+		if strings.HasPrefix(channel, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitState is where the process's threads are blocked in the kernel, from
+// each thread's wait channel (/proc/<pid>/task/<tid>/wchan)
+type WaitState struct {
+	// Channel is the wait channel the most threads are blocked in, and
+	// Threads how many are
+	Channel string `json:"channel"`
+	Threads int    `json:"threads"`
+	// OnLocks counts the threads waiting for a lock, in any channel, out of
+	// the Total that were read
+	OnLocks int `json:"on_locks"`
+	Total   int `json:"total"`
+}
+
+// AllOnLocks reports whether every thread is waiting for a lock, which a
+// deadlocked process shows while it makes no progress
+func (w *WaitState) AllOnLocks() bool {
+	return w.Total > 0 && w.OnLocks == w.Total
+}
+
+// String renders the dominant channel, e.g. "futex_wait_queue (12 of 12
+// threads, all waiting for locks)"
+func (w *WaitState) String() string {
+	text := fmt.Sprintf("%s (%d of %d threads", w.Channel, w.Threads, w.Total)
+	if w.AllOnLocks() {
+		text += ", all waiting for locks"
+	}
+	return text + ")"
+}